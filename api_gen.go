@@ -62,6 +62,30 @@ func Expm1(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(expm1OpTy
 // Softplus performs a pointwise softplus.
 func Softplus(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(softplusOpType, a), a) }
 
+// GELU performs a pointwise gelu.
+func GELU(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(geluOpType, a), a) }
+
+// GELUTanh performs a pointwise gelutanh.
+func GELUTanh(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(geluTanhOpType, a), a) }
+
+// SiLU performs a pointwise silu.
+func SiLU(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(siluOpType, a), a) }
+
+// ELU performs a pointwise elu.
+func ELU(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(eluOpType, a), a) }
+
+// SELU performs a pointwise selu.
+func SELU(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(seluOpType, a), a) }
+
+// HardSigmoid performs a pointwise hardsigmoid.
+func HardSigmoid(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(hardSigmoidOpType, a), a) }
+
+// HardSwish performs a pointwise hardswish.
+func HardSwish(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(hardSwishOpType, a), a) }
+
+// Softsign performs a pointwise softsign.
+func Softsign(a *Node) (*Node, error) { return unaryOpNode(newElemUnaryOp(softsignOpType, a), a) }
+
 // Add performs a pointwise add operation.
 func Add(a, b *Node) (*Node, error) { return binOpNode(newElemBinOp(addOpType, a, b), a, b) }
 
@@ -125,7 +149,7 @@ func Ne(a, b *Node, retSame bool) (*Node, error) {
 	return binOpNode(op, a, b)
 }
 
-//Add performs a add. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastAdd performs a add. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastAdd(a, b *Node, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -134,7 +158,7 @@ func BroadcastAdd(a, b *Node, leftPattern, rightPattern []byte) (*Node, error) {
 	return Add(a2, b2)
 }
 
-//Sub performs a sub. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastSub performs a sub. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastSub(a, b *Node, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -143,7 +167,7 @@ func BroadcastSub(a, b *Node, leftPattern, rightPattern []byte) (*Node, error) {
 	return Sub(a2, b2)
 }
 
-//HadamardProd performs a hadamardprod. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastHadamardProd performs a hadamardprod. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastHadamardProd(a, b *Node, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -152,7 +176,7 @@ func BroadcastHadamardProd(a, b *Node, leftPattern, rightPattern []byte) (*Node,
 	return HadamardProd(a2, b2)
 }
 
-//HadamardDiv performs a hadamarddiv. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastHadamardDiv performs a hadamarddiv. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastHadamardDiv(a, b *Node, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -161,7 +185,7 @@ func BroadcastHadamardDiv(a, b *Node, leftPattern, rightPattern []byte) (*Node,
 	return HadamardDiv(a2, b2)
 }
 
-//Pow performs a pow. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastPow performs a pow. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastPow(a, b *Node, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -170,7 +194,7 @@ func BroadcastPow(a, b *Node, leftPattern, rightPattern []byte) (*Node, error) {
 	return Pow(a2, b2)
 }
 
-//Lt performs a lt. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastLt performs a lt. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastLt(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -179,7 +203,7 @@ func BroadcastLt(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*N
 	return Lt(a2, b2, retSame)
 }
 
-//Gt performs a gt. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastGt performs a gt. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastGt(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -188,7 +212,7 @@ func BroadcastGt(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*N
 	return Gt(a2, b2, retSame)
 }
 
-//Lte performs a lte. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastLte performs a lte. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastLte(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -197,7 +221,7 @@ func BroadcastLte(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*
 	return Lte(a2, b2, retSame)
 }
 
-//Gte performs a gte. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastGte performs a gte. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastGte(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -206,7 +230,7 @@ func BroadcastGte(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*
 	return Gte(a2, b2, retSame)
 }
 
-//Eq performs a eq. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastEq performs a eq. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastEq(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -215,7 +239,7 @@ func BroadcastEq(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*N
 	return Eq(a2, b2, retSame)
 }
 
-//Ne performs a ne. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
+// BroadcastNe performs a ne. The operation is precomposed with a broadcast such that the shapes matches before operations commence.
 func BroadcastNe(a, b *Node, retSame bool, leftPattern, rightPattern []byte) (*Node, error) {
 	a2, b2, err := Broadcast(a, b, NewBroadcastPattern(leftPattern, rightPattern))
 	if err != nil {
@@ -0,0 +1,129 @@
+package gorgonia
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorgonia.org/tensor"
+)
+
+// arenaSlack is how much larger than requested a reused buffer may be
+const arenaSlack = 2
+
+// ArenaStats are the statistics of the CPU arena of a tapeMachine. The sizes are in bytes.
+type ArenaStats struct {
+	Gets   int // the buffers handed out to registers
+	Allocs int // the buffers that had to be allocated, because no free buffer fit
+	Puts   int // the buffers given back
+
+	InUse     int64 // held by the registers
+	Free      int64 // kept for reuse
+	PeakInUse int64
+	Allocated int64 // allocated over the life of the arena
+}
+
+// Reuses returns the number of buffers that were handed out without being allocated.
+func (s ArenaStats) Reuses() int { return s.Gets - s.Allocs }
+
+func (s ArenaStats) String() string {
+	return fmt.Sprintf("gets %d, allocs %d, puts %d | in use %d, free %d, peak in use %d, allocated %d bytes",
+		s.Gets, s.Allocs, s.Puts, s.InUse, s.Free, s.PeakInUse, s.Allocated)
+}
+
+// arenaBuf is a buffer of the arena. data is the whole backing slice, of which the tensors handed out may use a prefix.
+type arenaBuf struct {
+	dt   tensor.Dtype
+	data interface{}
+	size int
+}
+
+func (b arenaBuf) bytes() int64 { return int64(b.size) * int64(b.dt.Size()) }
+
+// cpuArena recycles the backing memory of CPU tensors, so that a machine that runs many times does not allocate
+// new tensors for every run and leave the old ones to the garbage collector.
+//
+// The buffers are kept in free lists by Dtype. A request is served by the smallest free buffer that is large enough,
+// as long as it is not more than arenaSlack times larger than needed.
+type cpuArena struct {
+	free  map[tensor.Dtype][]arenaBuf
+	owned map[*tensor.Dense]arenaBuf
+	stats ArenaStats
+}
+
+func newCPUArena() *cpuArena {
+	return &cpuArena{
+		free:  make(map[tensor.Dtype][]arenaBuf),
+		owned: make(map[*tensor.Dense]arenaBuf),
+	}
+}
+
+// get hands out a tensor of the Dtype and shape, filled with zeroes like a newly allocated one.
+func (a *cpuArena) get(dt tensor.Dtype, shape tensor.Shape) *tensor.Dense {
+	size := shape.TotalSize()
+	buf, ok := a.reuse(dt, size)
+	if !ok {
+		buf = arenaBuf{dt: dt, data: reflect.MakeSlice(reflect.SliceOf(dt.Type), size, size).Interface(), size: size}
+		a.stats.Allocs++
+		a.stats.Allocated += buf.bytes()
+	}
+
+	backing := buf.data
+	if buf.size != size {
+		backing = reflect.ValueOf(buf.data).Slice(0, size).Interface()
+	}
+	t := tensor.New(tensor.WithShape(shape.Clone()...), tensor.WithBacking(backing))
+	if ok {
+		t.Zero()
+	}
+	a.owned[t] = buf
+
+	a.stats.Gets++
+	a.stats.InUse += buf.bytes()
+	if a.stats.InUse > a.stats.PeakInUse {
+		a.stats.PeakInUse = a.stats.InUse
+	}
+	return t
+}
+
+// reuse takes the best fitting buffer out of the free list
+func (a *cpuArena) reuse(dt tensor.Dtype, size int) (arenaBuf, bool) {
+	free := a.free[dt]
+	best := -1
+	for i, buf := range free {
+		if buf.size >= size && buf.size <= size*arenaSlack && (best < 0 || buf.size < free[best].size) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return arenaBuf{}, false
+	}
+	buf := free[best]
+	free[best] = free[len(free)-1]
+	a.free[dt] = free[:len(free)-1]
+	a.stats.Free -= buf.bytes()
+	return buf, true
+}
+
+// put gives back a tensor handed out by get. The tensors that the arena did not hand out are ignored.
+func (a *cpuArena) put(t *tensor.Dense) {
+	buf, ok := a.owned[t]
+	if !ok {
+		return
+	}
+	delete(a.owned, t)
+	a.free[buf.dt] = append(a.free[buf.dt], buf)
+
+	a.stats.Puts++
+	a.stats.InUse -= buf.bytes()
+	a.stats.Free += buf.bytes()
+}
+
+// drop hands a tensor handed out by get over to its holder: the arena forgets about its buffer.
+func (a *cpuArena) drop(t *tensor.Dense) {
+	buf, ok := a.owned[t]
+	if !ok {
+		return
+	}
+	delete(a.owned, t)
+	a.stats.InUse -= buf.bytes()
+}
@@ -5,6 +5,7 @@ import (
 	"runtime"
 	"testing"
 
+	. "gorgonia.org/gorgonia"
 	"gorgonia.org/tensor"
 )
 
@@ -33,5 +34,8 @@ func BenchmarkTapeMachineExecution(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		linregRun(m, c, machine, 100, false)
 	}
+	if a, ok := machine.(interface{ ArenaStats() ArenaStats }); ok {
+		b.Logf("arena: %v", a.ArenaStats())
+	}
 	machine.(io.Closer).Close()
 }
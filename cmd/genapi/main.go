@@ -29,6 +29,15 @@ var (
 	gopath, gorgonialoc, golgiloc string
 )
 
+// acronyms are the unary API names that should not be title cased
+var acronyms = map[string]string{
+	"Gelu":     "GELU",
+	"GeluTanh": "GELUTanh",
+	"Silu":     "SiLU",
+	"Elu":      "ELU",
+	"Selu":     "SELU",
+}
+
 var funcmap = template.FuncMap{
 	"lower": strings.ToLower,
 }
@@ -115,11 +124,19 @@ func generateUnary(outFile io.Writer) {
 
 	unaryNames := constTypes(file.Decls, "ʘUnaryOperatorType", "maxʘUnaryOperator")
 	for _, v := range unaryNames {
+		// the derivatives of activation functions are internal
+		if strings.HasSuffix(v, "DerivOpType") {
+			continue
+		}
 		apiName := strings.Title(strings.TrimSuffix(v, "OpType"))
-		// legacy issue
-		if apiName == "Ln" {
+		switch apiName {
+		case "Ln":
+			// legacy issue
 			apiName = "Log"
 		}
+		if acronym, ok := acronyms[apiName]; ok {
+			apiName = acronym
+		}
 		data := struct{ FnName, OpType string }{apiName, v}
 		unaryTemplate.Execute(outFile, data)
 	}
@@ -158,7 +158,7 @@ func (cg *codegenerator) addInstr(node *Node, instr tapeInstr) {
 	switch inst := instr.(type) {
 	case loadArg:
 		if dt, err = dtypeOf(node.t); err != nil {
-			panic(err)
+			panic(errors.Wrapf(err, dtypeExtractionFail+". Node: %s", node.t, node.provenance()))
 		}
 		d := instr.writes().device
 		if d != CPU {
@@ -176,7 +176,7 @@ func (cg *codegenerator) addInstr(node *Node, instr tapeInstr) {
 		}
 	case alloc:
 		if dt, err = dtypeOf(inst.t); err != nil {
-			panic(err)
+			panic(errors.Wrapf(err, dtypeExtractionFail+". Node: %s", inst.t, node.provenance()))
 		}
 
 		d := instr.writes().device
@@ -0,0 +1,150 @@
+package darknet
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Section is a [section] of a Darknet configuration file, such as [net] or [convolutional].
+type Section struct {
+	Type    string
+	Line    int // the line the section starts at, for error messages
+	Options map[string]string
+}
+
+// Config is a parsed Darknet configuration file.
+type Config struct {
+	Net    Section   // the [net] section
+	Layers []Section // the other sections, one per layer
+}
+
+// ParseConfig parses a Darknet configuration file. Comments start with '#' or ';'.
+func ParseConfig(r io.Reader) (*Config, error) {
+	var sections []Section
+	scanner := bufio.NewScanner(r)
+	var line int
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' || text[0] == ';' {
+			continue
+		}
+		if text[0] == '[' {
+			if text[len(text)-1] != ']' {
+				return nil, errors.Errorf("Line %d: malformed section header %q", line, text)
+			}
+			sections = append(sections, Section{
+				Type:    strings.TrimSpace(text[1 : len(text)-1]),
+				Line:    line,
+				Options: make(map[string]string),
+			})
+			continue
+		}
+		if len(sections) == 0 {
+			return nil, errors.Errorf("Line %d: option %q is outside of any section", line, text)
+		}
+		kv := strings.SplitN(text, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.Errorf("Line %d: expected an option of the form key=value. Got %q", line, text)
+		}
+		sections[len(sections)-1].Options[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "Unable to read the configuration")
+	}
+
+	if len(sections) == 0 || (sections[0].Type != "net" && sections[0].Type != "network") {
+		return nil, errors.New("The configuration must start with a [net] section")
+	}
+	return &Config{Net: sections[0], Layers: sections[1:]}, nil
+}
+
+// LoadConfig parses the Darknet configuration file at the given path.
+func LoadConfig(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cfg, err := ParseConfig(f)
+	return cfg, errors.Wrapf(err, "Unable to parse %q", path)
+}
+
+// String returns the value of an option, or def if the option is not set.
+func (s Section) String(key, def string) string {
+	if v, ok := s.Options[key]; ok {
+		return v
+	}
+	return def
+}
+
+// Int returns the value of an integer option, or def if the option is not set.
+func (s Section) Int(key string, def int) (int, error) {
+	v, ok := s.Options[key]
+	if !ok {
+		return def, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, s.errorf("%q must be an integer. Got %q", key, v)
+	}
+	return i, nil
+}
+
+// Float returns the value of a floating point option, or def if the option is not set.
+func (s Section) Float(key string, def float64) (float64, error) {
+	v, ok := s.Options[key]
+	if !ok {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, s.errorf("%q must be a number. Got %q", key, v)
+	}
+	return f, nil
+}
+
+// Ints returns the values of a comma separated list of integers, or nil if the option is not set.
+func (s Section) Ints(key string) ([]int, error) {
+	fs, err := s.Floats(key)
+	if err != nil || fs == nil {
+		return nil, err
+	}
+	retVal := make([]int, len(fs))
+	for i, f := range fs {
+		if f != float64(int(f)) {
+			return nil, s.errorf("%q must be a list of integers. Got %q", key, s.Options[key])
+		}
+		retVal[i] = int(f)
+	}
+	return retVal, nil
+}
+
+// Floats returns the values of a comma separated list of numbers, or nil if the option is not set.
+func (s Section) Floats(key string) ([]float64, error) {
+	v, ok := s.Options[key]
+	if !ok {
+		return nil, nil
+	}
+	var retVal []float64
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(item, 64)
+		if err != nil {
+			return nil, s.errorf("%q must be a list of numbers. Got %q", key, v)
+		}
+		retVal = append(retVal, f)
+	}
+	return retVal, nil
+}
+
+func (s Section) errorf(format string, args ...interface{}) error {
+	return errors.Errorf("[%s] at line %d: %s", s.Type, s.Line, errors.Errorf(format, args...))
+}
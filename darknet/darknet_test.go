@@ -0,0 +1,257 @@
+package darknet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/chewxy/math32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig(strings.NewReader(`
+# a comment
+[net]
+width=32
+height = 16
+; another comment
+
+[convolutional]
+filters=4
+anchors = 1,2, 3,4
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "net", cfg.Net.Type)
+	require.Len(t, cfg.Layers, 1)
+
+	s := cfg.Layers[0]
+	assert.Equal(t, "convolutional", s.Type)
+	assert.Equal(t, 8, s.Line)
+	filters, err := s.Int("filters", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 4, filters)
+	size, err := s.Int("size", 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, size)
+	anchors, err := s.Ints("anchors")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4}, anchors)
+	height, err := cfg.Net.Int("height", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 16, height)
+
+	bad := []string{
+		"",
+		"[convolutional]\nfilters=1",
+		"filters=1\n[net]",
+		"[net\nwidth=1",
+		"[net]\nwidth",
+	}
+	for _, b := range bad {
+		_, err = ParseConfig(strings.NewReader(b))
+		assert.Error(t, err, "%q", b)
+	}
+
+	cfg, err = ParseConfig(strings.NewReader("[net]\nwidth=abc\n"))
+	require.NoError(t, err)
+	_, err = cfg.Net.Int("width", 0)
+	assert.Error(t, err)
+}
+
+func TestBuild_TinyYOLOv3(t *testing.T) {
+	cfg, err := LoadConfig("../examples/tiny-yolo-v3-coco/data/yolov3-tiny.cfg")
+	require.NoError(t, err)
+	g := gorgonia.NewGraph()
+	net, err := Build(g, cfg, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, tensor.Shape{1, 3, 416, 416}, net.Input.Shape())
+	require.Len(t, net.Outputs, 2)
+	assert.Equal(t, tensor.Shape{1, 13 * 13 * 3, 85}, net.Outputs[0].Shape())
+	assert.Equal(t, tensor.Shape{1, 26 * 26 * 3, 85}, net.Outputs[1].Shape())
+	assert.Len(t, net.Learnables(), 2*13)
+
+	yolo := net.Layers[16]
+	assert.Equal(t, "yolo", yolo.Section.Type)
+	assert.Equal(t, 80, yolo.Classes)
+	assert.Equal(t, []float32{81, 82, 135, 169, 344, 319}, yolo.Anchors)
+}
+
+func TestBuild_TinyYOLOv2(t *testing.T) {
+	cfg, err := LoadConfig("../examples/tiny-yolo-v2-coco/model/yolov2-tiny.cfg")
+	require.NoError(t, err)
+	g := gorgonia.NewGraph()
+	net, err := Build(g, cfg, 1)
+	require.NoError(t, err)
+
+	require.Len(t, net.Outputs, 1)
+	assert.Equal(t, tensor.Shape{1, 425, 13, 13}, net.Outputs[0].Shape())
+	last := net.Layers[len(net.Layers)-1]
+	assert.Equal(t, "region", last.Section.Type)
+	assert.Len(t, last.Anchors, 10)
+}
+
+const testCfg = `[net]
+width=4
+height=4
+channels=1
+
+[convolutional]
+batch_normalize=1
+filters=2
+size=3
+stride=1
+pad=1
+activation=linear
+
+[convolutional]
+filters=2
+size=1
+stride=1
+activation=leaky
+
+[shortcut]
+from=-2
+activation=linear
+
+[route]
+layers=-1
+groups=2
+group_id=1
+
+[route]
+layers=-1,0
+
+[maxpool]
+size=2
+stride=2
+
+[upsample]
+stride=2
+`
+
+func TestBuild_Layers(t *testing.T) {
+	cfg, err := ParseConfig(strings.NewReader(testCfg))
+	require.NoError(t, err)
+	g := gorgonia.NewGraph()
+	net, err := Build(g, cfg, 2)
+	require.NoError(t, err)
+
+	shapes := []tensor.Shape{
+		{2, 2, 4, 4}, // conv
+		{2, 2, 4, 4}, // conv
+		{2, 2, 4, 4}, // shortcut
+		{2, 1, 4, 4}, // route with groups
+		{2, 3, 4, 4}, // route
+		{2, 3, 2, 2}, // maxpool
+		{2, 3, 4, 4}, // upsample
+	}
+	require.Len(t, net.Layers, len(shapes))
+	for i, l := range net.Layers {
+		assert.Equal(t, shapes[i], l.Output.Shape(), "%v", l)
+	}
+	require.Len(t, net.Outputs, 1)
+	assert.Equal(t, net.Layers[6].Output, net.Outputs[0])
+	assert.True(t, net.Layers[0].BatchNormalize)
+	assert.False(t, net.Layers[1].BatchNormalize)
+
+	bad := []string{
+		"[net]\n[softmax]",
+		"[net]\n[convolutional]\nactivation=gelu",
+		"[net]\n[convolutional]\ngroups=2",
+		"[net]\n[route]\nlayers=-1",
+		"[net]\nchannels=1\n[convolutional]\nfilters=2\n[shortcut]\nfrom=-2",
+		"[net]\n[convolutional]\nfilters=4\n[yolo]\nmask=0\nanchors=1,1,2,2\nclasses=2",
+	}
+	for _, b := range bad {
+		cfg, err := ParseConfig(strings.NewReader(b))
+		require.NoError(t, err)
+		_, err = Build(gorgonia.NewGraph(), cfg, 1)
+		assert.Error(t, err, "%q", b)
+	}
+}
+
+func writeWeights(t *testing.T, major, minor int32, vals ...[]float32) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, []int32{major, minor, 0})
+	if major*10+minor >= 2 {
+		binary.Write(&buf, binary.LittleEndian, uint64(100))
+	} else {
+		binary.Write(&buf, binary.LittleEndian, uint32(100))
+	}
+	for _, v := range vals {
+		require.NoError(t, binary.Write(&buf, binary.LittleEndian, v))
+	}
+	return buf.Bytes()
+}
+
+func TestLoadWeights(t *testing.T) {
+	cfg, err := ParseConfig(strings.NewReader(`[net]
+width=2
+height=2
+channels=1
+
+[convolutional]
+batch_normalize=1
+filters=2
+size=1
+stride=1
+activation=linear
+
+[convolutional]
+filters=1
+size=1
+stride=1
+activation=linear
+`))
+	require.NoError(t, err)
+	g := gorgonia.NewGraph()
+	net, err := Build(g, cfg, 1)
+	require.NoError(t, err)
+
+	weights := [][]float32{
+		{1, 2},   // biases (β)
+		{2, 3},   // γ
+		{1, -1},  // μ
+		{4, 9},   // σ²
+		{1, 2},   // kernels
+		{0.5},    // biases
+		{1, 0.5}, // kernels
+	}
+	h, err := net.LoadWeights(bytes.NewReader(writeWeights(t, 0, 2, weights...)))
+	require.NoError(t, err)
+	assert.Equal(t, WeightsHeader{Major: 0, Minor: 2, Revision: 0, Seen: 100}, *h)
+
+	input := tensor.New(tensor.WithShape(1, 1, 2, 2), tensor.WithBacking([]float32{1, 2, 3, 4}))
+	require.NoError(t, gorgonia.Let(net.Input, input))
+	m := gorgonia.NewTapeMachine(g)
+	defer m.Close()
+	require.NoError(t, m.RunAll())
+
+	bn := func(x, w, beta, gamma, mu, sigma2 float32) float32 {
+		return gamma*(w*x-mu)/math32.Sqrt(sigma2+bnEpsilon) + beta
+	}
+	out := net.Outputs[0].Value().Data().([]float32)
+	for i, x := range []float32{1, 2, 3, 4} {
+		a := bn(x, 1, 1, 2, 1, 4)
+		b := bn(x, 2, 2, 3, -1, 9)
+		assert.InDelta(t, a+0.5*b+0.5, out[i], 1e-4)
+	}
+
+	// version 0.1 stores the number of images seen in 32 bits
+	h, err = net.LoadWeights(bytes.NewReader(writeWeights(t, 0, 1, weights...)))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), h.Seen)
+
+	_, err = net.LoadWeights(bytes.NewReader(writeWeights(t, 0, 2, weights[:6]...)))
+	assert.Error(t, err)
+	_, err = net.LoadWeights(bytes.NewReader(writeWeights(t, 0, 2, append(weights, []float32{1})...)))
+	assert.Error(t, err)
+	_, err = net.LoadWeights(bytes.NewReader([]byte{1, 2}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,378 @@
+// Package darknet builds expression graphs from Darknet configuration files, and loads Darknet's .weights files into them.
+//
+// The supported layers are [convolutional], [maxpool], [route], [shortcut], [upsample], [dropout], [yolo] and [region].
+// The [yolo] layers are built with the YOLOv3 op. A [region] layer (YOLOv2) passes its input through unchanged,
+// and its anchors are left to the caller to decode.
+//
+// Networks are built in tensor.Float32, as that is what Darknet's weights are stored in:
+//		cfg, err := darknet.LoadConfig("yolov3-tiny.cfg")
+//		...
+//		net, err := darknet.Build(g, cfg, 1)
+//		...
+//		if _, err = net.LoadWeightsFile("yolov3-tiny.weights"); err != nil { ... }
+//		gorgonia.Let(net.Input, image)
+//		// run the graph, then read net.Outputs
+package darknet
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// Layer is a layer of a Network.
+type Layer struct {
+	Index   int
+	Section Section
+	Output  *gorgonia.Node
+
+	// the learnables of a convolutional layer
+	Weights        *gorgonia.Node // (filters, channels, size, size)
+	Biases         *gorgonia.Node // (1, filters, 1, 1)
+	BatchNormalize bool
+
+	// the anchors of a yolo or region layer
+	Anchors []float32
+	Classes int
+}
+
+func (l *Layer) String() string {
+	return fmt.Sprintf("%3d %-14s %v", l.Index, l.Section.Type, l.Output.Shape())
+}
+
+// Network is an expression graph built from a Darknet configuration.
+type Network struct {
+	Input   *gorgonia.Node // the (batch, channels, height, width) input
+	Layers  []*Layer
+	Outputs []*gorgonia.Node // the outputs of the yolo and region layers. If there are none, the output of the last layer.
+
+	Width, Height, Channels int
+}
+
+// Build builds a network from a Darknet configuration, with an input of the given batch size.
+// The convolutions are initialized with GlorotN, and the biases with zeroes, until weights are loaded.
+func Build(g *gorgonia.ExprGraph, cfg *Config, batchSize int) (*Network, error) {
+	net := new(Network)
+	var err error
+	if net.Width, err = cfg.Net.Int("width", 416); err != nil {
+		return nil, err
+	}
+	if net.Height, err = cfg.Net.Int("height", 416); err != nil {
+		return nil, err
+	}
+	if net.Channels, err = cfg.Net.Int("channels", 3); err != nil {
+		return nil, err
+	}
+	net.Input = gorgonia.NewTensor(g, tensor.Float32, 4, gorgonia.WithShape(batchSize, net.Channels, net.Height, net.Width), gorgonia.WithName("input"))
+
+	input := net.Input
+	for i, s := range cfg.Layers {
+		l := &Layer{Index: i, Section: s}
+		if err = net.build(g, l, input); err != nil {
+			return nil, errors.Wrapf(err, "Unable to build layer %d ([%s] at line %d)", i, s.Type, s.Line)
+		}
+		net.Layers = append(net.Layers, l)
+		input = l.Output
+		if s.Type == "yolo" || s.Type == "region" {
+			net.Outputs = append(net.Outputs, l.Output)
+		}
+	}
+	if len(net.Layers) == 0 {
+		return nil, errors.New("The configuration has no layers")
+	}
+	if len(net.Outputs) == 0 {
+		net.Outputs = []*gorgonia.Node{input}
+	}
+	return net, nil
+}
+
+func (net *Network) build(g *gorgonia.ExprGraph, l *Layer, input *gorgonia.Node) (err error) {
+	s := l.Section
+	switch s.Type {
+	case "convolutional", "conv":
+		l.Output, err = net.convolutional(g, l, input)
+	case "maxpool":
+		l.Output, err = maxpool(s, input)
+	case "route":
+		l.Output, err = net.route(l)
+	case "shortcut":
+		l.Output, err = net.shortcut(l, input)
+	case "upsample":
+		var stride int
+		if stride, err = s.Int("stride", 2); err != nil {
+			return err
+		}
+		l.Output, err = gorgonia.Upsample2D(input, stride)
+	case "dropout":
+		// dropout is a no-op at inference time
+		l.Output = input
+	case "yolo":
+		l.Output, err = net.yolo(l, input)
+	case "region":
+		l.Output, err = region(l, input)
+	default:
+		return errors.Errorf("Unsupported layer type %q", s.Type)
+	}
+	return err
+}
+
+func (net *Network) convolutional(g *gorgonia.ExprGraph, l *Layer, input *gorgonia.Node) (*gorgonia.Node, error) {
+	s := l.Section
+	filters, err := s.Int("filters", 1)
+	if err != nil {
+		return nil, err
+	}
+	size, err := s.Int("size", 1)
+	if err != nil {
+		return nil, err
+	}
+	stride, err := s.Int("stride", 1)
+	if err != nil {
+		return nil, err
+	}
+	pad, err := s.Int("pad", 0)
+	if err != nil {
+		return nil, err
+	}
+	padding, err := s.Int("padding", 0)
+	if err != nil {
+		return nil, err
+	}
+	if pad != 0 {
+		padding = size / 2
+	}
+	groups, err := s.Int("groups", 1)
+	if err != nil {
+		return nil, err
+	}
+	if groups != 1 {
+		return nil, s.errorf("grouped convolutions are not supported")
+	}
+	bn, err := s.Int("batch_normalize", 0)
+	if err != nil {
+		return nil, err
+	}
+	l.BatchNormalize = bn != 0
+
+	channels := input.Shape()[1]
+	l.Weights = gorgonia.NewTensor(g, tensor.Float32, 4, gorgonia.WithShape(filters, channels, size, size), gorgonia.WithName(fmt.Sprintf("conv_%d", l.Index)), gorgonia.WithInit(gorgonia.GlorotN(1)))
+	l.Biases = gorgonia.NewTensor(g, tensor.Float32, 4, gorgonia.WithShape(1, filters, 1, 1), gorgonia.WithName(fmt.Sprintf("bias_%d", l.Index)), gorgonia.WithInit(gorgonia.Zeroes()))
+
+	retVal, err := gorgonia.Conv2d(input, l.Weights, tensor.Shape{size, size}, []int{padding, padding}, []int{stride, stride}, []int{1, 1})
+	if err != nil {
+		return nil, err
+	}
+	if retVal, err = gorgonia.Auto(gorgonia.BroadcastAdd, retVal, l.Biases); err != nil {
+		return nil, err
+	}
+	return activation(s, retVal)
+}
+
+func activation(s Section, x *gorgonia.Node) (*gorgonia.Node, error) {
+	switch act := s.String("activation", "linear"); act {
+	case "linear":
+		return x, nil
+	case "leaky":
+		return gorgonia.LeakyRelu(x, 0.1)
+	case "relu":
+		return gorgonia.Rectify(x)
+	case "logistic":
+		return gorgonia.Sigmoid(x)
+	case "tanh":
+		return gorgonia.Tanh(x)
+	case "swish":
+		return gorgonia.SiLU(x)
+	case "mish":
+		return gorgonia.Mish(x)
+	default:
+		return nil, s.errorf("unsupported activation %q", act)
+	}
+}
+
+// maxpool pads the input the way Darknet does: by size-1 in total, split between the top/left and the bottom/right.
+func maxpool(s Section, input *gorgonia.Node) (*gorgonia.Node, error) {
+	size, err := s.Int("size", 1)
+	if err != nil {
+		return nil, err
+	}
+	stride, err := s.Int("stride", size)
+	if err != nil {
+		return nil, err
+	}
+	padding, err := s.Int("padding", size-1)
+	if err != nil {
+		return nil, err
+	}
+	before, after := padding/2, padding-padding/2
+	return gorgonia.MaxPool2D(input, tensor.Shape{size, size}, []int{before, after, before, after}, []int{stride, stride})
+}
+
+// layer returns the output of the layer at the given index, which is relative to the current layer if it is negative
+func (net *Network) layer(l *Layer, idx int) (*Layer, error) {
+	if idx < 0 {
+		idx += l.Index
+	}
+	if idx < 0 || idx >= l.Index {
+		return nil, l.Section.errorf("layer %d is not a preceding layer", idx)
+	}
+	return net.Layers[idx], nil
+}
+
+// route concatenates the outputs of the given layers along the channels. With groups, only the group_id-th group of channels of a single layer is taken.
+func (net *Network) route(l *Layer) (*gorgonia.Node, error) {
+	s := l.Section
+	idxs, err := s.Ints("layers")
+	if err != nil {
+		return nil, err
+	}
+	if len(idxs) == 0 {
+		return nil, s.errorf("no layers to route")
+	}
+	groups, err := s.Int("groups", 1)
+	if err != nil {
+		return nil, err
+	}
+	groupID, err := s.Int("group_id", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var ns []*gorgonia.Node
+	for _, idx := range idxs {
+		src, err := net.layer(l, idx)
+		if err != nil {
+			return nil, err
+		}
+		n := src.Output
+		if groups > 1 {
+			c := n.Shape()[1] / groups
+			shp := n.Shape().Clone()
+			shp[1] = c
+			if n, err = gorgonia.Slice(n, nil, gorgonia.S(groupID*c, (groupID+1)*c)); err != nil {
+				return nil, err
+			}
+			// a group of a single channel is sliced away as a dimension
+			if n.Dims() != len(shp) {
+				if n, err = gorgonia.Reshape(n, shp); err != nil {
+					return nil, err
+				}
+			}
+		}
+		ns = append(ns, n)
+	}
+	if len(ns) == 1 {
+		return ns[0], nil
+	}
+	return gorgonia.Concat(1, ns...)
+}
+
+func (net *Network) shortcut(l *Layer, input *gorgonia.Node) (*gorgonia.Node, error) {
+	s := l.Section
+	from, err := s.Int("from", 0)
+	if err != nil {
+		return nil, err
+	}
+	src, err := net.layer(l, from)
+	if err != nil {
+		return nil, err
+	}
+	if !src.Output.Shape().Eq(input.Shape()) {
+		return nil, s.errorf("cannot add the output of layer %d of shape %v to an input of shape %v", src.Index, src.Output.Shape(), input.Shape())
+	}
+	retVal, err := gorgonia.Add(input, src.Output)
+	if err != nil {
+		return nil, err
+	}
+	return activation(s, retVal)
+}
+
+// anchors returns the anchors selected by the mask, if any
+func anchors(l *Layer) ([]float32, []int, error) {
+	s := l.Section
+	all, err := s.Floats("anchors")
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(all)%2 != 0 {
+		return nil, nil, s.errorf("anchors must come in pairs. Got %d numbers", len(all))
+	}
+	mask, err := s.Ints("mask")
+	if err != nil {
+		return nil, nil, err
+	}
+	if mask == nil {
+		for i := 0; i < len(all)/2; i++ {
+			mask = append(mask, i)
+		}
+	}
+	var retVal []float32
+	for _, m := range mask {
+		if m < 0 || m >= len(all)/2 {
+			return nil, nil, s.errorf("mask %d is out of range of %d anchors", m, len(all)/2)
+		}
+		retVal = append(retVal, float32(all[2*m]), float32(all[2*m+1]))
+	}
+	return retVal, mask, nil
+}
+
+func (net *Network) yolo(l *Layer, input *gorgonia.Node) (*gorgonia.Node, error) {
+	s := l.Section
+	var err error
+	if l.Classes, err = s.Int("classes", 20); err != nil {
+		return nil, err
+	}
+	ignoreThresh, err := s.Float("ignore_thresh", 0.5)
+	if err != nil {
+		return nil, err
+	}
+	var mask []int
+	if l.Anchors, mask, err = anchors(l); err != nil {
+		return nil, err
+	}
+	if want := len(mask) * (5 + l.Classes); input.Shape()[1] != want {
+		return nil, s.errorf("expected an input of %d channels for %d anchors and %d classes. Got %d", want, len(mask), l.Classes, input.Shape()[1])
+	}
+
+	// the YOLOv3 op is given the selected anchors, so its masks index them directly
+	idxs := make([]int, len(mask))
+	for i := range idxs {
+		idxs[i] = i
+	}
+	return gorgonia.YOLOv3(input, l.Anchors, idxs, net.Width, l.Classes, float32(ignoreThresh))
+}
+
+func region(l *Layer, input *gorgonia.Node) (*gorgonia.Node, error) {
+	s := l.Section
+	var err error
+	if l.Classes, err = s.Int("classes", 20); err != nil {
+		return nil, err
+	}
+	if l.Anchors, _, err = anchors(l); err != nil {
+		return nil, err
+	}
+	num, err := s.Int("num", len(l.Anchors)/2)
+	if err != nil {
+		return nil, err
+	}
+	coords, err := s.Int("coords", 4)
+	if err != nil {
+		return nil, err
+	}
+	if want := num * (coords + 1 + l.Classes); input.Shape()[1] != want {
+		return nil, s.errorf("expected an input of %d channels for %d anchors and %d classes. Got %d", want, num, l.Classes, input.Shape()[1])
+	}
+	return input, nil
+}
+
+// Learnables returns the weights and biases of the convolutional layers.
+func (net *Network) Learnables() gorgonia.Nodes {
+	var retVal gorgonia.Nodes
+	for _, l := range net.Layers {
+		if l.Weights != nil {
+			retVal = append(retVal, l.Weights, l.Biases)
+		}
+	}
+	return retVal
+}
@@ -0,0 +1,125 @@
+package darknet
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+
+	"github.com/chewxy/math32"
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// bnEpsilon is the epsilon Darknet uses when normalizing
+const bnEpsilon = 0.000001
+
+// WeightsHeader is the header of a .weights file.
+type WeightsHeader struct {
+	Major, Minor, Revision int32
+	Seen                   uint64 // the number of images the network was trained on
+}
+
+// LoadWeights reads a Darknet .weights file into the convolutional layers of the network.
+//
+// Batch normalization is folded into the weights and biases of the convolutions, so the network is ready for inference.
+// The file must hold exactly the weights that the network needs.
+func (net *Network) LoadWeights(r io.Reader) (*WeightsHeader, error) {
+	br := bufio.NewReader(r)
+	h := new(WeightsHeader)
+	var version [3]int32
+	if err := binary.Read(br, binary.LittleEndian, &version); err != nil {
+		return nil, errors.Wrap(err, "Unable to read the header of the weights")
+	}
+	h.Major, h.Minor, h.Revision = version[0], version[1], version[2]
+	// from version 0.2 on, the number of images seen is stored in 64 bits. The bounds are Darknet's own sanity checks.
+	if h.Major*10+h.Minor >= 2 && h.Major < 1000 && h.Minor < 1000 {
+		if err := binary.Read(br, binary.LittleEndian, &h.Seen); err != nil {
+			return nil, errors.Wrap(err, "Unable to read the header of the weights")
+		}
+	} else {
+		var seen uint32
+		if err := binary.Read(br, binary.LittleEndian, &seen); err != nil {
+			return nil, errors.Wrap(err, "Unable to read the header of the weights")
+		}
+		h.Seen = uint64(seen)
+	}
+
+	for _, l := range net.Layers {
+		if l.Weights == nil {
+			continue
+		}
+		if err := l.loadWeights(br); err != nil {
+			return nil, errors.Wrapf(err, "Unable to load the weights of layer %d", l.Index)
+		}
+	}
+	if _, err := br.ReadByte(); err != io.EOF {
+		return nil, errors.New("The weights file has more weights than the network")
+	}
+	return h, nil
+}
+
+// LoadWeightsFile reads the .weights file at the given path. See LoadWeights.
+func (net *Network) LoadWeightsFile(path string) (*WeightsHeader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h, err := net.LoadWeights(f)
+	return h, errors.Wrapf(err, "Unable to read %q", path)
+}
+
+func (l *Layer) loadWeights(r io.Reader) error {
+	shp := l.Weights.Shape()
+	filters := shp[0]
+	read := func(n int) ([]float32, error) {
+		retVal := make([]float32, n)
+		if err := binary.Read(r, binary.LittleEndian, retVal); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		return retVal, nil
+	}
+
+	biases, err := read(filters)
+	if err != nil {
+		return err
+	}
+	var gammas, means, vars []float32
+	if l.BatchNormalize {
+		if gammas, err = read(filters); err != nil {
+			return err
+		}
+		if means, err = read(filters); err != nil {
+			return err
+		}
+		if vars, err = read(filters); err != nil {
+			return err
+		}
+	}
+	kernels, err := read(shp.TotalSize())
+	if err != nil {
+		return err
+	}
+
+	if l.BatchNormalize {
+		// γ(Wx - μ)/√(σ²+ε) + β = (γ/√(σ²+ε))Wx + β - γμ/√(σ²+ε)
+		size := shp.TotalSize() / filters
+		for f := 0; f < filters; f++ {
+			scale := gammas[f] / math32.Sqrt(vars[f]+bnEpsilon)
+			biases[f] -= means[f] * scale
+			for j := f * size; j < (f+1)*size; j++ {
+				kernels[j] *= scale
+			}
+		}
+	}
+
+	if err = gorgonia.Let(l.Weights, tensor.New(tensor.WithShape(shp...), tensor.WithBacking(kernels))); err != nil {
+		return err
+	}
+	return gorgonia.Let(l.Biases, tensor.New(tensor.WithShape(l.Biases.Shape()...), tensor.WithBacking(biases)))
+}
@@ -0,0 +1,156 @@
+package data
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// CIFAR images are 3×32×32, stored channel by channel
+const (
+	cifarChannels = 3
+	cifarSize     = 32
+	cifarPixels   = cifarChannels * cifarSize * cifarSize
+)
+
+// CIFARVariant says which flavour of the CIFAR binary format a file is in, and for CIFAR-100, which labels to use.
+type CIFARVariant byte
+
+const (
+	CIFAR10        CIFARVariant = iota // records are a label and the pixels
+	CIFAR100Coarse                     // records are a coarse label, a fine label and the pixels. The coarse labels (20 superclasses) are used.
+	CIFAR100Fine                       // records are a coarse label, a fine label and the pixels. The fine labels (100 classes) are used.
+)
+
+func (v CIFARVariant) labelBytes() int {
+	if v == CIFAR10 {
+		return 1
+	}
+	return 2
+}
+
+// CIFARReader reads the binary versions of CIFAR-10 and CIFAR-100 record by record.
+type CIFARReader struct {
+	r       io.Reader
+	variant CIFARVariant
+	buf     []byte
+}
+
+// NewCIFARReader creates a new CIFARReader.
+func NewCIFARReader(r io.Reader, variant CIFARVariant) *CIFARReader {
+	return &CIFARReader{
+		r:       r,
+		variant: variant,
+		buf:     make([]byte, variant.labelBytes()+cifarPixels),
+	}
+}
+
+// Next reads the next n records (or fewer, at the end of the file). The images are returned as a (n, 3, 32, 32) tensor of the given Dtype,
+// with the raw pixel values in [0, 255]. The labels are returned as a (n) tensor of tensor.Int.
+// It returns io.EOF when all the records have been read.
+func (r *CIFARReader) Next(n int, dt tensor.Dtype) (images, labels *tensor.Dense, err error) {
+	var imgs *buffer
+	if imgs, err = newBuffer(dt, n*cifarPixels); err != nil {
+		return nil, nil, err
+	}
+	lbls := make([]int, n)
+
+	lb := r.variant.labelBytes()
+	var read int
+	for ; read < n; read++ {
+		if _, err = io.ReadFull(r.r, r.buf); err != nil {
+			if err == io.EOF {
+				err = nil
+				break
+			}
+			return nil, nil, errors.Wrap(err, "Unable to read a CIFAR record")
+		}
+		switch r.variant {
+		case CIFAR100Fine:
+			lbls[read] = int(r.buf[1])
+		default:
+			lbls[read] = int(r.buf[0])
+		}
+		for j, px := range r.buf[lb:] {
+			imgs.setInt(read*cifarPixels+j, int64(px))
+		}
+	}
+	if read == 0 {
+		return nil, nil, io.EOF
+	}
+	if read < n {
+		imgs = imgs.slice(read * cifarPixels)
+		lbls = lbls[:read]
+	}
+	images = imgs.tensor(read, cifarChannels, cifarSize, cifarSize)
+	labels = tensor.New(tensor.WithShape(read), tensor.WithBacking(lbls))
+	return images, labels, nil
+}
+
+// ReadCIFAR reads all the records of a CIFAR binary file.
+func ReadCIFAR(r io.Reader, variant CIFARVariant, dt tensor.Dtype) (images, labels *tensor.Dense, err error) {
+	cr := NewCIFARReader(r, variant)
+	const chunk = 1000
+	var imgChunks, lblChunks []*tensor.Dense
+	for {
+		imgs, lbls, err := cr.Next(chunk, dt)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		imgChunks = append(imgChunks, imgs)
+		lblChunks = append(lblChunks, lbls)
+	}
+	if len(imgChunks) == 0 {
+		return nil, nil, errors.New("CIFAR file has no records")
+	}
+	if images, err = concat(imgChunks); err != nil {
+		return nil, nil, err
+	}
+	if labels, err = concat(lblChunks); err != nil {
+		return nil, nil, err
+	}
+	return images, labels, nil
+}
+
+// LoadCIFAR loads one or more CIFAR binary files (say, data_batch_1.bin to data_batch_5.bin) into a TensorDataset
+// of images and labels, as read by (*CIFARReader).Next.
+func LoadCIFAR(variant CIFARVariant, dt tensor.Dtype, paths ...string) (*TensorDataset, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("No CIFAR files to load")
+	}
+	var imgs, lbls []*tensor.Dense
+	for _, path := range paths {
+		f, err := Open(path)
+		if err != nil {
+			return nil, err
+		}
+		images, labels, err := ReadCIFAR(f, variant, dt)
+		f.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to read %q", path)
+		}
+		imgs = append(imgs, images)
+		lbls = append(lbls, labels)
+	}
+	images, err := concat(imgs)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := concat(lbls)
+	if err != nil {
+		return nil, err
+	}
+	return NewTensorDataset(images, labels)
+}
+
+// concat concatenates the tensors along the first axis
+func concat(ts []*tensor.Dense) (*tensor.Dense, error) {
+	if len(ts) == 1 {
+		return ts[0], nil
+	}
+	return ts[0].Concat(0, ts[1:]...)
+}
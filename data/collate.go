@@ -0,0 +1,68 @@
+package data
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// CollateFunc collates the examples of a batch into one tensor per field.
+type CollateFunc func(examples [][]tensor.Tensor) ([]*tensor.Dense, error)
+
+// Collate is the default CollateFunc. It stacks each field of the examples along a new first axis,
+// so a batch of n examples whose field has shape (a, b) is collated into a tensor of shape (n, a, b).
+// Scalar fields (say, class labels) are collated into vectors.
+func Collate(examples [][]tensor.Tensor) ([]*tensor.Dense, error) {
+	if len(examples) == 0 {
+		return nil, errors.New("Cannot collate an empty batch")
+	}
+	fields := len(examples[0])
+	retVal := make([]*tensor.Dense, fields)
+	for f := 0; f < fields; f++ {
+		first := examples[0][f]
+		shp := first.Shape()
+		size := shp.TotalSize()
+
+		batchShape := append(tensor.Shape{len(examples)}, shp...)
+		batch := tensor.New(tensor.Of(first.Dtype()), tensor.WithShape(batchShape...))
+		dst := reflect.ValueOf(batch.Data())
+		for i, ex := range examples {
+			if len(ex) != fields {
+				return nil, errors.Errorf("Example %d of the batch has %d fields. Expected %d", i, len(ex), fields)
+			}
+			t := ex[f]
+			if t.Dtype() != first.Dtype() {
+				return nil, errors.Errorf("Field %d of example %d has Dtype %v. Expected %v", f, i, t.Dtype(), first.Dtype())
+			}
+			if !t.Shape().Eq(shp) {
+				return nil, errors.Errorf("Field %d of example %d has shape %v. Expected %v", f, i, t.Shape(), shp)
+			}
+			if err := copyInto(dst.Slice(i*size, (i+1)*size), t); err != nil {
+				return nil, errors.Wrapf(err, "Unable to collate field %d of example %d", f, i)
+			}
+		}
+		retVal[f] = batch
+	}
+	return retVal, nil
+}
+
+// copyInto copies the data of t into dst, which is a slice of the same length.
+func copyInto(dst reflect.Value, t tensor.Tensor) error {
+	if d, ok := t.(*tensor.Dense); ok && d.IsView() {
+		t = d.Materialize()
+	}
+	src := reflect.ValueOf(t.Data())
+	switch {
+	case src.Kind() == reflect.Slice:
+		if src.Len() < dst.Len() {
+			return errors.Errorf("Expected %d values. Got %d", dst.Len(), src.Len())
+		}
+		reflect.Copy(dst, src.Slice(0, dst.Len()))
+	case dst.Len() == 1:
+		dst.Index(0).Set(src)
+	default:
+		return errors.Errorf("Expected %d values. Got a scalar", dst.Len())
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package data
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// buffer is a flat backing of a requested Dtype that the readers fill value by value, converting the values they read as they go.
+type buffer struct {
+	dt   tensor.Dtype
+	data interface{}
+}
+
+func newBuffer(dt tensor.Dtype, n int) (*buffer, error) {
+	var data interface{}
+	switch dt {
+	case tensor.Float64:
+		data = make([]float64, n)
+	case tensor.Float32:
+		data = make([]float32, n)
+	case tensor.Int:
+		data = make([]int, n)
+	case tensor.Int64:
+		data = make([]int64, n)
+	case tensor.Int32:
+		data = make([]int32, n)
+	case tensor.Int16:
+		data = make([]int16, n)
+	case tensor.Int8:
+		data = make([]int8, n)
+	case tensor.Uint8:
+		data = make([]uint8, n)
+	case tensor.Uint16:
+		data = make([]uint16, n)
+	case tensor.Uint32:
+		data = make([]uint32, n)
+	case tensor.Uint64:
+		data = make([]uint64, n)
+	case tensor.Bool:
+		data = make([]bool, n)
+	default:
+		return nil, errors.Errorf("Cannot read values into a tensor of %v", dt)
+	}
+	return &buffer{dt: dt, data: data}, nil
+}
+
+func (b *buffer) isFloat() bool { return b.dt == tensor.Float64 || b.dt == tensor.Float32 }
+
+// setFloat sets the ith value. Floats are truncated when the buffer holds integers.
+func (b *buffer) setFloat(i int, v float64) {
+	switch data := b.data.(type) {
+	case []float64:
+		data[i] = v
+	case []float32:
+		data[i] = float32(v)
+	case []bool:
+		data[i] = v != 0
+	default:
+		b.setInt(i, int64(v))
+	}
+}
+
+// setInt sets the ith value.
+func (b *buffer) setInt(i int, v int64) {
+	switch data := b.data.(type) {
+	case []float64:
+		data[i] = float64(v)
+	case []float32:
+		data[i] = float32(v)
+	case []int:
+		data[i] = int(v)
+	case []int64:
+		data[i] = v
+	case []int32:
+		data[i] = int32(v)
+	case []int16:
+		data[i] = int16(v)
+	case []int8:
+		data[i] = int8(v)
+	case []uint8:
+		data[i] = uint8(v)
+	case []uint16:
+		data[i] = uint16(v)
+	case []uint32:
+		data[i] = uint32(v)
+	case []uint64:
+		data[i] = uint64(v)
+	case []bool:
+		data[i] = v != 0
+	}
+}
+
+// setUint sets the ith value. It exists so that large uint64s aren't mangled when read into a []uint64.
+func (b *buffer) setUint(i int, v uint64) {
+	if data, ok := b.data.([]uint64); ok {
+		data[i] = v
+		return
+	}
+	if b.isFloat() {
+		b.setFloat(i, float64(v))
+		return
+	}
+	b.setInt(i, int64(v))
+}
+
+// parse parses s as a value of the buffer's Dtype and sets the ith value.
+func (b *buffer) parse(i int, s string) error {
+	switch {
+	case b.isFloat():
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		b.setFloat(i, v)
+	case b.dt == tensor.Bool:
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		b.data.([]bool)[i] = v
+	case b.dt == tensor.Uint64:
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		b.setUint(i, v)
+	default:
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		b.setInt(i, v)
+	}
+	return nil
+}
+
+// tensor wraps the backing in a *tensor.Dense of the given shape. An empty shape makes a scalar.
+func (b *buffer) tensor(shape ...int) *tensor.Dense {
+	if len(shape) == 0 {
+		return tensor.New(tensor.FromScalar(reflect.ValueOf(b.data).Index(0).Interface()))
+	}
+	return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(b.data))
+}
+
+// slice returns the first n values of the buffer
+func (b *buffer) slice(n int) *buffer {
+	return &buffer{dt: b.dt, data: reflect.ValueOf(b.data).Slice(0, n).Interface()}
+}
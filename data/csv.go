@@ -0,0 +1,204 @@
+package data
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// CSVField selects the columns of a CSV file that make up one field of the examples, and the Dtype they are read as.
+// A field of a single column is read as a vector, and a field of several columns as a matrix.
+//
+// The columns are selected either by index or, if the file has a header, by name. A field that selects no columns selects all of them.
+type CSVField struct {
+	Columns []int
+	Names   []string
+	Dtype   tensor.Dtype
+}
+
+// CSVReader reads CSV files with typed columns, row by row.
+type CSVReader struct {
+	r      *csv.Reader
+	fields []CSVField
+	header []string
+	cols   [][]int // the resolved column indices of each field
+	line   int
+}
+
+// NewCSVReader creates a new CSVReader. If header is true, the first row of the file is read as the names of the columns.
+// If no fields are given, all the columns are read as one field of tensor.Float64.
+//
+// The underlying *csv.Reader is available through Reader, so that its delimiter, comment character and so on can be changed.
+func NewCSVReader(r io.Reader, header bool, fields ...CSVField) (*CSVReader, error) {
+	if len(fields) == 0 {
+		fields = []CSVField{{Dtype: tensor.Float64}}
+	}
+	retVal := &CSVReader{
+		r:      csv.NewReader(r),
+		fields: fields,
+	}
+	retVal.r.TrimLeadingSpace = true
+	if header {
+		h, err := retVal.r.Read()
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to read the header of the CSV file")
+		}
+		retVal.header = make([]string, len(h))
+		for i, name := range h {
+			retVal.header[i] = strings.TrimSpace(name)
+		}
+		retVal.line++
+	}
+	return retVal, nil
+}
+
+// Reader returns the underlying *csv.Reader.
+func (r *CSVReader) Reader() *csv.Reader { return r.r }
+
+// Header returns the names of the columns, if the file has a header.
+func (r *CSVReader) Header() []string { return r.header }
+
+// resolve resolves the columns of the fields, given the number of columns of the file
+func (r *CSVReader) resolve(ncols int) error {
+	r.cols = make([][]int, len(r.fields))
+	for f, field := range r.fields {
+		var cols []int
+		switch {
+		case len(field.Names) > 0:
+			if r.header == nil {
+				return errors.Errorf("Field %d selects columns by name, but the CSV file has no header", f)
+			}
+			for _, name := range field.Names {
+				idx := -1
+				for i, h := range r.header {
+					if h == name {
+						idx = i
+						break
+					}
+				}
+				if idx < 0 {
+					return errors.Errorf("No column named %q in the CSV file", name)
+				}
+				cols = append(cols, idx)
+			}
+		case len(field.Columns) > 0:
+			for _, c := range field.Columns {
+				if c < 0 || c >= ncols {
+					return errors.Errorf("Column %d is out of range of a CSV file of %d columns", c, ncols)
+				}
+			}
+			cols = field.Columns
+		default:
+			for i := 0; i < ncols; i++ {
+				cols = append(cols, i)
+			}
+		}
+		r.cols[f] = cols
+	}
+	return nil
+}
+
+// Next reads the next n rows (or fewer, at the end of the file) and returns one tensor per field, whose first axis indexes the rows.
+// It returns io.EOF when all the rows have been read.
+func (r *CSVReader) Next(n int) ([]*tensor.Dense, error) {
+	var rows [][]string
+	for len(rows) < n {
+		row, err := r.r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to read the CSV file")
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, io.EOF
+	}
+	if r.cols == nil {
+		if err := r.resolve(len(rows[0])); err != nil {
+			return nil, err
+		}
+	}
+
+	retVal := make([]*tensor.Dense, len(r.fields))
+	for f, field := range r.fields {
+		cols := r.cols[f]
+		b, err := newBuffer(field.Dtype, len(rows)*len(cols))
+		if err != nil {
+			return nil, err
+		}
+		for i, row := range rows {
+			for j, c := range cols {
+				if c >= len(row) {
+					return nil, errors.Errorf("Line %d of the CSV file has %d columns. Expected at least %d", r.line+i+1, len(row), c+1)
+				}
+				if err := b.parse(i*len(cols)+j, strings.TrimSpace(row[c])); err != nil {
+					return nil, errors.Wrapf(err, "Unable to parse column %d of line %d of the CSV file as %v", c, r.line+i+1, field.Dtype)
+				}
+			}
+		}
+		if len(cols) == 1 {
+			retVal[f] = b.tensor(len(rows))
+		} else {
+			retVal[f] = b.tensor(len(rows), len(cols))
+		}
+	}
+	r.line += len(rows)
+	return retVal, nil
+}
+
+// ReadCSV reads a whole CSV file, returning one tensor per field. See NewCSVReader for the meaning of the arguments.
+func ReadCSV(r io.Reader, header bool, fields ...CSVField) ([]*tensor.Dense, error) {
+	cr, err := NewCSVReader(r, header, fields...)
+	if err != nil {
+		return nil, err
+	}
+	const chunk = 4096
+	var chunks [][]*tensor.Dense
+	for {
+		ts, err := cr.Next(chunk)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, ts)
+	}
+	if len(chunks) == 0 {
+		return nil, errors.New("CSV file has no rows")
+	}
+	retVal := make([]*tensor.Dense, len(chunks[0]))
+	for f := range retVal {
+		ts := make([]*tensor.Dense, len(chunks))
+		for i, c := range chunks {
+			ts[i] = c[f]
+		}
+		if retVal[f], err = concat(ts); err != nil {
+			return nil, err
+		}
+	}
+	return retVal, nil
+}
+
+// LoadCSV reads the CSV file at the given path into a TensorDataset. See NewCSVReader for the meaning of the arguments.
+func LoadCSV(path string, header bool, fields ...CSVField) (*TensorDataset, error) {
+	f, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ts, err := ReadCSV(f, header, fields...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to read %q", path)
+	}
+	tts := make([]tensor.Tensor, len(ts))
+	for i, t := range ts {
+		tts[i] = t
+	}
+	return NewTensorDataset(tts...)
+}
@@ -0,0 +1,114 @@
+// Package data provides datasets and a DataLoader that shuffles, batches and prefetches the examples of a dataset,
+// ready to be bound to the input nodes of an expression graph.
+//
+// It also provides readers for the file formats datasets are commonly distributed as: IDX (MNIST, Fashion-MNIST), the binary CIFAR-10/100 batches,
+// CSV files and NumPy's .npy and .npz files. The readers convert the values to the requested tensor.Dtype, and where the format allows it, read the
+// examples in chunks rather than all at once.
+//
+// A typical training loop looks like this:
+//		loader := data.NewDataLoader(ds, data.WithBatchSize(32), data.WithShuffle(1337), data.WithWorkers(4))
+//		for epoch := 0; epoch < epochs; epoch++ {
+//			it := loader.Iter()
+//			for it.Next() {
+//				if err := it.Batch().Let(x, y); err != nil { ... }
+//				if err := machine.RunAll(); err != nil { ... }
+//				machine.Reset()
+//			}
+//			if err := it.Err(); err != nil { ... }
+//		}
+package data
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Dataset is a collection of examples that can be accessed by index.
+//
+// An example is made of one or more fields (say, an image and its label). Every example of a dataset must have the same
+// number of fields, and a field must have the same shape and Dtype across examples, so that the examples can be collated into batches.
+//
+// Get may be called concurrently by the workers of a DataLoader.
+type Dataset interface {
+	// Len returns the number of examples in the dataset.
+	Len() int
+
+	// Get returns the fields of the ith example.
+	Get(i int) ([]tensor.Tensor, error)
+}
+
+// TensorDataset is a Dataset made out of tensors whose first axis indexes the examples.
+// For example, the images of MNIST as a (60000, 784) tensor and the labels as a (60000, 10) tensor.
+type TensorDataset struct {
+	fields []*tensor.Dense
+	n      int
+}
+
+// NewTensorDataset creates a TensorDataset. All the tensors must have the same size along their first axis.
+func NewTensorDataset(ts ...tensor.Tensor) (*TensorDataset, error) {
+	if len(ts) == 0 {
+		return nil, errors.New("A TensorDataset needs at least one tensor")
+	}
+	retVal := &TensorDataset{n: -1}
+	for i, t := range ts {
+		if t.Dims() == 0 {
+			return nil, errors.Errorf("Tensor %d of the dataset is a scalar. Expected the first axis to index the examples", i)
+		}
+		d, ok := t.(*tensor.Dense)
+		if !ok {
+			return nil, errors.Errorf("Expected tensor %d of the dataset to be a *tensor.Dense. Got %T instead", i, t)
+		}
+		if d.IsView() {
+			d = d.Materialize().(*tensor.Dense)
+		}
+		if retVal.n >= 0 && d.Shape()[0] != retVal.n {
+			return nil, errors.Errorf("Tensor %d of the dataset has %d examples. Expected %d", i, d.Shape()[0], retVal.n)
+		}
+		retVal.n = d.Shape()[0]
+		retVal.fields = append(retVal.fields, d)
+	}
+	return retVal, nil
+}
+
+// Len returns the number of examples in the dataset.
+func (ds *TensorDataset) Len() int { return ds.n }
+
+// Get returns the ith row of each tensor. The rows are copies, not views.
+func (ds *TensorDataset) Get(i int) ([]tensor.Tensor, error) {
+	if i < 0 || i >= ds.n {
+		return nil, errors.Errorf("Index %d is out of range of a dataset of %d examples", i, ds.n)
+	}
+	retVal := make([]tensor.Tensor, len(ds.fields))
+	for f, t := range ds.fields {
+		shp := t.Shape()[1:]
+		size := shp.TotalSize()
+		row := tensor.New(tensor.Of(t.Dtype()), tensor.WithShape(shp.Clone()...))
+		src := reflect.ValueOf(t.Data()).Slice(i*size, (i+1)*size)
+		if len(shp) == 0 {
+			row.Set(0, src.Index(0).Interface())
+		} else {
+			reflect.Copy(reflect.ValueOf(row.Data()), src)
+		}
+		retVal[f] = row
+	}
+	return retVal, nil
+}
+
+// Subset is a view of a dataset restricted to the given indices, e.g. to split a dataset into a training and a validation set.
+type Subset struct {
+	Dataset
+	Indices []int
+}
+
+// Len returns the number of indices in the subset.
+func (s Subset) Len() int { return len(s.Indices) }
+
+// Get returns the example of the underlying dataset given by the ith index of the subset.
+func (s Subset) Get(i int) ([]tensor.Tensor, error) {
+	if i < 0 || i >= len(s.Indices) {
+		return nil, errors.Errorf("Index %d is out of range of a subset of %d examples", i, len(s.Indices))
+	}
+	return s.Dataset.Get(s.Indices[i])
+}
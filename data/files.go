@@ -0,0 +1,49 @@
+package data
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// file is an opened file, decompressed on the fly if it was gzipped.
+type file struct {
+	io.Reader
+	closers []io.Closer
+}
+
+// Open opens the file at the given path for reading. Gzipped files (such as the files MNIST is distributed as) are decompressed on the fly.
+func Open(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(f)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		f.Close()
+		return nil, errors.Wrapf(err, "Unable to read %q", path)
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			f.Close()
+			return nil, errors.Wrapf(err, "Unable to decompress %q", path)
+		}
+		return &file{Reader: zr, closers: []io.Closer{zr, f}}, nil
+	}
+	return &file{Reader: br, closers: []io.Closer{f}}, nil
+}
+
+func (f *file) Close() error {
+	var err error
+	for _, c := range f.closers {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,160 @@
+package data
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// IDX element types
+const (
+	idxUint8   = 0x08
+	idxInt8    = 0x09
+	idxInt16   = 0x0B
+	idxInt32   = 0x0C
+	idxFloat32 = 0x0D
+	idxFloat64 = 0x0E
+)
+
+// IDXReader reads the IDX files that MNIST, Fashion-MNIST and friends are distributed as.
+// The items of the file (the first axis) are read in chunks, so the whole file need not be held in memory.
+type IDXReader struct {
+	r      io.Reader
+	elType byte
+	elSize int
+	shape  tensor.Shape
+	read   int // number of items read
+	buf    []byte
+}
+
+// NewIDXReader reads the header of an IDX file.
+func NewIDXReader(r io.Reader) (*IDXReader, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, errors.Wrap(err, "Unable to read the magic number of the IDX file")
+	}
+	if magic[0] != 0 || magic[1] != 0 {
+		return nil, errors.Errorf("Not an IDX file. Got %x as the magic number instead", magic)
+	}
+	retVal := &IDXReader{r: r, elType: magic[2]}
+	switch magic[2] {
+	case idxUint8, idxInt8:
+		retVal.elSize = 1
+	case idxInt16:
+		retVal.elSize = 2
+	case idxInt32, idxFloat32:
+		retVal.elSize = 4
+	case idxFloat64:
+		retVal.elSize = 8
+	default:
+		return nil, errors.Errorf("Unknown IDX element type %#x", magic[2])
+	}
+	dims := int(magic[3])
+	if dims == 0 {
+		return nil, errors.New("IDX file has no dimensions")
+	}
+	retVal.shape = make(tensor.Shape, dims)
+	for i := range retVal.shape {
+		var size uint32
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			return nil, errors.Wrap(err, "Unable to read the shape of the IDX file")
+		}
+		retVal.shape[i] = int(size)
+	}
+	return retVal, nil
+}
+
+// Shape returns the shape of the whole file. The first axis indexes the items (e.g. the images).
+func (r *IDXReader) Shape() tensor.Shape { return r.shape.Clone() }
+
+// Next reads the next n items (or fewer, at the end of the file) into a tensor of the given Dtype, of shape (n, Shape()[1:]...).
+// It returns io.EOF when all the items have been read.
+func (r *IDXReader) Next(n int, dt tensor.Dtype) (*tensor.Dense, error) {
+	if left := r.shape[0] - r.read; n > left {
+		n = left
+	}
+	if n <= 0 {
+		return nil, io.EOF
+	}
+	itemSize := r.shape[1:].TotalSize()
+	if len(r.shape) == 1 {
+		itemSize = 1
+	}
+	b, err := newBuffer(dt, n*itemSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(r.buf) < itemSize*r.elSize {
+		r.buf = make([]byte, itemSize*r.elSize)
+	}
+	buf := r.buf[:itemSize*r.elSize]
+	for i := 0; i < n; i++ {
+		if _, err := io.ReadFull(r.r, buf); err != nil {
+			return nil, errors.Wrapf(err, "Unable to read item %d of the IDX file", r.read)
+		}
+		r.read++
+		for j := 0; j < itemSize; j++ {
+			r.decode(b, i*itemSize+j, buf[j*r.elSize:])
+		}
+	}
+
+	shp := append(tensor.Shape{n}, r.shape[1:]...)
+	return b.tensor(shp...), nil
+}
+
+func (r *IDXReader) decode(b *buffer, i int, p []byte) {
+	switch r.elType {
+	case idxUint8:
+		b.setInt(i, int64(p[0]))
+	case idxInt8:
+		b.setInt(i, int64(int8(p[0])))
+	case idxInt16:
+		b.setInt(i, int64(int16(binary.BigEndian.Uint16(p))))
+	case idxInt32:
+		b.setInt(i, int64(int32(binary.BigEndian.Uint32(p))))
+	case idxFloat32:
+		b.setFloat(i, float64(math.Float32frombits(binary.BigEndian.Uint32(p))))
+	case idxFloat64:
+		b.setFloat(i, math.Float64frombits(binary.BigEndian.Uint64(p)))
+	}
+}
+
+// ReadIDX reads a whole IDX file into a tensor of the given Dtype.
+func ReadIDX(r io.Reader, dt tensor.Dtype) (*tensor.Dense, error) {
+	ir, err := NewIDXReader(r)
+	if err != nil {
+		return nil, err
+	}
+	if ir.shape[0] == 0 {
+		return nil, errors.New("IDX file has no items")
+	}
+	return ir.Next(ir.shape[0], dt)
+}
+
+// LoadMNIST loads a pair of MNIST (or Fashion-MNIST) IDX files, gzipped or not, into a TensorDataset.
+// The images are read as a (n, 28, 28) tensor of the given Dtype, with the raw pixel values in [0, 255]. The labels are read as a (n) tensor of tensor.Int.
+func LoadMNIST(imagesPath, labelsPath string, dt tensor.Dtype) (*TensorDataset, error) {
+	images, err := readIDXFile(imagesPath, dt)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := readIDXFile(labelsPath, tensor.Int)
+	if err != nil {
+		return nil, err
+	}
+	return NewTensorDataset(images, labels)
+}
+
+func readIDXFile(path string, dt tensor.Dtype) (*tensor.Dense, error) {
+	f, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	retVal, err := ReadIDX(f, dt)
+	return retVal, errors.Wrapf(err, "Unable to read %q", path)
+}
@@ -0,0 +1,353 @@
+package data
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// DataLoader iterates over a dataset in batches.
+type DataLoader struct {
+	ds Dataset
+
+	batchSize int
+	dropLast  bool
+	shuffle   bool
+	seed      int64
+	workers   int
+	prefetch  int
+	collate   CollateFunc
+
+	mu    sync.Mutex
+	epoch int // the number of epochs started so far
+}
+
+// LoaderOpt is a function that provides construction options for a DataLoader
+type LoaderOpt func(*DataLoader)
+
+// WithBatchSize sets the number of examples in a batch. The default is 1.
+func WithBatchSize(n int) LoaderOpt {
+	return func(l *DataLoader) {
+		l.batchSize = n
+	}
+}
+
+// WithDropLast drops the last batch of an epoch if it is smaller than the batch size.
+func WithDropLast() LoaderOpt {
+	return func(l *DataLoader) {
+		l.dropLast = true
+	}
+}
+
+// WithShuffle shuffles the examples at the start of every epoch. The order of each epoch is determined by the seed and the epoch number,
+// so two loaders with the same seed produce the same batches.
+func WithShuffle(seed int64) LoaderOpt {
+	return func(l *DataLoader) {
+		l.shuffle = true
+		l.seed = seed
+	}
+}
+
+// WithWorkers sets the number of goroutines that load and collate batches in the background.
+// With 0 workers (the default), batches are loaded on demand by the goroutine iterating over them.
+func WithWorkers(n int) LoaderOpt {
+	return func(l *DataLoader) {
+		l.workers = n
+	}
+}
+
+// WithPrefetch sets the maximum number of batches that the workers load ahead of the batch being consumed.
+// The default is twice the number of workers.
+func WithPrefetch(n int) LoaderOpt {
+	return func(l *DataLoader) {
+		l.prefetch = n
+	}
+}
+
+// WithCollate replaces the default Collate function.
+func WithCollate(fn CollateFunc) LoaderOpt {
+	return func(l *DataLoader) {
+		l.collate = fn
+	}
+}
+
+// NewDataLoader creates a new DataLoader for the dataset.
+func NewDataLoader(ds Dataset, opts ...LoaderOpt) *DataLoader {
+	l := &DataLoader{
+		ds:        ds,
+		batchSize: 1,
+		collate:   Collate,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.batchSize < 1 {
+		l.batchSize = 1
+	}
+	if l.workers < 0 {
+		l.workers = 0
+	}
+	if l.prefetch < 1 {
+		l.prefetch = 2 * l.workers
+		if l.prefetch < 1 {
+			l.prefetch = 1
+		}
+	}
+	return l
+}
+
+// Len returns the number of batches in an epoch.
+func (l *DataLoader) Len() int {
+	n := l.ds.Len()
+	if l.dropLast {
+		return n / l.batchSize
+	}
+	return (n + l.batchSize - 1) / l.batchSize
+}
+
+// Epoch returns the number of epochs started so far.
+func (l *DataLoader) Epoch() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.epoch
+}
+
+// Iter starts a new epoch and returns an iterator over its batches.
+// The iterator must be closed if it is not run to completion, otherwise its workers leak.
+func (l *DataLoader) Iter() *Iterator {
+	l.mu.Lock()
+	epoch := l.epoch
+	l.epoch++
+	l.mu.Unlock()
+
+	order := l.order(epoch)
+	it := &Iterator{
+		l:     l,
+		epoch: epoch,
+		order: order,
+		n:     l.Len(),
+		done:  make(chan struct{}),
+	}
+	if l.workers > 0 {
+		it.start()
+	}
+	return it
+}
+
+// Run iterates over the given number of epochs, calling fn for every batch. It stops at the first error.
+func (l *DataLoader) Run(epochs int, fn func(b *Batch) error) error {
+	for e := 0; e < epochs; e++ {
+		it := l.Iter()
+		for it.Next() {
+			if err := fn(it.Batch()); err != nil {
+				it.Close()
+				return err
+			}
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// order returns the order in which the examples are visited in the given epoch
+func (l *DataLoader) order(epoch int) []int {
+	n := l.ds.Len()
+	if l.shuffle {
+		return rand.New(rand.NewSource(l.seed + int64(epoch))).Perm(n)
+	}
+	retVal := make([]int, n)
+	for i := range retVal {
+		retVal[i] = i
+	}
+	return retVal
+}
+
+// load loads and collates the bth batch of the given order
+func (l *DataLoader) load(order []int, b int) (*Batch, error) {
+	start := b * l.batchSize
+	end := start + l.batchSize
+	if end > len(order) {
+		end = len(order)
+	}
+
+	examples := make([][]tensor.Tensor, 0, end-start)
+	for _, i := range order[start:end] {
+		ex, err := l.ds.Get(i)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to get example %d", i)
+		}
+		examples = append(examples, ex)
+	}
+	fields, err := l.collate(examples)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to collate batch %d", b)
+	}
+	return &Batch{Index: b, Indices: order[start:end], Fields: fields}, nil
+}
+
+// Batch is a batch of examples, collated into one tensor per field.
+type Batch struct {
+	Epoch   int
+	Index   int   // the index of the batch within the epoch
+	Indices []int // the indices of the examples in the dataset
+	Fields  []*tensor.Dense
+}
+
+// Size returns the number of examples in the batch.
+func (b *Batch) Size() int { return len(b.Indices) }
+
+// Let binds the fields of the batch to the nodes, in order. There can be fewer nodes than fields.
+//
+// Do note that unless the DataLoader drops the last batch, the last batch of an epoch may be smaller than the others.
+func (b *Batch) Let(nodes ...*gorgonia.Node) error {
+	if len(nodes) > len(b.Fields) {
+		return errors.Errorf("Cannot bind %d nodes to a batch of %d fields", len(nodes), len(b.Fields))
+	}
+	for i, n := range nodes {
+		if n == nil {
+			continue
+		}
+		if err := gorgonia.Let(n, b.Fields[i]); err != nil {
+			return errors.Wrapf(err, "Unable to bind field %d to %v", i, n)
+		}
+	}
+	return nil
+}
+
+type loadResult struct {
+	batch *Batch
+	err   error
+}
+
+type loadJob struct {
+	b    int
+	slot chan loadResult
+}
+
+// Iterator iterates over the batches of an epoch. It is not safe for concurrent use.
+type Iterator struct {
+	l     *DataLoader
+	epoch int
+	order []int
+	n     int
+
+	next  int
+	batch *Batch
+	err   error
+
+	// background loading
+	pending chan chan loadResult
+	done    chan struct{}
+	wg      sync.WaitGroup
+	closed  bool
+}
+
+// start starts the dispatcher and the workers.
+//
+// The dispatcher hands out the batches to the workers in order, and queues a slot per batch in pending.
+// The workers put the loaded batches into their slots. Because pending is bounded, so is the number of batches loaded in advance.
+// A slot that is queued but never handed to a worker, because the iterator was closed in between, is closed instead.
+func (it *Iterator) start() {
+	jobs := make(chan loadJob)
+	it.pending = make(chan chan loadResult, it.l.prefetch)
+
+	for w := 0; w < it.l.workers; w++ {
+		it.wg.Add(1)
+		go func() {
+			defer it.wg.Done()
+			for job := range jobs {
+				batch, err := it.l.load(it.order, job.b)
+				job.slot <- loadResult{batch, err}
+			}
+		}()
+	}
+
+	it.wg.Add(1)
+	go func() {
+		defer it.wg.Done()
+		defer close(it.pending)
+		defer close(jobs)
+		for b := 0; b < it.n; b++ {
+			slot := make(chan loadResult, 1)
+			select {
+			case it.pending <- slot:
+			case <-it.done:
+				return
+			}
+			select {
+			case jobs <- loadJob{b, slot}:
+			case <-it.done:
+				// no worker will fill the slot, so it is closed for the drain in Close not to wait on it
+				close(slot)
+				return
+			}
+		}
+	}()
+}
+
+// Next loads the next batch, returning false at the end of the epoch or if an error occurred.
+func (it *Iterator) Next() bool {
+	if it.err != nil || it.closed || it.next >= it.n {
+		it.batch = nil
+		return false
+	}
+
+	var res loadResult
+	if it.pending == nil {
+		res.batch, res.err = it.l.load(it.order, it.next)
+	} else {
+		slot, ok := <-it.pending
+		if !ok {
+			it.batch = nil
+			return false
+		}
+		res = <-slot
+	}
+	it.next++
+
+	if res.err != nil {
+		it.err = res.err
+		it.batch = nil
+		it.Close()
+		return false
+	}
+	res.batch.Epoch = it.epoch
+	it.batch = res.batch
+	if it.next == it.n {
+		it.Close()
+	}
+	return true
+}
+
+// Batch returns the current batch.
+func (it *Iterator) Batch() *Batch { return it.batch }
+
+// Epoch returns the epoch the iterator iterates over, counting from 0.
+func (it *Iterator) Epoch() int { return it.epoch }
+
+// Err returns the first error that occurred while loading the batches.
+func (it *Iterator) Err() error { return it.err }
+
+// Close stops the workers. It is safe to call Close more than once.
+func (it *Iterator) Close() {
+	if it.closed {
+		return
+	}
+	it.closed = true
+	close(it.done)
+	if it.pending == nil {
+		return
+	}
+	// drain the batches that are in flight, so that the workers can exit
+	go func() {
+		for slot := range it.pending {
+			<-slot
+		}
+	}()
+	it.wg.Wait()
+}
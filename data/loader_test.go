@@ -0,0 +1,254 @@
+package data
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// rangeDataset returns a dataset of n examples. The ith example is a (2) vector {i, -i} and the label i.
+func rangeDataset(t *testing.T, n int) *TensorDataset {
+	xs := make([]float64, 2*n)
+	ys := make([]int, n)
+	for i := 0; i < n; i++ {
+		xs[2*i] = float64(i)
+		xs[2*i+1] = -float64(i)
+		ys[i] = i
+	}
+	ds, err := NewTensorDataset(
+		tensor.New(tensor.WithShape(n, 2), tensor.WithBacking(xs)),
+		tensor.New(tensor.WithShape(n), tensor.WithBacking(ys)),
+	)
+	require.NoError(t, err)
+	return ds
+}
+
+func collect(t *testing.T, it *Iterator) (labels [][]int) {
+	for it.Next() {
+		b := it.Batch()
+		require.Len(t, b.Fields, 2)
+		assert.Equal(t, tensor.Shape{b.Size(), 2}, b.Fields[0].Shape())
+		assert.Equal(t, tensor.Shape{b.Size()}, b.Fields[1].Shape())
+		ys := b.Fields[1].Data().([]int)
+		xs := b.Fields[0].Data().([]float64)
+		for i, y := range ys {
+			assert.Equal(t, float64(y), xs[2*i])
+			assert.Equal(t, -float64(y), xs[2*i+1])
+		}
+		assert.Equal(t, b.Indices, ys)
+		labels = append(labels, append([]int(nil), ys...))
+	}
+	require.NoError(t, it.Err())
+	return labels
+}
+
+func TestTensorDataset(t *testing.T) {
+	ds := rangeDataset(t, 5)
+	assert.Equal(t, 5, ds.Len())
+
+	ex, err := ds.Get(3)
+	require.NoError(t, err)
+	require.Len(t, ex, 2)
+	assert.Equal(t, []float64{3, -3}, ex[0].Data())
+	assert.Equal(t, 3, ex[1].Data())
+	assert.True(t, ex[1].Shape().IsScalar())
+
+	_, err = ds.Get(5)
+	assert.Error(t, err)
+
+	_, err = NewTensorDataset(tensor.New(tensor.WithShape(3, 2), tensor.Of(tensor.Float64)), tensor.New(tensor.WithShape(4), tensor.Of(tensor.Int)))
+	assert.Error(t, err)
+
+	sub := Subset{ds, []int{4, 0}}
+	assert.Equal(t, 2, sub.Len())
+	ex, err = sub.Get(0)
+	require.NoError(t, err)
+	assert.Equal(t, 4, ex[1].Data())
+}
+
+func TestDataLoader_Batching(t *testing.T) {
+	ds := rangeDataset(t, 10)
+
+	l := NewDataLoader(ds, WithBatchSize(4))
+	assert.Equal(t, 3, l.Len())
+	assert.Equal(t, [][]int{{0, 1, 2, 3}, {4, 5, 6, 7}, {8, 9}}, collect(t, l.Iter()))
+
+	l = NewDataLoader(ds, WithBatchSize(4), WithDropLast())
+	assert.Equal(t, 2, l.Len())
+	assert.Equal(t, [][]int{{0, 1, 2, 3}, {4, 5, 6, 7}}, collect(t, l.Iter()))
+}
+
+func TestDataLoader_Shuffle(t *testing.T) {
+	ds := rangeDataset(t, 20)
+	flatten := func(bs [][]int) (retVal []int) {
+		for _, b := range bs {
+			retVal = append(retVal, b...)
+		}
+		return
+	}
+
+	l1 := NewDataLoader(ds, WithBatchSize(3), WithShuffle(1337))
+	l2 := NewDataLoader(ds, WithBatchSize(3), WithShuffle(1337), WithWorkers(3))
+	e0 := collect(t, l1.Iter())
+	e1 := collect(t, l1.Iter())
+	assert.Equal(t, 2, l1.Epoch())
+
+	// same seed, same order, regardless of the number of workers
+	assert.Equal(t, e0, collect(t, l2.Iter()))
+	assert.Equal(t, e1, collect(t, l2.Iter()))
+
+	// every epoch is a permutation, and the epochs differ
+	assert.NotEqual(t, e0, e1)
+	seen := make(map[int]bool)
+	for _, i := range flatten(e0) {
+		seen[i] = true
+	}
+	assert.Len(t, seen, 20)
+}
+
+func TestDataLoader_Workers(t *testing.T) {
+	ds := rangeDataset(t, 103)
+	sequential := collect(t, NewDataLoader(ds, WithBatchSize(8)).Iter())
+	for _, workers := range []int{1, 2, 8} {
+		l := NewDataLoader(ds, WithBatchSize(8), WithWorkers(workers), WithPrefetch(3))
+		assert.Equal(t, sequential, collect(t, l.Iter()), "workers %d", workers)
+	}
+}
+
+// countingDataset counts the calls to Get.
+type countingDataset struct {
+	Dataset
+	gets int64
+	fail int
+}
+
+func (ds *countingDataset) Get(i int) ([]tensor.Tensor, error) {
+	atomic.AddInt64(&ds.gets, 1)
+	if i == ds.fail {
+		return nil, errors.New("corrupt example")
+	}
+	return ds.Dataset.Get(i)
+}
+
+func TestDataLoader_Prefetch(t *testing.T) {
+	ds := &countingDataset{Dataset: rangeDataset(t, 100), fail: -1}
+	l := NewDataLoader(ds, WithBatchSize(1), WithWorkers(4), WithPrefetch(2))
+	it := l.Iter()
+	require.True(t, it.Next())
+	// wait for the workers to fill up the buffer
+	time.Sleep(50 * time.Millisecond)
+	// the batch being consumed, the prefetched batches, and at most one batch being handed to a worker
+	assert.True(t, atomic.LoadInt64(&ds.gets) <= 1+2+1, "loaded %d batches", atomic.LoadInt64(&ds.gets))
+	it.Close()
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Err())
+}
+
+// gatedDataset blocks in Get until the gate is closed. It signals every call to Get on started.
+type gatedDataset struct {
+	Dataset
+	started chan struct{}
+	gate    chan struct{}
+}
+
+func (ds *gatedDataset) Get(i int) ([]tensor.Tensor, error) {
+	ds.started <- struct{}{}
+	<-ds.gate
+	return ds.Dataset.Get(i)
+}
+
+func TestIterator_CloseFullQueue(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	ds := &gatedDataset{Dataset: rangeDataset(t, 10), started: make(chan struct{}, 10), gate: make(chan struct{})}
+	l := NewDataLoader(ds, WithWorkers(1), WithPrefetch(2))
+	it := l.Iter()
+	// the worker is stuck on the first batch, and the slot of the second batch is queued but not handed out yet
+	<-ds.started
+	for len(it.pending) < cap(it.pending) {
+		time.Sleep(time.Millisecond)
+	}
+	go func() {
+		<-it.done
+		close(ds.gate)
+	}()
+	it.Close()
+
+	// the goroutines exit shortly after Close returns
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, before, runtime.NumGoroutine(), "goroutines were left behind")
+}
+
+func TestDataLoader_Errors(t *testing.T) {
+	for _, workers := range []int{0, 2} {
+		ds := &countingDataset{Dataset: rangeDataset(t, 10), fail: 5}
+		l := NewDataLoader(ds, WithBatchSize(2), WithWorkers(workers))
+		it := l.Iter()
+		var n int
+		for it.Next() {
+			n++
+		}
+		assert.Equal(t, 2, n, "workers %d", workers)
+		assert.Error(t, it.Err(), "workers %d", workers)
+		assert.Contains(t, it.Err().Error(), "corrupt example")
+
+		err := l.Run(1, func(b *Batch) error { return nil })
+		assert.Error(t, err)
+	}
+
+	ds := rangeDataset(t, 10)
+	l := NewDataLoader(ds, WithWorkers(2))
+	stop := errors.New("stop")
+	var n int
+	err := l.Run(2, func(b *Batch) error {
+		if n++; n == 3 {
+			return stop
+		}
+		return nil
+	})
+	assert.Equal(t, stop, err)
+}
+
+func TestBatch_Let(t *testing.T) {
+	ds := rangeDataset(t, 6)
+	l := NewDataLoader(ds, WithBatchSize(3), WithWorkers(1))
+
+	g := gorgonia.NewGraph()
+	x := gorgonia.NewMatrix(g, tensor.Float64, gorgonia.WithShape(3, 2), gorgonia.WithName("x"))
+	sum := gorgonia.Must(gorgonia.Sum(gorgonia.Must(gorgonia.Square(x))))
+	var out gorgonia.Value
+	gorgonia.Read(sum, &out)
+	m := gorgonia.NewTapeMachine(g)
+	defer m.Close()
+
+	var sums []float64
+	err := l.Run(1, func(b *Batch) error {
+		if err := b.Let(x); err != nil {
+			return err
+		}
+		if err := m.RunAll(); err != nil {
+			return err
+		}
+		m.Reset()
+		sums = append(sums, out.Data().(float64))
+		return nil
+	})
+	require.NoError(t, err)
+	// 2(0² + 1² + 2²) and 2(3² + 4² + 5²)
+	assert.Equal(t, []float64{10, 100}, sums)
+
+	it := l.Iter()
+	require.True(t, it.Next())
+	assert.Error(t, it.Batch().Let(x, nil, x))
+	it.Close()
+}
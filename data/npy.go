@@ -0,0 +1,335 @@
+package data
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+const npyMagic = "\x93NUMPY"
+
+var (
+	npyDescrRE   = regexp.MustCompile(`'descr'\s*:\s*'([<>|=])([a-zA-Z])(\d+)'`)
+	npyFortranRE = regexp.MustCompile(`'fortran_order'\s*:\s*(True|False)`)
+	npyShapeRE   = regexp.MustCompile(`'shape'\s*:\s*\(([\d\s,]*)\)`)
+)
+
+// NpyReader reads NumPy's .npy files (versions 1, 2 and 3 of the format).
+// The rows of the array (its first axis) are read in chunks, so the whole array need not be held in memory.
+type NpyReader struct {
+	r       io.Reader
+	order   binary.ByteOrder
+	kind    byte
+	elSize  int
+	dt      tensor.Dtype
+	shape   tensor.Shape
+	fortran bool
+	read    int // number of rows read
+	buf     []byte
+}
+
+// NewNpyReader reads the header of a .npy file.
+func NewNpyReader(r io.Reader) (*NpyReader, error) {
+	var magic [8]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, errors.Wrap(err, "Unable to read the magic number of the npy file")
+	}
+	if string(magic[:6]) != npyMagic {
+		return nil, errors.Errorf("Not a npy file. Got %q as the magic number instead", string(magic[:6]))
+	}
+
+	var headerLen int
+	switch magic[6] {
+	case 1:
+		var l uint16
+		if err := binary.Read(r, binary.LittleEndian, &l); err != nil {
+			return nil, errors.Wrap(err, "Unable to read the header of the npy file")
+		}
+		headerLen = int(l)
+	case 2, 3:
+		var l uint32
+		if err := binary.Read(r, binary.LittleEndian, &l); err != nil {
+			return nil, errors.Wrap(err, "Unable to read the header of the npy file")
+		}
+		headerLen = int(l)
+	default:
+		return nil, errors.Errorf("Version %d.%d of the npy format is not supported", magic[6], magic[7])
+	}
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errors.Wrap(err, "Unable to read the header of the npy file")
+	}
+
+	retVal := &NpyReader{r: r}
+	if err := retVal.parseHeader(string(header)); err != nil {
+		return nil, err
+	}
+	return retVal, nil
+}
+
+func (r *NpyReader) parseHeader(header string) error {
+	m := npyDescrRE.FindStringSubmatch(header)
+	if m == nil {
+		return errors.Errorf("No supported dtype in the npy header %q", header)
+	}
+	switch m[1] {
+	case ">":
+		r.order = binary.BigEndian
+	default:
+		r.order = binary.LittleEndian
+	}
+	r.kind = m[2][0]
+	r.elSize, _ = strconv.Atoi(m[3])
+
+	var err error
+	if r.dt, err = npyDtype(r.kind, r.elSize); err != nil {
+		return err
+	}
+
+	if m = npyFortranRE.FindStringSubmatch(header); m == nil {
+		return errors.Errorf("No fortran_order in the npy header %q", header)
+	}
+	r.fortran = m[1] == "True"
+
+	if m = npyShapeRE.FindStringSubmatch(header); m == nil {
+		return errors.Errorf("No shape in the npy header %q", header)
+	}
+	r.shape = tensor.Shape{}
+	for _, s := range strings.Split(m[1], ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		size, err := strconv.Atoi(s)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to parse the shape in the npy header %q", header)
+		}
+		r.shape = append(r.shape, size)
+	}
+	return nil
+}
+
+// npyDtype returns the Dtype of a NumPy array-protocol type
+func npyDtype(kind byte, size int) (tensor.Dtype, error) {
+	switch {
+	case kind == 'f' && size == 8:
+		return tensor.Float64, nil
+	case kind == 'f' && size == 4:
+		return tensor.Float32, nil
+	case kind == 'i' && size == 8:
+		return tensor.Int64, nil
+	case kind == 'i' && size == 4:
+		return tensor.Int32, nil
+	case kind == 'i' && size == 2:
+		return tensor.Int16, nil
+	case kind == 'i' && size == 1:
+		return tensor.Int8, nil
+	case kind == 'u' && size == 8:
+		return tensor.Uint64, nil
+	case kind == 'u' && size == 4:
+		return tensor.Uint32, nil
+	case kind == 'u' && size == 2:
+		return tensor.Uint16, nil
+	case kind == 'u' && size == 1:
+		return tensor.Uint8, nil
+	case kind == 'b' && size == 1:
+		return tensor.Bool, nil
+	}
+	return tensor.Dtype{}, errors.Errorf("The npy dtype %c%d is not supported", kind, size)
+}
+
+// Shape returns the shape of the array.
+func (r *NpyReader) Shape() tensor.Shape { return r.shape.Clone() }
+
+// Dtype returns the Dtype the array is stored as.
+func (r *NpyReader) Dtype() tensor.Dtype { return r.dt }
+
+// FortranOrder returns true if the array is stored in column major order.
+func (r *NpyReader) FortranOrder() bool { return r.fortran }
+
+// Next reads the next n rows of the array (or fewer, at the end of the array) into a tensor of the given Dtype, of shape (n, Shape()[1:]...).
+// If dt is the zero Dtype, the rows are read as the Dtype the array is stored as.
+// It returns io.EOF when all the rows have been read.
+//
+// Arrays in Fortran order and scalars cannot be read row by row. Use ReadNpy instead.
+func (r *NpyReader) Next(n int, dt tensor.Dtype) (*tensor.Dense, error) {
+	if r.fortran {
+		return nil, errors.New("Cannot read the rows of an array in Fortran order")
+	}
+	if len(r.shape) == 0 {
+		return nil, errors.New("Cannot read the rows of a scalar")
+	}
+	if left := r.shape[0] - r.read; n > left {
+		n = left
+	}
+	if n <= 0 {
+		return nil, io.EOF
+	}
+	rowSize := 1
+	if len(r.shape) > 1 {
+		rowSize = r.shape[1:].TotalSize()
+	}
+	b, err := r.readValues(n*rowSize, dt)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to read row %d of the npy file", r.read)
+	}
+	r.read += n
+	return b.tensor(append(tensor.Shape{n}, r.shape[1:]...)...), nil
+}
+
+// readValues reads n values into a buffer of the given Dtype
+func (r *NpyReader) readValues(n int, dt tensor.Dtype) (*buffer, error) {
+	if dt.Type == nil {
+		dt = r.dt
+	}
+	b, err := newBuffer(dt, n)
+	if err != nil {
+		return nil, err
+	}
+
+	const chunk = 1 << 16 // values decoded at a time
+	for start := 0; start < n; start += chunk {
+		end := start + chunk
+		if end > n {
+			end = n
+		}
+		size := (end - start) * r.elSize
+		if len(r.buf) < size {
+			r.buf = make([]byte, size)
+		}
+		buf := r.buf[:size]
+		if _, err := io.ReadFull(r.r, buf); err != nil {
+			return nil, err
+		}
+		for i := start; i < end; i++ {
+			r.decode(b, i, buf[(i-start)*r.elSize:])
+		}
+	}
+	return b, nil
+}
+
+func (r *NpyReader) decode(b *buffer, i int, p []byte) {
+	switch r.kind {
+	case 'f':
+		if r.elSize == 4 {
+			b.setFloat(i, float64(math.Float32frombits(r.order.Uint32(p))))
+		} else {
+			b.setFloat(i, math.Float64frombits(r.order.Uint64(p)))
+		}
+	case 'i':
+		switch r.elSize {
+		case 1:
+			b.setInt(i, int64(int8(p[0])))
+		case 2:
+			b.setInt(i, int64(int16(r.order.Uint16(p))))
+		case 4:
+			b.setInt(i, int64(int32(r.order.Uint32(p))))
+		case 8:
+			b.setInt(i, int64(r.order.Uint64(p)))
+		}
+	case 'u':
+		switch r.elSize {
+		case 1:
+			b.setUint(i, uint64(p[0]))
+		case 2:
+			b.setUint(i, uint64(r.order.Uint16(p)))
+		case 4:
+			b.setUint(i, uint64(r.order.Uint32(p)))
+		case 8:
+			b.setUint(i, r.order.Uint64(p))
+		}
+	case 'b':
+		var v int64
+		if p[0] != 0 {
+			v = 1
+		}
+		b.setInt(i, v)
+	}
+}
+
+// ReadNpy reads a whole .npy file into a tensor of the given Dtype. If dt is the zero Dtype, the array is read as the Dtype it is stored as.
+// Arrays in Fortran order are transposed into row major order.
+func ReadNpy(r io.Reader, dt tensor.Dtype) (*tensor.Dense, error) {
+	nr, err := NewNpyReader(r)
+	if err != nil {
+		return nil, err
+	}
+	b, err := nr.readValues(nr.shape.TotalSize(), dt)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to read the values of the npy file")
+	}
+	if !nr.fortran || len(nr.shape) < 2 {
+		return b.tensor(nr.shape...), nil
+	}
+
+	// an array in Fortran order is the row major transpose of the array
+	rev := make(tensor.Shape, len(nr.shape))
+	for i, s := range nr.shape {
+		rev[len(rev)-1-i] = s
+	}
+	t := b.tensor(rev...)
+	if err = t.T(); err != nil {
+		return nil, err
+	}
+	if err = t.Transpose(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ReadNpz reads all the arrays of a .npz archive, compressed or not, keyed by their names.
+// If dt is the zero Dtype, each array is read as the Dtype it is stored as.
+func ReadNpz(r io.ReaderAt, size int64, dt tensor.Dtype) (map[string]*tensor.Dense, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to read the npz archive")
+	}
+	retVal := make(map[string]*tensor.Dense, len(zr.File))
+	for _, f := range zr.File {
+		name := strings.TrimSuffix(f.Name, ".npy")
+		rc, err := f.Open()
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to open %q in the npz archive", name)
+		}
+		t, err := ReadNpy(rc, dt)
+		rc.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to read %q in the npz archive", name)
+		}
+		retVal[name] = t
+	}
+	return retVal, nil
+}
+
+// LoadNpy reads the .npy file at the given path. See ReadNpy.
+func LoadNpy(path string, dt tensor.Dtype) (*tensor.Dense, error) {
+	f, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	retVal, err := ReadNpy(f, dt)
+	return retVal, errors.Wrapf(err, "Unable to read %q", path)
+}
+
+// LoadNpz reads the .npz archive at the given path. See ReadNpz.
+func LoadNpz(path string, dt tensor.Dtype) (map[string]*tensor.Dense, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	retVal, err := ReadNpz(f, info.Size(), dt)
+	return retVal, errors.Wrapf(err, "Unable to read %q", path)
+}
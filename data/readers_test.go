@@ -0,0 +1,338 @@
+package data
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/tensor"
+)
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "gorgonia-data")
+	require.NoError(t, err)
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// idxFile builds an IDX file of uint8s
+func idxFile(shape []int, data []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0, 0, idxUint8, byte(len(shape))})
+	for _, s := range shape {
+		binary.Write(&buf, binary.BigEndian, uint32(s))
+	}
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func TestIDX(t *testing.T) {
+	// 5 images of 2×3
+	pixels := make([]byte, 5*6)
+	for i := range pixels {
+		pixels[i] = byte(i * 8)
+	}
+	file := idxFile([]int{5, 2, 3}, pixels)
+
+	imgs, err := ReadIDX(bytes.NewReader(file), tensor.Float32)
+	require.NoError(t, err)
+	assert.Equal(t, tensor.Shape{5, 2, 3}, imgs.Shape())
+	assert.Equal(t, float32(8*7), imgs.Float32s()[7])
+
+	// streaming
+	r, err := NewIDXReader(bytes.NewReader(file))
+	require.NoError(t, err)
+	assert.Equal(t, tensor.Shape{5, 2, 3}, r.Shape())
+	var sizes []int
+	for {
+		chunk, err := r.Next(2, tensor.Uint8)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		sizes = append(sizes, chunk.Shape()[0])
+	}
+	assert.Equal(t, []int{2, 2, 1}, sizes)
+
+	// big endian floats
+	var buf bytes.Buffer
+	buf.Write([]byte{0, 0, idxFloat64, 1})
+	binary.Write(&buf, binary.BigEndian, uint32(2))
+	binary.Write(&buf, binary.BigEndian, []float64{1.5, -2.25})
+	fs, err := ReadIDX(&buf, tensor.Float64)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1.5, -2.25}, fs.Data())
+
+	// errors
+	_, err = ReadIDX(bytes.NewReader([]byte{1, 2, 3, 4}), tensor.Float64)
+	assert.Error(t, err)
+	_, err = ReadIDX(bytes.NewReader(file[:20]), tensor.Float64)
+	assert.Error(t, err)
+	_, err = ReadIDX(bytes.NewReader(file), tensor.Complex128)
+	assert.Error(t, err)
+}
+
+func TestLoadMNIST(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	// the images are gzipped, the labels aren't
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(idxFile([]int{3, 28, 28}, make([]byte, 3*28*28)))
+	zw.Close()
+	imgPath := filepath.Join(dir, "t10k-images-idx3-ubyte.gz")
+	lblPath := filepath.Join(dir, "t10k-labels-idx1-ubyte")
+	require.NoError(t, ioutil.WriteFile(imgPath, gz.Bytes(), 0644))
+	require.NoError(t, ioutil.WriteFile(lblPath, idxFile([]int{3}, []byte{7, 2, 1}), 0644))
+
+	ds, err := LoadMNIST(imgPath, lblPath, tensor.Float64)
+	require.NoError(t, err)
+	assert.Equal(t, 3, ds.Len())
+	ex, err := ds.Get(1)
+	require.NoError(t, err)
+	assert.Equal(t, tensor.Shape{28, 28}, ex[0].Shape())
+	assert.Equal(t, 2, ex[1].Data())
+
+	// mismatched number of items
+	require.NoError(t, ioutil.WriteFile(lblPath, idxFile([]int{2}, []byte{7, 2}), 0644))
+	_, err = LoadMNIST(imgPath, lblPath, tensor.Float64)
+	assert.Error(t, err)
+
+	_, err = LoadMNIST(filepath.Join(dir, "nonexistent"), lblPath, tensor.Float64)
+	assert.Error(t, err)
+}
+
+func cifarFile(variant CIFARVariant, n int) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		buf.WriteByte(byte(i))
+		if variant != CIFAR10 {
+			buf.WriteByte(byte(50 + i))
+		}
+		px := make([]byte, cifarPixels)
+		for j := range px {
+			px[j] = byte(i + j)
+		}
+		buf.Write(px)
+	}
+	return buf.Bytes()
+}
+
+func TestCIFAR(t *testing.T) {
+	imgs, lbls, err := ReadCIFAR(bytes.NewReader(cifarFile(CIFAR10, 3)), CIFAR10, tensor.Float32)
+	require.NoError(t, err)
+	assert.Equal(t, tensor.Shape{3, 3, 32, 32}, imgs.Shape())
+	assert.Equal(t, []int{0, 1, 2}, lbls.Data())
+	// the green channel of the first pixel of the third image
+	v, err := imgs.At(2, 1, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, float32((2+1024)%256), v)
+
+	_, lbls, err = ReadCIFAR(bytes.NewReader(cifarFile(CIFAR100Fine, 2)), CIFAR100Fine, tensor.Uint8)
+	require.NoError(t, err)
+	assert.Equal(t, []int{50, 51}, lbls.Data())
+	_, lbls, err = ReadCIFAR(bytes.NewReader(cifarFile(CIFAR100Coarse, 2)), CIFAR100Coarse, tensor.Uint8)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1}, lbls.Data())
+
+	// streaming
+	r := NewCIFARReader(bytes.NewReader(cifarFile(CIFAR10, 5)), CIFAR10)
+	imgs, _, err = r.Next(3, tensor.Float64)
+	require.NoError(t, err)
+	assert.Equal(t, 3, imgs.Shape()[0])
+	imgs, _, err = r.Next(3, tensor.Float64)
+	require.NoError(t, err)
+	assert.Equal(t, 2, imgs.Shape()[0])
+	_, _, err = r.Next(3, tensor.Float64)
+	assert.Equal(t, io.EOF, err)
+
+	// truncated record
+	_, _, err = ReadCIFAR(bytes.NewReader(cifarFile(CIFAR10, 2)[:4000]), CIFAR10, tensor.Float64)
+	assert.Error(t, err)
+
+	// several files
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	var paths []string
+	for i := 1; i <= 2; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("data_batch_%d.bin", i))
+		require.NoError(t, ioutil.WriteFile(path, cifarFile(CIFAR10, 2), 0644))
+		paths = append(paths, path)
+	}
+	ds, err := LoadCIFAR(CIFAR10, tensor.Float32, paths...)
+	require.NoError(t, err)
+	assert.Equal(t, 4, ds.Len())
+}
+
+func TestCSV(t *testing.T) {
+	const file = `sepal_length, sepal_width, species, ok
+5.1, 3.5, 0, true
+4.9, 3.0, 1, false
+6.3, 3.3, 2, true
+`
+	ts, err := ReadCSV(strings.NewReader(file), true,
+		CSVField{Names: []string{"sepal_length", "sepal_width"}, Dtype: tensor.Float32},
+		CSVField{Columns: []int{2}, Dtype: tensor.Int},
+		CSVField{Names: []string{"ok"}, Dtype: tensor.Bool},
+	)
+	require.NoError(t, err)
+	require.Len(t, ts, 3)
+	assert.Equal(t, tensor.Shape{3, 2}, ts[0].Shape())
+	assert.Equal(t, []float32{5.1, 3.5, 4.9, 3.0, 6.3, 3.3}, ts[0].Data())
+	assert.Equal(t, []int{0, 1, 2}, ts[1].Data())
+	assert.Equal(t, []bool{true, false, true}, ts[2].Data())
+
+	// default: everything as float64, streaming
+	r, err := NewCSVReader(strings.NewReader("1,2\n3,4\n5,6\n"), false)
+	require.NoError(t, err)
+	chunk, err := r.Next(2)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 2, 3, 4}, chunk[0].Data())
+	chunk, err = r.Next(2)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{5, 6}, chunk[0].Data())
+	_, err = r.Next(2)
+	assert.Equal(t, io.EOF, err)
+
+	// errors
+	_, err = ReadCSV(strings.NewReader(file), true, CSVField{Names: []string{"petal_length"}, Dtype: tensor.Float64})
+	assert.Error(t, err)
+	_, err = ReadCSV(strings.NewReader(file), false, CSVField{Names: []string{"species"}, Dtype: tensor.Float64})
+	assert.Error(t, err)
+	_, err = ReadCSV(strings.NewReader(file), true, CSVField{Columns: []int{3}, Dtype: tensor.Int})
+	assert.Error(t, err)
+	_, err = ReadCSV(strings.NewReader(file), true, CSVField{Columns: []int{4}, Dtype: tensor.Int})
+	assert.Error(t, err)
+
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "iris.csv")
+	require.NoError(t, ioutil.WriteFile(path, []byte(file), 0644))
+	ds, err := LoadCSV(path, true, CSVField{Columns: []int{0, 1}, Dtype: tensor.Float64}, CSVField{Names: []string{"species"}, Dtype: tensor.Int})
+	require.NoError(t, err)
+	assert.Equal(t, 3, ds.Len())
+}
+
+// npyFile builds a .npy file
+func npyFile(version byte, descr string, fortran bool, shape []int, data interface{}, order binary.ByteOrder) []byte {
+	dims := make([]string, len(shape))
+	for i, s := range shape {
+		dims[i] = fmt.Sprintf("%d", s)
+	}
+	shp := strings.Join(dims, ", ")
+	if len(shape) == 1 {
+		shp += ","
+	}
+	fo := "False"
+	if fortran {
+		fo = "True"
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': %s, 'shape': (%s), }", descr, fo, shp)
+
+	var buf bytes.Buffer
+	buf.WriteString(npyMagic)
+	buf.Write([]byte{version, 0})
+	if version == 1 {
+		binary.Write(&buf, binary.LittleEndian, uint16(len(header)+1))
+	} else {
+		binary.Write(&buf, binary.LittleEndian, uint32(len(header)+1))
+	}
+	buf.WriteString(header + "\n")
+	binary.Write(&buf, order, data)
+	return buf.Bytes()
+}
+
+func TestNpy(t *testing.T) {
+	// written by the tensor package
+	src := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, 2, 3, 4, 5, 6}))
+	var buf bytes.Buffer
+	require.NoError(t, src.WriteNpy(&buf))
+	got, err := ReadNpy(&buf, tensor.Float32)
+	require.NoError(t, err)
+	assert.Equal(t, tensor.Shape{2, 3}, got.Shape())
+	assert.Equal(t, []float32{1, 2, 3, 4, 5, 6}, got.Data())
+
+	// version 2, big endian, native Dtype
+	file := npyFile(2, ">i4", false, []int{4}, []int32{1, -2, 3, math.MaxInt32}, binary.BigEndian)
+	got, err = ReadNpy(bytes.NewReader(file), tensor.Dtype{})
+	require.NoError(t, err)
+	assert.Equal(t, []int32{1, -2, 3, math.MaxInt32}, got.Data())
+
+	// fortran order
+	file = npyFile(1, "<f4", true, []int{2, 3}, []float32{1, 4, 2, 5, 3, 6}, binary.LittleEndian)
+	got, err = ReadNpy(bytes.NewReader(file), tensor.Float64)
+	require.NoError(t, err)
+	assert.Equal(t, tensor.Shape{2, 3}, got.Shape())
+	assert.Equal(t, []float64{1, 2, 3, 4, 5, 6}, got.Data())
+
+	// scalars and bools
+	file = npyFile(1, "<f8", false, nil, []float64{3.14}, binary.LittleEndian)
+	got, err = ReadNpy(bytes.NewReader(file), tensor.Float64)
+	require.NoError(t, err)
+	assert.True(t, got.Shape().IsScalar())
+	assert.Equal(t, 3.14, got.Data())
+	file = npyFile(1, "|b1", false, []int{3}, []uint8{1, 0, 1}, binary.LittleEndian)
+	got, err = ReadNpy(bytes.NewReader(file), tensor.Dtype{})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, true}, got.Data())
+
+	// streaming
+	file = npyFile(1, "<u1", false, []int{5, 2}, []uint8{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, binary.LittleEndian)
+	r, err := NewNpyReader(bytes.NewReader(file))
+	require.NoError(t, err)
+	assert.Equal(t, tensor.Uint8, r.Dtype())
+	assert.Equal(t, tensor.Shape{5, 2}, r.Shape())
+	chunk, err := r.Next(3, tensor.Int)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, chunk.Data())
+	chunk, err = r.Next(3, tensor.Int)
+	require.NoError(t, err)
+	assert.Equal(t, tensor.Shape{2, 2}, chunk.Shape())
+	_, err = r.Next(3, tensor.Int)
+	assert.Equal(t, io.EOF, err)
+
+	// errors
+	_, err = ReadNpy(strings.NewReader("not a npy file"), tensor.Float64)
+	assert.Error(t, err)
+	_, err = ReadNpy(bytes.NewReader(npyFile(1, "<c16", false, []int{1}, []float64{1, 2}, binary.LittleEndian)), tensor.Float64)
+	assert.Error(t, err)
+	_, err = ReadNpy(bytes.NewReader(file[:len(file)-1]), tensor.Float64)
+	assert.Error(t, err)
+}
+
+func TestNpz(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("weights.npy")
+	require.NoError(t, err)
+	w.Write(npyFile(1, "<f4", false, []int{2, 2}, []float32{1, 2, 3, 4}, binary.LittleEndian))
+	w, err = zw.CreateHeader(&zip.FileHeader{Name: "bias.npy", Method: zip.Deflate})
+	require.NoError(t, err)
+	w.Write(npyFile(1, "<f4", false, []int{2}, []float32{5, 6}, binary.LittleEndian))
+	require.NoError(t, zw.Close())
+
+	arrays, err := ReadNpz(bytes.NewReader(buf.Bytes()), int64(buf.Len()), tensor.Float64)
+	require.NoError(t, err)
+	require.Len(t, arrays, 2)
+	assert.Equal(t, []float64{1, 2, 3, 4}, arrays["weights"].Data())
+	assert.Equal(t, []float64{5, 6}, arrays["bias"].Data())
+
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "model.npz")
+	require.NoError(t, ioutil.WriteFile(path, buf.Bytes(), 0644))
+	arrays, err = LoadNpz(path, tensor.Dtype{})
+	require.NoError(t, err)
+	assert.Equal(t, tensor.Float32, arrays["bias"].Dtype())
+}
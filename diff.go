@@ -0,0 +1,326 @@
+package gorgonia
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/awalterschulze/gographviz"
+)
+
+// GraphDiff is the structural difference between two graphs, as computed by Diff.
+type GraphDiff struct {
+	Added   Nodes        // the nodes of b that have no counterpart in a
+	Removed Nodes        // the nodes of a that have no counterpart in b
+	Changed []NodeChange // the nodes of a whose counterpart in b has a different op, type, shape, name or arguments
+
+	AddedEdges   []DiffEdge // the edges of b that are not in a
+	RemovedEdges []DiffEdge // the edges of a that are not in b
+
+	// Matches maps the nodes of a to their counterparts in b, changed or not
+	Matches map[*Node]*Node
+
+	a, b *ExprGraph
+}
+
+// NodeChange is a node of the first graph and its counterpart in the second one, which differ.
+type NodeChange struct {
+	A, B    *Node
+	Reasons []string
+}
+
+// DiffEdge is an edge from a node to its argument at the position Arg.
+type DiffEdge struct {
+	From, To *Node
+	Arg      int
+}
+
+// Diff compares two graphs, e.g. the graph of a model before and after a refactoring.
+//
+// The nodes are matched by structure rather than by ID: the inputs by name, and the other nodes by op (as hashed by Hashcode),
+// arguments, shape and type. The nodes are first matched from the inputs up. The nodes that differ are then paired when they
+// are the same argument of matched nodes, or when they apply the same op to the same arguments, and reported as changed.
+// The nodes left are the added and removed ones.
+func Diff(a, b *ExprGraph) *GraphDiff {
+	d := &GraphDiff{Matches: make(map[*Node]*Node), a: a, b: b}
+	as, bs := sortedNodes(a), sortedNodes(b)
+	matched := make(map[*Node]*Node) // b to a
+
+	pair := func(n, m *Node) {
+		d.Matches[n] = m
+		matched[m] = n
+	}
+
+	// the candidates of a node of a are the nodes of b whose arguments are the counterparts of its own
+	byArgs := make(map[string]Nodes)
+	for _, m := range bs {
+		key := argsKey(m.children)
+		byArgs[key] = append(byArgs[key], m)
+	}
+	counterparts := func(n *Node) (key string, ok bool) {
+		args := make(Nodes, len(n.children))
+		for i, child := range n.children {
+			if args[i], ok = d.Matches[child]; !ok {
+				return "", false
+			}
+		}
+		return argsKey(args), true
+	}
+
+	for progress := true; progress; {
+		progress = false
+
+		// bottom up, the nodes with the same arguments: unchanged if they are the same, changed otherwise
+		for _, exact := range []bool{true, false} {
+			for _, n := range as {
+				// the leaves are only paired with a different leaf when they are the same argument of paired nodes
+				if _, ok := d.Matches[n]; ok || (!exact && len(n.children) == 0) {
+					continue
+				}
+				key, ok := counterparts(n)
+				if !ok {
+					continue
+				}
+				for _, m := range byArgs[key] {
+					if _, ok := matched[m]; ok || n.isInput() != m.isInput() {
+						continue
+					}
+					if !exact || localEq(n, m) {
+						pair(n, m)
+						progress = true
+						break
+					}
+				}
+			}
+		}
+
+		// top down, the arguments at the same position of paired nodes
+		for _, n := range as {
+			m, ok := d.Matches[n]
+			if !ok {
+				continue
+			}
+			for i := 0; i < len(n.children) && i < len(m.children); i++ {
+				nc, mc := n.children[i], m.children[i]
+				_, nok := d.Matches[nc]
+				_, mok := matched[mc]
+				if !nok && !mok && nc.isInput() == mc.isInput() {
+					pair(nc, mc)
+					progress = true
+				}
+			}
+		}
+		if progress {
+			continue
+		}
+
+		// the same op applied to other arguments, if there is no other candidate
+		for _, n := range as {
+			if _, ok := d.Matches[n]; ok || n.isInput() {
+				continue
+			}
+			var candidate *Node
+			count := 0
+			for _, m := range bs {
+				if _, ok := matched[m]; ok || m.isInput() || len(m.children) != len(n.children) || !localEq(n, m) {
+					continue
+				}
+				candidate = m
+				count++
+			}
+			if count == 1 {
+				pair(n, candidate)
+				progress = true
+			}
+		}
+	}
+
+	for _, n := range as {
+		m, ok := d.Matches[n]
+		if !ok {
+			d.Removed = append(d.Removed, n)
+			continue
+		}
+		if reasons := d.changes(n, m); len(reasons) > 0 {
+			d.Changed = append(d.Changed, NodeChange{A: n, B: m, Reasons: reasons})
+		}
+	}
+	for _, m := range bs {
+		if _, ok := matched[m]; !ok {
+			d.Added = append(d.Added, m)
+		}
+	}
+
+	for _, n := range as {
+		m := d.Matches[n]
+		for i, child := range n.children {
+			if m == nil || i >= len(m.children) || d.Matches[child] != m.children[i] {
+				d.RemovedEdges = append(d.RemovedEdges, DiffEdge{From: n, To: child, Arg: i})
+			}
+		}
+	}
+	for _, m := range bs {
+		n := matched[m]
+		for i, child := range m.children {
+			if n == nil || i >= len(n.children) || d.Matches[n.children[i]] != child {
+				d.AddedEdges = append(d.AddedEdges, DiffEdge{From: m, To: child, Arg: i})
+			}
+		}
+	}
+	return d
+}
+
+func sortedNodes(g *ExprGraph) Nodes {
+	retVal := make(Nodes, len(g.all))
+	copy(retVal, g.all)
+	sort.Slice(retVal, func(i, j int) bool { return retVal[i].id < retVal[j].id })
+	return retVal
+}
+
+func argsKey(args Nodes) string {
+	var buf bytes.Buffer
+	for _, arg := range args {
+		fmt.Fprintf(&buf, "%p,", arg)
+	}
+	return buf.String()
+}
+
+// localEq compares the nodes but not their arguments: the inputs by name, type and shape, and the others by op, type and shape
+func localEq(a, b *Node) bool {
+	if a.isInput() != b.isInput() || a.t != b.t || !a.shape.Eq(b.shape) {
+		return false
+	}
+	if a.isInput() {
+		return a.name == b.name
+	}
+	return a.op.Hashcode() == b.op.Hashcode()
+}
+
+// changes lists how a node and its counterpart differ
+func (d *GraphDiff) changes(n, m *Node) (reasons []string) {
+	if n.isInput() && n.name != m.name {
+		reasons = append(reasons, fmt.Sprintf("name %s → %s", n.name, m.name))
+	}
+	if !n.isInput() && n.op.Hashcode() != m.op.Hashcode() {
+		reasons = append(reasons, fmt.Sprintf("op %v → %v", n.op, m.op))
+	}
+	if n.t != m.t {
+		reasons = append(reasons, fmt.Sprintf("type %v → %v", n.t, m.t))
+	}
+	if !n.shape.Eq(m.shape) {
+		reasons = append(reasons, fmt.Sprintf("shape %v → %v", n.shape, m.shape))
+	}
+	if len(n.children) != len(m.children) {
+		return append(reasons, fmt.Sprintf("%d arguments → %d", len(n.children), len(m.children)))
+	}
+	for i, child := range n.children {
+		if d.Matches[child] != m.children[i] {
+			reasons = append(reasons, fmt.Sprintf("argument %d %s → %s", i, child.Name(), m.children[i].Name()))
+		}
+	}
+	return reasons
+}
+
+// Equal returns true if the graphs have the same structure.
+func (d *GraphDiff) Equal() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 && len(d.AddedEdges) == 0 && len(d.RemovedEdges) == 0
+}
+
+// String is the human readable report of the differences: the removed nodes and edges start with -, the added ones with +,
+// and the changed nodes with ~.
+func (d *GraphDiff) String() string {
+	if d.Equal() {
+		return "no differences\n"
+	}
+	var buf bytes.Buffer
+	for _, n := range d.Removed {
+		fmt.Fprintf(&buf, "- %s :: %v %v\n", n.Name(), n.t, n.shape)
+	}
+	for _, n := range d.Added {
+		fmt.Fprintf(&buf, "+ %s :: %v %v\n", n.Name(), n.t, n.shape)
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(&buf, "~ %s → %s: %s\n", c.A.Name(), c.B.Name(), strings.Join(c.Reasons, ", "))
+	}
+	for _, e := range d.RemovedEdges {
+		fmt.Fprintf(&buf, "- %s → %s (argument %d)\n", e.From.Name(), e.To.Name(), e.Arg)
+	}
+	for _, e := range d.AddedEdges {
+		fmt.Fprintf(&buf, "+ %s → %s (argument %d)\n", e.From.Name(), e.To.Name(), e.Arg)
+	}
+	return buf.String()
+}
+
+// ToDot draws the union of both graphs, in graphviz format: the nodes and the edges of b, and the removed ones of a.
+// The added nodes and edges are green, the removed ones red and dashed, and the changed nodes orange.
+func (d *GraphDiff) ToDot() string {
+	gv := gographviz.NewEscape()
+	gv.SetName(fullGraphName)
+	gv.SetDir(true)
+	gv.AddAttr(fullGraphName, "rankdir", "TB")
+
+	status := make(map[*Node]string)
+	for _, n := range d.Added {
+		status[n] = "green"
+	}
+	for _, n := range d.Removed {
+		status[n] = "red"
+	}
+	for _, c := range d.Changed {
+		status[c.B] = "orange"
+	}
+
+	id := func(n *Node) string { return fmt.Sprintf("Node_%p", n) }
+	addNode := func(n *Node) {
+		label := fmt.Sprintf("%s\n%v\n%v", n.Name(), n.t, n.shape)
+		if n.op != nil {
+			label = fmt.Sprintf("%s\n%v\n%v %v", n.Name(), n.op, n.t, n.shape)
+		}
+		attrs := map[string]string{"label": label, "shape": "box", "fontname": "monospace"}
+		if color, ok := status[n]; ok {
+			attrs["color"] = color
+			attrs["penwidth"] = "2"
+			if color == "red" {
+				attrs["style"] = "dashed"
+			}
+		}
+		gv.AddNode(fullGraphName, id(n), attrs)
+	}
+	for _, n := range sortedNodes(d.b) {
+		addNode(n)
+	}
+	for _, n := range d.Removed {
+		addNode(n)
+	}
+
+	// the unchanged edges of b, then the added and the removed ones. The ends of the removed edges are drawn as their counterparts in b
+	added := make(map[DiffEdge]bool)
+	for _, e := range d.AddedEdges {
+		added[e] = true
+	}
+	inB := func(n *Node) *Node {
+		if m, ok := d.Matches[n]; ok {
+			return m
+		}
+		return n
+	}
+	for _, n := range sortedNodes(d.b) {
+		for i, child := range n.children {
+			e := DiffEdge{From: n, To: child, Arg: i}
+			attrs := map[string]string{"taillabel": fmt.Sprintf(" %d ", i)}
+			if added[e] {
+				attrs["color"] = "green"
+			}
+			gv.AddEdge(id(n), id(child), true, attrs)
+		}
+	}
+	for _, e := range d.RemovedEdges {
+		gv.AddEdge(id(inB(e.From)), id(inB(e.To)), true, map[string]string{
+			"taillabel": fmt.Sprintf(" %d ", e.Arg),
+			"color":     "red",
+			"style":     "dashed",
+		})
+	}
+	return gv.String()
+}
@@ -0,0 +1,87 @@
+package gorgonia
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/tensor"
+)
+
+// diffModel builds cost = Σ(act(x·w [+ b])), with the input x named xName
+func diffModel(xName string, act func(*Node) (*Node, error), bias bool) *ExprGraph {
+	g := NewGraph()
+	x := NewMatrix(g, tensor.Float64, WithShape(2, 3), WithName(xName))
+	w := NewMatrix(g, tensor.Float64, WithShape(3, 4), WithName("w"))
+	h := Must(Mul(x, w))
+	if bias {
+		b := NewMatrix(g, tensor.Float64, WithShape(2, 4), WithName("b"))
+		h = Must(Add(h, b))
+	}
+	Must(Sum(Must(act(h))))
+	return g
+}
+
+func TestDiff(t *testing.T) {
+	a := diffModel("x", Tanh, false)
+
+	d := Diff(a, diffModel("x", Tanh, false))
+	assert.True(t, d.Equal(), d.String())
+	assert.Equal(t, "no differences\n", d.String())
+	assert.Len(t, d.Matches, len(a.AllNodes()))
+
+	t.Run("op", func(t *testing.T) {
+		d := Diff(a, diffModel("x", Sigmoid, false))
+		assert.False(t, d.Equal())
+		assert.Empty(t, d.Added)
+		assert.Empty(t, d.Removed)
+		assert.Empty(t, d.AddedEdges)
+		assert.Empty(t, d.RemovedEdges)
+		require.Len(t, d.Changed, 1)
+		assert.Equal(t, []string{"op tanh → sigmoid"}, d.Changed[0].Reasons)
+		assert.Contains(t, d.String(), "~ tanh(%2) → sigmoid(%2): op tanh → sigmoid")
+	})
+
+	t.Run("renamed input", func(t *testing.T) {
+		d := Diff(a, diffModel("input", Tanh, false))
+		assert.Empty(t, d.Added)
+		assert.Empty(t, d.Removed)
+		require.Len(t, d.Changed, 1)
+		assert.Equal(t, "x", d.Changed[0].A.Name())
+		assert.Equal(t, []string{"name x → input"}, d.Changed[0].Reasons)
+	})
+
+	t.Run("added nodes", func(t *testing.T) {
+		b := diffModel("x", Tanh, true)
+		d := Diff(a, b)
+		assert.Empty(t, d.Removed)
+		var added []string
+		for _, n := range d.Added {
+			added = append(added, n.Name())
+		}
+		assert.Equal(t, []string{"b", "+ false(%2, %3)"}, added)
+
+		// tanh now applies to the sum
+		require.Len(t, d.Changed, 1)
+		assert.Equal(t, "tanh", d.Changed[0].A.Op().String())
+		assert.Equal(t, []string{"argument 0 A × B(%0, %1) → + false(%2, %3)"}, d.Changed[0].Reasons)
+		require.Len(t, d.RemovedEdges, 1)
+		assert.Equal(t, d.Changed[0].A, d.RemovedEdges[0].From)
+		assert.Len(t, d.AddedEdges, 3)
+
+		dot := d.ToDot()
+		assert.Equal(t, 5, strings.Count(dot, "color=green"), dot)
+		assert.Contains(t, dot, "color=orange")
+		assert.Contains(t, dot, "style=dashed")
+	})
+
+	t.Run("removed nodes", func(t *testing.T) {
+		d := Diff(diffModel("x", Tanh, true), a)
+		assert.Empty(t, d.Added)
+		assert.Len(t, d.Removed, 2)
+		assert.Len(t, d.AddedEdges, 1)
+		assert.Len(t, d.RemovedEdges, 3)
+		assert.Contains(t, d.String(), "- b :: Matrix float64 (2, 4)")
+	})
+}
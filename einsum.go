@@ -0,0 +1,356 @@
+package gorgonia
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Einsum evaluates the Einstein summation convention on the operands. The spec is written in the same notation NumPy uses:
+//		Einsum("ij,jk->ik", a, b)         // matrix multiplication
+//		Einsum("bhqd,bhkd->bhqk", q, k)   // attention scores
+//		Einsum("ii->i", a)                // NOT supported - an axis may only appear once per operand
+//
+// Each operand is labelled by one letter per axis. Letters that do not appear in the output are summed over.
+// If the output ("->...") is omitted, the output consists of the letters that appear exactly once, in alphabetical order.
+//
+// Einsum does not introduce a new Op. The contractions are planned greedily - at every step the pair of operands
+// with the smallest intermediate result is contracted first - and each contraction is lowered onto Transpose, Reshape,
+// Mul/BatchedMatMul, HadamardProd and Sum. Hence the result is differentiable like any other expression.
+func Einsum(spec string, operands ...*Node) (retVal *Node, err error) {
+	var e *einsum
+	if e, err = parseEinsum(spec, operands); err != nil {
+		return nil, err
+	}
+	return e.eval()
+}
+
+// einsumTerm is an operand of an einsum, along with the letters labelling its axes
+type einsumTerm struct {
+	n       *Node
+	letters string
+}
+
+type einsum struct {
+	terms  []einsumTerm
+	output string
+	sizes  map[rune]int
+}
+
+func parseEinsum(spec string, operands Nodes) (*einsum, error) {
+	spec = strings.Replace(spec, " ", "", -1)
+
+	var inputs, output string
+	explicit := strings.Contains(spec, "->")
+	if explicit {
+		parts := strings.Split(spec, "->")
+		if len(parts) != 2 {
+			return nil, errors.Errorf("Einsum spec %q has more than one \"->\"", spec)
+		}
+		inputs, output = parts[0], parts[1]
+	} else {
+		inputs = spec
+	}
+
+	terms := strings.Split(inputs, ",")
+	if len(terms) != len(operands) {
+		return nil, errors.Errorf("Einsum spec %q describes %d operands. Got %d operands instead", spec, len(terms), len(operands))
+	}
+
+	e := &einsum{sizes: make(map[rune]int)}
+	counts := make(map[rune]int)
+	for i, term := range terms {
+		n := operands[i]
+		if len(term) != n.Dims() {
+			return nil, errors.Errorf("Einsum operand %d is labelled %q, but it has %d dimensions (shape %v)", i, term, n.Dims(), n.Shape())
+		}
+		seen := make(map[rune]struct{})
+		for j, l := range term {
+			if !isEinsumLetter(l) {
+				return nil, errors.Errorf("Einsum spec %q contains an invalid label %q", spec, l)
+			}
+			if _, ok := seen[l]; ok {
+				return nil, errors.Errorf("Einsum operand %d is labelled %q. Repeated labels within an operand (diagonals) are not supported", i, term)
+			}
+			seen[l] = empty
+			counts[l]++
+
+			size := n.Shape()[j]
+			if s, ok := e.sizes[l]; ok && s != size {
+				return nil, errors.Errorf("Einsum label %q has inconsistent sizes: %d and %d", l, s, size)
+			}
+			e.sizes[l] = size
+		}
+		e.terms = append(e.terms, einsumTerm{n: n, letters: term})
+	}
+
+	if !explicit {
+		var letters []rune
+		for l, c := range counts {
+			if c == 1 {
+				letters = append(letters, l)
+			}
+		}
+		sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+		output = string(letters)
+	}
+
+	seen := make(map[rune]struct{})
+	for _, l := range output {
+		if _, ok := counts[l]; !ok {
+			return nil, errors.Errorf("Einsum output label %q does not appear in any of the operands", l)
+		}
+		if _, ok := seen[l]; ok {
+			return nil, errors.Errorf("Einsum output label %q is repeated", l)
+		}
+		seen[l] = empty
+	}
+	e.output = output
+	return e, nil
+}
+
+func isEinsumLetter(l rune) bool { return (l >= 'a' && l <= 'z') || (l >= 'A' && l <= 'Z') }
+
+// needed returns the letters that are needed by the output or by any of the terms other than the ones excluded
+func (e *einsum) needed(excluded ...int) string {
+	var buf strings.Builder
+	buf.WriteString(e.output)
+outer:
+	for i, t := range e.terms {
+		for _, x := range excluded {
+			if i == x {
+				continue outer
+			}
+		}
+		buf.WriteString(t.letters)
+	}
+	return buf.String()
+}
+
+func (e *einsum) eval() (retVal *Node, err error) {
+	// sum out the axes that are private to each operand as early as possible
+	for i := range e.terms {
+		if e.terms[i], err = e.sumOut(e.terms[i], e.needed(i)); err != nil {
+			return nil, err
+		}
+	}
+
+	for len(e.terms) > 1 {
+		i, j := e.plan()
+		var t einsumTerm
+		if t, err = e.contract(e.terms[i], e.terms[j], e.needed(i, j)); err != nil {
+			return nil, err
+		}
+
+		// replace the pair with the result
+		terms := make([]einsumTerm, 0, len(e.terms)-1)
+		for k := range e.terms {
+			if k != i && k != j {
+				terms = append(terms, e.terms[k])
+			}
+		}
+		e.terms = append(terms, t)
+	}
+
+	t := e.terms[0]
+	if t, err = e.sumOut(t, e.output); err != nil {
+		return nil, err
+	}
+	if t, err = e.permute(t, e.output); err != nil {
+		return nil, err
+	}
+	return t.n, nil
+}
+
+// plan picks the pair of terms whose contraction yields the smallest intermediate result.
+func (e *einsum) plan() (int, int) {
+	bi, bj, best := 0, 1, -1
+	for i := 0; i < len(e.terms); i++ {
+		for j := i + 1; j < len(e.terms); j++ {
+			keep := e.needed(i, j)
+			size := 1
+			for _, l := range einsumUnion(e.terms[i].letters, e.terms[j].letters) {
+				if strings.ContainsRune(keep, l) {
+					size *= e.sizes[l]
+				}
+			}
+			if best < 0 || size < best {
+				bi, bj, best = i, j, size
+			}
+		}
+	}
+	return bi, bj
+}
+
+// sumOut sums the axes of the term whose letters are not in keep
+func (e *einsum) sumOut(t einsumTerm, keep string) (einsumTerm, error) {
+	var axes []int
+	var letters []rune
+	for i, l := range t.letters {
+		if strings.ContainsRune(keep, l) {
+			letters = append(letters, l)
+			continue
+		}
+		axes = append(axes, i)
+	}
+	if len(axes) == 0 {
+		return t, nil
+	}
+
+	n, err := Sum(t.n, axes...)
+	if err != nil {
+		return t, errors.Wrapf(err, "Einsum failed to sum %q along %v", t.letters, axes)
+	}
+	return einsumTerm{n: n, letters: string(letters)}, nil
+}
+
+// permute transposes the term so that its axes are in the order given by letters
+func (e *einsum) permute(t einsumTerm, letters string) (einsumTerm, error) {
+	if t.letters == letters {
+		return t, nil
+	}
+	axes := make([]int, 0, len(letters))
+	for _, l := range letters {
+		axes = append(axes, strings.IndexRune(t.letters, l))
+	}
+	n, err := Transpose(t.n, axes...)
+	if err != nil {
+		return t, errors.Wrapf(err, "Einsum failed to transpose %q into %q", t.letters, letters)
+	}
+	return einsumTerm{n: n, letters: letters}, nil
+}
+
+// reshape reshapes the node if it does not already have the given shape.
+func (e *einsum) reshape(n *Node, s tensor.Shape) (*Node, error) {
+	if SameShape(n.Shape(), s) {
+		return n, nil
+	}
+	return Reshape(n, s)
+}
+
+func (e *einsum) size(letters string) int {
+	retVal := 1
+	for _, l := range letters {
+		retVal *= e.sizes[l]
+	}
+	return retVal
+}
+
+func (e *einsum) shape(letters string) tensor.Shape {
+	retVal := make(tensor.Shape, 0, len(letters))
+	for _, l := range letters {
+		retVal = append(retVal, e.sizes[l])
+	}
+	return retVal
+}
+
+// contract contracts two terms. Letters that are in keep are kept, the rest of the shared letters are summed over.
+//
+// The terms are transposed into (batch, free, contracted) and (batch, contracted, free) respectively, flattened
+// into matrices, and multiplied with Mul (or BatchedMatMul if there are batch axes).
+func (e *einsum) contract(a, b einsumTerm, keep string) (retVal einsumTerm, err error) {
+	if a, err = e.sumOut(a, keep+b.letters); err != nil {
+		return
+	}
+	if b, err = e.sumOut(b, keep+a.letters); err != nil {
+		return
+	}
+
+	var batch, contracted, freeA, freeB string
+	for _, l := range a.letters {
+		switch {
+		case !strings.ContainsRune(b.letters, l):
+			freeA += string(l)
+		case strings.ContainsRune(keep, l):
+			batch += string(l)
+		default:
+			contracted += string(l)
+		}
+	}
+	for _, l := range b.letters {
+		if !strings.ContainsRune(a.letters, l) {
+			freeB += string(l)
+		}
+	}
+
+	switch {
+	case contracted == "" && freeA == "" && freeB == "":
+		// elementwise
+		if b, err = e.permute(b, a.letters); err != nil {
+			return
+		}
+		var n *Node
+		if n, err = HadamardProd(a.n, b.n); err != nil {
+			return retVal, errors.Wrapf(err, "Einsum failed to multiply %q and %q", a.letters, b.letters)
+		}
+		return einsumTerm{n: n, letters: a.letters}, nil
+	case batch == "" && freeA == "" && freeB == "":
+		// dot product
+		var x, y *Node
+		if a, err = e.permute(a, contracted); err != nil {
+			return
+		}
+		if b, err = e.permute(b, contracted); err != nil {
+			return
+		}
+		if x, err = e.reshape(a.n, tensor.Shape{e.size(contracted)}); err != nil {
+			return
+		}
+		if y, err = e.reshape(b.n, tensor.Shape{e.size(contracted)}); err != nil {
+			return
+		}
+		var n *Node
+		if n, err = Mul(x, y); err != nil {
+			return retVal, errors.Wrapf(err, "Einsum failed to contract %q and %q", a.letters, b.letters)
+		}
+		return einsumTerm{n: n}, nil
+	}
+
+	if a, err = e.permute(a, batch+freeA+contracted); err != nil {
+		return
+	}
+	if b, err = e.permute(b, batch+contracted+freeB); err != nil {
+		return
+	}
+
+	var x, y, n *Node
+	if batch == "" {
+		if x, err = e.reshape(a.n, tensor.Shape{e.size(freeA), e.size(contracted)}); err != nil {
+			return
+		}
+		if y, err = e.reshape(b.n, tensor.Shape{e.size(contracted), e.size(freeB)}); err != nil {
+			return
+		}
+		n, err = Mul(x, y)
+	} else {
+		if x, err = e.reshape(a.n, tensor.Shape{e.size(batch), e.size(freeA), e.size(contracted)}); err != nil {
+			return
+		}
+		if y, err = e.reshape(b.n, tensor.Shape{e.size(batch), e.size(contracted), e.size(freeB)}); err != nil {
+			return
+		}
+		n, err = BatchedMatMul(x, y)
+	}
+	if err != nil {
+		return retVal, errors.Wrapf(err, "Einsum failed to contract %q and %q", a.letters, b.letters)
+	}
+
+	letters := batch + freeA + freeB
+	if n, err = e.reshape(n, e.shape(letters)); err != nil {
+		return
+	}
+	return einsumTerm{n: n, letters: letters}, nil
+}
+
+// einsumUnion returns the letters of a followed by the letters of b that are not in a
+func einsumUnion(a, b string) string {
+	retVal := a
+	for _, l := range b {
+		if !strings.ContainsRune(a, l) {
+			retVal += string(l)
+		}
+	}
+	return retVal
+}
@@ -0,0 +1,190 @@
+package gorgonia
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/tensor"
+)
+
+// einsumRef is a naive reference implementation of Einsum for float64 tensors. It loops over every combination of the labels.
+func einsumRef(inputs []string, output string, shapes []tensor.Shape, data [][]float64) []float64 {
+	sizes := make(map[rune]int)
+	var letters []rune
+	for i, in := range inputs {
+		for j, l := range in {
+			if _, ok := sizes[l]; !ok {
+				letters = append(letters, l)
+			}
+			sizes[l] = shapes[i][j]
+		}
+	}
+
+	offset := func(term string, idx map[rune]int) int {
+		var off, stride int = 0, 1
+		for i := len(term) - 1; i >= 0; i-- {
+			l := rune(term[i])
+			off += idx[l] * stride
+			stride *= sizes[l]
+		}
+		return off
+	}
+
+	outSize := 1
+	for _, l := range output {
+		outSize *= sizes[l]
+	}
+	retVal := make([]float64, outSize)
+
+	idx := make(map[rune]int)
+	var loop func(int)
+	loop = func(k int) {
+		if k == len(letters) {
+			prod := 1.0
+			for i, in := range inputs {
+				prod *= data[i][offset(in, idx)]
+			}
+			retVal[offset(output, idx)] += prod
+			return
+		}
+		for v := 0; v < sizes[letters[k]]; v++ {
+			idx[letters[k]] = v
+			loop(k + 1)
+		}
+	}
+	loop(0)
+	return retVal
+}
+
+func TestEinsum(t *testing.T) {
+	testCases := []struct {
+		spec   string
+		shapes []tensor.Shape
+		output string // the explicit output, for the reference implementation
+	}{
+		{"ij,jk->ik", []tensor.Shape{{2, 3}, {3, 4}}, "ik"},
+		{"ij,jk", []tensor.Shape{{2, 3}, {3, 4}}, "ik"},
+		{"ij->ji", []tensor.Shape{{2, 3}}, "ji"},
+		{"ij->", []tensor.Shape{{2, 3}}, ""},
+		{"ijk->j", []tensor.Shape{{2, 3, 4}}, "j"},
+		{"i,i->", []tensor.Shape{{5}, {5}}, ""},
+		{"i,j->ij", []tensor.Shape{{2}, {3}}, "ij"},
+		{"ij,ij->ij", []tensor.Shape{{2, 3}, {2, 3}}, "ij"},
+		{"ij,ji->ij", []tensor.Shape{{2, 3}, {3, 2}}, "ij"},
+		{"bij,bjk->bik", []tensor.Shape{{2, 3, 4}, {2, 4, 5}}, "bik"},
+		{"bhqd,bhkd->bhqk", []tensor.Shape{{2, 2, 3, 4}, {2, 2, 5, 4}}, "bhqk"},
+		{"ijk,jl,lk->i", []tensor.Shape{{2, 3, 4}, {3, 5}, {5, 4}}, "i"},
+		{"ab,bc,cd->da", []tensor.Shape{{2, 3}, {3, 4}, {4, 2}}, "da"},
+		{"ij,k->ki", []tensor.Shape{{2, 3}, {4}}, "ki"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			c := require.New(t)
+			g := NewGraph()
+
+			var operands Nodes
+			var data [][]float64
+			for i, s := range tc.shapes {
+				backing := make([]float64, s.TotalSize())
+				for j := range backing {
+					backing[j] = float64((i+1)*j%7) - 3
+				}
+				data = append(data, backing)
+				v := tensor.New(tensor.WithShape(s...), tensor.WithBacking(backing))
+				operands = append(operands, NewTensor(g, tensor.Float64, s.Dims(), WithShape(s...), WithName(fmt.Sprintf("operand%d", i)), WithValue(v)))
+			}
+
+			inputs := strings.Split(strings.Split(tc.spec, "->")[0], ",")
+			expected := einsumRef(inputs, tc.output, tc.shapes, data)
+
+			expectedShape := make(tensor.Shape, len(tc.output))
+			for i, l := range tc.output {
+				for j, in := range inputs {
+					if k := strings.IndexRune(in, l); k >= 0 {
+						expectedShape[i] = tc.shapes[j][k]
+						break
+					}
+				}
+			}
+
+			out, err := Einsum(tc.spec, operands...)
+			c.NoError(err)
+
+			m := NewTapeMachine(g)
+			defer m.Close()
+			c.NoError(m.RunAll())
+
+			if len(tc.output) == 0 {
+				c.True(out.Shape().IsScalar())
+				c.InDelta(expected[0], out.Value().Data().(float64), 1e-9)
+				return
+			}
+			c.Equal(expectedShape, out.Shape())
+			c.InDeltaSlice(expected, out.Value().Data(), 1e-9)
+		})
+	}
+}
+
+func TestEinsumErrors(t *testing.T) {
+	g := NewGraph()
+	a := NewMatrix(g, tensor.Float64, WithShape(2, 3), WithName("a"))
+	b := NewMatrix(g, tensor.Float64, WithShape(4, 5), WithName("b"))
+
+	specs := []string{
+		"ij,jk->ik",  // 3 != 4
+		"ij->ik",     // k is not in the inputs
+		"ii->i",      // diagonals are not supported
+		"ijk->i",     // too many labels
+		"ij,jk,kl",   // too many operands
+		"ij,kl->ii",  // repeated output
+		"i1,kl->ik",  // bad label
+		"ij,kl->i->", // two arrows
+	}
+	for _, spec := range specs {
+		operands := Nodes{a, b}
+		if strings.Count(strings.Split(spec, "->")[0], ",") == 0 {
+			operands = Nodes{a}
+		}
+		_, err := Einsum(spec, operands...)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestEinsumGrad(t *testing.T) {
+	c := require.New(t)
+
+	build := func(einsum bool) (Nodes, *ExprGraph) {
+		g := NewGraph()
+		x := NewTensor(g, tensor.Float64, 3, WithShape(2, 3, 4), WithName("x"), WithValue(tensor.New(tensor.WithShape(2, 3, 4), tensor.WithBacking(tensor.Range(tensor.Float64, 0, 24)))))
+		y := NewTensor(g, tensor.Float64, 3, WithShape(2, 5, 4), WithName("y"), WithValue(tensor.New(tensor.WithShape(2, 5, 4), tensor.WithBacking(tensor.Range(tensor.Float64, 0, 40)))))
+
+		var z *Node
+		if einsum {
+			z = Must(Einsum("bik,bjk->bij", x, y))
+		} else {
+			z = Must(BatchedMatMul(x, y, false, true))
+		}
+		cost := Must(Sum(Must(Square(z))))
+		grads, err := Grad(cost, x, y)
+		c.NoError(err)
+		return grads, g
+	}
+
+	expected, g1 := build(false)
+	got, g2 := build(true)
+
+	m1 := NewTapeMachine(g1)
+	defer m1.Close()
+	c.NoError(m1.RunAll())
+	m2 := NewTapeMachine(g2)
+	defer m2.Close()
+	c.NoError(m2.RunAll())
+
+	for i := range expected {
+		c.InDeltaSlice(expected[i].Value().Data(), got[i].Value().Data(), 1e-6)
+	}
+}
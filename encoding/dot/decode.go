@@ -0,0 +1,367 @@
+package dot
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/graph/formats/dot"
+	"gonum.org/v1/gonum/graph/formats/dot/ast"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// dtypes are the dtypes that the types of the labels may name
+var dtypes = []tensor.Dtype{
+	tensor.Float64, tensor.Float32, tensor.Int, tensor.Int64, tensor.Int32, tensor.Int16, tensor.Int8,
+	tensor.Uint, tensor.Uint64, tensor.Uint32, tensor.Uint16, tensor.Uint8, tensor.Bool, tensor.Complex64, tensor.Complex128,
+}
+
+// dotNode is a node as read from the DOT
+type dotNode struct {
+	dotID string
+	attrs map[string]string
+
+	id    int64
+	name  string
+	op    string
+	typ   string // empty if the label has no type
+	shape tensor.Shape
+	args  []int64 // the IDs of the arguments, in order, if the node has a comment listing them
+
+	edges []string // the DOT IDs of the nodes the edges from this node go to, in the order of the file
+}
+
+// Unmarshal rebuilds an ExprGraph from the DOT written by Marshal.
+//
+// The nodes are rebuilt from their labels: the inputs from their type and shape, the scalar constants from their value,
+// and the other nodes by applying their op, which is rebuilt by the builder registered for its label (see RegisterOp).
+// The order of the arguments of an op is read from the comment of its node. A DOT written by hand may omit it,
+// in which case the arguments are the nodes its edges go to, in the order of the file.
+//
+// The type and the shape of every rebuilt node are checked against the ones of the label.
+func Unmarshal(data []byte) (*gorgonia.ExprGraph, error) {
+	f, err := dot.ParseBytes(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse the DOT")
+	}
+	if len(f.Graphs) != 1 {
+		return nil, errors.Errorf("Expected a single graph. Got %d", len(f.Graphs))
+	}
+	if !f.Graphs[0].Directed {
+		return nil, errors.New("Expected a directed graph")
+	}
+
+	byDOTID := make(map[string]*dotNode)
+	if err = collect(f.Graphs[0].Stmts, byDOTID); err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]*dotNode, len(byDOTID))
+	nodes := make([]*dotNode, 0, len(byDOTID))
+	for _, n := range byDOTID {
+		if err = n.parse(); err != nil {
+			return nil, err
+		}
+		if other, ok := byID[n.id]; ok {
+			return nil, errors.Errorf("%v and %v have the same ID %#x", other.dotID, n.dotID, n.id)
+		}
+		byID[n.id] = n
+		nodes = append(nodes, n)
+	}
+	for _, n := range nodes {
+		if n.args != nil {
+			continue
+		}
+		for _, to := range n.edges {
+			n.args = append(n.args, byDOTID[to].id)
+		}
+	}
+
+	// the children are created before their parents, so the nodes are rebuilt in the same order, and get the same IDs
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].id < nodes[j].id })
+	b := &builder{g: gorgonia.NewGraph(), byID: byID, built: make(map[int64]*gorgonia.Node), building: make(map[int64]bool)}
+	for _, n := range nodes {
+		if _, err = b.build(n); err != nil {
+			return nil, err
+		}
+	}
+	return b.g, nil
+}
+
+// collect walks the statements of the graph and its subgraphs. Marshal declares the nodes several times, so their attributes are merged.
+func collect(stmts []ast.Stmt, nodes map[string]*dotNode) error {
+	get := func(id string) *dotNode {
+		id = unquote(id)
+		n, ok := nodes[id]
+		if !ok {
+			n = &dotNode{dotID: id, attrs: make(map[string]string)}
+			nodes[id] = n
+		}
+		return n
+	}
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.NodeStmt:
+			n := get(s.Node.ID)
+			for _, a := range s.Attrs {
+				n.attrs[a.Key] = unquote(a.Val)
+			}
+		case *ast.EdgeStmt:
+			from, ok := s.From.(*ast.Node)
+			if !ok {
+				return errors.Errorf("Edges from subgraphs are not supported: %v", s)
+			}
+			u := get(from.ID)
+			for e := s.To; e != nil; e = e.To {
+				to, ok := e.Vertex.(*ast.Node)
+				if !ok {
+					return errors.Errorf("Edges to subgraphs are not supported: %v", s)
+				}
+				u.edges = append(u.edges, unquote(to.ID))
+				u = get(to.ID)
+			}
+		case *ast.Subgraph:
+			if err := collect(s.Stmts, nodes); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unquote removes the quotes of a DOT string
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	return strings.Replace(s[1:len(s)-1], `\"`, `"`, -1)
+}
+
+// unescape reverts the escaping of the labels by node.Attributes
+func unescape(s string) string {
+	return html.UnescapeString(strings.Replace(s, "const|", "const", -1))
+}
+
+// parse reads the label {{name|id}|{Op|op}|{Type|type}|{Shape|shape}}, and the comment listing the arguments.
+func (n *dotNode) parse() error {
+	label, ok := n.attrs["label"]
+	if !ok {
+		return errors.Errorf("%v has no label", n.dotID)
+	}
+	if !strings.HasPrefix(label, "{{") || !strings.HasSuffix(label, "}}") {
+		return errors.Errorf("Unable to parse the label of %v: %q", n.dotID, label)
+	}
+	fields := strings.Split(label[2:len(label)-2], "}|{")
+	sep := strings.LastIndex(fields[0], "|")
+	if sep < 0 {
+		return errors.Errorf("Unable to parse the name and ID of %v: %q", n.dotID, fields[0])
+	}
+	n.name = unescape(fields[0][:sep])
+	var err error
+	if n.id, err = strconv.ParseInt(fields[0][sep+1:], 0, 64); err != nil {
+		return errors.Wrapf(err, "Unable to parse the ID of %v", n.dotID)
+	}
+
+	for _, field := range fields[1:] {
+		sep := strings.Index(field, "|")
+		if sep < 0 {
+			return errors.Errorf("Unable to parse the label of %v: %q", n.dotID, field)
+		}
+		v := unescape(field[sep+1:])
+		switch field[:sep] {
+		case "Op":
+			n.op = v
+		case "Type":
+			n.typ = v
+		case "Shape":
+			if n.shape, err = parseShape(v); err != nil {
+				return errors.Wrapf(err, "Unable to parse the shape of %v", n.dotID)
+			}
+		}
+	}
+	if n.op == "%!s(<nil>)" {
+		n.op = ""
+	}
+
+	if comment := n.attrs["comment"]; strings.HasPrefix(comment, "args") {
+		n.args = []int64{}
+		for _, f := range strings.Fields(comment)[1:] {
+			id, err := strconv.ParseInt(f, 0, 64)
+			if err != nil {
+				return errors.Wrapf(err, "Unable to parse the arguments of %v", n.dotID)
+			}
+			n.args = append(n.args, id)
+		}
+	}
+	return nil
+}
+
+func parseShape(s string) (tensor.Shape, error) {
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return nil, errors.Errorf("Expected a shape such as (2, 3). Got %q", s)
+	}
+	dims, err := ints(s[1 : len(s)-1])
+	if err != nil {
+		return nil, err
+	}
+	return tensor.Shape(dims), nil
+}
+
+// parseType parses the types as printed by gorgonia: float64, Vector float64, Matrix float64 or Tensor-3 float64
+func parseType(s string) (dt tensor.Dtype, dims int, err error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return dt, 0, errors.Errorf("Unable to parse the type %q", s)
+	}
+	found := false
+	for _, d := range dtypes {
+		if d.String() == fields[len(fields)-1] {
+			dt, found = d, true
+			break
+		}
+	}
+	if !found {
+		return dt, 0, errors.Errorf("Unknown dtype in the type %q", s)
+	}
+	if len(fields) == 1 {
+		return dt, 0, nil
+	}
+	switch t := fields[0]; {
+	case t == "Vector":
+		dims = 1
+	case t == "Matrix":
+		dims = 2
+	case strings.HasPrefix(t, "Tensor-"):
+		if dims, err = strconv.Atoi(strings.TrimPrefix(t, "Tensor-")); err != nil {
+			return dt, 0, errors.Wrapf(err, "Unable to parse the type %q", s)
+		}
+	default:
+		return dt, 0, errors.Errorf("Unable to parse the type %q", s)
+	}
+	return dt, dims, nil
+}
+
+// parseScalar parses the value of a scalar constant
+func parseScalar(s string, dt tensor.Dtype) (interface{}, error) {
+	switch dt {
+	case tensor.Float64:
+		return strconv.ParseFloat(s, 64)
+	case tensor.Float32:
+		v, err := strconv.ParseFloat(s, 32)
+		return float32(v), err
+	case tensor.Int:
+		v, err := strconv.ParseInt(s, 10, 0)
+		return int(v), err
+	case tensor.Int64:
+		return strconv.ParseInt(s, 10, 64)
+	case tensor.Int32:
+		v, err := strconv.ParseInt(s, 10, 32)
+		return int32(v), err
+	case tensor.Uint8:
+		v, err := strconv.ParseUint(s, 10, 8)
+		return uint8(v), err
+	case tensor.Bool:
+		return strconv.ParseBool(s)
+	}
+	return nil, errors.Errorf("Scalar constants of %v are not supported", dt)
+}
+
+type builder struct {
+	g        *gorgonia.ExprGraph
+	byID     map[int64]*dotNode
+	built    map[int64]*gorgonia.Node
+	building map[int64]bool
+}
+
+func (b *builder) build(n *dotNode) (retVal *gorgonia.Node, err error) {
+	if retVal, ok := b.built[n.id]; ok {
+		return retVal, nil
+	}
+	if b.building[n.id] {
+		return nil, errors.Errorf("%v is part of a cycle", n.name)
+	}
+	b.building[n.id] = true
+
+	args := make(gorgonia.Nodes, len(n.args))
+	for i, id := range n.args {
+		arg, ok := b.byID[id]
+		if !ok {
+			return nil, errors.Errorf("The argument %#x of %v is not in the graph", id, n.name)
+		}
+		if args[i], err = b.build(arg); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case n.op == "" && len(args) == 0:
+		retVal, err = b.input(n)
+	default:
+		retVal, err = b.apply(n, args)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if n.shape != nil && !retVal.Shape().Eq(n.shape) {
+		return nil, errors.Errorf("%v was rebuilt with the shape %v, instead of %v", n.name, retVal.Shape(), n.shape)
+	}
+	if n.typ != "" {
+		if t := fmt.Sprintf("%v", retVal.Type()); t != n.typ {
+			return nil, errors.Errorf("%v was rebuilt with the type %v, instead of %v", n.name, t, n.typ)
+		}
+	}
+	b.built[n.id] = retVal
+	return retVal, nil
+}
+
+func (b *builder) input(n *dotNode) (*gorgonia.Node, error) {
+	if n.typ == "" {
+		return nil, errors.Errorf("The input %v has no type", n.name)
+	}
+	dt, dims, err := parseType(n.typ)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to rebuild the input %v", n.name)
+	}
+	return gorgonia.NewTensor(b.g, dt, dims, gorgonia.WithShape(n.shape...), gorgonia.WithName(n.name)), nil
+}
+
+func (b *builder) apply(n *dotNode, args gorgonia.Nodes) (*gorgonia.Node, error) {
+	build, match := lookup(n.op)
+	if build == nil {
+		if len(args) == 0 && strings.HasPrefix(n.op, "const ") {
+			return b.constant(n)
+		}
+		return nil, errors.Errorf("No op is registered for %q, the op of %v", n.op, n.name)
+	}
+	retVal, err := build(b.g, match, args)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to rebuild %v", n.name)
+	}
+
+	// the nodes are only named if they were named in the original graph
+	ids := make([]string, len(n.args))
+	for i, id := range n.args {
+		ids[i] = fmt.Sprintf("%%%x", id)
+	}
+	if defaultName := fmt.Sprintf("%s(%s)", n.op, strings.Join(ids, ", ")); n.name != defaultName {
+		gorgonia.WithName(n.name)(retVal)
+	}
+	return retVal, nil
+}
+
+// constant rebuilds a scalar constant. The values of the constant tensors are not written in the DOT, so they need a builder.
+func (b *builder) constant(n *dotNode) (*gorgonia.Node, error) {
+	dt, dims, err := parseType(n.typ)
+	if err != nil || dims != 0 {
+		return nil, errors.Errorf("No op is registered for %q, the op of %v, and it is not a scalar constant", n.op, n.name)
+	}
+	v, err := parseScalar(strings.TrimPrefix(n.op, "const "), dt)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to parse the value of the constant %v", n.name)
+	}
+	return gorgonia.NewConstant(v, gorgonia.In(b.g), gorgonia.WithName(n.name)), nil
+}
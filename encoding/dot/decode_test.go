@@ -0,0 +1,130 @@
+package dot
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// assertRoundTrip checks that every node of g was rebuilt in g2, with the same ID and name
+func assertRoundTrip(t *testing.T, g, g2 *gorgonia.ExprGraph) {
+	t.Helper()
+	require.Len(t, g2.AllNodes(), len(g.AllNodes()))
+	for _, n := range g.AllNodes() {
+		n2, ok := g2.Node(n.ID()).(*gorgonia.Node)
+		require.True(t, ok, "%v was not rebuilt", n)
+		assert.True(t, gorgonia.ExprEq(n, n2), "%v was rebuilt as %v", n, n2)
+		assert.Equal(t, n.Name(), n2.Name())
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	g := gorgonia.NewGraph()
+	x := gorgonia.NewMatrix(g, tensor.Float64, gorgonia.WithShape(2, 3), gorgonia.WithName("x"))
+	w := gorgonia.NewMatrix(g, tensor.Float64, gorgonia.WithShape(3, 4), gorgonia.WithName("w"))
+	v := gorgonia.NewVector(g, tensor.Float64, gorgonia.WithShape(3), gorgonia.WithName("v"))
+	xw := gorgonia.Must(gorgonia.Mul(x, w))
+	h := gorgonia.Must(gorgonia.Tanh(gorgonia.Must(gorgonia.HadamardProd(xw, gorgonia.NewConstant(0.5)))))
+	gorgonia.WithName("hidden")(h)
+	gorgonia.Must(gorgonia.Gt(h, xw, true))
+	gorgonia.Must(gorgonia.Reshape(gorgonia.Must(gorgonia.Sum(h, 1)), tensor.Shape{1, 2}))
+	gorgonia.Must(gorgonia.Transpose(xw))
+	gorgonia.Must(gorgonia.Concat(0, xw, xw))
+	gorgonia.Must(gorgonia.Mul(x, v))
+	gorgonia.Must(gorgonia.SizeOf(0, x))
+	gorgonia.Must(gorgonia.Sub(x, x))
+
+	b, err := Marshal(g)
+	require.NoError(t, err)
+	g2, err := Unmarshal(b)
+	require.NoError(t, err)
+	assertRoundTrip(t, g, g2)
+	assert.Equal(t, "hidden", g2.ByName("hidden")[0].Name())
+}
+
+func TestUnmarshal_registry(t *testing.T) {
+	g := gorgonia.NewGraph()
+	x := gorgonia.NewMatrix(g, tensor.Float64, gorgonia.WithShape(2, 3), gorgonia.WithName("x"))
+	w := gorgonia.NewMatrix(g, tensor.Float64, gorgonia.WithShape(3, 4), gorgonia.WithName("w"))
+	cost := gorgonia.Must(gorgonia.Sum(gorgonia.Must(gorgonia.Mul(x, w))))
+	_, err := gorgonia.Grad(cost, w)
+	require.NoError(t, err)
+
+	b, err := Marshal(g)
+	require.NoError(t, err)
+	// the gradients transpose the matrix multiplications, which Mul cannot do
+	_, err = Unmarshal(b)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "No op is registered")
+
+	for _, n := range g.AllNodes() {
+		if n.Op() == nil || g.From(n.ID()).Len() == 0 {
+			continue
+		}
+		if build, _ := lookup(n.Op().String()); build == nil {
+			RegisterOp(regexp.QuoteMeta(n.Op().String()), Apply(n.Op()))
+		}
+	}
+	g2, err := Unmarshal(b)
+	require.NoError(t, err)
+	assertRoundTrip(t, g, g2)
+}
+
+func TestUnmarshal_handWritten(t *testing.T) {
+	// without the comments listing the arguments, the arguments are read from the edges, in order
+	g, err := Unmarshal([]byte(`digraph {
+	x [label="{{x|0x0}|{Op|}|{Type|Vector float64}|{Shape|(3)}}"];
+	y [label="{{y|0x1}|{Op|}|{Type|Vector float64}|{Shape|(3)}}"];
+	two [label="{{2|0x2}|{Op|const| 2}|{Type|float64}|{Shape|()}}"];
+	diff [label="{{diff|0x3}|{Op|- false}|{Shape|(3)}}"];
+	scaled [label="{{scaled|0x4}|{Op|⊙ false}|{Type|Vector float64}}"];
+	diff -> y;
+	diff -> x;
+	scaled -> diff;
+	scaled -> two [style=invis];
+}`))
+	require.NoError(t, err)
+
+	want := gorgonia.NewGraph()
+	x := gorgonia.NewVector(want, tensor.Float64, gorgonia.WithShape(3), gorgonia.WithName("x"))
+	y := gorgonia.NewVector(want, tensor.Float64, gorgonia.WithShape(3), gorgonia.WithName("y"))
+	diff := gorgonia.Must(gorgonia.Sub(y, x))
+	scaled := gorgonia.Must(gorgonia.HadamardProd(diff, gorgonia.NewConstant(2.0)))
+	assert.True(t, gorgonia.ExprEq(scaled, g.ByName("scaled")[0]))
+	assert.False(t, gorgonia.ExprEq(diff, g.ByName("scaled")[0]))
+}
+
+func TestUnmarshal_errors(t *testing.T) {
+	for name, dot := range map[string]string{
+		"No op is registered": `digraph {
+	x [label="{{x|0x0}|{Op|}|{Type|float64}|{Shape|()}}"];
+	y [label="{{y|0x1}|{Op|frobnicate}|{Shape|()}}"];
+	y -> x;
+}`,
+		"rebuilt with the shape (3)": `digraph {
+	x [label="{{x|0x0}|{Op|}|{Type|Vector float64}|{Shape|(3)}}"];
+	y [label="{{y|0x1}|{Op|tanh}|{Shape|(4)}}"];
+	y -> x;
+}`,
+		"has no type": `digraph {
+	x [label="{{x|0x0}|{Op|}|{Shape|(3)}}"];
+}`,
+		"has no label": `digraph {
+	x -> y;
+}`,
+		"part of a cycle": `digraph {
+	x [label="{{x|0x0}|{Op|tanh}}"];
+	y [label="{{y|0x1}|{Op|tanh}}"];
+	x -> y -> x;
+}`,
+	} {
+		_, err := Unmarshal([]byte(dot))
+		if assert.Error(t, err, name) {
+			assert.Contains(t, err.Error(), name)
+		}
+	}
+}
@@ -1,2 +1,2 @@
-// Package dot creates a graphviz compatible version of the ExprGraph
+// Package dot creates a graphviz compatible version of the ExprGraph, and rebuilds an ExprGraph from it
 package dot
@@ -45,13 +45,27 @@ func (n *node) Attributes() []encoding.Attribute {
 		},
 		{
 			Key: "label",
-			Value: fmt.Sprintf(`"{{%s|%#x}|{Op|%s}|{Shape|%v}}"`,
+			Value: fmt.Sprintf(`"{{%s|%#x}|{Op|%s}|{Type|%s}|{Shape|%v}}"`,
 				htmlEscaper.Replace(n.n.Name()),
 				n.ID(),
 				htmlEscaper.Replace(fmt.Sprintf("%s", n.n.Op())),
+				htmlEscaper.Replace(fmt.Sprintf("%v", n.n.Type())),
 				n.n.Shape()),
 		},
 	}
+	// the edges do not keep the order of the arguments of the op, nor how many times an argument is used, so Unmarshal reads them here
+	if g := n.n.Graph(); g != nil {
+		var args []string
+		for children := g.From(n.ID()); children.Next(); {
+			args = append(args, fmt.Sprintf("%#x", children.Node().ID()))
+		}
+		if len(args) > 0 {
+			attrs = append(attrs, encoding.Attribute{
+				Key:   "comment",
+				Value: fmt.Sprintf(`"args %s"`, strings.Join(args, " ")),
+			})
+		}
+	}
 	return attrs
 }
 
@@ -0,0 +1,216 @@
+package dot
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// OpBuilder rebuilds a node in g from the label of its op and its arguments.
+// match holds the label, followed by the submatches of the pattern the builder was registered with.
+type OpBuilder func(g *gorgonia.ExprGraph, match []string, args gorgonia.Nodes) (*gorgonia.Node, error)
+
+type registration struct {
+	pattern *regexp.Regexp
+	build   OpBuilder
+}
+
+var registry struct {
+	sync.RWMutex
+	ops []registration
+}
+
+// RegisterOp registers the builder of the ops whose label matches the pattern. The pattern is anchored, so it matches the whole label.
+// The builders registered last are tried first, so a builder can replace the one of a built-in op.
+//
+// Unmarshal only rebuilds the ops that have a builder. Most of the ops of the operations API are built in, but the labels of
+// some ops do not hold all their parameters, e.g. the transposed matrix multiplications of the gradients. Those need a builder,
+// such as one that applies the op of the original graph:
+//		dot.RegisterOp(`A × Bᵀ`, dot.Apply(op))
+func RegisterOp(pattern string, build OpBuilder) {
+	re := regexp.MustCompile("^(?:" + pattern + ")$")
+	registry.Lock()
+	registry.ops = append(registry.ops, registration{pattern: re, build: build})
+	registry.Unlock()
+}
+
+// Apply returns a builder that applies the op to the arguments.
+func Apply(op gorgonia.Op) OpBuilder {
+	return func(g *gorgonia.ExprGraph, match []string, args gorgonia.Nodes) (*gorgonia.Node, error) {
+		return gorgonia.ApplyOp(op, args...)
+	}
+}
+
+// lookup returns the builder of an op and the submatches of its label
+func lookup(label string) (OpBuilder, []string) {
+	registry.RLock()
+	defer registry.RUnlock()
+	for i := len(registry.ops) - 1; i >= 0; i-- {
+		if match := registry.ops[i].pattern.FindStringSubmatch(label); match != nil {
+			return registry.ops[i].build, match
+		}
+	}
+	return nil, nil
+}
+
+func unary(fn func(*gorgonia.Node) (*gorgonia.Node, error)) OpBuilder {
+	return func(g *gorgonia.ExprGraph, match []string, args gorgonia.Nodes) (*gorgonia.Node, error) {
+		if err := checkArgs(match[0], args, 1); err != nil {
+			return nil, err
+		}
+		return fn(args[0])
+	}
+}
+
+func binary(fn func(a, b *gorgonia.Node) (*gorgonia.Node, error)) OpBuilder {
+	return func(g *gorgonia.ExprGraph, match []string, args gorgonia.Nodes) (*gorgonia.Node, error) {
+		if err := checkArgs(match[0], args, 2); err != nil {
+			return nil, err
+		}
+		return fn(args[0], args[1])
+	}
+}
+
+func checkArgs(label string, args gorgonia.Nodes, n int) error {
+	if len(args) != n {
+		return errors.Errorf("%v expects %d arguments. Got %d", label, n, len(args))
+	}
+	return nil
+}
+
+// ints parses a list of integers separated by spaces or commas, as the ops print their axes and shapes
+func ints(s string) ([]int, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' })
+	retVal := make([]int, len(fields))
+	for i, f := range fields {
+		var err error
+		if retVal[i], err = strconv.Atoi(f); err != nil {
+			return nil, errors.Wrapf(err, "Unable to parse %q", s)
+		}
+	}
+	return retVal, nil
+}
+
+func init() {
+	unaryOps := map[string]func(*gorgonia.Node) (*gorgonia.Node, error){
+		"abs": gorgonia.Abs, "sign": gorgonia.Sign, "ceil": gorgonia.Ceil, "floor": gorgonia.Floor,
+		"sin": gorgonia.Sin, "cos": gorgonia.Cos, "exp": gorgonia.Exp,
+		"ln": gorgonia.Log, "log2": gorgonia.Log2, "neg": gorgonia.Neg, "square": gorgonia.Square, "sqrt": gorgonia.Sqrt,
+		"inv": gorgonia.Inverse, "invSqrt": gorgonia.InverseSqrt,
+		"cube": gorgonia.Cube, "tanh": gorgonia.Tanh, "sigmoid": gorgonia.Sigmoid,
+		"log1p": gorgonia.Log1p, "expm1": gorgonia.Expm1, "softplus": gorgonia.Softplus,
+		"gelu": gorgonia.GELU, "geluTanh": gorgonia.GELUTanh, "silu": gorgonia.SiLU, "elu": gorgonia.ELU, "selu": gorgonia.SELU,
+		"hardSigmoid": gorgonia.HardSigmoid, "hardSwish": gorgonia.HardSwish, "softsign": gorgonia.Softsign,
+	}
+	for name, fn := range unaryOps {
+		RegisterOp(regexp.QuoteMeta(name), unary(fn))
+	}
+
+	arith := map[string]func(a, b *gorgonia.Node) (*gorgonia.Node, error){
+		"+": gorgonia.Add, "-": gorgonia.Sub, "⊙": gorgonia.HadamardProd, "÷": gorgonia.HadamardDiv, "^": gorgonia.Pow,
+	}
+	for sym, fn := range arith {
+		RegisterOp(regexp.QuoteMeta(sym)+" false", binary(fn))
+	}
+	cmp := map[string]func(a, b *gorgonia.Node, retSame bool) (*gorgonia.Node, error){
+		"<": gorgonia.Lt, ">": gorgonia.Gt, "<=": gorgonia.Lte, ">=": gorgonia.Gte, "==": gorgonia.Eq, "!=": gorgonia.Ne,
+	}
+	for sym, fn := range cmp {
+		fn := fn
+		RegisterOp(regexp.QuoteMeta(sym)+" (true|false)", func(g *gorgonia.ExprGraph, match []string, args gorgonia.Nodes) (*gorgonia.Node, error) {
+			if err := checkArgs(match[0], args, 2); err != nil {
+				return nil, err
+			}
+			return fn(args[0], args[1], match[1] == "true")
+		})
+	}
+
+	// Mul swaps the arguments of a vector × matrix, and transposes the matrix
+	RegisterOp(`A × B|A × b|a ⋅ b`, binary(gorgonia.Mul))
+	RegisterOp(`Aᵀ × b`, func(g *gorgonia.ExprGraph, match []string, args gorgonia.Nodes) (*gorgonia.Node, error) {
+		if err := checkArgs(match[0], args, 2); err != nil {
+			return nil, err
+		}
+		return gorgonia.Mul(args[1], args[0])
+	})
+	RegisterOp(`a ⊗ b`, binary(gorgonia.OuterProd))
+	RegisterOp(`A(ᵀ?) ××× B(ᵀ?)`, func(g *gorgonia.ExprGraph, match []string, args gorgonia.Nodes) (*gorgonia.Node, error) {
+		if err := checkArgs(match[0], args, 2); err != nil {
+			return nil, err
+		}
+		return gorgonia.BatchedMatMul(args[0], args[1], match[1] != "", match[2] != "")
+	})
+
+	along := func(fn func(*gorgonia.Node, ...int) (*gorgonia.Node, error)) OpBuilder {
+		return func(g *gorgonia.ExprGraph, match []string, args gorgonia.Nodes) (*gorgonia.Node, error) {
+			if err := checkArgs(match[0], args, 1); err != nil {
+				return nil, err
+			}
+			axes, err := ints(match[1])
+			if err != nil {
+				return nil, err
+			}
+			return fn(args[0], axes...)
+		}
+	}
+	RegisterOp(`Σ\[([0-9 ]*)\]`, along(gorgonia.Sum))
+	RegisterOp(`MaxAlong\[([0-9 ]*)\]`, along(gorgonia.Max))
+	RegisterOp(`Aᵀ\{([0-9, ]*)\}`, along(gorgonia.Transpose))
+
+	RegisterOp(`Reshape\(([0-9, ]*)\)`, func(g *gorgonia.ExprGraph, match []string, args gorgonia.Nodes) (*gorgonia.Node, error) {
+		if err := checkArgs(match[0], args, 1); err != nil {
+			return nil, err
+		}
+		to, err := ints(match[1])
+		if err != nil {
+			return nil, err
+		}
+		return gorgonia.Reshape(args[0], tensor.Shape(to))
+	})
+	RegisterOp(`Concat\(axis=([0-9]+)\)`, func(g *gorgonia.ExprGraph, match []string, args gorgonia.Nodes) (*gorgonia.Node, error) {
+		axis, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to parse the axis of %v", match[0])
+		}
+		return gorgonia.Concat(axis, args...)
+	})
+	RegisterOp(`SizeOf\(([0-9]+)\)`, func(g *gorgonia.ExprGraph, match []string, args gorgonia.Nodes) (*gorgonia.Node, error) {
+		if err := checkArgs(match[0], args, 1); err != nil {
+			return nil, err
+		}
+		axis, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to parse the axis of %v", match[0])
+		}
+		return gorgonia.SizeOf(axis, args[0])
+	})
+	// the label of a size with a known shape is its value, not its axis, so the axis is only known if a single one has that size
+	RegisterOp(`SizeOf=([0-9]+)`, func(g *gorgonia.ExprGraph, match []string, args gorgonia.Nodes) (*gorgonia.Node, error) {
+		if err := checkArgs(match[0], args, 1); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to parse the size of %v", match[0])
+		}
+		axis := -1
+		for i, d := range args[0].Shape() {
+			if d != size {
+				continue
+			}
+			if axis >= 0 {
+				return nil, errors.Errorf("%v is ambiguous: the axes %d and %d of %v have that size", match[0], axis, i, args[0].Shape())
+			}
+			axis = i
+		}
+		if axis < 0 {
+			return nil, errors.Errorf("No axis of %v has the size of %v", args[0].Shape(), match[0])
+		}
+		return gorgonia.SizeOf(axis, args[0])
+	})
+}
@@ -0,0 +1,287 @@
+// Package html writes an ExprGraph as a single, self-contained HTML page to explore it in a browser.
+//
+// Unlike the dot encoding, the page needs neither Graphviz nor a network connection: the layout is computed in the page,
+// so it copes with graphs of thousands of nodes. The nodes are grouped in clusters after their groups,
+// and every cluster can be collapsed into a single node. Clicking a node shows its op, type, shape and device,
+// and the nodes can be searched by name.
+//
+// After a run, the values and the gradients bound to the nodes can be written too:
+//		m.RunAll()
+//		f, _ := os.Create("graph.html")
+//		defer f.Close()
+//		err := html.Encode(f, g, html.WithValues(), html.WithGradients())
+package html
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+	internalEncoding "gorgonia.org/gorgonia/internal/encoding"
+)
+
+// maxPrintedValue is the size of the largest values that are printed in full. Only the statistics of the larger ones are written.
+const maxPrintedValue = 64
+
+// Opt is a function that configures the encoding of a graph.
+type Opt func(*encoder)
+
+// WithValues writes the values bound to the nodes, as statistics, and in full if they are small.
+func WithValues() Opt {
+	return func(e *encoder) {
+		e.values = true
+	}
+}
+
+// WithGradients writes the statistics of the gradients of the nodes that have one.
+func WithGradients() Opt {
+	return func(e *encoder) {
+		e.grads = true
+	}
+}
+
+// WithTitle sets the title of the page. The default is "ExprGraph".
+func WithTitle(title string) Opt {
+	return func(e *encoder) {
+		e.title = title
+	}
+}
+
+type encoder struct {
+	values bool
+	grads  bool
+	title  string
+}
+
+// Marshal the graph in a self-contained HTML page.
+func Marshal(g *gorgonia.ExprGraph, opts ...Opt) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, g, opts...); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode writes the graph as a self-contained HTML page to w.
+func Encode(w io.Writer, g *gorgonia.ExprGraph, opts ...Opt) error {
+	e := &encoder{title: "ExprGraph"}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	data, err := json.Marshal(e.graph(g))
+	if err != nil {
+		return errors.Wrap(err, "Unable to encode the graph")
+	}
+	// json.Marshal escapes <, > and &, so the data cannot close the script element
+	for _, part := range [][]byte{[]byte(pageHead), []byte(htmlEscaper.Replace(e.title)), []byte(pageData), data, []byte(pageTail)} {
+		if _, err = w.Write(part); err != nil {
+			return errors.Wrap(err, "Unable to write the page")
+		}
+	}
+	return nil
+}
+
+// graph is the data of the page
+type graph struct {
+	Nodes    []node    `json:"nodes"`
+	Edges    [][2]int  `json:"edges"` // from an input to its consumer, as indices of Nodes
+	Clusters []cluster `json:"clusters"`
+}
+
+type node struct {
+	ID      int64    `json:"id"`
+	Name    string   `json:"name"`
+	Op      string   `json:"op"`
+	Type    string   `json:"type"`
+	Shape   string   `json:"shape"`
+	Device  string   `json:"device"`
+	Groups  []string `json:"groups"`
+	Cluster int      `json:"cluster"` // index of Clusters
+	Value   *stats   `json:"value,omitempty"`
+	Grad    *stats   `json:"grad,omitempty"`
+}
+
+type cluster struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+// stats sums up a value
+type stats struct {
+	Dtype string  `json:"dtype"`
+	Size  int     `json:"size"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Mean  float64 `json:"mean"`
+	Std   float64 `json:"std"`
+	NaN   int     `json:"nan"`
+	Inf   int     `json:"inf"`
+	Data  string  `json:"data,omitempty"` // the value, if it is small
+	Error string  `json:"error,omitempty"`
+}
+
+func (e *encoder) graph(g *gorgonia.ExprGraph) *graph {
+	all := g.AllNodes()
+	sort.Slice(all, func(i, j int) bool { return all[i].ID() < all[j].ID() })
+
+	retVal := &graph{Nodes: make([]node, len(all))}
+	index := make(map[int64]int, len(all))
+	clusters := make(map[int]int) // group ID to index of retVal.Clusters
+	for i, n := range all {
+		index[n.ID()] = i
+		grp := clusterOf(n.Groups())
+		c, ok := clusters[grp.ID]
+		if !ok {
+			c = len(retVal.Clusters)
+			clusters[grp.ID] = c
+			retVal.Clusters = append(retVal.Clusters, cluster{Name: clusterName(grp)})
+		}
+		retVal.Clusters[c].Size++
+
+		nn := node{
+			ID:      n.ID(),
+			Name:    n.Name(),
+			Op:      "input",
+			Shape:   fmt.Sprintf("%v", n.Shape()),
+			Device:  n.Device().String(),
+			Cluster: c,
+		}
+		if n.Op() != nil {
+			nn.Op = n.Op().String()
+		}
+		if t := n.Type(); t != nil {
+			nn.Type = t.String()
+		}
+		for _, grp := range n.Groups() {
+			nn.Groups = append(nn.Groups, clusterName(grp))
+		}
+		if e.values {
+			if v := n.Value(); v != nil {
+				nn.Value = summarize(v, true)
+			}
+		}
+		if e.grads {
+			if v, err := n.Grad(); err == nil && v != nil {
+				nn.Grad = summarize(v, false)
+			}
+		}
+		retVal.Nodes[i] = nn
+	}
+
+	for i, n := range all {
+		for children := g.From(n.ID()); children.Next(); {
+			retVal.Edges = append(retVal.Edges, [2]int{index[children.Node().ID()], i})
+		}
+	}
+	return retVal
+}
+
+// clusterOf returns the most specific group: the last one that is not one of the default clusters.
+func clusterOf(groups internalEncoding.Groups) internalEncoding.Group {
+	if len(groups) == 0 {
+		return internalEncoding.UndefinedCluster
+	}
+	groups = append(internalEncoding.Groups(nil), groups...)
+	sort.Sort(groups)
+	for i := len(groups) - 1; i >= 0; i-- {
+		switch groups[i].ID {
+		case internalEncoding.UndefinedCluster.ID, internalEncoding.ExprGraphCluster.ID, internalEncoding.ConstantCluster.ID,
+			internalEncoding.InputCluster.ID, internalEncoding.StrayCluster.ID:
+		default:
+			return groups[i]
+		}
+	}
+	return groups[0]
+}
+
+// clusterName trims the names of the default clusters, which end with a space
+func clusterName(grp internalEncoding.Group) string {
+	name := grp.Name
+	for len(name) > 0 && name[len(name)-1] == ' ' {
+		name = name[:len(name)-1]
+	}
+	return name
+}
+
+func summarize(v gorgonia.Value, full bool) *stats {
+	retVal := &stats{Dtype: v.Dtype().String(), Size: v.Shape().TotalSize()}
+	if full && retVal.Size <= maxPrintedValue {
+		retVal.Data = fmt.Sprintf("%v", v)
+	}
+	xs, err := floats(v)
+	if err != nil {
+		retVal.Error = err.Error()
+		return retVal
+	}
+
+	var n int
+	var sum, sumSq float64
+	for _, x := range xs {
+		switch {
+		case math.IsNaN(x):
+			retVal.NaN++
+			continue
+		case math.IsInf(x, 0):
+			retVal.Inf++
+			continue
+		}
+		if n == 0 || x < retVal.Min {
+			retVal.Min = x
+		}
+		if n == 0 || x > retVal.Max {
+			retVal.Max = x
+		}
+		n++
+		sum += x
+		sumSq += x * x
+	}
+	if n > 0 {
+		retVal.Mean = sum / float64(n)
+		retVal.Std = math.Sqrt(math.Max(sumSq/float64(n)-retVal.Mean*retVal.Mean, 0))
+	}
+	return retVal
+}
+
+// floats converts the data of a numeric value
+func floats(v gorgonia.Value) ([]float64, error) {
+	switch d := v.Data().(type) {
+	case []float64:
+		return d, nil
+	case []float32:
+		retVal := make([]float64, len(d))
+		for i, x := range d {
+			retVal[i] = float64(x)
+		}
+		return retVal, nil
+	}
+
+	data := reflect.ValueOf(v.Data())
+	if data.Kind() != reflect.Slice {
+		data = reflect.Append(reflect.MakeSlice(reflect.SliceOf(data.Type()), 0, 1), data)
+	}
+	retVal := make([]float64, data.Len())
+	for i := range retVal {
+		switch x := data.Index(i); x.Kind() {
+		case reflect.Float32, reflect.Float64:
+			retVal[i] = x.Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			retVal[i] = float64(x.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			retVal[i] = float64(x.Uint())
+		case reflect.Bool:
+			if x.Bool() {
+				retVal[i] = 1
+			}
+		default:
+			return nil, errors.Errorf("Expected a numeric value. Got %v instead", v.Dtype())
+		}
+	}
+	return retVal, nil
+}
@@ -0,0 +1,102 @@
+package html
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// decode extracts the data of a page
+func decode(t *testing.T, page []byte) *graph {
+	start := bytes.Index(page, []byte(pageData))
+	end := bytes.LastIndex(page, []byte(pageTail))
+	require.True(t, start > 0 && end > start, "the page is malformed")
+	var g graph
+	require.NoError(t, json.Unmarshal(page[start+len(pageData):end], &g))
+	return &g
+}
+
+func TestMarshal(t *testing.T) {
+	g := gorgonia.NewGraph()
+	x := gorgonia.NewVector(g, tensor.Float64, gorgonia.WithShape(3), gorgonia.WithName("</script>x"),
+		gorgonia.WithValue(tensor.New(tensor.WithBacking([]float64{1, 2, math.NaN()}))))
+	w := gorgonia.NewVector(g, tensor.Float64, gorgonia.WithShape(3), gorgonia.WithName("w"),
+		gorgonia.WithValue(tensor.New(tensor.WithBacking([]float64{1, -1, 0}))))
+	gorgonia.Must(gorgonia.Sum(x))
+	cost := gorgonia.Must(gorgonia.Sum(gorgonia.Must(gorgonia.HadamardProd(w, w))))
+	_, err := gorgonia.Grad(cost, w)
+	require.NoError(t, err)
+	m := gorgonia.NewTapeMachine(g, gorgonia.BindDualValues(w))
+	defer m.Close()
+	require.NoError(t, m.RunAll())
+
+	page, err := Marshal(g, WithTitle("<model>"))
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(page, []byte(pageHead+"&lt;model&gt;"+pageData)))
+	assert.Equal(t, 1, strings.Count(string(page), "</script>\n<script>"), "a node name must not close the script element")
+
+	data := decode(t, page)
+	require.Len(t, data.Nodes, len(g.AllNodes()))
+	byName := make(map[string]node)
+	for _, n := range data.Nodes {
+		byName[n.Name] = n
+		assert.Nil(t, n.Value, "the values are only written on demand")
+	}
+	require.Contains(t, byName, "</script>x")
+	assert.Equal(t, "input", byName["w"].Op)
+	assert.Equal(t, "(3)", byName["w"].Shape)
+	assert.Equal(t, "CPU", byName["w"].Device)
+	assert.Equal(t, "Inputs", data.Clusters[byName["w"].Cluster].Name)
+	assert.Equal(t, []string{"Inputs"}, byName["w"].Groups)
+
+	// every edge goes from an input to its consumer
+	var total int
+	for _, c := range data.Clusters {
+		total += c.Size
+	}
+	assert.Equal(t, len(data.Nodes), total)
+	for _, e := range data.Edges {
+		children := g.From(data.Nodes[e[1]].ID)
+		var found bool
+		for children.Next() {
+			found = found || children.Node().ID() == data.Nodes[e[0]].ID
+		}
+		assert.True(t, found, "%v is not an input of %v", data.Nodes[e[0]].Name, data.Nodes[e[1]].Name)
+	}
+
+	page, err = Marshal(g, WithValues(), WithGradients())
+	require.NoError(t, err)
+	data = decode(t, page)
+	for _, n := range data.Nodes {
+		byName[n.Name] = n
+	}
+	v := byName["</script>x"].Value
+	require.NotNil(t, v)
+	assert.Equal(t, stats{Dtype: "float64", Size: 3, Min: 1, Max: 2, Mean: 1.5, Std: 0.5, NaN: 1, Data: v.Data}, *v)
+	assert.Contains(t, v.Data, "NaN")
+	require.NotNil(t, byName["w"].Grad)
+	assert.Equal(t, 2.0, byName["w"].Grad.Max)
+	assert.Equal(t, -2.0, byName["w"].Grad.Min)
+	assert.Empty(t, byName["w"].Grad.Data, "the gradients are only summarized")
+}
+
+func TestSummarize(t *testing.T) {
+	s := summarize(tensor.New(tensor.WithBacking([]int{1, 2, 3, 4})), true)
+	assert.Equal(t, 2.5, s.Mean)
+	assert.Equal(t, 4.0, s.Max)
+	assert.Empty(t, s.Error)
+
+	s = summarize(tensor.New(tensor.WithShape(100), tensor.Of(tensor.Float32)), true)
+	assert.Empty(t, s.Data, "large values are not printed")
+	assert.Equal(t, 100, s.Size)
+
+	s = summarize(tensor.New(tensor.WithBacking([]string{"a"})), true)
+	assert.NotEmpty(t, s.Error)
+}
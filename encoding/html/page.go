@@ -0,0 +1,377 @@
+package html
+
+import "strings"
+
+var htmlEscaper = strings.NewReplacer(
+	`&`, "&amp;",
+	`'`, "&#39;",
+	`<`, "&lt;",
+	`>`, "&gt;",
+	`"`, "&#34;",
+)
+
+// The page is written as pageHead, the title, pageData, the JSON of the graph, then pageTail.
+const pageHead = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>`
+
+const pageData = `</title>
+<style>
+body { margin: 0; font: 12px sans-serif; display: flex; height: 100vh; overflow: hidden; }
+#side { width: 320px; min-width: 320px; display: flex; flex-direction: column; border-right: 1px solid #ccc; }
+#side > div { padding: 6px 8px; border-bottom: 1px solid #eee; }
+#search { width: 100%; box-sizing: border-box; }
+#clusters { max-height: 30vh; overflow: auto; }
+#details { flex: 1; overflow: auto; }
+#details table { border-collapse: collapse; width: 100%; }
+#details td { border-bottom: 1px solid #eee; padding: 2px 4px; vertical-align: top; word-break: break-all; }
+#details td:first-child { color: #666; white-space: nowrap; word-break: normal; }
+#details pre { margin: 0; white-space: pre-wrap; }
+#details h3 { margin: 8px 0 4px; }
+a { color: #06c; cursor: pointer; }
+#view { flex: 1; cursor: grab; }
+#view.dragging { cursor: grabbing; }
+.node rect { stroke: #555; stroke-width: 1; }
+.node text { pointer-events: none; font-size: 11px; }
+.node.cluster rect { stroke-width: 3; stroke-dasharray: 6 2; }
+.node.match rect { stroke: #e80; stroke-width: 3; }
+.node.selected rect { stroke: #d00; stroke-width: 3; }
+.node.nan rect { fill: #f88 !important; }
+.edge { fill: none; stroke: #999; stroke-width: 1; }
+.edge.selected { stroke: #d00; stroke-width: 2; }
+</style>
+</head>
+<body>
+<div id="side">
+<div><input id="search" type="search" placeholder="Search nodes by name (Enter for the next match)"><div id="matches"></div></div>
+<div><b>Clusters</b> <a id="collapseAll">collapse all</a> · <a id="expandAll">expand all</a><div id="clusters"></div></div>
+<div id="details">Click a node to see its details. Double click a collapsed cluster to expand it.</div>
+</div>
+<svg id="view"><g id="viewport"></g></svg>
+<script id="data" type="application/json">`
+
+const pageTail = `</script>
+<script>
+(function() {
+"use strict";
+var data = JSON.parse(document.getElementById("data").textContent);
+var nodes = data.nodes, edges = data.edges || [], clusters = data.clusters || [];
+var W = 160, H = 36, GX = 30, GY = 50, MAX_VISIBLE = 2000;
+var SVG = "http://www.w3.org/2000/svg";
+
+var inputs = nodes.map(function() { return []; }), consumers = nodes.map(function() { return []; });
+edges.forEach(function(e) { consumers[e[0]].push(e[1]); inputs[e[1]].push(e[0]); });
+
+// the largest clusters are collapsed until the graph is small enough to be drawn
+var collapsed = {};
+(function() {
+	var visible = nodes.length;
+	var order = clusters.map(function(c, i) { return i; }).sort(function(a, b) { return clusters[b].size - clusters[a].size; });
+	for (var k = 0; k < order.length && visible > MAX_VISIBLE; k++) {
+		if (clusters[order[k]].size < 2) { break; }
+		collapsed[order[k]] = true;
+		visible -= clusters[order[k]].size - 1;
+	}
+})();
+
+function color(c) { return "hsl(" + ((c * 137.5) % 360) + ",60%,85%)"; }
+function keyOf(i) { var c = nodes[i].cluster; return collapsed[c] ? "c" + c : "n" + i; }
+function el(name, attrs, parent) {
+	var e = document.createElementNS(SVG, name);
+	for (var k in attrs) { e.setAttribute(k, attrs[k]); }
+	if (parent) { parent.appendChild(e); }
+	return e;
+}
+function text(s) { return document.createTextNode(s); }
+function clip(s, n) { return s.length > n ? s.slice(0, n - 1) + "…" : s; }
+
+var layout = null, selected = null, matches = [], matchIndex = -1;
+var viewport = document.getElementById("viewport"), view = document.getElementById("view");
+var tx = 20, ty = 20, scale = 1;
+function transform() { viewport.setAttribute("transform", "translate(" + tx + "," + ty + ") scale(" + scale + ")"); }
+
+// build lays out the visible graph in layers: a node is below all its inputs
+function build() {
+	var vnodes = {}, list = [];
+	nodes.forEach(function(n, i) {
+		var k = keyOf(i);
+		if (!vnodes[k]) {
+			vnodes[k] = { key: k, members: [], preds: {}, succs: {}, index: list.length };
+			if (k[0] === "c") { vnodes[k].cluster = n.cluster; }
+			list.push(vnodes[k]);
+		}
+		vnodes[k].members.push(i);
+	});
+	var vedges = [];
+	edges.forEach(function(e) {
+		var a = vnodes[keyOf(e[0])], b = vnodes[keyOf(e[1])];
+		if (a === b || a.succs[b.key]) { return; }
+		a.succs[b.key] = b; b.preds[a.key] = a;
+		vedges.push([a, b]);
+	});
+
+	// longest path layering. Collapsing clusters may create cycles, which are broken at the node with the fewest inputs left
+	var indeg = {}, queue = [], done = 0;
+	list.forEach(function(v) { v.layer = 0; indeg[v.key] = Object.keys(v.preds).length; if (!indeg[v.key]) { queue.push(v); } });
+	var seen = {};
+	while (done < list.length) {
+		if (queue.length === 0) {
+			var best = null;
+			list.forEach(function(v) { if (!seen[v.key] && (best === null || indeg[v.key] < indeg[best.key])) { best = v; } });
+			queue.push(best);
+		}
+		var v = queue.shift();
+		if (seen[v.key]) { continue; }
+		seen[v.key] = true; done++;
+		for (var k in v.succs) {
+			var s = v.succs[k];
+			if (seen[s.key]) { continue; }
+			s.layer = Math.max(s.layer, v.layer + 1);
+			if (--indeg[s.key] === 0) { queue.push(s); }
+		}
+	}
+
+	var layers = [];
+	list.forEach(function(v) { (layers[v.layer] = layers[v.layer] || []).push(v); });
+	for (var l = 0; l < layers.length; l++) { layers[l] = layers[l] || []; }
+	function place(layer) { layer.forEach(function(v, i) { v.pos = (i + 0.5) / layer.length; }); }
+	layers.forEach(place);
+	// barycenter sweeps to reduce the crossings
+	function sweep(down) {
+		for (var i = 0; i < layers.length; i++) {
+			var layer = layers[down ? i : layers.length - 1 - i];
+			layer.forEach(function(v) {
+				var ns = down ? v.preds : v.succs, sum = 0, n = 0;
+				for (var k in ns) { sum += ns[k].pos; n++; }
+				v.bary = n ? sum / n : v.pos;
+			});
+			layer.sort(function(a, b) { return a.bary - b.bary; });
+			place(layer);
+		}
+	}
+	sweep(true); sweep(false); sweep(true);
+
+	var width = 0;
+	layers.forEach(function(layer) { width = Math.max(width, layer.length * (W + GX)); });
+	layers.forEach(function(layer, l) {
+		var offset = (width - layer.length * (W + GX)) / 2;
+		layer.forEach(function(v, i) { v.x = offset + i * (W + GX); v.y = l * (H + GY); });
+	});
+	return { nodes: vnodes, list: list, edges: vedges };
+}
+
+function label(v) {
+	if (v.cluster !== undefined) { return [clusters[v.cluster].name, v.members.length + " nodes"]; }
+	var n = nodes[v.members[0]];
+	return [n.name, n.op + " " + n.shape];
+}
+
+function draw() {
+	layout = build();
+	while (viewport.firstChild) { viewport.removeChild(viewport.firstChild); }
+	var eg = el("g", {}, viewport), ng = el("g", {}, viewport);
+	layout.edges.forEach(function(e) {
+		var a = e[0], b = e[1], x1 = a.x + W / 2, y1 = a.y + H, x2 = b.x + W / 2, y2 = b.y, my = (y1 + y2) / 2;
+		e.path = el("path", { "class": "edge", d: "M" + x1 + "," + y1 + " C" + x1 + "," + my + " " + x2 + "," + my + " " + x2 + "," + y2 }, eg);
+	});
+	layout.list.forEach(function(v) {
+		var c = v.cluster !== undefined ? v.cluster : nodes[v.members[0]].cluster;
+		var g = el("g", { "class": "node" + (v.cluster !== undefined ? " cluster" : ""), transform: "translate(" + v.x + "," + v.y + ")" }, ng);
+		if (v.cluster === undefined) {
+			var n = nodes[v.members[0]];
+			if ((n.value && (n.value.nan || n.value.inf)) || (n.grad && (n.grad.nan || n.grad.inf))) { g.classList.add("nan"); }
+		}
+		el("rect", { width: W, height: H, rx: 6, fill: color(c) }, g);
+		var lines = label(v);
+		el("text", { x: 6, y: 14 }, g).appendChild(text(clip(lines[0], 26)));
+		el("text", { x: 6, y: 28, fill: "#444" }, g).appendChild(text(clip(lines[1], 28)));
+		var title = el("title", {}, g);
+		title.appendChild(text(lines.join("\n")));
+		g.addEventListener("click", function(ev) { ev.stopPropagation(); select(v); });
+		g.addEventListener("dblclick", function(ev) {
+			ev.stopPropagation();
+			if (v.cluster !== undefined) { delete collapsed[v.cluster]; refresh(); }
+		});
+		v.el = g;
+	});
+	highlight();
+	if (selected) {
+		var v = layout.nodes[selected.key] || layout.nodes[keyOf(selected.members[0])];
+		selected = null;
+		if (v) { select(v); }
+	}
+}
+
+function refresh() { draw(); listClusters(); }
+
+function highlight() {
+	var set = {};
+	matches.forEach(function(i) { set[keyOf(i)] = true; });
+	layout.list.forEach(function(v) { v.el.classList.toggle("match", !!set[v.key]); });
+}
+
+function select(v) {
+	if (selected && selected.el) { selected.el.classList.remove("selected"); }
+	selected = v;
+	v.el.classList.add("selected");
+	layout.edges.forEach(function(e) { e.path.classList.toggle("selected", e[0] === v || e[1] === v); });
+	details(v);
+}
+
+function center(v) {
+	var r = view.getBoundingClientRect();
+	tx = r.width / 2 - (v.x + W / 2) * scale;
+	ty = r.height / 2 - (v.y + H / 2) * scale;
+	transform();
+}
+
+// focus shows the node i, expanding its cluster if needed
+function focus(i) {
+	var c = nodes[i].cluster;
+	if (collapsed[c]) { delete collapsed[c]; refresh(); }
+	var v = layout.nodes[keyOf(i)];
+	select(v);
+	center(v);
+}
+
+function row(table, k, v) {
+	var tr = table.insertRow(), td = tr.insertCell();
+	td.appendChild(text(k));
+	td = tr.insertCell();
+	if (v instanceof Node) { td.appendChild(v); } else { td.appendChild(text(v === undefined ? "" : String(v))); }
+}
+
+function links(list) {
+	var span = document.createElement("span");
+	list.forEach(function(i, k) {
+		if (k > 0) { span.appendChild(text(", ")); }
+		var a = document.createElement("a");
+		a.appendChild(text(nodes[i].name));
+		a.addEventListener("click", function() { focus(i); });
+		span.appendChild(a);
+	});
+	return span;
+}
+
+function statsTable(parent, title, s) {
+	var h = document.createElement("h3");
+	h.appendChild(text(title));
+	parent.appendChild(h);
+	var t = document.createElement("table");
+	row(t, "dtype", s.dtype);
+	row(t, "size", s.size);
+	if (s.error) {
+		row(t, "error", s.error);
+	} else {
+		row(t, "min", s.min); row(t, "max", s.max); row(t, "mean", s.mean); row(t, "std", s.std);
+		if (s.nan) { row(t, "NaN", s.nan); }
+		if (s.inf) { row(t, "Inf", s.inf); }
+	}
+	if (s.data) {
+		var pre = document.createElement("pre");
+		pre.appendChild(text(s.data));
+		row(t, "data", pre);
+	}
+	parent.appendChild(t);
+}
+
+function details(v) {
+	var d = document.getElementById("details");
+	d.innerHTML = "";
+	var t = document.createElement("table");
+	if (v.cluster !== undefined) {
+		row(t, "cluster", clusters[v.cluster].name);
+		row(t, "nodes", v.members.length);
+		var expand = document.createElement("a");
+		expand.appendChild(text("expand"));
+		expand.addEventListener("click", function() { delete collapsed[v.cluster]; refresh(); });
+		row(t, "", expand);
+		row(t, "members", links(v.members.slice(0, 500)));
+		d.appendChild(t);
+		return;
+	}
+	var i = v.members[0], n = nodes[i];
+	row(t, "name", n.name);
+	row(t, "id", n.id);
+	row(t, "op", n.op);
+	row(t, "type", n.type);
+	row(t, "shape", n.shape);
+	row(t, "device", n.device);
+	row(t, "groups", (n.groups || []).join(", "));
+	row(t, "inputs", links(inputs[i]));
+	row(t, "consumers", links(consumers[i]));
+	d.appendChild(t);
+	if (n.value) { statsTable(d, "Value", n.value); }
+	if (n.grad) { statsTable(d, "Gradient", n.grad); }
+}
+
+function listClusters() {
+	var div = document.getElementById("clusters");
+	div.innerHTML = "";
+	clusters.forEach(function(c, i) {
+		var l = document.createElement("label");
+		var cb = document.createElement("input");
+		cb.type = "checkbox";
+		cb.checked = !!collapsed[i];
+		cb.addEventListener("change", function() {
+			if (cb.checked) { collapsed[i] = true; } else { delete collapsed[i]; }
+			refresh();
+		});
+		var sw = document.createElement("span");
+		sw.style.background = color(i);
+		sw.style.padding = "0 6px";
+		sw.style.marginRight = "4px";
+		l.appendChild(cb);
+		l.appendChild(sw);
+		l.appendChild(text(c.name + " (" + c.size + ")"));
+		div.appendChild(l);
+		div.appendChild(document.createElement("br"));
+	});
+}
+
+var search = document.getElementById("search");
+search.addEventListener("input", function() {
+	var q = search.value.toLowerCase();
+	matches = [];
+	matchIndex = -1;
+	if (q) { nodes.forEach(function(n, i) { if (n.name.toLowerCase().indexOf(q) >= 0) { matches.push(i); } }); }
+	document.getElementById("matches").textContent = q ? matches.length + " matches" : "";
+	highlight();
+});
+search.addEventListener("keydown", function(ev) {
+	if (ev.key !== "Enter" || matches.length === 0) { return; }
+	matchIndex = (matchIndex + 1) % matches.length;
+	document.getElementById("matches").textContent = (matchIndex + 1) + " / " + matches.length + " matches";
+	focus(matches[matchIndex]);
+	highlight();
+});
+document.getElementById("collapseAll").addEventListener("click", function() {
+	clusters.forEach(function(c, i) { collapsed[i] = true; });
+	refresh();
+});
+document.getElementById("expandAll").addEventListener("click", function() { collapsed = {}; refresh(); });
+
+// pan and zoom
+var drag = null;
+view.addEventListener("mousedown", function(ev) { drag = { x: ev.clientX - tx, y: ev.clientY - ty }; view.classList.add("dragging"); });
+window.addEventListener("mousemove", function(ev) { if (drag) { tx = ev.clientX - drag.x; ty = ev.clientY - drag.y; transform(); } });
+window.addEventListener("mouseup", function() { drag = null; view.classList.remove("dragging"); });
+view.addEventListener("wheel", function(ev) {
+	ev.preventDefault();
+	var r = view.getBoundingClientRect(), px = ev.clientX - r.left, py = ev.clientY - r.top;
+	var f = Math.exp(-ev.deltaY * 0.001);
+	tx = px - (px - tx) * f;
+	ty = py - (py - ty) * f;
+	scale *= f;
+	transform();
+}, { passive: false });
+
+refresh();
+transform();
+})();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,122 @@
+package statedict
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia/data"
+	"gorgonia.org/tensor"
+)
+
+// ReadNpz reads a .npz archive of named arrays, as written by numpy.savez. Each array keeps the Dtype it is stored as.
+func ReadNpz(r io.ReaderAt, size int64) (StateDict, error) {
+	arrays, err := data.ReadNpz(r, size, tensor.Dtype{})
+	if err != nil {
+		return nil, err
+	}
+	return StateDict(arrays), nil
+}
+
+// WriteNpz writes the StateDict as an uncompressed .npz archive that numpy.load can read.
+func WriteNpz(w io.Writer, sd StateDict) error {
+	zw := zip.NewWriter(w)
+	for _, k := range sd.Keys() {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: k + ".npy", Method: zip.Store})
+		if err != nil {
+			return errors.Wrapf(err, "Unable to write %q", k)
+		}
+		if err = writeNpy(f, sd[k]); err != nil {
+			return errors.Wrapf(err, "Unable to write %q", k)
+		}
+	}
+	return zw.Close()
+}
+
+// npyDescr returns the NumPy array-protocol type of a Dtype. Go's ints and uints are stored as 64 bit integers.
+func npyDescr(dt tensor.Dtype) (string, error) {
+	switch dt {
+	case tensor.Float64:
+		return "<f8", nil
+	case tensor.Float32:
+		return "<f4", nil
+	case tensor.Int, tensor.Int64:
+		return "<i8", nil
+	case tensor.Int32:
+		return "<i4", nil
+	case tensor.Int16:
+		return "<i2", nil
+	case tensor.Int8:
+		return "|i1", nil
+	case tensor.Uint, tensor.Uint64:
+		return "<u8", nil
+	case tensor.Uint32:
+		return "<u4", nil
+	case tensor.Uint16:
+		return "<u2", nil
+	case tensor.Uint8:
+		return "|u1", nil
+	case tensor.Bool:
+		return "|b1", nil
+	}
+	return "", errors.Errorf("Cannot store a tensor of %v as a npy array", dt)
+}
+
+// writeNpy writes t in version 1.0 of the npy format
+func writeNpy(w io.Writer, t *tensor.Dense) error {
+	descr, err := npyDescr(t.Dtype())
+	if err != nil {
+		return err
+	}
+	dims := make([]string, t.Dims())
+	for i, s := range t.Shape() {
+		dims[i] = fmt.Sprintf("%d", s)
+	}
+	shape := strings.Join(dims, ", ")
+	if len(dims) == 1 {
+		shape += ","
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%s), }", descr, shape)
+	// the header is padded with spaces and terminated by a newline, so that the data is aligned to 64 bytes
+	const preamble = 10 // magic, version and header length
+	if pad := 64 - (preamble+len(header)+1)%64; pad < 64 {
+		header += strings.Repeat(" ", pad)
+	}
+	header += "\n"
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("\x93NUMPY\x01\x00")
+	binary.Write(bw, binary.LittleEndian, uint16(len(header)))
+	bw.WriteString(header)
+	if err = binary.Write(bw, binary.LittleEndian, littleEndianData(t)); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// LoadNpz reads the .npz archive at the given path.
+func LoadNpz(path string) (StateDict, error) {
+	arrays, err := data.LoadNpz(path, tensor.Dtype{})
+	if err != nil {
+		return nil, err
+	}
+	return StateDict(arrays), nil
+}
+
+// SaveNpz writes the StateDict to a .npz archive at the given path.
+func SaveNpz(path string, sd StateDict) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = WriteNpz(f, sd); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "Unable to write %q", path)
+	}
+	return f.Close()
+}
@@ -0,0 +1,299 @@
+package statedict
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// safetensors files start with the length of their JSON header, which is capped to keep a corrupt file from exhausting memory
+const maxSafetensorsHeader = 100 << 20
+
+const safetensorsMetadataKey = "__metadata__"
+
+// safetensorsTypes are the Go types the safetensors dtypes are read as. Half precision floats are read as their bits.
+var safetensorsTypes = map[string]reflect.Type{
+	"F64":  reflect.TypeOf(float64(0)),
+	"F32":  reflect.TypeOf(float32(0)),
+	"F16":  reflect.TypeOf(uint16(0)),
+	"BF16": reflect.TypeOf(uint16(0)),
+	"I64":  reflect.TypeOf(int64(0)),
+	"I32":  reflect.TypeOf(int32(0)),
+	"I16":  reflect.TypeOf(int16(0)),
+	"I8":   reflect.TypeOf(int8(0)),
+	"U64":  reflect.TypeOf(uint64(0)),
+	"U32":  reflect.TypeOf(uint32(0)),
+	"U16":  reflect.TypeOf(uint16(0)),
+	"U8":   reflect.TypeOf(uint8(0)),
+	"BOOL": reflect.TypeOf(false),
+}
+
+type safetensorsEntry struct {
+	Dtype       string   `json:"dtype"`
+	Shape       []int    `json:"shape"`
+	DataOffsets [2]int64 `json:"data_offsets"`
+
+	name string
+}
+
+// safetensorsDtype returns the safetensors name of a Dtype. Go's ints and uints are stored as 64 bit integers.
+func safetensorsDtype(dt tensor.Dtype) (string, error) {
+	switch dt {
+	case tensor.Float64:
+		return "F64", nil
+	case tensor.Float32:
+		return "F32", nil
+	case tensor.Int, tensor.Int64:
+		return "I64", nil
+	case tensor.Int32:
+		return "I32", nil
+	case tensor.Int16:
+		return "I16", nil
+	case tensor.Int8:
+		return "I8", nil
+	case tensor.Uint, tensor.Uint64:
+		return "U64", nil
+	case tensor.Uint32:
+		return "U32", nil
+	case tensor.Uint16:
+		return "U16", nil
+	case tensor.Uint8:
+		return "U8", nil
+	case tensor.Bool:
+		return "BOOL", nil
+	}
+	return "", errors.Errorf("Cannot store a tensor of %v in the safetensors format", dt)
+}
+
+// ReadSafetensors reads a file in the safetensors format. It also returns the metadata stored in the file, if any.
+//
+// Half precision tensors (F16 and BF16) are widened to tensor.Float32, as Gorgonia has no half precision Dtype.
+func ReadSafetensors(r io.Reader) (StateDict, map[string]string, error) {
+	var headerLen uint64
+	if err := binary.Read(r, binary.LittleEndian, &headerLen); err != nil {
+		return nil, nil, errors.Wrap(err, "Unable to read the header length of the safetensors file")
+	}
+	if headerLen > maxSafetensorsHeader {
+		return nil, nil, errors.Errorf("The header of the safetensors file is too large (%d bytes)", headerLen)
+	}
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, errors.Wrap(err, "Unable to read the header of the safetensors file")
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(header, &raw); err != nil {
+		return nil, nil, errors.Wrap(err, "Unable to parse the header of the safetensors file")
+	}
+	var metadata map[string]string
+	entries := make([]*safetensorsEntry, 0, len(raw))
+	for name, msg := range raw {
+		if name == safetensorsMetadataKey {
+			if err := json.Unmarshal(msg, &metadata); err != nil {
+				return nil, nil, errors.Wrap(err, "Unable to parse the metadata of the safetensors file")
+			}
+			continue
+		}
+		e := &safetensorsEntry{name: name}
+		if err := json.Unmarshal(msg, e); err != nil {
+			return nil, nil, errors.Wrapf(err, "Unable to parse the header entry of %q", name)
+		}
+		entries = append(entries, e)
+	}
+
+	// the data of the tensors is read in order, so that the file can be streamed
+	sort.Slice(entries, func(i, j int) bool { return entries[i].DataOffsets[0] < entries[j].DataOffsets[0] })
+	br := bufio.NewReader(r)
+	var pos int64
+	sd := make(StateDict, len(entries))
+	for _, e := range entries {
+		begin, end := e.DataOffsets[0], e.DataOffsets[1]
+		if begin < pos || end < begin {
+			return nil, nil, errors.Errorf("Invalid data offsets %v for %q", e.DataOffsets, e.name)
+		}
+		if _, err := io.CopyN(ioutil.Discard, br, begin-pos); err != nil {
+			return nil, nil, errors.Wrapf(err, "Unable to read the data of %q", e.name)
+		}
+		t, err := readSafetensor(io.LimitReader(br, end-begin), e, end-begin)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "Unable to read the data of %q", e.name)
+		}
+		sd[e.name] = t
+		pos = end
+	}
+	return sd, metadata, nil
+}
+
+func readSafetensor(r io.Reader, e *safetensorsEntry, size int64) (*tensor.Dense, error) {
+	n := 1
+	for _, s := range e.Shape {
+		if s < 0 {
+			return nil, errors.Errorf("Invalid shape %v", e.Shape)
+		}
+		n *= s
+	}
+
+	elType, ok := safetensorsTypes[e.Dtype]
+	if !ok {
+		return nil, errors.Errorf("The safetensors dtype %q is not supported", e.Dtype)
+	}
+	if elSize := int64(elType.Size()); int64(n)*elSize != size {
+		return nil, errors.Errorf("Expected %d bytes for a tensor of %s with shape %v. Got %d", int64(n)*elSize, e.Dtype, e.Shape, size)
+	}
+	data := reflect.MakeSlice(reflect.SliceOf(elType), n, n).Interface()
+	if err := binary.Read(r, binary.LittleEndian, data); err != nil {
+		return nil, err
+	}
+
+	switch e.Dtype {
+	case "F16":
+		data = widen(data.([]uint16), float16ToFloat32)
+	case "BF16":
+		data = widen(data.([]uint16), bfloat16ToFloat32)
+	}
+
+	if len(e.Shape) == 0 {
+		return tensor.New(tensor.FromScalar(reflectIndex0(data))), nil
+	}
+	return tensor.New(tensor.WithShape(e.Shape...), tensor.WithBacking(data)), nil
+}
+
+func widen(bits []uint16, fn func(uint16) float32) []float32 {
+	retVal := make([]float32, len(bits))
+	for i, b := range bits {
+		retVal[i] = fn(b)
+	}
+	return retVal
+}
+
+func bfloat16ToFloat32(b uint16) float32 {
+	return math.Float32frombits(uint32(b) << 16)
+}
+
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h) & 0x3ff
+	switch {
+	case exp == 0 && mant == 0: // ±0
+		return math.Float32frombits(sign)
+	case exp == 0: // subnormal: normalize it
+		e := uint32(127 - 15 + 1)
+		for mant&0x400 == 0 {
+			mant <<= 1
+			e--
+		}
+		return math.Float32frombits(sign | e<<23 | (mant&0x3ff)<<13)
+	case exp == 0x1f: // ±Inf and NaN
+		return math.Float32frombits(sign | 0xff<<23 | mant<<13)
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+}
+
+// WriteSafetensors writes the StateDict in the safetensors format, along with the optional metadata.
+func WriteSafetensors(w io.Writer, sd StateDict, metadata map[string]string) error {
+	header := make(map[string]interface{}, len(sd)+1)
+	if len(metadata) > 0 {
+		header[safetensorsMetadataKey] = metadata
+	}
+	keys := sd.Keys()
+	datas := make([]interface{}, len(keys))
+	var offset int64
+	for i, k := range keys {
+		t := sd[k]
+		dt, err := safetensorsDtype(t.Dtype())
+		if err != nil {
+			return errors.Wrapf(err, "Unable to write %q", k)
+		}
+		data := littleEndianData(t)
+		size := int64(binary.Size(data))
+		header[k] = &safetensorsEntry{
+			Dtype:       dt,
+			Shape:       append([]int{}, t.Shape()...),
+			DataOffsets: [2]int64{offset, offset + size},
+		}
+		datas[i] = data
+		offset += size
+	}
+
+	h, err := json.Marshal(header)
+	if err != nil {
+		return errors.Wrap(err, "Unable to encode the header of the safetensors file")
+	}
+	// the data is aligned to 8 bytes
+	for len(h)%8 != 0 {
+		h = append(h, ' ')
+	}
+	bw := bufio.NewWriter(w)
+	if err := binary.Write(bw, binary.LittleEndian, uint64(len(h))); err != nil {
+		return err
+	}
+	if _, err := bw.Write(h); err != nil {
+		return err
+	}
+	for i, data := range datas {
+		if err := binary.Write(bw, binary.LittleEndian, data); err != nil {
+			return errors.Wrapf(err, "Unable to write %q", keys[i])
+		}
+	}
+	return bw.Flush()
+}
+
+// littleEndianData returns the data of t as a slice that encoding/binary can write. Go's ints and uints are widened to 64 bits.
+func littleEndianData(t *tensor.Dense) interface{} {
+	if t.IsView() {
+		t = t.Materialize().(*tensor.Dense)
+	}
+	data := t.Data()
+	if t.Shape().IsScalar() {
+		data = reflectSlice(data)
+	}
+	switch d := data.(type) {
+	case []int:
+		retVal := make([]int64, len(d))
+		for i, v := range d {
+			retVal[i] = int64(v)
+		}
+		return retVal
+	case []uint:
+		retVal := make([]uint64, len(d))
+		for i, v := range d {
+			retVal[i] = uint64(v)
+		}
+		return retVal
+	}
+	return data
+}
+
+// LoadSafetensors reads the safetensors file at the given path.
+func LoadSafetensors(path string) (StateDict, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sd, _, err := ReadSafetensors(f)
+	return sd, errors.Wrapf(err, "Unable to read %q", path)
+}
+
+// SaveSafetensors writes the StateDict to a safetensors file at the given path.
+func SaveSafetensors(path string, sd StateDict, metadata map[string]string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = WriteSafetensors(f, sd, metadata); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "Unable to write %q", path)
+	}
+	return f.Close()
+}
@@ -0,0 +1,340 @@
+// Package statedict imports and exports the weights of a model as named tensors, in the safetensors format or as NumPy .npz archives.
+//
+// The tensors are matched to the nodes of an expression graph by name, much like a PyTorch state_dict is matched to the parameters of a module.
+// Loading is strict by default: every node must be given a tensor, and every tensor must be used.
+//
+// A typical use is to fine-tune a model trained in PyTorch:
+//		sd, err := statedict.LoadSafetensors("model.safetensors")
+//		...
+//		report, err := statedict.Load(sd, learnables,
+//			statedict.WithNameMap(map[string]string{"fc.weight": "w0", "fc.bias": "b0"}),
+//			statedict.WithTranspose("fc.weight"), // PyTorch stores the weights of a linear layer as (out, in)
+//		)
+package statedict
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// StateDict is a set of tensors, keyed by name.
+type StateDict map[string]*tensor.Dense
+
+// Keys returns the sorted names of the tensors.
+func (sd StateDict) Keys() []string {
+	retVal := make([]string, 0, len(sd))
+	for k := range sd {
+		retVal = append(retVal, k)
+	}
+	sort.Strings(retVal)
+	return retVal
+}
+
+// Common permutations of the axes of 4-D tensors, for use with WithPermute.
+var (
+	HWIOToOIHW = []int{3, 2, 0, 1} // convolution kernels as stored by TensorFlow, to the (out, in, height, width) layout Conv2d uses
+	NHWCToNCHW = []int{0, 3, 1, 2} // channels-last images to the channels-first layout Conv2d uses
+)
+
+type options struct {
+	names    map[string]string // key → node name
+	rename   func(string) string
+	permutes map[string][]int // key → permutation
+	permIf   func(key string, shape tensor.Shape) []int
+	strict   bool
+	cast     bool
+}
+
+// Opt is a function that configures how tensors are matched to nodes when loading or saving a StateDict.
+type Opt func(*options)
+
+// WithNameMap maps the keys of a StateDict to the names of nodes. Keys that are not in the map are matched to nodes of the same name.
+// When saving, the map is used in reverse.
+func WithNameMap(m map[string]string) Opt {
+	return func(o *options) {
+		for k, v := range m {
+			o.names[k] = v
+		}
+	}
+}
+
+// WithRenamer renames the keys that are not in the name map, e.g. to strip a "module." prefix. It is only used when loading.
+func WithRenamer(fn func(key string) string) Opt {
+	return func(o *options) {
+		o.rename = fn
+	}
+}
+
+// WithTranspose transposes the 2-D tensors with the given keys when loading, and again when saving.
+// PyTorch stores the weights of a linear layer as (out, in), whereas Mul(x, w) expects w to be (in, out).
+func WithTranspose(keys ...string) Opt {
+	return WithPermute([]int{1, 0}, keys...)
+}
+
+// WithTransposeIf is like WithTranspose, for every 2-D tensor whose key pred returns true for.
+func WithTransposeIf(pred func(key string) bool) Opt {
+	return func(o *options) {
+		o.permIf = func(key string, shape tensor.Shape) []int {
+			if shape.Dims() == 2 && pred(key) {
+				return []int{1, 0}
+			}
+			return nil
+		}
+	}
+}
+
+// WithPermute permutes the axes of the tensors with the given keys when loading, and permutes them back when saving.
+func WithPermute(axes []int, keys ...string) Opt {
+	return func(o *options) {
+		for _, k := range keys {
+			o.permutes[k] = axes
+		}
+	}
+}
+
+// WithNonStrict allows nodes without a tensor, and tensors without a node. They are listed in the Report instead.
+func WithNonStrict() Opt {
+	return func(o *options) {
+		o.strict = false
+	}
+}
+
+// WithCast converts tensors to the Dtype of the nodes they are loaded into. By default a Dtype mismatch is an error.
+func WithCast() Opt {
+	return func(o *options) {
+		o.cast = true
+	}
+}
+
+func newOptions(opts []Opt) *options {
+	o := &options{
+		names:    make(map[string]string),
+		permutes: make(map[string][]int),
+		strict:   true,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *options) nodeName(key string) string {
+	if name, ok := o.names[key]; ok {
+		return name
+	}
+	if o.rename != nil {
+		return o.rename(key)
+	}
+	return key
+}
+
+func (o *options) key(nodeName string) string {
+	for k, v := range o.names {
+		if v == nodeName {
+			return k
+		}
+	}
+	return nodeName
+}
+
+func (o *options) permutation(key string, shape tensor.Shape) []int {
+	if axes, ok := o.permutes[key]; ok {
+		return axes
+	}
+	if o.permIf != nil {
+		return o.permIf(key, shape)
+	}
+	return nil
+}
+
+// Report lists the outcome of loading a StateDict.
+type Report struct {
+	Loaded     []string // the names of the nodes that were loaded
+	Missing    []string // the names of the nodes that had no tensor
+	Unexpected []string // the keys of the tensors that matched no node
+}
+
+// KeyError is returned by a strict Load when some nodes had no tensor, or some tensors matched no node.
+type KeyError struct {
+	Missing    []string
+	Unexpected []string
+}
+
+func (err *KeyError) Error() string {
+	var buf strings.Builder
+	buf.WriteString("Error(s) in loading state dict:")
+	if len(err.Missing) > 0 {
+		fmt.Fprintf(&buf, " Missing key(s): %q.", err.Missing)
+	}
+	if len(err.Unexpected) > 0 {
+		fmt.Fprintf(&buf, " Unexpected key(s): %q.", err.Unexpected)
+	}
+	return buf.String()
+}
+
+// Load binds the tensors of the StateDict to the nodes they match. The shapes (after any permutation) and Dtypes must match those of the nodes.
+//
+// Nothing is bound unless every tensor that matches a node can be bound, and, when loading strictly, unless every node and every tensor is matched.
+// A strict Load that fails because of unmatched nodes or tensors returns a *KeyError along with the Report.
+func Load(sd StateDict, nodes []*gorgonia.Node, opts ...Opt) (*Report, error) {
+	o := newOptions(opts)
+
+	byName := make(map[string]*gorgonia.Node, len(nodes))
+	for _, n := range nodes {
+		if _, ok := byName[n.Name()]; ok {
+			return nil, errors.Errorf("More than one node is named %q", n.Name())
+		}
+		byName[n.Name()] = n
+	}
+
+	report := new(Report)
+	values := make(map[*gorgonia.Node]*tensor.Dense)
+	for _, key := range sd.Keys() {
+		name := o.nodeName(key)
+		n, ok := byName[name]
+		if !ok {
+			report.Unexpected = append(report.Unexpected, key)
+			continue
+		}
+		if _, ok := values[n]; ok {
+			return nil, errors.Errorf("More than one tensor is mapped to the node %q", name)
+		}
+
+		t := sd[key]
+		if axes := o.permutation(key, t.Shape()); axes != nil {
+			var err error
+			if t, err = permute(t, axes); err != nil {
+				return nil, errors.Wrapf(err, "Unable to permute %q", key)
+			}
+		}
+		if !gorgonia.SameShape(t.Shape(), n.Shape()) {
+			return nil, errors.Errorf("Shape mismatch for %q: the tensor has shape %v, the node %q has shape %v", key, t.Shape(), name, n.Shape())
+		}
+		if !t.Dtype().Eq(n.Dtype()) {
+			if !o.cast {
+				return nil, errors.Errorf("Dtype mismatch for %q: the tensor is %v, the node %q is %v", key, t.Dtype(), name, n.Dtype())
+			}
+			var err error
+			if t, err = cast(t, n.Dtype()); err != nil {
+				return nil, errors.Wrapf(err, "Unable to cast %q", key)
+			}
+		}
+		if t == sd[key] {
+			t = t.Clone().(*tensor.Dense)
+		}
+		values[n] = t
+	}
+	for _, n := range nodes {
+		if _, ok := values[n]; !ok {
+			report.Missing = append(report.Missing, n.Name())
+		}
+	}
+	if o.strict && (len(report.Missing) > 0 || len(report.Unexpected) > 0) {
+		return report, &KeyError{Missing: report.Missing, Unexpected: report.Unexpected}
+	}
+
+	for _, n := range nodes {
+		t, ok := values[n]
+		if !ok {
+			continue
+		}
+		if t.Shape().IsScalar() {
+			if err := gorgonia.Let(n, t.Data()); err != nil {
+				return report, errors.Wrapf(err, "Unable to load %q", n.Name())
+			}
+		} else if err := gorgonia.Let(n, t); err != nil {
+			return report, errors.Wrapf(err, "Unable to load %q", n.Name())
+		}
+		report.Loaded = append(report.Loaded, n.Name())
+	}
+	return report, nil
+}
+
+// Save collects the values of the nodes into a StateDict. The values are copied, and the name map and permutations are applied in reverse.
+func Save(nodes []*gorgonia.Node, opts ...Opt) (StateDict, error) {
+	o := newOptions(opts)
+	sd := make(StateDict, len(nodes))
+	for _, n := range nodes {
+		key := o.key(n.Name())
+		if _, ok := sd[key]; ok {
+			return nil, errors.Errorf("More than one node is saved as %q", key)
+		}
+		v := n.Value()
+		if v == nil {
+			return nil, errors.Errorf("Node %q has no value to save", n.Name())
+		}
+		var t *tensor.Dense
+		switch vt := v.(type) {
+		case *tensor.Dense:
+			t = vt.Clone().(*tensor.Dense)
+		case gorgonia.Scalar:
+			t = tensor.New(tensor.FromScalar(vt.Data()))
+		default:
+			return nil, errors.Errorf("Cannot save the value of node %q, a %T", n.Name(), v)
+		}
+		if axes := o.permutation(key, t.Shape()); axes != nil {
+			var err error
+			if t, err = permute(t, inverse(axes)); err != nil {
+				return nil, errors.Wrapf(err, "Unable to permute %q", key)
+			}
+		}
+		sd[key] = t
+	}
+	return sd, nil
+}
+
+// permute returns a row major copy of t with its axes permuted
+func permute(t *tensor.Dense, axes []int) (*tensor.Dense, error) {
+	if len(axes) != t.Dims() {
+		return nil, errors.Errorf("Cannot permute the axes of a tensor of shape %v with %v", t.Shape(), axes)
+	}
+	v, err := tensor.T(t, axes...)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*tensor.Dense).Materialize().(*tensor.Dense), nil
+}
+
+func inverse(axes []int) []int {
+	retVal := make([]int, len(axes))
+	for i, a := range axes {
+		retVal[a] = i
+	}
+	return retVal
+}
+
+// cast converts the values of t to the given Dtype
+func cast(t *tensor.Dense, dt tensor.Dtype) (*tensor.Dense, error) {
+	src := reflect.ValueOf(t.Data())
+	if src.Kind() != reflect.Slice {
+		src = reflect.ValueOf(reflectSlice(t.Data()))
+	}
+	if !src.Type().Elem().ConvertibleTo(dt.Type) {
+		return nil, errors.Errorf("Cannot convert %v to %v", t.Dtype(), dt)
+	}
+	dst := reflect.MakeSlice(reflect.SliceOf(dt.Type), src.Len(), src.Len())
+	for i := 0; i < src.Len(); i++ {
+		dst.Index(i).Set(src.Index(i).Convert(dt.Type))
+	}
+	if t.Shape().IsScalar() {
+		return tensor.New(tensor.FromScalar(reflectIndex0(dst.Interface()))), nil
+	}
+	return tensor.New(tensor.WithShape(t.Shape().Clone()...), tensor.WithBacking(dst.Interface())), nil
+}
+
+// reflectSlice wraps a scalar in a slice of one
+func reflectSlice(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	return reflect.Append(reflect.MakeSlice(reflect.SliceOf(rv.Type()), 0, 1), rv).Interface()
+}
+
+// reflectIndex0 returns the first element of a slice
+func reflectIndex0(slice interface{}) interface{} {
+	return reflect.ValueOf(slice).Index(0).Interface()
+}
@@ -0,0 +1,260 @@
+package statedict
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+func testStateDict() StateDict {
+	return StateDict{
+		"fc.weight": tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, 2, 3, 4, 5, 6})),
+		"fc.bias":   tensor.New(tensor.WithShape(2), tensor.WithBacking([]float64{0.5, -0.5})),
+		"steps":     tensor.New(tensor.WithShape(1), tensor.WithBacking([]int{1337})),
+		"mask":      tensor.New(tensor.WithShape(3), tensor.WithBacking([]bool{true, false, true})),
+		"emb":       tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float32{1, 2, 3, 4})),
+		"scale":     tensor.New(tensor.FromScalar(2.5)),
+	}
+}
+
+func assertRoundTrip(t *testing.T, want, got StateDict) {
+	require.Equal(t, want.Keys(), got.Keys())
+	for _, k := range want.Keys() {
+		assert.True(t, want[k].Shape().Eq(got[k].Shape()), "%q: %v vs %v", k, want[k].Shape(), got[k].Shape())
+		switch data := want[k].Data().(type) {
+		case []int:
+			// Go's ints are stored as int64s
+			assert.Equal(t, []int64{int64(data[0])}, got[k].Data(), k)
+		default:
+			assert.Equal(t, data, got[k].Data(), k)
+		}
+	}
+}
+
+func TestSafetensors(t *testing.T) {
+	sd := testStateDict()
+	var buf bytes.Buffer
+	require.NoError(t, WriteSafetensors(&buf, sd, map[string]string{"format": "pt"}))
+
+	// the data is aligned to 8 bytes
+	headerLen := binary.LittleEndian.Uint64(buf.Bytes())
+	assert.Equal(t, uint64(0), headerLen%8)
+
+	got, metadata, err := ReadSafetensors(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"format": "pt"}, metadata)
+	assertRoundTrip(t, sd, got)
+
+	dir, err := ioutil.TempDir("", "statedict")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "model.safetensors")
+	require.NoError(t, SaveSafetensors(path, sd, nil))
+	got, err = LoadSafetensors(path)
+	require.NoError(t, err)
+	assertRoundTrip(t, sd, got)
+
+	// errors
+	_, _, err = ReadSafetensors(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	assert.Error(t, err)
+	_, _, err = ReadSafetensors(strings.NewReader("not a safetensors file"))
+	assert.Error(t, err)
+	err = WriteSafetensors(&buf, StateDict{"c": tensor.New(tensor.WithShape(1), tensor.WithBacking([]complex128{1}))}, nil)
+	assert.Error(t, err)
+}
+
+// safetensorsFile builds a safetensors file by hand
+func safetensorsFile(header string, data ...interface{}) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint64(len(header)))
+	buf.WriteString(header)
+	for _, d := range data {
+		binary.Write(&buf, binary.LittleEndian, d)
+	}
+	return buf.Bytes()
+}
+
+func TestSafetensors_HalfPrecision(t *testing.T) {
+	// the tensors are not stored in the order of the header, and there is a gap between them
+	header := `{"h":{"dtype":"F16","shape":[4],"data_offsets":[12,20]},"b":{"dtype":"BF16","shape":[2],"data_offsets":[0,4]}}`
+	file := safetensorsFile(header,
+		[]uint16{0x3fc0, 0xc000},                 // bf16: 1.5, -2
+		[]byte{0, 0, 0, 0, 0, 0, 0, 0},           // padding
+		[]uint16{0x3c00, 0xc100, 0x0001, 0x7c00}, // f16: 1, -2.5, the smallest subnormal, +Inf
+	)
+	sd, _, err := ReadSafetensors(bytes.NewReader(file))
+	require.NoError(t, err)
+	assert.Equal(t, []float32{1.5, -2}, sd["b"].Data())
+	assert.Equal(t, []float32{1, -2.5, float32(math.Pow(2, -24)), float32(math.Inf(1))}, sd["h"].Data())
+
+	// overlapping tensors
+	header = `{"a":{"dtype":"F32","shape":[2],"data_offsets":[0,8]},"b":{"dtype":"F32","shape":[1],"data_offsets":[4,8]}}`
+	_, _, err = ReadSafetensors(bytes.NewReader(safetensorsFile(header, []float32{1, 2})))
+	assert.Error(t, err)
+
+	// size mismatch
+	header = `{"a":{"dtype":"F32","shape":[3],"data_offsets":[0,8]}}`
+	_, _, err = ReadSafetensors(bytes.NewReader(safetensorsFile(header, []float32{1, 2})))
+	assert.Error(t, err)
+}
+
+func TestNpz(t *testing.T) {
+	sd := testStateDict()
+	var buf bytes.Buffer
+	require.NoError(t, WriteNpz(&buf, sd))
+	got, err := ReadNpz(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assertRoundTrip(t, sd, got)
+
+	// the data of every array is aligned to 64 bytes
+	var npy bytes.Buffer
+	require.NoError(t, writeNpy(&npy, sd["fc.weight"]))
+	headerLen := binary.LittleEndian.Uint16(npy.Bytes()[8:])
+	assert.Equal(t, 0, (10+int(headerLen))%64)
+	assert.Equal(t, byte('\n'), npy.Bytes()[10+int(headerLen)-1])
+
+	dir, err := ioutil.TempDir("", "statedict")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "model.npz")
+	require.NoError(t, SaveNpz(path, sd))
+	got, err = LoadNpz(path)
+	require.NoError(t, err)
+	assertRoundTrip(t, sd, got)
+}
+
+// linearModel makes the learnables of a linear layer xw+b
+func linearModel() (*gorgonia.ExprGraph, *gorgonia.Node, *gorgonia.Node) {
+	g := gorgonia.NewGraph()
+	w := gorgonia.NewMatrix(g, tensor.Float64, gorgonia.WithShape(3, 2), gorgonia.WithName("w0"), gorgonia.WithInit(gorgonia.Zeroes()))
+	b := gorgonia.NewVector(g, tensor.Float64, gorgonia.WithShape(2), gorgonia.WithName("b0"), gorgonia.WithInit(gorgonia.Zeroes()))
+	return g, w, b
+}
+
+func TestLoad(t *testing.T) {
+	_, w, b := linearModel()
+	sd := testStateDict()
+	names := WithNameMap(map[string]string{"fc.weight": "w0", "fc.bias": "b0"})
+
+	// strict: the other tensors are unexpected, and nothing is loaded
+	report, err := Load(sd, []*gorgonia.Node{w, b}, names, WithTranspose("fc.weight"))
+	require.Error(t, err)
+	var keyErr *KeyError
+	require.True(t, errors.As(err, &keyErr))
+	assert.Equal(t, []string{"emb", "mask", "scale", "steps"}, keyErr.Unexpected)
+	assert.Empty(t, keyErr.Missing)
+	assert.Equal(t, keyErr.Unexpected, report.Unexpected)
+	assert.Equal(t, []float64{0, 0}, b.Value().Data())
+
+	// non strict
+	report, err = Load(sd, []*gorgonia.Node{w, b}, names, WithTranspose("fc.weight"), WithNonStrict())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"w0", "b0"}, report.Loaded)
+	assert.Equal(t, []float64{1, 4, 2, 5, 3, 6}, w.Value().Data())
+	assert.Equal(t, []float64{0.5, -0.5}, b.Value().Data())
+
+	// the loaded values are copies
+	b.Value().(*tensor.Dense).Set(0, 100.0)
+	assert.Equal(t, 0.5, sd["fc.bias"].Float64s()[0])
+
+	// missing keys
+	_, w, b = linearModel()
+	report, err = Load(StateDict{"w0": sd["fc.weight"]}, []*gorgonia.Node{w, b}, WithTransposeIf(func(key string) bool { return strings.HasPrefix(key, "w") }), WithNonStrict())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b0"}, report.Missing)
+	assert.Equal(t, []float64{1, 4, 2, 5, 3, 6}, w.Value().Data())
+	_, err = Load(StateDict{"w0": sd["fc.weight"]}, []*gorgonia.Node{w, b}, WithTranspose("w0"))
+	assert.Error(t, err)
+
+	// renamer
+	_, w, b = linearModel()
+	_, err = Load(StateDict{"module.b0": sd["fc.bias"]}, []*gorgonia.Node{b}, WithRenamer(func(k string) string { return strings.TrimPrefix(k, "module.") }))
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.5, -0.5}, b.Value().Data())
+}
+
+func TestLoad_Mismatches(t *testing.T) {
+	_, w, b := linearModel()
+	sd := testStateDict()
+
+	// without the transpose, the shapes don't match
+	_, err := Load(StateDict{"w0": sd["fc.weight"]}, []*gorgonia.Node{w})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Shape mismatch")
+
+	// a vector is not loaded into a column, even though tensor.Shape.Eq says they are equal
+	col := gorgonia.NewMatrix(w.Graph(), tensor.Float64, gorgonia.WithShape(2, 1), gorgonia.WithName("col"))
+	_, err = Load(StateDict{"col": tensor.New(tensor.WithShape(2), tensor.WithBacking([]float64{1, 2}))}, []*gorgonia.Node{col})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Shape mismatch")
+
+	// dtypes
+	f32 := StateDict{"b0": tensor.New(tensor.WithShape(2), tensor.WithBacking([]float32{1, 2}))}
+	_, err = Load(f32, []*gorgonia.Node{b})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Dtype mismatch")
+	_, err = Load(f32, []*gorgonia.Node{b}, WithCast())
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 2}, b.Value().Data())
+
+	bools := StateDict{"b0": tensor.New(tensor.WithShape(2), tensor.WithBacking([]bool{true, false}))}
+	_, err = Load(bools, []*gorgonia.Node{b}, WithCast())
+	assert.Error(t, err)
+}
+
+func TestLoadSave_Permute(t *testing.T) {
+	g := gorgonia.NewGraph()
+	kernel := gorgonia.NewTensor(g, tensor.Float32, 4, gorgonia.WithShape(3, 2, 1, 1), gorgonia.WithName("conv0"), gorgonia.WithInit(gorgonia.Zeroes()))
+
+	// a TensorFlow kernel of (height, width, in, out) = (1, 1, 2, 3)
+	hwio := make([]float32, 6)
+	for i := range hwio {
+		hwio[i] = float32(i)
+	}
+	sd := StateDict{"conv/kernel": tensor.New(tensor.WithShape(1, 1, 2, 3), tensor.WithBacking(hwio))}
+	opts := []Opt{WithNameMap(map[string]string{"conv/kernel": "conv0"}), WithPermute(HWIOToOIHW, "conv/kernel")}
+	_, err := Load(sd, []*gorgonia.Node{kernel}, opts...)
+	require.NoError(t, err)
+	// kernel[o, i] = hwio[i, o]
+	assert.Equal(t, []float32{0, 3, 1, 4, 2, 5}, kernel.Value().Data())
+
+	saved, err := Save([]*gorgonia.Node{kernel}, opts...)
+	require.NoError(t, err)
+	require.Equal(t, []string{"conv/kernel"}, saved.Keys())
+	assert.Equal(t, tensor.Shape{1, 1, 2, 3}, saved["conv/kernel"].Shape())
+	assert.Equal(t, hwio, saved["conv/kernel"].Data())
+}
+
+func TestSave(t *testing.T) {
+	g, w, b := linearModel()
+	s := gorgonia.NewScalar(g, tensor.Float64, gorgonia.WithName("s"), gorgonia.WithValue(2.0))
+	sd, err := Save([]*gorgonia.Node{w, b, s}, WithNameMap(map[string]string{"fc.weight": "w0"}), WithTranspose("fc.weight"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b0", "fc.weight", "s"}, sd.Keys())
+	assert.Equal(t, tensor.Shape{2, 3}, sd["fc.weight"].Shape())
+	assert.Equal(t, 2.0, sd["s"].Data())
+
+	// a round trip through safetensors
+	var buf bytes.Buffer
+	require.NoError(t, WriteSafetensors(&buf, sd, nil))
+	got, _, err := ReadSafetensors(&buf)
+	require.NoError(t, err)
+	_, err = Load(got, []*gorgonia.Node{w, b, s}, WithNameMap(map[string]string{"fc.weight": "w0"}), WithTranspose("fc.weight"))
+	require.NoError(t, err)
+
+	// nodes without values
+	x := gorgonia.NewVector(g, tensor.Float64, gorgonia.WithShape(2), gorgonia.WithName("x"))
+	_, err = Save([]*gorgonia.Node{x})
+	assert.Error(t, err)
+}
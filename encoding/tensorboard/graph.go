@@ -0,0 +1,124 @@
+package tensorboard
+
+import (
+	"fmt"
+
+	"github.com/chewxy/hm"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// dataTypes maps the Dtypes to the DataType enum of TensorFlow
+var dataTypes = map[tensor.Dtype]int64{
+	tensor.Float32:    1,
+	tensor.Float64:    2,
+	tensor.Int32:      3,
+	tensor.Uint8:      4,
+	tensor.Int16:      5,
+	tensor.Int8:       6,
+	tensor.String:     7,
+	tensor.Complex64:  8,
+	tensor.Int64:      9,
+	tensor.Bool:       10,
+	tensor.Uint16:     17,
+	tensor.Complex128: 18,
+	tensor.Uint32:     22,
+	tensor.Uint64:     23,
+}
+
+func init() {
+	if tensor.Int.Size() == 8 {
+		dataTypes[tensor.Int] = dataTypes[tensor.Int64]
+		dataTypes[tensor.Uint] = dataTypes[tensor.Uint64]
+	} else {
+		dataTypes[tensor.Int] = dataTypes[tensor.Int32]
+		dataTypes[tensor.Uint] = dataTypes[tensor.Uint32]
+	}
+}
+
+// graphDef converts g into a GraphDef. Every node of g becomes a NodeDef named after the node, whose inputs are the children of the node.
+// The dtype and the shape of the nodes are stored as the "dtype" and "_output_shapes" attributes, which TensorBoard shows.
+func graphDef(g *gorgonia.ExprGraph) message {
+	nodes := g.AllNodes()
+	names := make(map[*gorgonia.Node]string, len(nodes))
+	taken := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		name := n.Name()
+		if taken[name] {
+			name = fmt.Sprintf("%s_%d", name, n.ID())
+		}
+		taken[name] = true
+		names[n] = name
+	}
+
+	var def message
+	for _, n := range nodes {
+		var nd message
+		nd.string(1, names[n])
+		nd.string(2, opName(n))
+		for it := g.From(n.ID()); it.Next(); {
+			child := it.Node().(*gorgonia.Node)
+			nd.string(3, names[child])
+		}
+		if dt, ok := dataType(n.Type()); ok {
+			var attr message
+			attr.int64(6, dt)
+			nd.message(5, attrEntry("dtype", attr))
+		}
+		var shapes, attr message
+		shapes.message(7, shapeProto(n.Shape()))
+		attr.message(1, shapes)
+		nd.message(5, attrEntry("_output_shapes", attr))
+		def.message(1, nd)
+	}
+	var versions message
+	versions.int64(1, 22) // the producer version of the GraphDef
+	def.message(4, versions)
+	return def
+}
+
+// opName is the op of the node as shown by TensorBoard
+func opName(n *gorgonia.Node) string {
+	if n.Op() == nil {
+		return "Input"
+	}
+	if _, ok := n.Op().(interface{ Value() gorgonia.Value }); ok {
+		return "Const"
+	}
+	return n.Op().String()
+}
+
+// dataType returns the DataType enum of a scalar or tensor type
+func dataType(t hm.Type) (int64, bool) {
+	switch tt := t.(type) {
+	case tensor.Dtype:
+		dt, ok := dataTypes[tt]
+		return dt, ok
+	case gorgonia.TensorType:
+		return dataType(tt.Of)
+	}
+	return 0, false
+}
+
+// attrEntry is an entry of the attr map of a NodeDef
+func attrEntry(key string, value message) message {
+	var entry message
+	entry.string(1, key)
+	entry.message(2, value)
+	return entry
+}
+
+// shapeProto converts a shape into a TensorShapeProto. Scalars have no dimensions.
+func shapeProto(shp tensor.Shape) message {
+	var retVal message
+	if shp.IsScalar() {
+		return retVal
+	}
+	for _, d := range shp {
+		var dim message
+		dim.key(1, wireVarint) // sizes of 0 are written explicitly
+		dim.varint(uint64(d))
+		retVal.message(2, dim)
+	}
+	return retVal
+}
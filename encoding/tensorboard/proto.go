@@ -0,0 +1,69 @@
+package tensorboard
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// The protocol buffer wire types used by the messages TensorBoard reads.
+const (
+	wireVarint = 0
+	wire64     = 1
+	wireBytes  = 2
+	wire32     = 5
+)
+
+// message is a minimal protocol buffer encoder. Only the fields of the messages that are written are supported,
+// so that the package does not need the generated TensorFlow protos.
+type message []byte
+
+func (m *message) key(field, wire int) { m.varint(uint64(field<<3 | wire)) }
+
+func (m *message) varint(v uint64) { *m = append(*m, encodeVarint(v)...) }
+
+func encodeVarint(v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return buf[:n]
+}
+
+func (m *message) int64(field int, v int64) {
+	if v == 0 {
+		return
+	}
+	m.key(field, wireVarint)
+	m.varint(uint64(v))
+}
+
+func (m *message) double(field int, v float64) {
+	m.key(field, wire64)
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+	*m = append(*m, buf[:]...)
+}
+
+func (m *message) float(field int, v float32) {
+	m.key(field, wire32)
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], math.Float32bits(v))
+	*m = append(*m, buf[:]...)
+}
+
+func (m *message) bytes(field int, b []byte) {
+	m.key(field, wireBytes)
+	m.varint(uint64(len(b)))
+	*m = append(*m, b...)
+}
+
+func (m *message) string(field int, s string) { m.bytes(field, []byte(s)) }
+
+func (m *message) message(field int, sub message) { m.bytes(field, sub) }
+
+// packedDoubles writes a repeated double field in the packed encoding
+func (m *message) packedDoubles(field int, vs []float64) {
+	buf := make([]byte, 8*len(vs))
+	for i, v := range vs {
+		binary.LittleEndian.PutUint64(buf[8*i:], math.Float64bits(v))
+	}
+	m.bytes(field, buf)
+}
@@ -0,0 +1,35 @@
+package tensorboard
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// maskedCRC is the masked CRC32C that TFRecord files store, so that CRCs of data that contains CRCs are not degenerate.
+func maskedCRC(b []byte) uint32 {
+	crc := crc32.Checksum(b, castagnoli)
+	return (crc>>15 | crc<<17) + 0xa282ead8
+}
+
+// writeRecord writes data in the TFRecord framing:
+//		uint64 length
+//		uint32 masked CRC of the length
+//		byte   data[length]
+//		uint32 masked CRC of the data
+// All integers are little endian.
+func writeRecord(w io.Writer, data []byte) error {
+	buf := make([]byte, 12, 12+len(data)+4)
+	binary.LittleEndian.PutUint64(buf, uint64(len(data)))
+	binary.LittleEndian.PutUint32(buf[8:], maskedCRC(buf[:8]))
+	buf = append(buf, data...)
+	var crc [4]byte
+	binary.LittleEndian.PutUint32(crc[:], maskedCRC(data))
+	buf = append(buf, crc[:]...)
+	_, err := w.Write(buf)
+	return errors.Wrap(err, "Unable to write a record")
+}
@@ -0,0 +1,233 @@
+package tensorboard
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// readRecord reads a record written by writeRecord, checking both CRCs. It returns io.EOF at the end of r.
+func readRecord(r io.Reader) ([]byte, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, errors.Wrap(err, "Unable to read the header of a record")
+	}
+	if got, want := binary.LittleEndian.Uint32(header[8:]), maskedCRC(header[:8]); got != want {
+		return nil, errors.Errorf("Corrupted record length: CRC %#x, expected %#x", got, want)
+	}
+	data := make([]byte, binary.LittleEndian.Uint64(header[:8])+4)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, errors.Wrap(err, "Unable to read a record")
+	}
+	data, crc := data[:len(data)-4], data[len(data)-4:]
+	if got, want := binary.LittleEndian.Uint32(crc), maskedCRC(data); got != want {
+		return nil, errors.Errorf("Corrupted record data: CRC %#x, expected %#x", got, want)
+	}
+	return data, nil
+}
+
+// fields is a decoded protocol buffer message: the values of every field number, in order.
+// Varints and fixed64s are uint64s, fixed32s are uint32s and length delimited fields are []byte.
+type fields map[int][]interface{}
+
+func decode(t *testing.T, b []byte) fields {
+	retVal := make(fields)
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		require.True(t, n > 0, "bad key")
+		b = b[n:]
+		field := int(key >> 3)
+		switch key & 7 {
+		case wireVarint:
+			v, n := binary.Uvarint(b)
+			require.True(t, n > 0, "bad varint")
+			retVal[field] = append(retVal[field], v)
+			b = b[n:]
+		case wire64:
+			retVal[field] = append(retVal[field], binary.LittleEndian.Uint64(b))
+			b = b[8:]
+		case wire32:
+			retVal[field] = append(retVal[field], binary.LittleEndian.Uint32(b))
+			b = b[4:]
+		case wireBytes:
+			l, n := binary.Uvarint(b)
+			require.True(t, n > 0, "bad length")
+			retVal[field] = append(retVal[field], b[n:n+int(l)])
+			b = b[n+int(l):]
+		default:
+			t.Fatalf("Unexpected wire type %d", key&7)
+		}
+	}
+	return retVal
+}
+
+func (f fields) msg(t *testing.T, field int) fields { return decode(t, f[field][0].([]byte)) }
+func (f fields) str(field int) string               { return string(f[field][0].([]byte)) }
+func (f fields) double(field int) float64           { return math.Float64frombits(f[field][0].(uint64)) }
+func (f fields) float(field int) float32            { return math.Float32frombits(f[field][0].(uint32)) }
+func (f fields) varint(field int) uint64            { return f[field][0].(uint64) }
+func (f fields) doubles(field int) (retVal []float64) {
+	b := f[field][0].([]byte)
+	for i := 0; i < len(b); i += 8 {
+		retVal = append(retVal, math.Float64frombits(binary.LittleEndian.Uint64(b[i:])))
+	}
+	return retVal
+}
+
+func readEvents(t *testing.T, path string) []fields {
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	r := bytes.NewReader(b)
+	var retVal []fields
+	for {
+		rec, err := readRecord(r)
+		if err == io.EOF {
+			return retVal
+		}
+		require.NoError(t, err)
+		retVal = append(retVal, decode(t, rec))
+	}
+}
+
+func TestRecord(t *testing.T) {
+	// the check value of CRC-32C
+	assert.Equal(t, uint32(0xe3069283), crc32.Checksum([]byte("123456789"), castagnoli))
+
+	var buf bytes.Buffer
+	require.NoError(t, writeRecord(&buf, []byte("hello")))
+	require.NoError(t, writeRecord(&buf, nil))
+	assert.Equal(t, 12+5+4+12+4, buf.Len())
+	b := buf.Bytes()
+
+	r := bytes.NewReader(b)
+	rec, err := readRecord(r)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(rec))
+	rec, err = readRecord(r)
+	require.NoError(t, err)
+	assert.Empty(t, rec)
+	_, err = readRecord(r)
+	assert.Equal(t, io.EOF, err)
+
+	b[14] ^= 1
+	_, err = readRecord(bytes.NewReader(b))
+	assert.Error(t, err)
+}
+
+func TestHistogram(t *testing.T) {
+	h := decode(t, histogram([]float64{0, 1, 2, 3, math.NaN(), math.Inf(1)}, 3))
+	assert.Equal(t, 0.0, h.double(1))
+	assert.Equal(t, 3.0, h.double(2))
+	assert.Equal(t, 4.0, h.double(3))
+	assert.Equal(t, 6.0, h.double(4))
+	assert.Equal(t, 14.0, h.double(5))
+	assert.Equal(t, []float64{1, 2, 3}, h.doubles(6))
+	assert.Equal(t, []float64{1, 1, 2}, h.doubles(7))
+
+	h = decode(t, histogram([]float64{2, 2}, 3))
+	assert.Equal(t, []float64{2}, h.doubles(6))
+	assert.Equal(t, []float64{2}, h.doubles(7))
+}
+
+func TestWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tensorboard")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	g := gorgonia.NewGraph()
+	w := gorgonia.NewMatrix(g, tensor.Float64, gorgonia.WithShape(2, 2), gorgonia.WithName("w"),
+		gorgonia.WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2, 3, 4}))))
+	sq := gorgonia.Must(gorgonia.Square(w))
+	cost := gorgonia.Must(gorgonia.Sum(sq))
+	_, err = gorgonia.Grad(cost, w)
+	require.NoError(t, err)
+	m := gorgonia.NewTapeMachine(g, gorgonia.BindDualValues(w))
+	defer m.Close()
+	require.NoError(t, m.RunAll())
+
+	tb, err := NewWriter(dir, WithBins(2))
+	require.NoError(t, err)
+	require.NoError(t, tb.Scalar("loss", 3, 0.5))
+	require.NoError(t, tb.Scalars(4, map[string]float64{"loss": 0.25, "lr": 0.1}))
+	require.NoError(t, tb.Histogram("steps", 5, tensor.New(tensor.WithBacking([]int{1, 2, 3}))))
+	require.NoError(t, tb.Values(6, w))
+	require.NoError(t, tb.Gradients(6, w))
+	require.NoError(t, tb.Graph(g))
+	assert.Error(t, tb.Histogram("bools", 7, tensor.New(tensor.WithBacking([]bool{true}))))
+	require.NoError(t, tb.Close())
+	assert.Error(t, tb.Scalar("loss", 8, 0))
+
+	evs := readEvents(t, tb.Path())
+	require.Len(t, evs, 7)
+	for _, ev := range evs {
+		assert.InDelta(t, float64(tb.now().Unix()), ev.double(1), 60)
+	}
+	assert.Equal(t, "brain.Event:2", evs[0].str(3))
+
+	assert.Equal(t, uint64(3), evs[1].varint(2))
+	v := evs[1].msg(t, 5).msg(t, 1)
+	assert.Equal(t, "loss", v.str(1))
+	assert.Equal(t, float32(0.5), v.float(2))
+
+	values := evs[2].msg(t, 5)[1]
+	require.Len(t, values, 2)
+	v = decode(t, values[1].([]byte))
+	assert.Equal(t, "lr", v.str(1))
+	assert.Equal(t, float32(0.1), v.float(2))
+
+	v = evs[3].msg(t, 5).msg(t, 1)
+	assert.Equal(t, "steps", v.str(1))
+	assert.Equal(t, 3.0, v.msg(t, 5).double(3))
+	assert.Equal(t, 6.0, v.msg(t, 5).double(4))
+
+	v = evs[4].msg(t, 5).msg(t, 1)
+	assert.Equal(t, "w", v.str(1))
+	assert.Equal(t, 10.0, v.msg(t, 5).double(4))
+	assert.Equal(t, []float64{2, 2}, v.msg(t, 5).doubles(7))
+
+	// d(Σw²)/dw = 2w
+	v = evs[5].msg(t, 5).msg(t, 1)
+	assert.Equal(t, "w/grad", v.str(1))
+	assert.Equal(t, 20.0, v.msg(t, 5).double(4))
+
+	def := evs[6].msg(t, 4)
+	nodes := make(map[string]fields)
+	for _, b := range def[1] {
+		nd := decode(t, b.([]byte))
+		nodes[nd.str(1)] = nd
+	}
+	assert.Len(t, nodes, len(g.AllNodes()))
+	require.Contains(t, nodes, "w")
+	assert.Equal(t, "Input", nodes["w"].str(2))
+	sqNode := nodes[sq.Name()]
+	require.NotNil(t, sqNode)
+	assert.Equal(t, "w", sqNode.str(3))
+
+	// the attributes of w
+	attrs := make(map[string]fields)
+	for _, b := range nodes["w"][5] {
+		entry := decode(t, b.([]byte))
+		attrs[entry.str(1)] = entry.msg(t, 2)
+	}
+	assert.Equal(t, uint64(2), attrs["dtype"].varint(6))
+	shape := attrs["_output_shapes"].msg(t, 1).msg(t, 7)
+	require.Len(t, shape[2], 2)
+	assert.Equal(t, uint64(2), decode(t, shape[2][0].([]byte)).varint(1))
+
+	_, err = NewWriter(dir, WithBins(0))
+	assert.Error(t, err)
+}
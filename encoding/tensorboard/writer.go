@@ -0,0 +1,304 @@
+// Package tensorboard writes event files that TensorBoard reads, so that training can be watched with:
+//		tensorboard --logdir runs
+//
+// A Writer logs scalars, such as the loss and the learning rate, histograms of the values and the gradients of nodes,
+// and the expression graph itself. It is meant to be called from a training loop, after every run of the VM:
+//		w, err := tensorboard.NewWriter("runs/mnist")
+//		...
+//		defer w.Close()
+//		w.Graph(g)
+//		for step := 0; step < steps; step++ {
+//			// bind the batch, m.RunAll(), solver.Step(...)
+//			w.Scalar("loss", step, loss)
+//			if step%100 == 0 {
+//				w.Values(step, learnables...)
+//				w.Gradients(step, learnables...)
+//			}
+//			m.Reset()
+//		}
+//
+// The files are TFRecord files of Event protocol buffers. The messages are encoded by hand, so TensorFlow is not needed.
+package tensorboard
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+)
+
+// files counts the event files created by this process, so that writers created at the same time do not share a file
+var files int64
+
+// Writer writes the events to a file in a log directory. It is safe for concurrent use.
+type Writer struct {
+	mu   sync.Mutex
+	f    *os.File
+	w    *bufio.Writer
+	bins int
+	now  func() time.Time
+}
+
+// Opt is a function that configures a Writer.
+type Opt func(*Writer)
+
+// WithBins sets the number of buckets of the histograms. The default is 30.
+func WithBins(n int) Opt {
+	return func(w *Writer) {
+		w.bins = n
+	}
+}
+
+// NewWriter creates a Writer to a new event file in dir, creating dir if needed.
+func NewWriter(dir string, opts ...Opt) (*Writer, error) {
+	w := &Writer{bins: 30, now: time.Now}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.bins < 1 {
+		return nil, errors.Errorf("Expected at least 1 bin for the histograms. Got %d", w.bins)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "Unable to create the log directory")
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+	name := fmt.Sprintf("events.out.tfevents.%010d.%s.%d.%d", w.now().Unix(), host, os.Getpid(), atomic.AddInt64(&files, 1))
+	if w.f, err = os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644); err != nil {
+		return nil, errors.Wrap(err, "Unable to create the event file")
+	}
+	w.w = bufio.NewWriter(w.f)
+
+	// every event file starts with its version
+	var ev message
+	ev.double(1, w.wallTime())
+	ev.string(3, "brain.Event:2")
+	if err = w.write(ev); err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		w.f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Path returns the path of the event file.
+func (w *Writer) Path() string { return w.f.Name() }
+
+func (w *Writer) wallTime() float64 {
+	return float64(w.now().UnixNano()) / 1e9
+}
+
+// write writes a serialized Event
+func (w *Writer) write(ev message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.w == nil {
+		return errors.New("The writer is closed")
+	}
+	return writeRecord(w.w, ev)
+}
+
+// summary writes an Event holding a Summary made of values
+func (w *Writer) summary(step int, values ...message) error {
+	var sum message
+	for _, v := range values {
+		sum.message(1, v)
+	}
+	var ev message
+	ev.double(1, w.wallTime())
+	ev.int64(2, int64(step))
+	ev.message(5, sum)
+	return w.write(ev)
+}
+
+func scalarValue(tag string, v float64) message {
+	var val message
+	val.string(1, tag)
+	val.float(2, float32(v))
+	return val
+}
+
+// Scalar logs a scalar value, such as the loss, at a step.
+func (w *Writer) Scalar(tag string, step int, v float64) error {
+	return w.summary(step, scalarValue(tag, v))
+}
+
+// Scalars logs several scalars at a step, keyed by their tags. It can be given the logs of the train package.
+func (w *Writer) Scalars(step int, scalars map[string]float64) error {
+	tags := make([]string, 0, len(scalars))
+	for tag := range scalars {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	values := make([]message, len(tags))
+	for i, tag := range tags {
+		values[i] = scalarValue(tag, scalars[tag])
+	}
+	return w.summary(step, values...)
+}
+
+// Histogram logs the distribution of the elements of a value at a step. NaNs and infinities are left out.
+func (w *Writer) Histogram(tag string, step int, v gorgonia.Value) error {
+	xs, err := floats(v)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to log the histogram %q", tag)
+	}
+	var val message
+	val.string(1, tag)
+	val.message(5, histogram(xs, w.bins))
+	return w.summary(step, val)
+}
+
+// Values logs the histograms of the values of the nodes at a step, tagged with the names of the nodes.
+func (w *Writer) Values(step int, nodes ...*gorgonia.Node) error {
+	for _, n := range nodes {
+		if n.Value() == nil {
+			return errors.Errorf("Unable to log the histogram of %v: the node has no value", n.Name())
+		}
+		if err := w.Histogram(n.Name(), step, n.Value()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Gradients logs the histograms of the gradients of the nodes at a step, tagged with the names of the nodes followed by "/grad".
+func (w *Writer) Gradients(step int, nodes ...*gorgonia.Node) error {
+	for _, n := range nodes {
+		grad, err := n.Grad()
+		if err != nil {
+			return errors.Wrapf(err, "Unable to log the gradient of %v", n.Name())
+		}
+		if err = w.Histogram(n.Name()+"/grad", step, grad); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Graph logs the expression graph, which TensorBoard shows in its Graphs tab.
+func (w *Writer) Graph(g *gorgonia.ExprGraph) error {
+	var ev message
+	ev.double(1, w.wallTime())
+	ev.bytes(4, graphDef(g))
+	return w.write(ev)
+}
+
+// Flush writes the buffered events to the file, so that TensorBoard sees them.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.w == nil {
+		return errors.New("The writer is closed")
+	}
+	return errors.Wrap(w.w.Flush(), "Unable to flush the events")
+}
+
+// Close flushes the events and closes the file.
+func (w *Writer) Close() error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.w = nil
+	return errors.Wrap(w.f.Close(), "Unable to close the event file")
+}
+
+// histogram builds a HistogramProto of equal width buckets over the finite values of xs
+func histogram(xs []float64, bins int) message {
+	min, max := math.Inf(1), math.Inf(-1)
+	var num, sum, sumSq float64
+	for _, x := range xs {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			continue
+		}
+		min, max = math.Min(min, x), math.Max(max, x)
+		num++
+		sum += x
+		sumSq += x * x
+	}
+	if num == 0 {
+		min, max = 0, 0
+	}
+
+	// TensorBoard takes the upper limits of the buckets, starting the first bucket at min
+	limits := []float64{max}
+	counts := []float64{num}
+	if max > min {
+		width := (max - min) / float64(bins)
+		limits = make([]float64, bins)
+		counts = make([]float64, bins)
+		for i := range limits {
+			limits[i] = min + float64(i+1)*width
+		}
+		limits[bins-1] = max
+		for _, x := range xs {
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				continue
+			}
+			i := int((x - min) / width)
+			if i >= bins {
+				i = bins - 1
+			}
+			counts[i]++
+		}
+	}
+
+	var h message
+	h.double(1, min)
+	h.double(2, max)
+	h.double(3, num)
+	h.double(4, sum)
+	h.double(5, sumSq)
+	h.packedDoubles(6, limits)
+	h.packedDoubles(7, counts)
+	return h
+}
+
+// floats returns the elements of a numeric value as float64s
+func floats(v gorgonia.Value) ([]float64, error) {
+	switch d := v.Data().(type) {
+	case []float64:
+		return d, nil
+	case []float32:
+		retVal := make([]float64, len(d))
+		for i, x := range d {
+			retVal[i] = float64(x)
+		}
+		return retVal, nil
+	}
+
+	data := reflect.ValueOf(v.Data())
+	if data.Kind() != reflect.Slice {
+		data = reflect.Append(reflect.MakeSlice(reflect.SliceOf(data.Type()), 0, 1), data)
+	}
+	retVal := make([]float64, data.Len())
+	for i := range retVal {
+		switch x := data.Index(i); x.Kind() {
+		case reflect.Float32, reflect.Float64:
+			retVal[i] = x.Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			retVal[i] = float64(x.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			retVal[i] = float64(x.Uint())
+		default:
+			return nil, errors.Errorf("Expected a numeric value. Got %v instead", v.Dtype())
+		}
+	}
+	return retVal, nil
+}
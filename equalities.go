@@ -211,3 +211,25 @@ func nodeEq(a, b *Node) bool {
 
 	return true
 }
+
+// ExprEq returns true if the two nodes are the same expression: the same ops, types and shapes, applied to equal arguments,
+// down to the inputs, which are compared by name. Unlike the comparison of the nodes of a graph, the nodes may come from different graphs,
+// e.g. a graph and a copy of it.
+func ExprEq(a, b *Node) bool {
+	return exprEq(a, b, make(map[[2]*Node]bool))
+}
+
+func exprEq(a, b *Node, seen map[[2]*Node]bool) bool {
+	if eq, ok := seen[[2]*Node{a, b}]; ok {
+		return eq
+	}
+	eq := nodeEq(a, b)
+	if eq && a.isInput() {
+		eq = a.t == b.t && a.shape.Eq(b.shape)
+	}
+	for i := 0; eq && i < len(a.children) && a != b; i++ {
+		eq = exprEq(a.children[i], b.children[i], seen)
+	}
+	seen[[2]*Node{a, b}] = eq
+	return eq
+}
@@ -42,3 +42,27 @@ func TestScalarEq(t *testing.T) {
 		}
 	}
 }
+
+func TestExprEq(t *testing.T) {
+	build := func(name string) (x, y *Node) {
+		g := NewGraph()
+		x = NewVector(g, Float64, WithShape(2), WithName(name))
+		sq := Must(Square(x))
+		return Must(Add(sq, x)), Must(Sub(sq, x))
+	}
+	a, aSub := build("x")
+	b, bSub := build("x")
+	c, _ := build("z")
+	if !ExprEq(a, b) {
+		t.Errorf("Expected %v and %v of different graphs to be the same expression", a, b)
+	}
+	if ExprEq(a, aSub) || ExprEq(aSub, b) {
+		t.Errorf("Expected the addition and the subtraction to be different expressions")
+	}
+	if !ExprEq(aSub, bSub) {
+		t.Errorf("Expected %v and %v to be the same expression", aSub, bSub)
+	}
+	if ExprEq(a, c) {
+		t.Errorf("Expected expressions of different inputs to be different")
+	}
+}
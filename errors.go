@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
 )
 
 // NoOpError is an error returned when an operation does nothing.
@@ -16,18 +17,6 @@ type noopError struct{}
 func (e noopError) NoOp() bool    { return true }
 func (e noopError) Error() string { return "NoOp" }
 
-// errNoStabilization is an error used internally for when there is no stabilization mechanism is found.
-type errNoStabilization interface {
-	error
-	noStabilization() bool
-}
-
-// nostabilizationErr is used internally to communicate that there isn't any stabilization possible
-type noStabilizationErr struct{}
-
-func (noStabilizationErr) Error() string         { return "No stabilization mechanism found" }
-func (noStabilizationErr) noStabilization() bool { return true }
-
 // noIncrErr is an error used internally when a Value cannot be incremented
 type noIncrErr struct {
 	v Value
@@ -51,6 +40,122 @@ type AutoDiffError struct{}
 
 func (err AutoDiffError) Error() string { return "AutoDiffError" }
 
+// ShapeMismatchError is returned when two shapes that have to agree do not, e.g. when the shapes of the arguments of
+// an elementwise op differ, when a shape is not the one that an op expects, or when the value bound to a node does not
+// have the shape of the node. The errors about the number of dimensions that an op accepts, or about an axis, are not
+// ShapeMismatchErrors.
+//
+// Use errors.As to find it in the errors returned by the op constructors, by InferShape, by Let, and by the VMs:
+//		var sme *ShapeMismatchError
+//		if errors.As(err, &sme) {
+//			fmt.Println(sme.Expected, sme.Actual, sme.Node)
+//		}
+type ShapeMismatchError struct {
+	Expected, Actual tensor.Shape
+
+	Op       Op    // the op that rejects the shape, if any
+	Node     *Node // the node that has, or is bound to, the shape. Only the VMs and Let set it
+	Children Nodes // the arguments of the op, if any
+
+	msg string
+}
+
+func (err *ShapeMismatchError) Error() string {
+	if err.msg != "" {
+		return err.msg
+	}
+	return fmt.Sprintf("Shape mismatch: expected %v, got %v", err.Expected, err.Actual)
+}
+
+// newShapeMismatch creates a ShapeMismatchError with a message of its own. The shapes are copied, as they may be pooled
+func newShapeMismatch(expected, actual tensor.Shape, format string, args ...interface{}) *ShapeMismatchError {
+	return &ShapeMismatchError{Expected: expected.Clone(), Actual: actual.Clone(), msg: fmt.Sprintf(format, args...)}
+}
+
+// DtypeMismatchError is returned when two Dtypes that have to agree do not, e.g. when the arguments of an op that
+// requires the same Dtype have different Dtypes, when an argument does not have the Dtype that an op requires (e.g.
+// tensor.Int for indices), or when the value bound to a node does not have the Dtype of the node. The errors about the
+// Dtypes that an op does not support are not DtypeMismatchErrors.
+//
+// It is found with errors.As, like ShapeMismatchError.
+type DtypeMismatchError struct {
+	Expected, Actual tensor.Dtype
+
+	Op       Op    // the op that rejects the Dtype, if any
+	Node     *Node // the node that has, or is bound to, the Dtype. Only the VMs and Let set it
+	Children Nodes // the arguments of the op, if any
+
+	msg string
+}
+
+func (err *DtypeMismatchError) Error() string {
+	if err.msg != "" {
+		return err.msg
+	}
+	return fmt.Sprintf("Dtype mismatch: expected %v, got %v", err.Expected, err.Actual)
+}
+
+// newDtypeMismatch creates a DtypeMismatchError with a message of its own
+func newDtypeMismatch(expected, actual tensor.Dtype, format string, args ...interface{}) *DtypeMismatchError {
+	return &DtypeMismatchError{Expected: expected, Actual: actual, msg: fmt.Sprintf(format, args...)}
+}
+
+// NaNDetectedError is returned by the VMs that watch for NaNs, when a node has a NaN in its value or in its gradient.
+// The Report describes the instruction that produced it, and its inputs; its String method prints them.
+type NaNDetectedError struct {
+	Node   *Node
+	Report *WatchReport
+}
+
+func (err *NaNDetectedError) Error() string { return watchErrorString("NaN", err.Node, err.Report) }
+
+// InfDetectedError is returned by the VMs that watch for infinities, when a node has an Inf in its value.
+// The Report describes the instruction that produced it, and its inputs; its String method prints them.
+type InfDetectedError struct {
+	Node   *Node
+	Report *WatchReport
+}
+
+func (err *InfDetectedError) Error() string { return watchErrorString("Inf", err.Node, err.Report) }
+
+func watchErrorString(what string, n *Node, r *WatchReport) string {
+	if n == nil {
+		return what + " found in value"
+	}
+	if r == nil {
+		return fmt.Sprintf("%s found in value. Node: %v(%x)", what, n, n.ID())
+	}
+	return fmt.Sprintf("%s found in value. Node: %v(%x). Op %v, %s instruction %d", what, n, n.ID(), r.Op, r.pass(), r.Instruction)
+}
+
+// setErrContext fills in the op, the node and the children of the typed errors in err that do not know them yet
+func setErrContext(err error, op Op, n *Node, children Nodes) {
+	var sme *ShapeMismatchError
+	if errors.As(err, &sme) {
+		if sme.Op == nil {
+			sme.Op = op
+		}
+		if sme.Node == nil {
+			sme.Node = n
+		}
+		if sme.Children == nil {
+			sme.Children = children
+		}
+	}
+	var dme *DtypeMismatchError
+	if errors.As(err, &dme) {
+		if dme.Op == nil {
+			dme.Op = op
+		}
+		if dme.Node == nil {
+			dme.Node = n
+		}
+		if dme.Children == nil {
+			dme.Children = children
+		}
+	}
+}
+
 // vmContextualError is an error that is used to wrap errors that arise from the VM
 type vmContextualError struct {
 	error
@@ -62,6 +167,7 @@ func (err vmContextualError) Node() *Node        { return err.node }
 func (err vmContextualError) Value() Value       { return err.node.Value() }
 func (err vmContextualError) InstructionID() int { return err.instr }
 func (err vmContextualError) Err() error         { return err.error }
+func (err vmContextualError) Unwrap() error      { return err.error }
 
 func nyi(what string, implFor interface{}) error {
 	return errors.Errorf(nyiFail, what, implFor)
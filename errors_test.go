@@ -0,0 +1,150 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/tensor"
+)
+
+func TestShapeMismatchError(t *testing.T) {
+	g := NewGraph()
+	x := NewMatrix(g, tensor.Float64, WithShape(2, 3))
+	y := NewMatrix(g, tensor.Float64, WithShape(3, 2))
+
+	_, err := Add(x, y)
+	var sme *ShapeMismatchError
+	require.True(t, errors.As(err, &sme), "%v", err)
+	assert.Equal(t, tensor.Shape{2, 3}, sme.Expected)
+	assert.Equal(t, tensor.Shape{3, 2}, sme.Actual)
+	assert.Equal(t, "+ false", sme.Op.String())
+	assert.Nil(t, sme.Node)
+	assert.Equal(t, Nodes{x, y}, sme.Children)
+	assert.Equal(t, "Shape mismatch: (2, 3) and (3, 2)", sme.Error())
+
+	_, err = Mul(x, x)
+	sme = nil
+	require.True(t, errors.As(err, &sme), "%v", err)
+	assert.Equal(t, tensor.Shape{3, 3}, sme.Expected)
+	assert.Equal(t, tensor.Shape{2, 3}, sme.Actual)
+
+	_, err = Mul(x, NewVector(g, tensor.Float64, WithShape(4)))
+	sme = nil
+	require.True(t, errors.As(err, &sme), "%v", err)
+	assert.Equal(t, tensor.Shape{3}, sme.Expected)
+
+	// the value bound to a node
+	err = Let(x, tensor.New(tensor.WithShape(3, 3), tensor.Of(tensor.Float64)))
+	sme = nil
+	require.True(t, errors.As(err, &sme), "%v", err)
+	assert.Equal(t, x, sme.Node)
+	assert.Equal(t, tensor.Shape{3, 3}, sme.Actual)
+
+	// the errors of the ops while the VMs run know their node
+	g = NewGraph()
+	x = NewMatrix(g, tensor.Float64, WithShape(2, 3), WithInit(Zeroes()))
+	bad := Must(ApplyOp(failingOp{err: newShapeMismatch(tensor.Shape{2, 3}, tensor.Shape{6}, "bad shape")}, Must(Tanh(x))))
+	for _, m := range []VM{NewTapeMachine(g), NewLispMachine(g, ExecuteFwdOnly())} {
+		err = m.RunAll()
+		sme = nil
+		require.True(t, errors.As(err, &sme), "%T: %v", m, err)
+		assert.Equal(t, bad, sme.Node, "%T", m)
+		assert.Equal(t, bad.Op(), sme.Op, "%T", m)
+		assert.Equal(t, bad.children, sme.Children, "%T", m)
+		m.Close()
+	}
+}
+
+func TestDtypeMismatchError(t *testing.T) {
+	g := NewGraph()
+	x := NewMatrix(g, tensor.Float32, WithShape(2, 3))
+	y := NewMatrix(g, tensor.Float64, WithShape(2, 3))
+
+	_, err := Add(x, y)
+	var dme *DtypeMismatchError
+	require.True(t, errors.As(err, &dme), "%v", err)
+	assert.Equal(t, tensor.Float32, dme.Expected)
+	assert.Equal(t, tensor.Float64, dme.Actual)
+	assert.Equal(t, "+ false", dme.Op.String())
+	assert.Equal(t, Nodes{x, y}, dme.Children)
+	var sme *ShapeMismatchError
+	assert.False(t, errors.As(err, &sme))
+
+	err = Let(x, tensor.New(tensor.WithShape(2, 3), tensor.Of(tensor.Float64)))
+	dme = nil
+	require.True(t, errors.As(err, &dme), "%v", err)
+	assert.Equal(t, x, dme.Node)
+	assert.Equal(t, tensor.Float64, dme.Actual)
+
+	// the Dtype that an op requires
+	ids := NewVector(g, tensor.Float64, WithShape(2))
+	_, err = Embedding(y, ids)
+	dme = nil
+	require.True(t, errors.As(err, &dme), "%v", err)
+	assert.Equal(t, tensor.Int, dme.Expected)
+	assert.Equal(t, tensor.Float64, dme.Actual)
+
+	// the indices of an op do not have the Dtype of the other arguments, which is not a Dtype mismatch
+	_, err = ApplyOp(newEmbeddingOp(1), y, NewScalar(g, tensor.Int))
+	require.Error(t, err)
+	dme = nil
+	assert.False(t, errors.As(err, &dme), "%v", err)
+}
+
+func TestNaNDetectedError(t *testing.T) {
+	g := NewGraph()
+	x := NewVector(g, tensor.Float64, WithShape(2), WithValue(tensor.New(tensor.WithBacking([]float64{1, -1}))))
+	logx := Must(Log(x))
+	Must(Sum(logx))
+
+	m := NewTapeMachine(g, WithNaNWatch())
+	defer m.Close()
+	err := m.RunAll()
+	var nde *NaNDetectedError
+	require.True(t, errors.As(err, &nde), "%v", err)
+	assert.Equal(t, logx, nde.Node)
+
+	lm := NewLispMachine(g, ExecuteFwdOnly(), WithNaNWatch())
+	defer lm.Close()
+	err = lm.RunAll()
+	nde = nil
+	require.True(t, errors.As(err, &nde), "%v", err)
+	assert.Equal(t, logx, nde.Node)
+
+	g = NewGraph()
+	x = NewVector(g, tensor.Float64, WithShape(2), WithValue(tensor.New(tensor.WithBacking([]float64{1, math.MaxFloat64}))))
+	sq := Must(Square(x))
+	m = NewTapeMachine(g, WithInfWatch())
+	defer m.Close()
+	err = m.RunAll()
+	var ide *InfDetectedError
+	require.True(t, errors.As(err, &ide), "%v", err)
+	assert.Equal(t, sq, ide.Node)
+}
+
+func TestMismatchErrors_ops(t *testing.T) {
+	g := NewGraph()
+	x := NewMatrix(g, tensor.Float64, WithShape(2, 3))
+
+	shapeErrs := map[string]error{}
+	_, shapeErrs["Reshape"] = Reshape(x, tensor.Shape{4, 2})
+	_, shapeErrs["Concat"] = Concat(0, x, NewVector(g, tensor.Float64, WithShape(3)))
+	sr, err := NewSparseRows(tensor.Shape{4, 3}, []int{1}, tensor.New(tensor.WithShape(1, 3), tensor.Of(tensor.Float64)))
+	require.NoError(t, err)
+	shapeErrs["SparseRows.AddTo"] = sr.AddTo(tensor.New(tensor.WithShape(2, 3), tensor.Of(tensor.Float64)))
+	for name, err := range shapeErrs {
+		var sme *ShapeMismatchError
+		assert.True(t, errors.As(err, &sme), "%s: %v", name, err)
+	}
+
+	dtypeErrs := map[string]error{}
+	_, dtypeErrs["Gather"] = Gather(x, NewMatrix(g, tensor.Float64, WithShape(2, 1)), 1)
+	dtypeErrs["SparseRows.AddTo"] = sr.AddTo(tensor.New(tensor.WithShape(4, 3), tensor.Of(tensor.Float32)))
+	for name, err := range dtypeErrs {
+		var dme *DtypeMismatchError
+		assert.True(t, errors.As(err, &dme), "%s: %v", name, err)
+	}
+}
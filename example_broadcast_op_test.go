@@ -47,7 +47,7 @@ func ExampleBroadcastAdd() {
 	// ⎡1  1⎤
 	// ⎣2  2⎦
 	//
-	// a + b yields an error: Failed to infer shape. Op: + false: Shape mismatch: (2) and (2, 2)
+	// a + b yields an error: Failed to infer shape. Op: + false. Applied at example_broadcast_op_test.go:20: Shape mismatch: (2) and (2, 2)
 	//
 	// a +⃗ b =
 	// ⎡101  101⎤
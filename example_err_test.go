@@ -62,8 +62,8 @@ func Example_errorHandling() {
 
 	// Output:
 	// nn: Softmax{-1, false}()(%9) :: Matrix float32
-	// An error occurs: Type inference error. Op: + false. Children: [Matrix float32, Matrix float64], OpType:Matrix a → Matrix a → Matrix a: Unable to unify while inferring type of + false: Unification Fail: float64 ~ float32 cannot be unified
+	// An error occurs: Type inference error. Op: + false. Children: [Matrix float32, Matrix float64], OpType:Matrix a → Matrix a → Matrix a. Applied at example_err_test.go:31: Unable to unify while inferring type of + false: Unification Fail: float64 ~ float32 cannot be unified
 	// nn2: Softmax{-1, false}()(%9) :: Matrix float32
-	// An error occurs (caught by recover()): Type inference error. Op: + false. Children: [Matrix float32, Matrix float64], OpType:Matrix a → Matrix a → Matrix a: Unable to unify while inferring type of + false: Unification Fail: float64 ~ float32 cannot be unified
+	// An error occurs (caught by recover()): Type inference error. Op: + false. Children: [Matrix float32, Matrix float64], OpType:Matrix a → Matrix a → Matrix a. Applied at example_err_test.go:60: Unable to unify while inferring type of + false: Unification Fail: float64 ~ float32 cannot be unified
 
 }
@@ -134,8 +134,8 @@ case2:
 	fmt.Printf("Node: %v\n", act2.Node())
 
 	// Output:
-	// Err while Add: Failed to infer shape. Op: + false: Shape mismatch: (32, 100) and (1, 10000)
-	// act2: Failed to infer shape. Op: + false: Shape mismatch: (32, 100) and (1, 10000)
-	// error: Failed to infer shape. Op: + false: Shape mismatch: (32, 100) and (1, 10000)
+	// Err while Add: Failed to infer shape. Op: + false. Applied at example_monad_test.go:98: Shape mismatch: (32, 100) and (1, 10000)
+	// act2: Failed to infer shape. Op: + false. Applied at example_monad_test.go:125: Shape mismatch: (32, 100) and (1, 10000)
+	// error: Failed to infer shape. Op: + false. Applied at example_monad_test.go:125: Shape mismatch: (32, 100) and (1, 10000)
 	// Node: <nil>
 }
@@ -10,7 +10,6 @@ import (
 )
 
 var (
-	anchors       = []float32{0.57273, 0.677385, 1.87446, 2.06253, 3.33843, 5.47434, 7.88282, 3.52778, 9.77052, 9.16828}
 	classes       = []string{"person", "bicycle", "car", "motorbike", "aeroplane", "bus", "train", "truck", "boat", "traffic light", "fire hydrant", "stop sign", "parking meter", "bench", "bird", "cat", "dog", "horse", "sheep", "cow", "elephant", "bear", "zebra", "giraffe", "backpack", "umbrella", "handbag", "tie", "suitcase", "frisbee", "skis", "snowboard", "sports ball", "kite", "baseball bat", "baseball glove", "skateboard", "surfboard", "tennis racket", "bottle", "wine glass", "cup", "fork", "knife", "spoon", "bowl", "banana", "apple", "sandwich", "orange", "broccoli", "carrot", "hot dog", "pizza", "donut", "cake", "chair", "sofa", "pottedplant", "bed", "diningtable", "toilet", "tvmonitor", "laptop", "mouse", "remote", "keyboard", "cell phone", "microwave", "oven", "toaster", "sink", "refrigerator", "book", "clock", "vase", "scissors", "teddy bear", "hair drier", "toothbrush"}
 	scoreTreshold = float32(0.6)
 	iouTreshold   = float32(0.2)
@@ -89,8 +88,8 @@ func (tiny *TinyYOLOv2Net) ProcessOutput() (Detections, error) {
 				x := (float32(cy) + Sigmoid(tx)) * 32 * rw
 				y := (float32(cx) + Sigmoid(ty)) * 32 * rh
 
-				w := math32.Exp(tw) * anchors[2*b] * 32 * rw
-				h := math32.Exp(th) * anchors[2*b+1] * 32 * rh
+				w := math32.Exp(tw) * tiny.anchors[2*b] * 32 * rw
+				h := math32.Exp(th) * tiny.anchors[2*b+1] * 32 * rh
 
 				sigmoidCoefficient := Sigmoid(tc)
 				finalCoefficient := sigmoidCoefficient * maxProbability
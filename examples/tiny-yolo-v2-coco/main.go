@@ -20,7 +20,10 @@ func main() {
 	// Init Graph
 	g := G.NewGraph()
 
-	model := NewTinyYOLOv2Net(g, 80, 5, "model/yolov2-tiny.weights")
+	model, err := NewTinyYOLOv2Net(g, "model/yolov2-tiny.cfg", "model/yolov2-tiny.weights")
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
 
 	imgf32, err := GetFloat32Image("data/dog_416x416.jpg")
 	if err != nil {
@@ -28,10 +31,7 @@ func main() {
 	}
 
 	image := tensor.New(tensor.WithShape(1, channels, height, width), tensor.Of(tensor.Float32), tensor.WithBacking(imgf32))
-	x := gorgonia.NewTensor(g, tensor.Float32, 4, gorgonia.WithShape(1, channels, width, height), gorgonia.WithName("x"))
-
-	gorgonia.Let(x, image)
-	if err := model.FeedForward(g, x); err != nil {
+	if err = gorgonia.Let(model.Input, image); err != nil {
 		log.Fatalf("%+v", err)
 	}
 
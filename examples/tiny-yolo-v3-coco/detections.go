@@ -85,7 +85,7 @@ func (net *YOLOv3) ProcessOutput(classes []string, scoreTreshold, iouTreshold fl
 			break
 		}
 
-		detections := prepareDetections(dataF32, scoreTreshold, net.netSize, classes)
+		detections := prepareDetections(dataF32, scoreTreshold, net.Width, classes)
 		preparedDetections = append(preparedDetections, detections...)
 	}
 
@@ -14,8 +14,7 @@ func BenchmarkSample(b *testing.B) {
 
 	g := G.NewGraph()
 
-	input := gorgonia.NewTensor(g, tensor.Float32, 4, gorgonia.WithShape(1, channels, imgWidth, imgHeight), gorgonia.WithName("input"))
-	model, err := NewYoloV3Tiny(g, input, len(cocoClasses), boxes, leakyCoef, cfg, weights)
+	model, err := NewYoloV3Tiny(g, len(cocoClasses), cfg, weights)
 	if err != nil {
 		fmt.Printf("Can't prepare YOLOv3 network due the error: %s\n", err.Error())
 		return
@@ -27,7 +26,7 @@ func BenchmarkSample(b *testing.B) {
 		return
 	}
 	image := tensor.New(tensor.WithShape(1, channels, imgHeight, imgWidth), tensor.Of(tensor.Float32), tensor.WithBacking(imgf32))
-	err = gorgonia.Let(input, image)
+	err = gorgonia.Let(model.Input, image)
 	if err != nil {
 		fmt.Printf("Can't let input = []float32 due the error: %s\n", err.Error())
 		return
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/binary"
 	"image"
 	"image/color"
 	"image/draw"
@@ -12,13 +11,6 @@ import (
 	"github.com/chewxy/math32"
 )
 
-// Float32frombytes Converts []byte to float32
-func Float32frombytes(bytes []byte) float32 {
-	bits := binary.LittleEndian.Uint32(bytes)
-	float := math.Float32frombits(bits)
-	return float
-}
-
 // GetFloat32Image Returns []float32 representation of image file
 func GetFloat32Image(fname string, resizeWidth, resizeHeight int) ([]float32, error) {
 	file, err := os.Open(fname)
@@ -272,11 +272,15 @@ func UnsafeLet(n *Node, be interface{}) error {
 
 	case Value:
 		if !n.Shape().Eq(v.Shape()) {
-			return fmt.Errorf("Node's expected shape is %v. Got %v instead", n.Shape(), v.Shape())
+			err := newShapeMismatch(n.Shape(), v.Shape(), "Node's expected shape is %v. Got %v instead", n.Shape(), v.Shape())
+			err.Node = n
+			return err
 		}
 
 		if !n.Dtype().Eq(v.Dtype()) {
-			return errors.Errorf("Unable to let %v be %v. Expected Dtype of %v. Got %v instead", n.name, be, n.Dtype(), v.Dtype())
+			err := newDtypeMismatch(n.Dtype(), v.Dtype(), "Unable to let %v be %v. Expected Dtype of %v. Got %v instead", n.name, be, n.Dtype(), v.Dtype())
+			err.Node = n
+			return err
 		}
 		n.bind(v)
 	default:
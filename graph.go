@@ -25,6 +25,8 @@ type ExprGraph struct {
 	constants Nodes
 	roots     Nodes
 	counter   uint
+
+	noSources bool // do not record where the nodes are created
 }
 
 // graphconopt sets options
@@ -0,0 +1,400 @@
+package gorgonia
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// This file contains loss functions built out of the other operations, so they are all differentiable.
+//
+// Losses that compare whole distributions (CrossEntropyLoss, KLDivLoss, FocalLoss) and the ones that compare vectors
+// (CosineEmbeddingLoss, TripletMarginLoss) compute one loss per sample along the last axis. The rest are elementwise.
+// The reduction is then applied over the samples (or elements).
+
+// lossEps keeps the square roots of the distance based losses differentiable at 0
+const lossEps = 1e-8
+
+// CrossEntropyLoss computes the categorical cross entropy between the logits and the targets. The log-softmax of
+// the logits is fused into the loss (see LogSoftMax), so there is no need to call SoftMax on the logits beforehand.
+//
+// targets may either be a vector of tensor.Int holding the class of each sample, in which case the logits have to be a matrix
+// of shape (samples, classes) and the loss is NLLLoss(LogSoftMax(logits), targets), or probabilities of the same shape as the logits.
+// The options (class weights, ignore index) are only supported for class targets.
+func CrossEntropyLoss(logits, targets *Node, reduction Reduction, opts ...NLLOpt) (retVal *Node, err error) {
+	var logProbs *Node
+	if logProbs, err = LogSoftMax(logits, logits.Dims()-1); err != nil {
+		return nil, errors.Wrap(err, "Failed to carry LogSoftMax()")
+	}
+
+	if targets.Dtype() == tensor.Int {
+		return NLLLoss(logProbs, targets, reduction, opts...)
+	}
+	if len(opts) > 0 {
+		return nil, errors.New("Class weights and ignore index are only supported for targets that are classes")
+	}
+	if !targets.Shape().Eq(logits.Shape()) {
+		return nil, newShapeMismatch(logits.Shape(), targets.Shape(), shapeMismatchErr, logits.Shape(), targets.Shape())
+	}
+
+	if retVal, err = HadamardProd(targets, logProbs); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if retVal, err = sumLastAxis(retVal); err != nil {
+		return nil, err
+	}
+	if retVal, err = Neg(retVal); err != nil {
+		return nil, errors.Wrap(err, negFail)
+	}
+	return reduceLoss(retVal, reduction)
+}
+
+// MSELoss computes the squared error between output and target.
+func MSELoss(output, target *Node, reduction Reduction) (retVal *Node, err error) {
+	if retVal, err = Sub(output, target); err != nil {
+		return nil, errors.Wrap(err, subFail)
+	}
+	if retVal, err = Square(retVal); err != nil {
+		return nil, errors.Wrap(err, pointWiseSquareFail)
+	}
+	return reduceLoss(retVal, reduction)
+}
+
+// MAELoss computes the absolute error between output and target.
+func MAELoss(output, target *Node, reduction Reduction) (retVal *Node, err error) {
+	if retVal, err = Sub(output, target); err != nil {
+		return nil, errors.Wrap(err, subFail)
+	}
+	if retVal, err = Abs(retVal); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return reduceLoss(retVal, reduction)
+}
+
+// HuberLoss is quadratic for errors smaller than delta and linear beyond:
+//		0.5 * x²                 if |x| <= delta
+//		delta * (|x| - 0.5*delta) otherwise
+// where x = output - target.
+func HuberLoss(output, target *Node, delta float64, reduction Reduction) (retVal *Node, err error) {
+	if retVal, err = huber(output, target, delta); err != nil {
+		return nil, err
+	}
+	return reduceLoss(retVal, reduction)
+}
+
+// SmoothL1Loss is the Huber loss divided by beta. Unlike the Huber loss, its linear part has a slope of 1 regardless of beta.
+// If beta is 0, it is the same as MAELoss.
+func SmoothL1Loss(output, target *Node, beta float64, reduction Reduction) (retVal *Node, err error) {
+	if beta == 0 {
+		return MAELoss(output, target, reduction)
+	}
+	if retVal, err = huber(output, target, beta); err != nil {
+		return nil, err
+	}
+	var betaN *Node
+	if betaN, err = lossConstant(retVal, beta); err != nil {
+		return nil, err
+	}
+	if retVal, err = HadamardDiv(retVal, betaN); err != nil {
+		return nil, errors.Wrap(err, hadamardDivFail)
+	}
+	return reduceLoss(retVal, reduction)
+}
+
+func huber(output, target *Node, delta float64) (retVal *Node, err error) {
+	var diff, abs, half, deltaN, halfDelta *Node
+	if diff, err = Sub(output, target); err != nil {
+		return nil, errors.Wrap(err, subFail)
+	}
+	if abs, err = Abs(diff); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if half, err = lossConstant(diff, 0.5); err != nil {
+		return nil, err
+	}
+	if deltaN, err = lossConstant(diff, delta); err != nil {
+		return nil, err
+	}
+	if halfDelta, err = lossConstant(diff, 0.5*delta); err != nil {
+		return nil, err
+	}
+
+	var quad, lin, small *Node
+	if quad, err = Square(diff); err != nil {
+		return nil, errors.Wrap(err, pointWiseSquareFail)
+	}
+	if quad, err = HadamardProd(quad, half); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if lin, err = Sub(abs, halfDelta); err != nil {
+		return nil, errors.Wrap(err, subFail)
+	}
+	if lin, err = HadamardProd(lin, deltaN); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if small, err = Lte(abs, deltaN, false); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return Where(small, quad, lin)
+}
+
+// KLDivLoss computes the Kullback-Leibler divergence KL(targets || exp(logProbs)) of each sample:
+//		Σ targets * (log(targets) - logProbs)
+// logProbs are log-probabilities (e.g. the output of LogSoftMax) and targets are probabilities of the same shape.
+// Targets that are 0 contribute nothing to the loss. targets are not expected to be differentiated.
+func KLDivLoss(logProbs, targets *Node, reduction Reduction) (retVal *Node, err error) {
+	var zero, pos, logT, tLogT, tLogP *Node
+	if zero, err = lossConstant(logProbs, 0); err != nil {
+		return nil, err
+	}
+	if pos, err = Gt(targets, zero, false); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if logT, err = Log(targets); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if tLogT, err = HadamardProd(targets, logT); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	// 0 * log(0) is NaN. Where the target is 0, the target itself is the correct value for t*log(t).
+	if tLogT, err = Where(pos, tLogT, targets); err != nil {
+		return nil, err
+	}
+	if tLogP, err = HadamardProd(targets, logProbs); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if retVal, err = Sub(tLogT, tLogP); err != nil {
+		return nil, errors.Wrap(err, subFail)
+	}
+	if retVal, err = sumLastAxis(retVal); err != nil {
+		return nil, err
+	}
+	return reduceLoss(retVal, reduction)
+}
+
+// HingeLoss computes max(0, margin - output*target), where the targets are -1 or 1.
+func HingeLoss(output, target *Node, margin float64, reduction Reduction) (retVal *Node, err error) {
+	var marginN *Node
+	if marginN, err = lossConstant(output, margin); err != nil {
+		return nil, err
+	}
+	if retVal, err = HadamardProd(output, target); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if retVal, err = Sub(marginN, retVal); err != nil {
+		return nil, errors.Wrap(err, subFail)
+	}
+	if retVal, err = Rectify(retVal); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return reduceLoss(retVal, reduction)
+}
+
+// FocalLoss is the cross entropy loss with the loss of each class scaled down by (1-p)^gamma, where p is the predicted
+// probability of the class. This focuses the training on the examples that are misclassified. alpha scales the whole loss.
+// Like CrossEntropyLoss, the log-softmax of the logits is fused in. targets are probabilities (usually one-hot) of the same shape as the logits.
+//
+// The loss was introduced in https://arxiv.org/abs/1708.02002
+func FocalLoss(logits, targets *Node, alpha, gamma float64, reduction Reduction) (retVal *Node, err error) {
+	if !targets.Shape().Eq(logits.Shape()) {
+		return nil, newShapeMismatch(logits.Shape(), targets.Shape(), shapeMismatchErr, logits.Shape(), targets.Shape())
+	}
+
+	var logProbs, probs, one, gammaN, alphaN, w *Node
+	if logProbs, err = LogSoftMax(logits, logits.Dims()-1); err != nil {
+		return nil, errors.Wrap(err, "Failed to carry LogSoftMax()")
+	}
+	if probs, err = Exp(logProbs); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if one, err = lossConstant(logits, 1); err != nil {
+		return nil, err
+	}
+	if gammaN, err = lossConstant(logits, gamma); err != nil {
+		return nil, err
+	}
+	if alphaN, err = lossConstant(logits, -alpha); err != nil {
+		return nil, err
+	}
+
+	if w, err = Sub(one, probs); err != nil {
+		return nil, errors.Wrap(err, subFail)
+	}
+	if w, err = Pow(w, gammaN); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if retVal, err = HadamardProd(targets, w); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if retVal, err = HadamardProd(retVal, logProbs); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if retVal, err = sumLastAxis(retVal); err != nil {
+		return nil, err
+	}
+	if retVal, err = HadamardProd(retVal, alphaN); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	return reduceLoss(retVal, reduction)
+}
+
+// CosineEmbeddingLoss measures whether pairs of vectors are similar (y = 1) or dissimilar (y = -1) using the cosine similarity:
+//		1 - cos(x1, x2)              if y = 1
+//		max(0, cos(x1, x2) - margin) if y = -1
+// x1 and x2 are matrices of shape (samples, features) (or vectors), and y has one value per sample.
+func CosineEmbeddingLoss(x1, x2, y *Node, margin float64, reduction Reduction) (retVal *Node, err error) {
+	var dot, n1, n2, norm, eps, cos *Node
+	if dot, err = HadamardProd(x1, x2); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if dot, err = sumLastAxis(dot); err != nil {
+		return nil, err
+	}
+	if n1, err = squaredNorm(x1); err != nil {
+		return nil, err
+	}
+	if n2, err = squaredNorm(x2); err != nil {
+		return nil, err
+	}
+	if eps, err = lossConstant(x1, lossEps); err != nil {
+		return nil, err
+	}
+	if norm, err = HadamardProd(n1, n2); err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	if norm, err = Add(norm, eps); err != nil {
+		return nil, errors.Wrap(err, addFail)
+	}
+	if norm, err = Sqrt(norm); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if cos, err = HadamardDiv(dot, norm); err != nil {
+		return nil, errors.Wrap(err, hadamardDivFail)
+	}
+
+	var one, zero, marginN, similar, dissimilar, cond *Node
+	if one, err = lossConstant(x1, 1); err != nil {
+		return nil, err
+	}
+	if zero, err = lossConstant(x1, 0); err != nil {
+		return nil, err
+	}
+	if marginN, err = lossConstant(x1, margin); err != nil {
+		return nil, err
+	}
+	if similar, err = Sub(one, cos); err != nil {
+		return nil, errors.Wrap(err, subFail)
+	}
+	if dissimilar, err = Sub(cos, marginN); err != nil {
+		return nil, errors.Wrap(err, subFail)
+	}
+	if dissimilar, err = Rectify(dissimilar); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if cond, err = Gt(y, zero, false); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	if retVal, err = Where(cond, similar, dissimilar); err != nil {
+		return nil, err
+	}
+	return reduceLoss(retVal, reduction)
+}
+
+// TripletMarginLoss pulls the anchor towards the positive and pushes it away from the negative:
+//		max(0, ‖anchor - positive‖ - ‖anchor - negative‖ + margin)
+// The distances are Euclidean distances along the last axis.
+func TripletMarginLoss(anchor, positive, negative *Node, margin float64, reduction Reduction) (retVal *Node, err error) {
+	var dPos, dNeg, marginN *Node
+	if dPos, err = euclideanDist(anchor, positive); err != nil {
+		return nil, err
+	}
+	if dNeg, err = euclideanDist(anchor, negative); err != nil {
+		return nil, err
+	}
+	if marginN, err = lossConstant(anchor, margin); err != nil {
+		return nil, err
+	}
+	if retVal, err = Sub(dPos, dNeg); err != nil {
+		return nil, errors.Wrap(err, subFail)
+	}
+	if retVal, err = Add(retVal, marginN); err != nil {
+		return nil, errors.Wrap(err, addFail)
+	}
+	if retVal, err = Rectify(retVal); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return reduceLoss(retVal, reduction)
+}
+
+func squaredNorm(x *Node) (retVal *Node, err error) {
+	if retVal, err = Square(x); err != nil {
+		return nil, errors.Wrap(err, pointWiseSquareFail)
+	}
+	return sumLastAxis(retVal)
+}
+
+// euclideanDist computes the Euclidean distance between a and b along the last axis.
+// A small epsilon is added before the square root, which is not differentiable at 0.
+func euclideanDist(a, b *Node) (retVal *Node, err error) {
+	var eps *Node
+	if eps, err = lossConstant(a, lossEps); err != nil {
+		return nil, err
+	}
+	if retVal, err = Sub(a, b); err != nil {
+		return nil, errors.Wrap(err, subFail)
+	}
+	if retVal, err = squaredNorm(retVal); err != nil {
+		return nil, err
+	}
+	if retVal, err = Add(retVal, eps); err != nil {
+		return nil, errors.Wrap(err, addFail)
+	}
+	if retVal, err = Sqrt(retVal); err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return retVal, nil
+}
+
+func sumLastAxis(n *Node) (*Node, error) {
+	if n.IsScalar() {
+		return n, nil
+	}
+	retVal, err := Sum(n, n.Dims()-1)
+	if err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return retVal, nil
+}
+
+// lossConstant returns a scalar constant of the given value, with the same Dtype as n.
+func lossConstant(n *Node, v float64) (*Node, error) {
+	dt, err := dtypeOf(n.t)
+	if err != nil {
+		return nil, errors.Wrap(err, dtypeOfFail)
+	}
+	switch dt {
+	case Float64:
+		return NewConstant(v), nil
+	case Float32:
+		return NewConstant(float32(v)), nil
+	default:
+		return nil, errors.Errorf(nyiFail, "Loss", dt)
+	}
+}
+
+// reduceLoss reduces the losses as per the reduction.
+func reduceLoss(loss *Node, reduction Reduction) (retVal *Node, err error) {
+	switch {
+	case reduction == ReductionNone || loss.IsScalar():
+		return loss, nil
+	case reduction == ReductionSum:
+		retVal, err = Sum(loss)
+	case reduction == ReductionMean:
+		retVal, err = Mean(loss)
+	default:
+		return nil, errors.Errorf("Unknown reduction %d", reduction)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, operationError)
+	}
+	return retVal, nil
+}
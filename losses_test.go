@@ -0,0 +1,240 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/tensor"
+)
+
+func logSoftMaxRef(xs []float64) []float64 {
+	max := math.Inf(-1)
+	for _, x := range xs {
+		max = math.Max(max, x)
+	}
+	var sum float64
+	for _, x := range xs {
+		sum += math.Exp(x - max)
+	}
+	retVal := make([]float64, len(xs))
+	for i, x := range xs {
+		retVal[i] = x - max - math.Log(sum)
+	}
+	return retVal
+}
+
+func TestLosses(t *testing.T) {
+	logits := []float64{1, 2, 3, 1, 0, -1}
+	lsm0, lsm1 := logSoftMaxRef(logits[:3]), logSoftMaxRef(logits[3:])
+
+	mat := func(g *ExprGraph, name string, rows, cols int, data interface{}) *Node {
+		return NewMatrix(g, tensor.Float64, WithShape(rows, cols), WithName(name), WithValue(tensor.New(tensor.WithShape(rows, cols), tensor.WithBacking(data))))
+	}
+	vec := func(g *ExprGraph, name string, data []float64) *Node {
+		return NewVector(g, tensor.Float64, WithShape(len(data)), WithName(name), WithValue(tensor.New(tensor.WithShape(len(data)), tensor.WithBacking(data))))
+	}
+	classes := func(g *ExprGraph, data []int) *Node {
+		return NewVector(g, tensor.Int, WithShape(len(data)), WithName("classes"), WithValue(tensor.New(tensor.WithShape(len(data)), tensor.WithBacking(data))))
+	}
+
+	testCases := []struct {
+		desc     string
+		loss     func(g *ExprGraph) (*Node, error)
+		expected interface{}
+	}{
+		{
+			"CrossEntropyLoss with classes",
+			func(g *ExprGraph) (*Node, error) {
+				return CrossEntropyLoss(mat(g, "logits", 2, 3, logits), classes(g, []int{2, 0}), ReductionMean)
+			},
+			-(lsm0[2] + lsm1[0]) / 2,
+		},
+		{
+			"CrossEntropyLoss with probabilities",
+			func(g *ExprGraph) (*Node, error) {
+				return CrossEntropyLoss(mat(g, "logits", 2, 3, logits), mat(g, "targets", 2, 3, []float64{0, 0.5, 0.5, 1, 0, 0}), ReductionNone)
+			},
+			[]float64{-(lsm0[1] + lsm0[2]) / 2, -lsm1[0]},
+		},
+		{
+			"NLLLoss with weights and ignore index",
+			func(g *ExprGraph) (*Node, error) {
+				return NLLLoss(mat(g, "logprobs", 3, 3, append(append([]float64{}, lsm0...), append(lsm1, lsm0...)...)), classes(g, []int{2, 0, 1}), ReductionMean, WithClassWeights(1, 2, 3), WithIgnoreIndex(1))
+			},
+			-(3*lsm0[2] + 1*lsm1[0]) / 4,
+		},
+		{
+			"MSELoss",
+			func(g *ExprGraph) (*Node, error) {
+				return MSELoss(vec(g, "output", []float64{1, 2, 3, 4}), vec(g, "target", []float64{1, 0, 5, 4}), ReductionSum)
+			},
+			8.0,
+		},
+		{
+			"MAELoss",
+			func(g *ExprGraph) (*Node, error) {
+				return MAELoss(vec(g, "output", []float64{1, 2, 3, 4}), vec(g, "target", []float64{1, 0, 5, 4}), ReductionMean)
+			},
+			1.0,
+		},
+		{
+			"HuberLoss",
+			func(g *ExprGraph) (*Node, error) {
+				return HuberLoss(vec(g, "output", []float64{0.5, -2, 3, 0}), vec(g, "target", []float64{0, 0, 0, 0}), 1, ReductionNone)
+			},
+			[]float64{0.125, 1.5, 2.5, 0},
+		},
+		{
+			"SmoothL1Loss",
+			func(g *ExprGraph) (*Node, error) {
+				return SmoothL1Loss(vec(g, "output", []float64{0.5, -2, 3, 0}), vec(g, "target", []float64{0, 0, 0, 0}), 2, ReductionSum)
+			},
+			3.0625,
+		},
+		{
+			"KLDivLoss",
+			func(g *ExprGraph) (*Node, error) {
+				logProbs := mat(g, "logprobs", 2, 2, []float64{math.Log(0.5), math.Log(0.5), math.Log(0.5), math.Log(0.5)})
+				return KLDivLoss(logProbs, mat(g, "targets", 2, 2, []float64{1, 0, 0.25, 0.75}), ReductionNone)
+			},
+			[]float64{math.Ln2, 0.25*math.Log(0.5) + 0.75*math.Log(1.5)},
+		},
+		{
+			"HingeLoss",
+			func(g *ExprGraph) (*Node, error) {
+				return HingeLoss(vec(g, "output", []float64{0.5, -2, 2, 3}), vec(g, "target", []float64{1, 1, -1, 1}), 1, ReductionNone)
+			},
+			[]float64{0.5, 3, 3, 0},
+		},
+		{
+			"FocalLoss",
+			func(g *ExprGraph) (*Node, error) {
+				return FocalLoss(mat(g, "logits", 2, 3, logits), mat(g, "targets", 2, 3, []float64{0, 0, 1, 1, 0, 0}), 0.25, 2, ReductionSum)
+			},
+			-0.25 * (math.Pow(1-math.Exp(lsm0[2]), 2)*lsm0[2] + math.Pow(1-math.Exp(lsm1[0]), 2)*lsm1[0]),
+		},
+		{
+			"CosineEmbeddingLoss",
+			func(g *ExprGraph) (*Node, error) {
+				x1 := mat(g, "x1", 2, 2, []float64{1, 0, 1, 0})
+				x2 := mat(g, "x2", 2, 2, []float64{2, 0, 0.6, 0.8})
+				return CosineEmbeddingLoss(x1, x2, vec(g, "y", []float64{1, -1}), 0.5, ReductionNone)
+			},
+			[]float64{0, 0.1},
+		},
+		{
+			"TripletMarginLoss",
+			func(g *ExprGraph) (*Node, error) {
+				a := mat(g, "anchor", 2, 2, []float64{0, 0, 0, 0})
+				p := mat(g, "positive", 2, 2, []float64{3, 4, 0, 1})
+				n := mat(g, "negative", 2, 2, []float64{0, 1, 3, 4})
+				return TripletMarginLoss(a, p, n, 1, ReductionMean)
+			},
+			2.5,
+		},
+	}
+
+	for _, tcase := range testCases {
+		t.Run(tcase.desc, func(t *testing.T) {
+			c := require.New(t)
+			g := NewGraph()
+			loss, err := tcase.loss(g)
+			c.NoError(err)
+
+			m := NewTapeMachine(g)
+			defer m.Close()
+			c.NoError(m.RunAll())
+
+			switch expected := tcase.expected.(type) {
+			case float64:
+				c.True(loss.IsScalar(), "Expected a scalar loss. Got %v instead", loss.Shape())
+				c.InDelta(expected, loss.Value().Data(), 1e-6)
+			case []float64:
+				c.InDeltaSlice(expected, loss.Value().Data(), 1e-6)
+			}
+		})
+	}
+}
+
+func TestCrossEntropyLossGrad(t *testing.T) {
+	logits := []float64{1, 2, 3, 1, 0, -1, 0, 0, 0}
+	targets := []int{2, 0, 1}
+
+	for _, reduction := range []Reduction{ReductionMean, ReductionSum} {
+		c := require.New(t)
+		g := NewGraph()
+		x := NewMatrix(g, tensor.Float64, WithShape(3, 3), WithName("logits"), WithValue(tensor.New(tensor.WithShape(3, 3), tensor.WithBacking(logits))))
+		y := NewVector(g, tensor.Int, WithShape(3), WithName("targets"), WithValue(tensor.New(tensor.WithShape(3), tensor.WithBacking(targets))))
+
+		// the last sample is ignored, and the first one has twice the weight
+		cost, err := CrossEntropyLoss(x, y, reduction, WithClassWeights(1, 1, 2), WithIgnoreIndex(1))
+		c.NoError(err)
+		_, err = Grad(cost, x)
+		c.NoError(err)
+
+		m := NewTapeMachine(g)
+		c.NoError(m.RunAll())
+		m.Close()
+
+		// d/dx = w * (softmax(x) - onehot(target)) / Σw
+		expected := make([]float64, 9)
+		weights := []float64{2, 1, 0}
+		norm := 1.0
+		if reduction == ReductionMean {
+			norm = 3
+		}
+		for i := 0; i < 3; i++ {
+			lsm := logSoftMaxRef(logits[i*3 : i*3+3])
+			for j := 0; j < 3; j++ {
+				p := math.Exp(lsm[j])
+				if j == targets[i] {
+					p--
+				}
+				expected[i*3+j] = weights[i] * p / norm
+			}
+		}
+
+		grad, err := x.Grad()
+		c.NoError(err)
+		c.InDeltaSlice(expected, grad.Data(), 1e-6)
+	}
+}
+
+func TestNLLLossLispMachine(t *testing.T) {
+	c := require.New(t)
+	g := NewGraph()
+	lp := NewMatrix(g, tensor.Float32, WithShape(2, 2), WithName("logprobs"), WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float32{-1, -2, -3, -4}))))
+	y := NewVector(g, tensor.Int, WithShape(2), WithName("targets"), WithValue(tensor.New(tensor.WithShape(2), tensor.WithBacking([]int{1, 0}))))
+
+	loss, err := NLLLoss(lp, y, ReductionNone)
+	c.NoError(err)
+	cost := Must(Sum(loss))
+
+	m := NewLispMachine(g)
+	defer m.Close()
+	c.NoError(m.RunAll())
+
+	c.Equal([]float32{2, 3}, loss.Value().Data())
+	c.InDelta(float32(5), cost.Value().Data(), 1e-6)
+	grad, err := lp.Grad()
+	c.NoError(err)
+	c.Equal([]float32{0, -1, -1, 0}, grad.Data())
+}
+
+func TestLossErrors(t *testing.T) {
+	c := require.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, tensor.Float64, WithShape(2, 3), WithName("x"))
+	p := NewMatrix(g, tensor.Float64, WithShape(2, 2), WithName("p"))
+	y := NewVector(g, tensor.Int, WithShape(2), WithName("y"))
+
+	_, err := CrossEntropyLoss(x, p, ReductionMean)
+	c.Error(err)
+	_, err = CrossEntropyLoss(x, x, ReductionMean, WithIgnoreIndex(0))
+	c.Error(err)
+	_, err = NLLLoss(x, y, ReductionMean, WithClassWeights(1, 2))
+	c.Error(err)
+	_, err = CTCLoss(x, y, y, y, ReductionNone)
+	c.Error(err)
+}
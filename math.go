@@ -66,3 +66,165 @@ func _softplusf32(x float32) float32 {
 	}
 	return float32(math.Log1p(math.Exp(float64(x))))
 }
+
+/* ACTIVATION FUNCTIONS */
+
+const (
+	seluAlpha     = 1.6732632423543772848170429916717
+	seluScale     = 1.0507009873554804934193349852946
+	sqrt2OverPi   = 0.79788456080286535587989211986876 // sqrt(2/π), used in the tanh approximation of GELU
+	invSqrt2Pi    = 0.39894228040143267793994605993438 // 1/sqrt(2π), the normalization of the standard normal pdf
+	geluTanhCoeff = 0.044715
+)
+
+// The float32 versions are written in float32 throughout (using math32) rather than converting to and from float64.
+
+// GELU(x) = x * Φ(x), where Φ is the cumulative distribution function of the standard normal distribution
+func _geluf64(x float64) float64 { return 0.5 * x * (1 + math.Erf(x/math.Sqrt2)) }
+func _geluf32(x float32) float32 { return 0.5 * x * (1 + math32.Erf(x/math32.Sqrt2)) }
+
+// GELU'(x) = Φ(x) + x * φ(x)
+func _geluDerivf64(x float64) float64 {
+	return 0.5*(1+math.Erf(x/math.Sqrt2)) + x*invSqrt2Pi*math.Exp(-0.5*x*x)
+}
+func _geluDerivf32(x float32) float32 {
+	return 0.5*(1+math32.Erf(x/math32.Sqrt2)) + x*invSqrt2Pi*math32.Exp(-0.5*x*x)
+}
+
+// the tanh approximation of GELU: 0.5 * x * (1 + tanh(sqrt(2/π) * (x + 0.044715x³)))
+func _geluTanhf64(x float64) float64 {
+	return 0.5 * x * (1 + math.Tanh(sqrt2OverPi*(x+geluTanhCoeff*x*x*x)))
+}
+func _geluTanhf32(x float32) float32 {
+	return 0.5 * x * (1 + math32.Tanh(sqrt2OverPi*(x+geluTanhCoeff*x*x*x)))
+}
+
+func _geluTanhDerivf64(x float64) float64 {
+	t := math.Tanh(sqrt2OverPi * (x + geluTanhCoeff*x*x*x))
+	return 0.5*(1+t) + 0.5*x*(1-t*t)*sqrt2OverPi*(1+3*geluTanhCoeff*x*x)
+}
+func _geluTanhDerivf32(x float32) float32 {
+	t := math32.Tanh(sqrt2OverPi * (x + geluTanhCoeff*x*x*x))
+	return 0.5*(1+t) + 0.5*x*(1-t*t)*sqrt2OverPi*(1+3*geluTanhCoeff*x*x)
+}
+
+// SiLU(x) = x * sigmoid(x). It is also known as Swish.
+func _siluf64(x float64) float64 { return x / (1 + math.Exp(-x)) }
+func _siluf32(x float32) float32 { return x / (1 + math32.Exp(-x)) }
+
+// SiLU'(x) = sigmoid(x) * (1 + x * (1 - sigmoid(x)))
+func _siluDerivf64(x float64) float64 {
+	s := 1 / (1 + math.Exp(-x))
+	return s * (1 + x*(1-s))
+}
+func _siluDerivf32(x float32) float32 {
+	s := 1 / (1 + math32.Exp(-x))
+	return s * (1 + x*(1-s))
+}
+
+// ELU(x) = x if x > 0, exp(x) - 1 otherwise
+func _eluf64(x float64) float64 {
+	if x > 0 {
+		return x
+	}
+	return math.Expm1(x)
+}
+func _eluf32(x float32) float32 {
+	if x > 0 {
+		return x
+	}
+	return math32.Expm1(x)
+}
+
+func _eluDerivf64(x float64) float64 {
+	if x > 0 {
+		return 1
+	}
+	return math.Exp(x)
+}
+func _eluDerivf32(x float32) float32 {
+	if x > 0 {
+		return 1
+	}
+	return math32.Exp(x)
+}
+
+// SELU(x) = λ * x if x > 0, λ * α * (exp(x) - 1) otherwise. The constants come from https://arxiv.org/abs/1706.02515
+func _seluf64(x float64) float64 {
+	if x > 0 {
+		return seluScale * x
+	}
+	return seluScale * seluAlpha * math.Expm1(x)
+}
+func _seluf32(x float32) float32 {
+	if x > 0 {
+		return seluScale * x
+	}
+	return seluScale * seluAlpha * math32.Expm1(x)
+}
+
+func _seluDerivf64(x float64) float64 {
+	if x > 0 {
+		return seluScale
+	}
+	return seluScale * seluAlpha * math.Exp(x)
+}
+func _seluDerivf32(x float32) float32 {
+	if x > 0 {
+		return seluScale
+	}
+	return seluScale * seluAlpha * math32.Exp(x)
+}
+
+// HardSigmoid(x) = clamp((x + 3) / 6, 0, 1)
+func _hardSigmoidf64(x float64) float64 { return math.Max(0, math.Min(1, (x+3)/6)) }
+func _hardSigmoidf32(x float32) float32 { return math32.Max(0, math32.Min(1, (x+3)/6)) }
+
+func _hardSigmoidDerivf64(x float64) float64 {
+	if x > -3 && x < 3 {
+		return 1.0 / 6
+	}
+	return 0
+}
+func _hardSigmoidDerivf32(x float32) float32 {
+	if x > -3 && x < 3 {
+		return 1.0 / 6
+	}
+	return 0
+}
+
+// HardSwish(x) = x * HardSigmoid(x)
+func _hardSwishf64(x float64) float64 { return x * _hardSigmoidf64(x) }
+func _hardSwishf32(x float32) float32 { return x * _hardSigmoidf32(x) }
+
+func _hardSwishDerivf64(x float64) float64 {
+	switch {
+	case x <= -3:
+		return 0
+	case x >= 3:
+		return 1
+	}
+	return (2*x + 3) / 6
+}
+func _hardSwishDerivf32(x float32) float32 {
+	switch {
+	case x <= -3:
+		return 0
+	case x >= 3:
+		return 1
+	}
+	return (2*x + 3) / 6
+}
+
+// Softsign(x) = x / (1 + |x|)
+func _softsignf64(x float64) float64 { return x / (1 + math.Abs(x)) }
+func _softsignf32(x float32) float32 { return x / (1 + math32.Abs(x)) }
+
+func _softsignDerivf64(x float64) float64 {
+	d := 1 + math.Abs(x)
+	return 1 / (d * d)
+}
+func _softsignDerivf32(x float32) float32 {
+	d := 1 + math32.Abs(x)
+	return 1 / (d * d)
+}
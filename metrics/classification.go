@@ -0,0 +1,290 @@
+package metrics
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+)
+
+// Accuracy is the fraction of the predictions that are of the right class.
+type Accuracy struct {
+	correct, total int
+}
+
+// Update adds a batch of predictions and targets.
+func (m *Accuracy) Update(pred, target gorgonia.Value) error {
+	p, t, err := classes(pred, target)
+	if err != nil {
+		return err
+	}
+	for i := range p {
+		if p[i] == t[i] {
+			m.correct++
+		}
+	}
+	m.total += len(p)
+	return nil
+}
+
+// Reset forgets all the batches.
+func (m *Accuracy) Reset() { m.correct, m.total = 0, 0 }
+
+// Value returns the accuracy, or 0 if there were no predictions.
+func (m *Accuracy) Value() float64 {
+	if m.total == 0 {
+		return 0
+	}
+	return float64(m.correct) / float64(m.total)
+}
+
+// TopKAccuracy is the fraction of the predictions whose right class is within the K highest scores.
+// The predictions have to be scores, of shape (batch, classes).
+type TopKAccuracy struct {
+	K int
+
+	correct, total int
+}
+
+// Update adds a batch of predictions and targets.
+func (m *TopKAccuracy) Update(pred, target gorgonia.Value) error {
+	shp := pred.Shape()
+	if !isScores(shp) {
+		return errors.Errorf("TopKAccuracy expects predictions of shape (batch, classes). Got %v instead", shp)
+	}
+	scores, err := floats(pred)
+	if err != nil {
+		return err
+	}
+	t, err := labels(target)
+	if err != nil {
+		return err
+	}
+	n, c := shp[0], shp[1]
+	if err = sameLen(n, len(t)); err != nil {
+		return err
+	}
+	for i, class := range t {
+		if class < 0 || class >= c {
+			return errors.Errorf("Target %d is not one of the %d classes", class, c)
+		}
+		row := scores[i*c : (i+1)*c]
+		// the target is in the top k if fewer than k scores beat it
+		var higher int
+		for _, s := range row {
+			if s > row[class] {
+				higher++
+			}
+		}
+		if higher < m.K {
+			m.correct++
+		}
+	}
+	m.total += n
+	return nil
+}
+
+// Reset forgets all the batches.
+func (m *TopKAccuracy) Reset() { m.correct, m.total = 0, 0 }
+
+// Value returns the top-k accuracy, or 0 if there were no predictions.
+func (m *TopKAccuracy) Value() float64 {
+	if m.total == 0 {
+		return 0
+	}
+	return float64(m.correct) / float64(m.total)
+}
+
+// ConfusionMatrix counts the predictions of each class for each target class.
+type ConfusionMatrix struct {
+	counts [][]int
+}
+
+// NewConfusionMatrix creates a ConfusionMatrix for the given number of classes.
+func NewConfusionMatrix(classes int) *ConfusionMatrix {
+	m := &ConfusionMatrix{counts: make([][]int, classes)}
+	for i := range m.counts {
+		m.counts[i] = make([]int, classes)
+	}
+	return m
+}
+
+// Classes returns the number of classes.
+func (m *ConfusionMatrix) Classes() int { return len(m.counts) }
+
+// Update adds a batch of predictions and targets.
+func (m *ConfusionMatrix) Update(pred, target gorgonia.Value) error {
+	p, t, err := classes(pred, target)
+	if err != nil {
+		return err
+	}
+	n := m.Classes()
+	for i := range p {
+		if p[i] < 0 || p[i] >= n {
+			return errors.Errorf("Prediction %d is not one of the %d classes", p[i], n)
+		}
+		if t[i] < 0 || t[i] >= n {
+			return errors.Errorf("Target %d is not one of the %d classes", t[i], n)
+		}
+	}
+	for i := range p {
+		m.counts[t[i]][p[i]]++
+	}
+	return nil
+}
+
+// Reset forgets all the batches.
+func (m *ConfusionMatrix) Reset() {
+	for _, row := range m.counts {
+		for j := range row {
+			row[j] = 0
+		}
+	}
+}
+
+// Matrix returns a copy of the counts. The rows are the targets and the columns are the predictions.
+func (m *ConfusionMatrix) Matrix() [][]int {
+	retVal := make([][]int, len(m.counts))
+	for i, row := range m.counts {
+		retVal[i] = append([]int(nil), row...)
+	}
+	return retVal
+}
+
+// Accuracy returns the fraction of the predictions that are right.
+func (m *ConfusionMatrix) Accuracy() float64 {
+	var correct, total int
+	for i, row := range m.counts {
+		for j, c := range row {
+			total += c
+			if i == j {
+				correct += c
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(correct) / float64(total)
+}
+
+// Average is how the per class precisions, recalls and F1 scores are summed up.
+type Average int
+
+const (
+	// Macro averages the scores of the classes that occur in the targets or in the predictions.
+	Macro Average = iota
+	// Micro computes the scores from the counts of all the classes together. For single label classification, this is the accuracy.
+	Micro
+	// Weighted averages the scores of the classes weighted by their number of targets.
+	Weighted
+	// Binary is the score of class 1, the positive class of a binary classification.
+	Binary
+)
+
+// classCounts returns the true positives, false positives and false negatives of a class
+func (m *ConfusionMatrix) classCounts(class int) (tp, fp, fn int) {
+	tp = m.counts[class][class]
+	for i := range m.counts {
+		if i != class {
+			fp += m.counts[i][class]
+			fn += m.counts[class][i]
+		}
+	}
+	return
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+func precision(tp, fp, fn int) float64 { return ratio(tp, tp+fp) }
+func recall(tp, fp, fn int) float64    { return ratio(tp, tp+fn) }
+func f1(tp, fp, fn int) float64        { return ratio(2*tp, 2*tp+fp+fn) }
+
+// average sums up the per class score
+func (m *ConfusionMatrix) average(score func(tp, fp, fn int) float64, avg Average) float64 {
+	switch avg {
+	case Micro:
+		var tp, fp, fn int
+		for c := range m.counts {
+			ctp, cfp, cfn := m.classCounts(c)
+			tp, fp, fn = tp+ctp, fp+cfp, fn+cfn
+		}
+		return score(tp, fp, fn)
+	case Binary:
+		if m.Classes() < 2 {
+			return 0
+		}
+		return score(m.classCounts(1))
+	}
+
+	var sum, weights float64
+	for c := range m.counts {
+		tp, fp, fn := m.classCounts(c)
+		var w float64
+		switch {
+		case avg == Weighted:
+			w = float64(tp + fn)
+		case tp+fp+fn > 0:
+			w = 1
+		}
+		sum += w * score(tp, fp, fn)
+		weights += w
+	}
+	if weights == 0 {
+		return 0
+	}
+	return sum / weights
+}
+
+// Precision returns the fraction of the predictions of a class that are right, averaged over the classes.
+func (m *ConfusionMatrix) Precision(avg Average) float64 { return m.average(precision, avg) }
+
+// Recall returns the fraction of the targets of a class that are predicted, averaged over the classes.
+func (m *ConfusionMatrix) Recall(avg Average) float64 { return m.average(recall, avg) }
+
+// F1 returns the harmonic mean of the precision and the recall, averaged over the classes.
+func (m *ConfusionMatrix) F1(avg Average) float64 { return m.average(f1, avg) }
+
+// ClassScores returns the precision, recall and F1 score of a class.
+func (m *ConfusionMatrix) ClassScores(class int) (p, r, f float64) {
+	tp, fp, fn := m.classCounts(class)
+	return precision(tp, fp, fn), recall(tp, fp, fn), f1(tp, fp, fn)
+}
+
+// classScore is a Metric for a score of a confusion matrix
+type classScore struct {
+	*ConfusionMatrix
+	score func(tp, fp, fn int) float64
+	avg   Average
+}
+
+func (m *classScore) Value() float64 { return m.average(m.score, m.avg) }
+
+// NewPrecision returns a Metric of the precision over the given number of classes.
+func NewPrecision(classes int, avg Average) Metric {
+	return &classScore{NewConfusionMatrix(classes), precision, avg}
+}
+
+// NewRecall returns a Metric of the recall over the given number of classes.
+func NewRecall(classes int, avg Average) Metric {
+	return &classScore{NewConfusionMatrix(classes), recall, avg}
+}
+
+// NewF1 returns a Metric of the F1 score over the given number of classes.
+func NewF1(classes int, avg Average) Metric {
+	return &classScore{NewConfusionMatrix(classes), f1, avg}
+}
+
+// classes returns the predicted and the target classes
+func classes(pred, target gorgonia.Value) (p, t []int, err error) {
+	if p, err = labels(pred); err != nil {
+		return nil, nil, errors.Wrap(err, "Invalid predictions")
+	}
+	if t, err = labels(target); err != nil {
+		return nil, nil, errors.Wrap(err, "Invalid targets")
+	}
+	return p, t, sameLen(len(p), len(t))
+}
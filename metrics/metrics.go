@@ -0,0 +1,137 @@
+// Package metrics evaluates the predictions of models.
+//
+// The metrics are updated incrementally, one batch of predictions and targets at a time, and can be read at any point:
+//		acc := new(metrics.Accuracy)
+//		for ... {
+//			// run the graph on a batch
+//			if err := acc.Update(pred.Value(), y.Value()); err != nil { ... }
+//		}
+//		fmt.Println(acc.Value())
+//
+// Predictions and targets may be float32, float64 or integer values.
+// For classification, a matrix of shape (batch, classes) holds scores (such as probabilities or logits, or one-hot targets)
+// and the class is the one with the highest score. A vector holds classes, which are rounded to the nearest integer:
+// binary probabilities are thereby thresholded at 0.5.
+package metrics
+
+import (
+	"math"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// Metric is a metric that is updated a batch at a time, and that sums up to a single number.
+type Metric interface {
+	// Update adds a batch of predictions and their targets.
+	Update(pred, target gorgonia.Value) error
+
+	// Reset forgets all the batches.
+	Reset()
+
+	// Value returns the metric over all the batches since the last Reset.
+	Value() float64
+}
+
+// floats returns the data of a value as a []float64
+func floats(v gorgonia.Value) ([]float64, error) {
+	if v == nil {
+		return nil, errors.New("Expected a value. Got nil instead")
+	}
+	switch d := v.Data().(type) {
+	case []float64:
+		return d, nil
+	case []float32:
+		retVal := make([]float64, len(d))
+		for i, f := range d {
+			retVal[i] = float64(f)
+		}
+		return retVal, nil
+	}
+
+	rv := reflect.ValueOf(v.Data())
+	if rv.Kind() != reflect.Slice {
+		// a scalar
+		f, err := toFloat(rv)
+		if err != nil {
+			return nil, err
+		}
+		return []float64{f}, nil
+	}
+	retVal := make([]float64, rv.Len())
+	for i := range retVal {
+		f, err := toFloat(rv.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		retVal[i] = f
+	}
+	return retVal, nil
+}
+
+func toFloat(v reflect.Value) (float64, error) {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, errors.Errorf("Expected a numeric value. Got %v instead", v.Type())
+}
+
+// isScores tells whether a value holds a row of scores per example, as opposed to one number per example
+func isScores(shp tensor.Shape) bool {
+	return shp.Dims() == 2 && shp[1] > 1
+}
+
+// labels returns the classes held by a value. See the package documentation.
+func labels(v gorgonia.Value) ([]int, error) {
+	fs, err := floats(v)
+	if err != nil {
+		return nil, err
+	}
+	shp := v.Shape()
+	if isScores(shp) {
+		n, classes := shp[0], shp[1]
+		retVal := make([]int, n)
+		for i := range retVal {
+			retVal[i] = argmax(fs[i*classes : (i+1)*classes])
+		}
+		return retVal, nil
+	}
+	if shp.Dims() > 2 {
+		return nil, errors.Errorf("Expected a vector of classes or a matrix of scores. Got a value of shape %v instead", shp)
+	}
+	retVal := make([]int, len(fs))
+	for i, f := range fs {
+		retVal[i] = int(math.Round(f))
+	}
+	return retVal, nil
+}
+
+func argmax(xs []float64) int {
+	var retVal int
+	for i, x := range xs {
+		if x > xs[retVal] {
+			retVal = i
+		}
+	}
+	return retVal
+}
+
+// sameLen checks that there are as many predictions as targets
+func sameLen(pred, target int) error {
+	if pred != target {
+		return errors.Errorf("Expected as many predictions as targets. Got %d predictions and %d targets", pred, target)
+	}
+	return nil
+}
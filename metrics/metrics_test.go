@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+func vec(data interface{}) gorgonia.Value { return tensor.New(tensor.WithBacking(data)) }
+
+func mat(r, c int, data interface{}) gorgonia.Value {
+	return tensor.New(tensor.WithShape(r, c), tensor.WithBacking(data))
+}
+
+func TestAccuracy(t *testing.T) {
+	m := new(Accuracy)
+	assert.Equal(t, 0.0, m.Value())
+
+	// scores against classes
+	require.NoError(t, m.Update(mat(3, 2, []float32{0.9, 0.1, 0.2, 0.8, 0.6, 0.4}), vec([]int{0, 1, 1})))
+	assert.InDelta(t, 2.0/3, m.Value(), 1e-12)
+	// thresholded probabilities against one-hot targets
+	require.NoError(t, m.Update(vec([]float64{0.7}), mat(1, 2, []float64{1, 0})))
+	assert.InDelta(t, 2.0/4, m.Value(), 1e-12)
+
+	assert.Error(t, m.Update(vec([]float64{0, 1}), vec([]int{0})))
+	m.Reset()
+	assert.Equal(t, 0.0, m.Value())
+}
+
+func TestTopKAccuracy(t *testing.T) {
+	m := &TopKAccuracy{K: 2}
+	scores := mat(3, 3, []float64{
+		0.5, 0.3, 0.2,
+		0.1, 0.2, 0.7,
+		0.2, 0.3, 0.5,
+	})
+	require.NoError(t, m.Update(scores, vec([]float64{1, 0, 1})))
+	assert.InDelta(t, 2.0/3, m.Value(), 1e-12)
+
+	assert.Error(t, m.Update(vec([]float64{1, 0}), vec([]int{1, 0})))
+	assert.Error(t, m.Update(scores, vec([]int{0, 0, 3})))
+}
+
+func TestConfusionMatrix(t *testing.T) {
+	m := NewConfusionMatrix(3)
+	// two batches of different types
+	require.NoError(t, m.Update(vec([]int{0, 2, 1}), vec([]int{0, 1, 2})))
+	require.NoError(t, m.Update(vec([]float32{0, 0, 1}), vec([]int64{0, 1, 2})))
+
+	assert.Equal(t, [][]int{
+		{2, 0, 0},
+		{1, 0, 1},
+		{0, 2, 0},
+	}, m.Matrix())
+	assert.InDelta(t, 1.0/3, m.Accuracy(), 1e-12)
+
+	// the same as scikit-learn's
+	assert.InDelta(t, 2.0/9, m.Precision(Macro), 1e-12)
+	assert.InDelta(t, 1.0/3, m.Recall(Macro), 1e-12)
+	assert.InDelta(t, 0.8/3, m.F1(Macro), 1e-12)
+	assert.InDelta(t, 1.0/3, m.Precision(Micro), 1e-12)
+	assert.InDelta(t, 1.0/3, m.F1(Micro), 1e-12)
+	assert.InDelta(t, 2.0/9, m.Precision(Weighted), 1e-12)
+	assert.InDelta(t, 0.0, m.F1(Binary), 1e-12)
+
+	p, r, f := m.ClassScores(0)
+	assert.InDelta(t, 2.0/3, p, 1e-12)
+	assert.InDelta(t, 1.0, r, 1e-12)
+	assert.InDelta(t, 0.8, f, 1e-12)
+
+	assert.Error(t, m.Update(vec([]int{3}), vec([]int{0})))
+	m.Reset()
+	assert.Equal(t, 0.0, m.Accuracy())
+}
+
+func TestClassScores(t *testing.T) {
+	pred, target := vec([]float64{0.9, 0.8, 0.3, 0.1, 0.6}), vec([]float64{1, 0, 1, 0, 1})
+	p := NewPrecision(2, Binary)
+	r := NewRecall(2, Binary)
+	f := NewF1(2, Binary)
+	for _, m := range []Metric{p, r, f} {
+		require.NoError(t, m.Update(pred, target))
+	}
+	assert.InDelta(t, 2.0/3, p.Value(), 1e-12)
+	assert.InDelta(t, 2.0/3, r.Value(), 1e-12)
+	assert.InDelta(t, 2.0/3, f.Value(), 1e-12)
+
+	// a class that never occurs does not count towards the macro average
+	m := NewPrecision(4, Macro)
+	require.NoError(t, m.Update(pred, target))
+	assert.InDelta(t, (0.5+2.0/3)/2, m.Value(), 1e-12)
+}
+
+func TestROCAUC(t *testing.T) {
+	m := new(ROCAUC)
+	require.NoError(t, m.Update(vec([]float64{0.1, 0.4}), vec([]int{0, 0})))
+	assert.Equal(t, 0.0, m.Value())
+	require.NoError(t, m.Update(vec([]float32{0.35, 0.8}), vec([]int{1, 1})))
+	assert.InDelta(t, 0.75, m.Value(), 1e-12)
+
+	// ties count as half
+	m.Reset()
+	require.NoError(t, m.Update(mat(4, 2, []float64{0.5, 0.5, 0.5, 0.5, 0.8, 0.2, 0.1, 0.9}), vec([]int{0, 1, 0, 1})))
+	assert.InDelta(t, 0.875, m.Value(), 1e-12)
+
+	assert.Error(t, m.Update(vec([]float64{0.5}), vec([]int{2})))
+	assert.Error(t, m.Update(mat(1, 3, []float64{0.5, 0.2, 0.3}), vec([]int{1})))
+}
+
+func TestPRAUC(t *testing.T) {
+	m := new(PRAUC)
+	require.NoError(t, m.Update(vec([]float64{0.1, 0.4, 0.35, 0.8}), vec([]int{0, 0, 1, 1})))
+	assert.InDelta(t, 0.8333333333333333, m.Value(), 1e-12)
+
+	m.Reset()
+	assert.Equal(t, 0.0, m.Value())
+	require.NoError(t, m.Update(vec([]float64{0.5, 0.5, 0.2, 0.9}), vec([]int{0, 1, 0, 1})))
+	// thresholds 0.9: P=1, R=0.5; 0.5: P=2/3, R=1
+	assert.InDelta(t, 0.5*1+0.5*2.0/3, m.Value(), 1e-12)
+}
+
+func TestRegression(t *testing.T) {
+	mse, mae, r2 := new(MSE), new(MAE), new(R2)
+	for _, m := range []Metric{mse, mae, r2} {
+		require.NoError(t, m.Update(mat(2, 1, []float32{2.5, 0}), mat(2, 1, []float32{3, -0.5})))
+		require.NoError(t, m.Update(vec([]float64{2, 8}), vec([]int{2, 7})))
+	}
+	assert.InDelta(t, 0.375, mse.Value(), 1e-12)
+	assert.InDelta(t, 0.5, mae.Value(), 1e-12)
+	assert.InDelta(t, 0.9486081370449679, r2.Value(), 1e-12)
+
+	r2.Reset()
+	require.NoError(t, r2.Update(vec([]float64{1, 1}), vec([]float64{1, 1})))
+	assert.Equal(t, 1.0, r2.Value())
+
+	assert.Error(t, mse.Update(vec([]float64{1, 2}), vec([]float64{1})))
+	assert.Error(t, mse.Update(vec([]string{"a"}), vec([]float64{1})))
+}
+
+func TestScalars(t *testing.T) {
+	m := new(MAE)
+	require.NoError(t, m.Update(gorgonia.NewF64(1), gorgonia.NewF32(3)))
+	assert.Equal(t, 2.0, m.Value())
+}
@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+)
+
+// binaryScores collects the scores of binary predictions with their targets
+type binaryScores struct {
+	scores    []float64
+	positives []bool
+}
+
+// update adds a batch. See ROCAUC for the shapes.
+func (b *binaryScores) update(pred, target gorgonia.Value) error {
+	scores, err := floats(pred)
+	if err != nil {
+		return errors.Wrap(err, "Invalid predictions")
+	}
+	switch shp := pred.Shape(); {
+	case shp.Dims() == 2 && shp[1] == 2:
+		positives := make([]float64, shp[0])
+		for i := range positives {
+			positives[i] = scores[2*i+1]
+		}
+		scores = positives
+	case isScores(shp) || shp.Dims() > 2:
+		return errors.Errorf("Expected a score per example, or scores of shape (batch, 2). Got predictions of shape %v instead", shp)
+	}
+	t, err := labels(target)
+	if err != nil {
+		return errors.Wrap(err, "Invalid targets")
+	}
+	if err = sameLen(len(scores), len(t)); err != nil {
+		return err
+	}
+	for _, class := range t {
+		if class != 0 && class != 1 {
+			return errors.Errorf("Expected binary targets. Got %d", class)
+		}
+	}
+	b.scores = append(b.scores, scores...)
+	for _, class := range t {
+		b.positives = append(b.positives, class == 1)
+	}
+	return nil
+}
+
+func (b *binaryScores) reset() {
+	b.scores = b.scores[:0]
+	b.positives = b.positives[:0]
+}
+
+// curve calls fn with the cumulative true and false positives at every distinct threshold, from the highest score down.
+// It returns the total number of positives and negatives.
+func (b *binaryScores) curve(fn func(tp, fp int)) (positives, negatives int) {
+	order := make([]int, len(b.scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return b.scores[order[i]] > b.scores[order[j]] })
+
+	var tp, fp int
+	for i, idx := range order {
+		if b.positives[idx] {
+			tp++
+		} else {
+			fp++
+		}
+		// examples of equal scores are on the same threshold
+		if i == len(order)-1 || b.scores[order[i+1]] != b.scores[idx] {
+			fn(tp, fp)
+		}
+	}
+	return tp, fp
+}
+
+// ROCAUC is the area under the receiver operating characteristic curve of a binary classification.
+//
+// The predictions are a score per example, or a matrix of shape (batch, 2) whose second column is the score of the positive class.
+// The targets are 0 or 1.
+type ROCAUC struct {
+	binaryScores
+}
+
+// Update adds a batch of predictions and targets.
+func (m *ROCAUC) Update(pred, target gorgonia.Value) error { return m.update(pred, target) }
+
+// Reset forgets all the batches.
+func (m *ROCAUC) Reset() { m.reset() }
+
+// Value returns the ROC-AUC. It is 0 unless there are both positive and negative targets.
+func (m *ROCAUC) Value() float64 {
+	var area float64
+	var prevTP, prevFP int
+	positives, negatives := m.curve(func(tp, fp int) {
+		// the trapezoid between the previous and this threshold
+		area += float64(fp-prevFP) * float64(tp+prevTP) / 2
+		prevTP, prevFP = tp, fp
+	})
+	if positives == 0 || negatives == 0 {
+		return 0
+	}
+	return area / float64(positives) / float64(negatives)
+}
+
+// PRAUC is the area under the precision-recall curve of a binary classification, computed as the average precision:
+// the mean of the precisions at every threshold, weighted by the increase in recall.
+// The predictions and the targets are as for ROCAUC.
+type PRAUC struct {
+	binaryScores
+}
+
+// Update adds a batch of predictions and targets.
+func (m *PRAUC) Update(pred, target gorgonia.Value) error { return m.update(pred, target) }
+
+// Reset forgets all the batches.
+func (m *PRAUC) Reset() { m.reset() }
+
+// Value returns the PR-AUC. It is 0 if there are no positive targets.
+func (m *PRAUC) Value() float64 {
+	var sum float64
+	var prevTP int
+	positives, _ := m.curve(func(tp, fp int) {
+		sum += float64(tp-prevTP) * float64(tp) / float64(tp+fp)
+		prevTP = tp
+	})
+	if positives == 0 {
+		return 0
+	}
+	return sum / float64(positives)
+}
@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+)
+
+// errs returns the predictions and the targets as floats. They have to be of the same size.
+func errs(pred, target gorgonia.Value) (p, t []float64, err error) {
+	if p, err = floats(pred); err != nil {
+		return nil, nil, errors.Wrap(err, "Invalid predictions")
+	}
+	if t, err = floats(target); err != nil {
+		return nil, nil, errors.Wrap(err, "Invalid targets")
+	}
+	return p, t, sameLen(len(p), len(t))
+}
+
+// MSE is the mean squared error.
+type MSE struct {
+	sum float64
+	n   int
+}
+
+// Update adds a batch of predictions and targets.
+func (m *MSE) Update(pred, target gorgonia.Value) error {
+	p, t, err := errs(pred, target)
+	if err != nil {
+		return err
+	}
+	for i := range p {
+		d := p[i] - t[i]
+		m.sum += d * d
+	}
+	m.n += len(p)
+	return nil
+}
+
+// Reset forgets all the batches.
+func (m *MSE) Reset() { m.sum, m.n = 0, 0 }
+
+// Value returns the mean squared error, or 0 if there were no predictions.
+func (m *MSE) Value() float64 {
+	if m.n == 0 {
+		return 0
+	}
+	return m.sum / float64(m.n)
+}
+
+// MAE is the mean absolute error.
+type MAE struct {
+	sum float64
+	n   int
+}
+
+// Update adds a batch of predictions and targets.
+func (m *MAE) Update(pred, target gorgonia.Value) error {
+	p, t, err := errs(pred, target)
+	if err != nil {
+		return err
+	}
+	for i := range p {
+		m.sum += math.Abs(p[i] - t[i])
+	}
+	m.n += len(p)
+	return nil
+}
+
+// Reset forgets all the batches.
+func (m *MAE) Reset() { m.sum, m.n = 0, 0 }
+
+// Value returns the mean absolute error, or 0 if there were no predictions.
+func (m *MAE) Value() float64 {
+	if m.n == 0 {
+		return 0
+	}
+	return m.sum / float64(m.n)
+}
+
+// R2 is the coefficient of determination: 1 - Σ(target - prediction)² / Σ(target - mean target)².
+type R2 struct {
+	residuals  float64 // Σ(t - p)²
+	sum, sumSq float64 // Σt, Σt²
+	n          int
+}
+
+// Update adds a batch of predictions and targets.
+func (m *R2) Update(pred, target gorgonia.Value) error {
+	p, t, err := errs(pred, target)
+	if err != nil {
+		return err
+	}
+	for i := range p {
+		d := t[i] - p[i]
+		m.residuals += d * d
+		m.sum += t[i]
+		m.sumSq += t[i] * t[i]
+	}
+	m.n += len(p)
+	return nil
+}
+
+// Reset forgets all the batches.
+func (m *R2) Reset() { *m = R2{} }
+
+// Value returns R². If the targets are all equal, it is 1 for perfect predictions and 0 otherwise.
+func (m *R2) Value() float64 {
+	if m.n == 0 {
+		return 0
+	}
+	total := m.sumSq - m.sum*m.sum/float64(m.n)
+	if total <= 0 {
+		if m.residuals == 0 {
+			return 1
+		}
+		return 0
+	}
+	return 1 - m.residuals/total
+}
@@ -0,0 +1,34 @@
+package module
+
+import (
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// Embedding maps ids to learned vectors.
+type Embedding struct {
+	Table *gorgonia.Node // (vocabulary size, embedding size)
+
+	opts []gorgonia.EmbeddingOpt
+}
+
+// NewEmbedding creates an Embedding module with a table of size vectors of dims dimensions. The table is initialized with the weight initializer.
+// See WithEmbeddingOpts to set up the lookups.
+func NewEmbedding(s Scope, size, dims int, opts ...Opt) *Embedding {
+	c := newConfig(opts)
+	return &Embedding{
+		Table: s.MustParam("table", tensor.Shape{size, dims}, c.weightInit),
+		opts:  c.embeddingOpts,
+	}
+}
+
+// Forward looks up the vectors of ids, which is a tensor of tensor.Int. See gorgonia.Embedding.
+func (l *Embedding) Forward(inputs ...*gorgonia.Node) (*gorgonia.Node, error) {
+	if err := checkInputs("Embedding", 1, inputs); err != nil {
+		return nil, err
+	}
+	return gorgonia.Embedding(l.Table, inputs[0], l.opts...)
+}
+
+// Parameters returns the table.
+func (l *Embedding) Parameters() gorgonia.Nodes { return gorgonia.Nodes{l.Table} }
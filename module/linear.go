@@ -0,0 +1,114 @@
+package module
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// Linear is a fully connected layer: y = xW + b.
+type Linear struct {
+	Weight *gorgonia.Node // (in, out)
+	Bias   *gorgonia.Node // (1, out), or nil
+}
+
+// NewLinear creates a Linear module mapping in features to out features.
+func NewLinear(s Scope, in, out int, opts ...Opt) *Linear {
+	c := newConfig(opts)
+	l := &Linear{Weight: s.MustParam("weight", tensor.Shape{in, out}, c.weightInit)}
+	if !c.noBias {
+		l.Bias = s.MustParam("bias", tensor.Shape{1, out}, c.biasInit)
+	}
+	return l
+}
+
+// Forward applies the layer to x, whose last axis holds the features. Any leading axes are kept.
+func (l *Linear) Forward(inputs ...*gorgonia.Node) (*gorgonia.Node, error) {
+	if err := checkInputs("Linear", 1, inputs); err != nil {
+		return nil, err
+	}
+	x := inputs[0]
+	in, out := l.Weight.Shape()[0], l.Weight.Shape()[1]
+	shp := x.Shape()
+	if shp.Dims() == 0 || shp[shp.Dims()-1] != in {
+		return nil, errors.Errorf("Linear expects an input with %d features on its last axis. Got %v instead", in, shp)
+	}
+
+	// flatten the leading axes, so that the input is a matrix
+	var err error
+	if shp.Dims() != 2 {
+		if x, err = gorgonia.Reshape(x, tensor.Shape{shp.TotalSize() / in, in}); err != nil {
+			return nil, err
+		}
+	}
+	retVal, err := gorgonia.Mul(x, l.Weight)
+	if err != nil {
+		return nil, err
+	}
+	if l.Bias != nil {
+		if retVal, err = gorgonia.Auto(gorgonia.BroadcastAdd, retVal, l.Bias); err != nil {
+			return nil, err
+		}
+	}
+	if shp.Dims() != 2 {
+		outShp := shp.Clone()
+		outShp[outShp.Dims()-1] = out
+		return gorgonia.Reshape(retVal, outShp)
+	}
+	return retVal, nil
+}
+
+// Parameters returns the weight and the bias.
+func (l *Linear) Parameters() gorgonia.Nodes {
+	if l.Bias == nil {
+		return gorgonia.Nodes{l.Weight}
+	}
+	return gorgonia.Nodes{l.Weight, l.Bias}
+}
+
+// Conv2d is a 2D convolution over inputs of shape (batch, channels, height, width).
+type Conv2d struct {
+	Weight *gorgonia.Node // (out, in, kernel height, kernel width)
+	Bias   *gorgonia.Node // (1, out, 1, 1), or nil
+
+	stride, pad, dilation []int
+}
+
+// NewConv2d creates a Conv2d module from in channels to out channels. See WithStride, WithPad and WithDilation for the other parameters of the convolution.
+func NewConv2d(s Scope, in, out int, kernel tensor.Shape, opts ...Opt) *Conv2d {
+	c := newConfig(opts)
+	l := &Conv2d{
+		Weight:   s.MustParam("weight", tensor.Shape{out, in, kernel[0], kernel[1]}, c.weightInit),
+		stride:   c.stride,
+		pad:      c.pad,
+		dilation: c.dilation,
+	}
+	if !c.noBias {
+		l.Bias = s.MustParam("bias", tensor.Shape{1, out, 1, 1}, c.biasInit)
+	}
+	return l
+}
+
+// Forward convolves x.
+func (l *Conv2d) Forward(inputs ...*gorgonia.Node) (*gorgonia.Node, error) {
+	if err := checkInputs("Conv2d", 1, inputs); err != nil {
+		return nil, err
+	}
+	kernel := tensor.Shape(l.Weight.Shape()[2:])
+	retVal, err := gorgonia.Conv2d(inputs[0], l.Weight, kernel, l.pad, l.stride, l.dilation)
+	if err != nil {
+		return nil, err
+	}
+	if l.Bias != nil {
+		return gorgonia.Auto(gorgonia.BroadcastAdd, retVal, l.Bias)
+	}
+	return retVal, nil
+}
+
+// Parameters returns the weight and the bias.
+func (l *Conv2d) Parameters() gorgonia.Nodes {
+	if l.Bias == nil {
+		return gorgonia.Nodes{l.Weight}
+	}
+	return gorgonia.Nodes{l.Weight, l.Bias}
+}
@@ -0,0 +1,180 @@
+package module
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// lstmGate holds the parameters of one of the gates of an LSTM: gate = x·Wx + h·Wh + b
+type lstmGate struct {
+	wx, wh, b *gorgonia.Node
+}
+
+func newLSTMGate(s Scope, name string, in, hidden int, c *config) lstmGate {
+	g := lstmGate{
+		wx: s.MustParam("wx_"+name, tensor.Shape{in, hidden}, c.weightInit),
+		wh: s.MustParam("wh_"+name, tensor.Shape{hidden, hidden}, c.weightInit),
+	}
+	if !c.noBias {
+		g.b = s.MustParam("b_"+name, tensor.Shape{1, hidden}, c.biasInit)
+	}
+	return g
+}
+
+func (g lstmGate) apply(x, h *gorgonia.Node) (retVal *gorgonia.Node, err error) {
+	var xw, hw *gorgonia.Node
+	if xw, err = gorgonia.Mul(x, g.wx); err != nil {
+		return nil, err
+	}
+	if hw, err = gorgonia.Mul(h, g.wh); err != nil {
+		return nil, err
+	}
+	if retVal, err = gorgonia.Add(xw, hw); err != nil {
+		return nil, err
+	}
+	if g.b != nil {
+		retVal, err = gorgonia.Auto(gorgonia.BroadcastAdd, retVal, g.b)
+	}
+	return retVal, err
+}
+
+// LSTM is a long short-term memory layer.
+type LSTM struct {
+	// the input, forget and output gates, and the cell write
+	input, forget, output, cell lstmGate
+
+	inputSize, hiddenSize int
+	dt                    tensor.Dtype
+}
+
+// NewLSTM creates an LSTM module with inputs of inputSize features and a hidden state of hiddenSize features.
+func NewLSTM(s Scope, inputSize, hiddenSize int, opts ...Opt) *LSTM {
+	c := newConfig(opts)
+	return &LSTM{
+		input:      newLSTMGate(s, "i", inputSize, hiddenSize, c),
+		forget:     newLSTMGate(s, "f", inputSize, hiddenSize, c),
+		output:     newLSTMGate(s, "o", inputSize, hiddenSize, c),
+		cell:       newLSTMGate(s, "c", inputSize, hiddenSize, c),
+		inputSize:  inputSize,
+		hiddenSize: hiddenSize,
+		dt:         s.Dtype(),
+	}
+}
+
+// Step runs a single time step on x of shape (batch, inputSize), given the previous hidden and cell states of shape (batch, hiddenSize).
+func (l *LSTM) Step(x, prevHidden, prevCell *gorgonia.Node) (hidden, cell *gorgonia.Node, err error) {
+	var inputGate, forgetGate, outputGate, cellWrite *gorgonia.Node
+	if inputGate, err = l.input.apply(x, prevHidden); err != nil {
+		return nil, nil, err
+	}
+	if inputGate, err = gorgonia.Sigmoid(inputGate); err != nil {
+		return nil, nil, err
+	}
+	if forgetGate, err = l.forget.apply(x, prevHidden); err != nil {
+		return nil, nil, err
+	}
+	if forgetGate, err = gorgonia.Sigmoid(forgetGate); err != nil {
+		return nil, nil, err
+	}
+	if outputGate, err = l.output.apply(x, prevHidden); err != nil {
+		return nil, nil, err
+	}
+	if outputGate, err = gorgonia.Sigmoid(outputGate); err != nil {
+		return nil, nil, err
+	}
+	if cellWrite, err = l.cell.apply(x, prevHidden); err != nil {
+		return nil, nil, err
+	}
+	if cellWrite, err = gorgonia.Tanh(cellWrite); err != nil {
+		return nil, nil, err
+	}
+
+	// cell = forget ⊙ prevCell + input ⊙ cellWrite
+	var retain, write *gorgonia.Node
+	if retain, err = gorgonia.HadamardProd(forgetGate, prevCell); err != nil {
+		return nil, nil, err
+	}
+	if write, err = gorgonia.HadamardProd(inputGate, cellWrite); err != nil {
+		return nil, nil, err
+	}
+	if cell, err = gorgonia.Add(retain, write); err != nil {
+		return nil, nil, err
+	}
+	// hidden = output ⊙ tanh(cell)
+	if hidden, err = gorgonia.Tanh(cell); err != nil {
+		return nil, nil, err
+	}
+	if hidden, err = gorgonia.HadamardProd(outputGate, hidden); err != nil {
+		return nil, nil, err
+	}
+	return hidden, cell, nil
+}
+
+// Run runs the LSTM over x of shape (sequence length, batch, inputSize). If the initial hidden and cell states are nil, they are zeroes.
+// It returns the hidden states of all the time steps, of shape (sequence length, batch, hiddenSize), and the final hidden and cell states.
+func (l *LSTM) Run(x, h0, c0 *gorgonia.Node) (out, hidden, cell *gorgonia.Node, err error) {
+	shp := x.Shape()
+	if shp.Dims() != 3 || shp[2] != l.inputSize {
+		return nil, nil, nil, errors.Errorf("LSTM expects an input of shape (sequence length, batch, %d). Got %v instead", l.inputSize, shp)
+	}
+	steps, batch := shp[0], shp[1]
+	zeroes := func() *gorgonia.Node {
+		return gorgonia.NewConstant(tensor.New(tensor.Of(l.dt), tensor.WithShape(batch, l.hiddenSize)))
+	}
+	if hidden = h0; hidden == nil {
+		hidden = zeroes()
+	}
+	if cell = c0; cell == nil {
+		cell = zeroes()
+	}
+
+	hiddens := make(gorgonia.Nodes, steps)
+	for t := 0; t < steps; t++ {
+		var xt *gorgonia.Node
+		if xt, err = gorgonia.Slice(x, gorgonia.S(t)); err != nil {
+			return nil, nil, nil, err
+		}
+		if hidden, cell, err = l.Step(xt, hidden, cell); err != nil {
+			return nil, nil, nil, errors.Wrapf(err, "LSTM step %d", t)
+		}
+		if hiddens[t], err = gorgonia.Reshape(hidden, tensor.Shape{1, batch, l.hiddenSize}); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if steps == 1 {
+		return hiddens[0], hidden, cell, nil
+	}
+	if out, err = gorgonia.Concat(0, hiddens...); err != nil {
+		return nil, nil, nil, err
+	}
+	return out, hidden, cell, nil
+}
+
+// Forward runs the LSTM over a sequence x, optionally followed by the initial hidden and cell states. See Run.
+// It returns the hidden states of all the time steps.
+func (l *LSTM) Forward(inputs ...*gorgonia.Node) (*gorgonia.Node, error) {
+	var x, h0, c0 *gorgonia.Node
+	switch len(inputs) {
+	case 1:
+		x = inputs[0]
+	case 3:
+		x, h0, c0 = inputs[0], inputs[1], inputs[2]
+	default:
+		return nil, errors.Errorf("LSTM expects either 1 or 3 inputs. Got %d instead", len(inputs))
+	}
+	out, _, _, err := l.Run(x, h0, c0)
+	return out, err
+}
+
+// Parameters returns the weights and biases of the gates.
+func (l *LSTM) Parameters() gorgonia.Nodes {
+	var retVal gorgonia.Nodes
+	for _, g := range []lstmGate{l.input, l.forget, l.output, l.cell} {
+		retVal = append(retVal, g.wx, g.wh)
+		if g.b != nil {
+			retVal = append(retVal, g.b)
+		}
+	}
+	return retVal
+}
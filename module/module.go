@@ -0,0 +1,69 @@
+// Package module provides building blocks for neural networks: modules that own their parameters and know how to apply themselves to their inputs.
+//
+// Modules create their parameters in a Scope, which gives them hierarchical names such as "mlp.fc1.weight".
+// The parameters of a module are grouped (see gorgonia.WithGroupName) by the name of the module that owns them.
+//
+// A typical multilayer perceptron looks like this:
+//		s := module.NewScope(g, tensor.Float32, "mlp")
+//		mlp := module.NewSequential(
+//			module.NewLinear(s.Sub("fc1"), 784, 128),
+//			module.Func(gorgonia.Rectify),
+//			module.NewLinear(s.Sub("fc2"), 128, 10),
+//		)
+//		logits, err := mlp.Forward(x)
+//		...
+//		if _, err = gorgonia.Grad(cost, mlp.Parameters()...); err != nil { ... }
+//		...
+//		solver.Step(gorgonia.NodesToValueGrads(mlp.Parameters()))
+package module
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+)
+
+// Module is a part of a neural network.
+type Module interface {
+	// Forward applies the module to its inputs, adding the computation to the graph.
+	Forward(inputs ...*gorgonia.Node) (*gorgonia.Node, error)
+
+	// Parameters returns the learnable parameters of the module, including those of its submodules.
+	Parameters() gorgonia.Nodes
+}
+
+// TrainingSetter is implemented by modules that behave differently in training and in inference, such as BatchNorm and Dropout.
+type TrainingSetter interface {
+	SetTraining(training bool) error
+}
+
+// SetTraining puts a module and its submodules in training or in inference mode. Modules that do not implement TrainingSetter are left alone.
+//
+// The mode applies to the graphs that are already built. Creating a tape machine resets the mode of the ops in its graph
+// (see gorgonia.EvalMode), so SetTraining should be called after the machines are created.
+func SetTraining(m Module, training bool) error {
+	if ts, ok := m.(TrainingSetter); ok {
+		return ts.SetTraining(training)
+	}
+	return nil
+}
+
+// Func is a Module without parameters, made of a function of a single node. It is typically an activation, such as gorgonia.Rectify.
+type Func func(x *gorgonia.Node) (*gorgonia.Node, error)
+
+// Forward applies the function to its single input.
+func (f Func) Forward(inputs ...*gorgonia.Node) (*gorgonia.Node, error) {
+	if err := checkInputs("Func", 1, inputs); err != nil {
+		return nil, err
+	}
+	return f(inputs[0])
+}
+
+// Parameters returns nil, as a Func has no parameters.
+func (f Func) Parameters() gorgonia.Nodes { return nil }
+
+func checkInputs(module string, n int, inputs []*gorgonia.Node) error {
+	if len(inputs) != n {
+		return errors.Errorf("%s expects %d input(s). Got %d instead", module, n, len(inputs))
+	}
+	return nil
+}
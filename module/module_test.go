@@ -0,0 +1,202 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+func names(ns gorgonia.Nodes) []string {
+	retVal := make([]string, len(ns))
+	for i, n := range ns {
+		retVal[i] = n.Name()
+	}
+	return retVal
+}
+
+func TestScope(t *testing.T) {
+	g := gorgonia.NewGraph()
+	s := NewScope(g, tensor.Float64, "net")
+	enc := s.Sub("encoder")
+	assert.Equal(t, "net.encoder", enc.Name())
+	assert.Equal(t, "net.encoder.0", enc.Sub("0").Name())
+	assert.Equal(t, "fc", NewScope(g, tensor.Float64, "").Sub("fc").Name())
+
+	w, err := enc.Param("weight", tensor.Shape{2, 3}, gorgonia.Zeroes())
+	require.NoError(t, err)
+	assert.Equal(t, "net.encoder.weight", w.Name())
+	assert.Equal(t, tensor.Shape{2, 3}, w.Shape())
+	assert.Equal(t, tensor.Float64, w.Dtype())
+	assert.NotNil(t, w.Value())
+
+	_, err = enc.Param("weight", tensor.Shape{2, 3}, gorgonia.Zeroes())
+	assert.Error(t, err)
+	assert.Panics(t, func() { enc.MustParam("weight", tensor.Shape{2, 3}, gorgonia.Zeroes()) })
+}
+
+func TestLinear(t *testing.T) {
+	g := gorgonia.NewGraph()
+	s := NewScope(g, tensor.Float64, "fc")
+	l := NewLinear(s, 3, 2, WithWeightInit(gorgonia.RangedFrom(0)), WithBiasInit(gorgonia.Ones()))
+	assert.Equal(t, []string{"fc.weight", "fc.bias"}, names(l.Parameters()))
+
+	// leading axes are kept
+	x := gorgonia.NewTensor(g, tensor.Float64, 3, gorgonia.WithShape(2, 2, 3), gorgonia.WithName("x"), gorgonia.WithInit(gorgonia.RangedFrom(0)))
+	y, err := l.Forward(x)
+	require.NoError(t, err)
+	assert.Equal(t, tensor.Shape{2, 2, 2}, y.Shape())
+
+	m := gorgonia.NewTapeMachine(g)
+	defer m.Close()
+	require.NoError(t, m.RunAll())
+	// W = [[0 1] [2 3] [4 5]]
+	assert.Equal(t, []float64{11, 14, 29, 41, 47, 68, 65, 95}, y.Value().Data())
+
+	_, err = l.Forward(x, x)
+	assert.Error(t, err)
+	x2 := gorgonia.NewMatrix(g, tensor.Float64, gorgonia.WithShape(2, 4), gorgonia.WithName("x2"))
+	_, err = l.Forward(x2)
+	assert.Error(t, err)
+
+	nb := NewLinear(s.Sub("nobias"), 3, 2, WithoutBias())
+	assert.Len(t, nb.Parameters(), 1)
+}
+
+func TestConv2d(t *testing.T) {
+	g := gorgonia.NewGraph()
+	s := NewScope(g, tensor.Float32, "conv")
+	l := NewConv2d(s, 3, 4, tensor.Shape{3, 3}, WithPad(1, 1), WithStride(2, 2))
+	assert.Equal(t, tensor.Shape{4, 3, 3, 3}, l.Weight.Shape())
+	assert.Equal(t, tensor.Shape{1, 4, 1, 1}, l.Bias.Shape())
+
+	x := gorgonia.NewTensor(g, tensor.Float32, 4, gorgonia.WithShape(2, 3, 8, 8), gorgonia.WithName("x"), gorgonia.WithInit(gorgonia.GlorotN(1)))
+	y, err := l.Forward(x)
+	require.NoError(t, err)
+	assert.Equal(t, tensor.Shape{2, 4, 4, 4}, y.Shape())
+
+	m := gorgonia.NewTapeMachine(g)
+	defer m.Close()
+	require.NoError(t, m.RunAll())
+}
+
+func TestBatchNormAndDropout(t *testing.T) {
+	g := gorgonia.NewGraph()
+	s := NewScope(g, tensor.Float64, "")
+	bn := NewBatchNorm(s.Sub("bn"), 2)
+	drop := NewDropout(0.5)
+	seq := NewSequential(bn, drop)
+	assert.Equal(t, []string{"bn.scale", "bn.bias"}, names(seq.Parameters()))
+
+	x := gorgonia.NewMatrix(g, tensor.Float64, gorgonia.WithShape(4, 2), gorgonia.WithName("x"), gorgonia.WithInit(gorgonia.RangedFrom(0)))
+	y, err := seq.Forward(x)
+	require.NoError(t, err)
+	assert.Equal(t, tensor.Shape{4, 2}, y.Shape())
+
+	ones := gorgonia.NewMatrix(g, tensor.Float64, gorgonia.WithShape(50, 2), gorgonia.WithName("ones"), gorgonia.WithInit(gorgonia.Ones()))
+	z, err := drop.Forward(ones)
+	require.NoError(t, err)
+
+	m := gorgonia.NewTapeMachine(g)
+	defer m.Close()
+	require.NoError(t, m.RunAll())
+	assert.Contains(t, z.Value().Data(), 0.0)
+	m.Reset()
+
+	// the mode is switched in the graph that is already built: in inference mode, dropout passes its input through
+	require.NoError(t, SetTraining(seq, false))
+	assert.False(t, drop.training)
+	assert.False(t, bn.training)
+	require.NoError(t, m.RunAll())
+	assert.Equal(t, ones.Value().Data(), z.Value().Data())
+	m.Reset()
+
+	require.NoError(t, SetTraining(seq, true))
+	require.NoError(t, m.RunAll())
+	assert.Contains(t, z.Value().Data(), 0.0)
+
+	x4 := gorgonia.NewTensor(g, tensor.Float64, 4, gorgonia.WithShape(2, 3, 2, 2), gorgonia.WithName("x4"))
+	_, err = bn.Forward(x4)
+	assert.Error(t, err)
+}
+
+func TestEmbedding(t *testing.T) {
+	g := gorgonia.NewGraph()
+	s := NewScope(g, tensor.Float32, "emb")
+	l := NewEmbedding(s, 10, 4, WithEmbeddingOpts(gorgonia.WithDenseGrad()))
+	assert.Equal(t, []string{"emb.table"}, names(l.Parameters()))
+
+	ids := gorgonia.NewTensor(g, tensor.Int, 2, gorgonia.WithShape(2, 3), gorgonia.WithName("ids"), gorgonia.WithValue(tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]int{0, 1, 2, 3, 4, 9}))))
+	y, err := l.Forward(ids)
+	require.NoError(t, err)
+	assert.Equal(t, tensor.Shape{2, 3, 4}, y.Shape())
+}
+
+func TestLSTM(t *testing.T) {
+	g := gorgonia.NewGraph()
+	s := NewScope(g, tensor.Float64, "lstm")
+	l := NewLSTM(s, 3, 1)
+	assert.Len(t, l.Parameters(), 12)
+	assert.Equal(t, "lstm.wx_i", l.Parameters()[0].Name())
+
+	x := gorgonia.NewTensor(g, tensor.Float64, 3, gorgonia.WithShape(4, 2, 3), gorgonia.WithName("x"), gorgonia.WithInit(gorgonia.GlorotN(1)))
+	out, h, c, err := l.Run(x, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, tensor.Shape{4, 2, 1}, out.Shape())
+	assert.Equal(t, tensor.Shape{2, 1}, h.Shape())
+	assert.Equal(t, tensor.Shape{2, 1}, c.Shape())
+
+	cost, err := gorgonia.Sum(out)
+	require.NoError(t, err)
+	_, err = gorgonia.Grad(cost, l.Parameters()...)
+	require.NoError(t, err)
+
+	m := gorgonia.NewTapeMachine(g, gorgonia.BindDualValues(l.Parameters()...))
+	defer m.Close()
+	require.NoError(t, m.RunAll())
+
+	// the last step of the output is the final hidden state
+	data := out.Value().Data().([]float64)
+	assert.Equal(t, h.Value().Data(), data[len(data)-2:])
+
+	_, err = l.Forward(x, h)
+	assert.Error(t, err)
+}
+
+// TestTraining trains a small network with all its parameters handed to a solver in one call.
+func TestTraining(t *testing.T) {
+	g := gorgonia.NewGraph()
+	s := NewScope(g, tensor.Float64, "mlp")
+	mlp := NewSequential(
+		NewLinear(s.Sub("fc1"), 2, 8),
+		Func(gorgonia.Tanh),
+	)
+	mlp.Add(NewLinear(s.Sub("fc2"), 8, 1))
+	assert.Equal(t, []string{"mlp.fc1.weight", "mlp.fc1.bias", "mlp.fc2.weight", "mlp.fc2.bias"}, names(mlp.Parameters()))
+
+	// XOR
+	x := gorgonia.NewMatrix(g, tensor.Float64, gorgonia.WithShape(4, 2), gorgonia.WithName("x"), gorgonia.WithValue(tensor.New(tensor.WithShape(4, 2), tensor.WithBacking([]float64{0, 0, 0, 1, 1, 0, 1, 1}))))
+	y := gorgonia.NewMatrix(g, tensor.Float64, gorgonia.WithShape(4, 1), gorgonia.WithName("y"), gorgonia.WithValue(tensor.New(tensor.WithShape(4, 1), tensor.WithBacking([]float64{0, 1, 1, 0}))))
+	pred, err := mlp.Forward(x)
+	require.NoError(t, err)
+	cost := gorgonia.Must(gorgonia.Mean(gorgonia.Must(gorgonia.Square(gorgonia.Must(gorgonia.Sub(pred, y))))))
+	_, err = gorgonia.Grad(cost, mlp.Parameters()...)
+	require.NoError(t, err)
+
+	m := gorgonia.NewTapeMachine(g, gorgonia.BindDualValues(mlp.Parameters()...))
+	defer m.Close()
+	solver := gorgonia.NewAdamSolver(gorgonia.WithLearnRate(0.05))
+	var first, last float64
+	for i := 0; i < 300; i++ {
+		require.NoError(t, m.RunAll())
+		if i == 0 {
+			first = cost.Value().Data().(float64)
+		}
+		last = cost.Value().Data().(float64)
+		require.NoError(t, solver.Step(gorgonia.NodesToValueGrads(mlp.Parameters())))
+		m.Reset()
+	}
+	assert.True(t, last < first/10, "cost went from %v to %v", first, last)
+}
@@ -0,0 +1,128 @@
+package module
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// BatchNorm normalizes its input over the batch, for each channel. The input is a matrix of shape (batch, channels)
+// or a tensor of shape (batch, channels, height, width).
+//
+// A BatchNorm is created in training mode. Call SetTraining(false) to normalize with the running statistics instead.
+type BatchNorm struct {
+	Scale *gorgonia.Node // (1, channels, 1, 1)
+	Bias  *gorgonia.Node // (1, channels, 1, 1)
+
+	momentum, epsilon float64
+	training          bool
+	ops               []*gorgonia.BatchNormOp
+}
+
+// NewBatchNorm creates a BatchNorm module for the given number of channels. The scale is initialized with ones and the bias with zeroes.
+func NewBatchNorm(s Scope, channels int, opts ...Opt) *BatchNorm {
+	c := newConfig(opts)
+	return &BatchNorm{
+		Scale:    s.MustParam("scale", tensor.Shape{1, channels, 1, 1}, gorgonia.Ones()),
+		Bias:     s.MustParam("bias", tensor.Shape{1, channels, 1, 1}, c.biasInit),
+		momentum: c.momentum,
+		epsilon:  c.epsilon,
+		training: true,
+	}
+}
+
+// Forward normalizes x.
+func (l *BatchNorm) Forward(inputs ...*gorgonia.Node) (*gorgonia.Node, error) {
+	if err := checkInputs("BatchNorm", 1, inputs); err != nil {
+		return nil, err
+	}
+	x := inputs[0]
+	channels := l.Scale.Shape()[1]
+	if (x.Dims() != 2 && x.Dims() != 4) || x.Shape()[1] != channels {
+		return nil, errors.Errorf("BatchNorm expects an input of shape (batch, %d) or (batch, %d, height, width). Got %v instead", channels, channels, x.Shape())
+	}
+
+	scale, bias := l.Scale, l.Bias
+	if x.Dims() == 2 {
+		var err error
+		if scale, err = gorgonia.Reshape(scale, tensor.Shape{1, channels}); err != nil {
+			return nil, err
+		}
+		if bias, err = gorgonia.Reshape(bias, tensor.Shape{1, channels}); err != nil {
+			return nil, err
+		}
+	}
+	retVal, _, _, op, err := gorgonia.BatchNorm(x, scale, bias, l.momentum, l.epsilon)
+	if err != nil {
+		return nil, err
+	}
+	if err = op.SetTraining(l.training); err != nil {
+		return nil, err
+	}
+	l.ops = append(l.ops, op)
+	return retVal, nil
+}
+
+// Parameters returns the scale and the bias.
+func (l *BatchNorm) Parameters() gorgonia.Nodes { return gorgonia.Nodes{l.Scale, l.Bias} }
+
+// SetTraining switches between normalizing with the statistics of the batch (and updating the running statistics),
+// and normalizing with the running statistics. It applies to the graphs that have already been built as well.
+func (l *BatchNorm) SetTraining(training bool) error {
+	l.training = training
+	for _, op := range l.ops {
+		if err := op.SetTraining(training); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Dropout randomly zeroes out its input with a given probability during training.
+//
+// A Dropout is created in training mode. Call SetTraining(false) to pass the input through unchanged instead.
+type Dropout struct {
+	Probability float64
+	training    bool
+	ops         []gorgonia.TrainModeOp
+}
+
+// NewDropout creates a Dropout module in training mode.
+func NewDropout(probability float64) *Dropout {
+	return &Dropout{Probability: probability, training: true}
+}
+
+// Forward applies dropout to x.
+func (l *Dropout) Forward(inputs ...*gorgonia.Node) (*gorgonia.Node, error) {
+	if err := checkInputs("Dropout", 1, inputs); err != nil {
+		return nil, err
+	}
+	if l.Probability == 0 {
+		return inputs[0], nil
+	}
+	retVal, err := gorgonia.Dropout(inputs[0], l.Probability)
+	if err != nil {
+		return nil, err
+	}
+	op := retVal.Op().(gorgonia.TrainModeOp)
+	if err = op.SetTraining(l.training); err != nil {
+		return nil, err
+	}
+	l.ops = append(l.ops, op)
+	return retVal, nil
+}
+
+// Parameters returns nil, as a Dropout has no parameters.
+func (l *Dropout) Parameters() gorgonia.Nodes { return nil }
+
+// SetTraining switches between dropping out and passing the input through unchanged.
+// It applies to the graphs that have already been built as well.
+func (l *Dropout) SetTraining(training bool) error {
+	l.training = training
+	for _, op := range l.ops {
+		if err := op.SetTraining(training); err != nil {
+			return err
+		}
+	}
+	return nil
+}
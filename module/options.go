@@ -0,0 +1,82 @@
+package module
+
+import "gorgonia.org/gorgonia"
+
+// Opt is a construction option for the modules. Options that do not apply to a module are ignored by it.
+type Opt func(*config)
+
+type config struct {
+	weightInit gorgonia.InitWFn
+	biasInit   gorgonia.InitWFn
+	noBias     bool
+
+	// Conv2d
+	stride, pad, dilation []int
+
+	// BatchNorm
+	momentum, epsilon float64
+
+	// Embedding
+	embeddingOpts []gorgonia.EmbeddingOpt
+}
+
+func newConfig(opts []Opt) *config {
+	c := &config{
+		weightInit: gorgonia.GlorotN(1),
+		biasInit:   gorgonia.Zeroes(),
+		stride:     []int{1, 1},
+		pad:        []int{0, 0},
+		dilation:   []int{1, 1},
+		momentum:   0.9,
+		epsilon:    1e-5,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithWeightInit sets how the weights are initialized. The default is GlorotN(1).
+func WithWeightInit(fn gorgonia.InitWFn) Opt {
+	return func(c *config) { c.weightInit = fn }
+}
+
+// WithBiasInit sets how the biases are initialized. The default is Zeroes().
+func WithBiasInit(fn gorgonia.InitWFn) Opt {
+	return func(c *config) { c.biasInit = fn }
+}
+
+// WithoutBias creates a Linear, Conv2d or LSTM module without biases.
+func WithoutBias() Opt {
+	return func(c *config) { c.noBias = true }
+}
+
+// WithStride sets the stride of a Conv2d. The default is 1 along both axes.
+func WithStride(height, width int) Opt {
+	return func(c *config) { c.stride = []int{height, width} }
+}
+
+// WithPad sets the padding of a Conv2d. The default is 0 along both axes.
+func WithPad(height, width int) Opt {
+	return func(c *config) { c.pad = []int{height, width} }
+}
+
+// WithDilation sets the dilation of a Conv2d. The default is 1 along both axes.
+func WithDilation(height, width int) Opt {
+	return func(c *config) { c.dilation = []int{height, width} }
+}
+
+// WithMomentum sets the momentum of the running statistics of a BatchNorm. The default is 0.9.
+func WithMomentum(momentum float64) Opt {
+	return func(c *config) { c.momentum = momentum }
+}
+
+// WithEpsilon sets the epsilon a BatchNorm adds to the variance. The default is 1e-5.
+func WithEpsilon(epsilon float64) Opt {
+	return func(c *config) { c.epsilon = epsilon }
+}
+
+// WithEmbeddingOpts passes options on to gorgonia.Embedding.
+func WithEmbeddingOpts(opts ...gorgonia.EmbeddingOpt) Opt {
+	return func(c *config) { c.embeddingOpts = append(c.embeddingOpts, opts...) }
+}
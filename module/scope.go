@@ -0,0 +1,61 @@
+package module
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// Scope is where modules create their parameters. It holds the graph, the Dtype of the parameters and the hierarchical name of a module.
+type Scope struct {
+	g    *gorgonia.ExprGraph
+	dt   tensor.Dtype
+	name string
+}
+
+// NewScope creates a root scope. The name may be empty, in which case the names of the submodules are used as they are.
+func NewScope(g *gorgonia.ExprGraph, dt tensor.Dtype, name string) Scope {
+	return Scope{g: g, dt: dt, name: name}
+}
+
+// Graph returns the graph the parameters are created in.
+func (s Scope) Graph() *gorgonia.ExprGraph { return s.g }
+
+// Dtype returns the Dtype of the parameters.
+func (s Scope) Dtype() tensor.Dtype { return s.dt }
+
+// Name returns the hierarchical name of the scope.
+func (s Scope) Name() string { return s.name }
+
+// Sub returns the scope of a submodule. Its name is joined to the name of the scope with a ".".
+func (s Scope) Sub(name string) Scope {
+	s.name = s.join(name)
+	return s
+}
+
+// Param creates a parameter named after the scope, initialized with init. The parameter is grouped by the name of the scope.
+//
+// An error is returned if the graph already has a node of the same name, as that usually means two modules were given the same scope.
+func (s Scope) Param(name string, shape tensor.Shape, init gorgonia.InitWFn) (*gorgonia.Node, error) {
+	name = s.join(name)
+	if len(s.g.ByName(name)) > 0 {
+		return nil, errors.Errorf("A node named %q already exists in the graph", name)
+	}
+	return gorgonia.NewTensor(s.g, s.dt, shape.Dims(), gorgonia.WithShape(shape...), gorgonia.WithName(name), gorgonia.WithGroupName(s.name), gorgonia.WithInit(init)), nil
+}
+
+// MustParam is Param, but it panics instead of returning an error. The constructors of the modules use it.
+func (s Scope) MustParam(name string, shape tensor.Shape, init gorgonia.InitWFn) *gorgonia.Node {
+	n, err := s.Param(name, shape, init)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+func (s Scope) join(name string) string {
+	if s.name == "" {
+		return name
+	}
+	return s.name + "." + name
+}
@@ -0,0 +1,57 @@
+package module
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+)
+
+// Sequential chains modules: the output of each module is the input of the next one.
+type Sequential struct {
+	Modules []Module
+}
+
+// NewSequential creates a Sequential module out of the given modules.
+func NewSequential(modules ...Module) *Sequential {
+	return &Sequential{Modules: modules}
+}
+
+// Add appends modules to the chain.
+func (s *Sequential) Add(modules ...Module) {
+	s.Modules = append(s.Modules, modules...)
+}
+
+// Forward passes the inputs to the first module, and its output through the rest of the modules.
+func (s *Sequential) Forward(inputs ...*gorgonia.Node) (*gorgonia.Node, error) {
+	if len(s.Modules) == 0 {
+		return nil, errors.New("Sequential has no modules")
+	}
+	retVal, err := s.Modules[0].Forward(inputs...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Sequential module 0")
+	}
+	for i, m := range s.Modules[1:] {
+		if retVal, err = m.Forward(retVal); err != nil {
+			return nil, errors.Wrapf(err, "Sequential module %d", i+1)
+		}
+	}
+	return retVal, nil
+}
+
+// Parameters returns the parameters of all the modules, in order.
+func (s *Sequential) Parameters() gorgonia.Nodes {
+	var retVal gorgonia.Nodes
+	for _, m := range s.Modules {
+		retVal = append(retVal, m.Parameters()...)
+	}
+	return retVal
+}
+
+// SetTraining sets the mode of all the modules.
+func (s *Sequential) SetTraining(training bool) error {
+	for _, m := range s.Modules {
+		if err := SetTraining(m, training); err != nil {
+			return err
+		}
+	}
+	return nil
+}
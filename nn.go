@@ -160,6 +160,29 @@ func Rectify(x *Node) (retVal *Node, err error) {
 	return HadamardProd(x, retVal)
 }
 
+// PReLU is a leaky rectifier whose slope for the negative values is learnt: max(0, x) + slope * min(0, x).
+// slope is either a scalar, or has as many dimensions as x, with a size of 1 along the axes where the slope is shared.
+// For example, a slope of shape (1, C, 1, 1) learns a slope per channel of a BCHW input.
+func PReLU(x, slope *Node) (retVal *Node, err error) {
+	var pos, neg *Node
+	if pos, err = Rectify(x); err != nil {
+		return nil, err
+	}
+	if neg, err = Sub(x, pos); err != nil {
+		return nil, errors.Wrap(err, subFail)
+	}
+
+	if slope.IsScalar() {
+		neg, err = HadamardProd(neg, slope)
+	} else {
+		neg, err = Auto(BroadcastHadamardProd, neg, slope)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, hadamardProdFail)
+	}
+	return Add(pos, neg)
+}
+
 // Im2Col converts a BCHW image block to columns. The kernel, pad and stride parameter must be shape of size 2, no more no less
 // This poor naming scheme clearly comes from matlab
 func Im2Col(n *Node, kernel, pad, stride, dilation tensor.Shape) (retVal *Node, err error) {
@@ -725,3 +725,39 @@ func TestConv2dErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestPReLU(t *testing.T) {
+	c := require.New(t)
+
+	g := NewGraph()
+	x := NewTensor(g, Float64, 4, WithShape(1, 2, 1, 2), WithName("x"), WithValue(tensor.New(tensor.WithShape(1, 2, 1, 2), tensor.WithBacking([]float64{-1, 2, -3, 4}))))
+	slope := NewTensor(g, Float64, 4, WithShape(1, 2, 1, 1), WithName("slope"), WithValue(tensor.New(tensor.WithShape(1, 2, 1, 1), tensor.WithBacking([]float64{0.1, 0.5}))))
+	y, err := PReLU(x, slope)
+	c.NoError(err)
+	cost := Must(Sum(y))
+	_, err = Grad(cost, x, slope)
+	c.NoError(err)
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	c.NoError(m.RunAll())
+
+	c.InDeltaSlice([]float64{-0.1, 2, -1.5, 4}, y.Value().Data(), 1e-6)
+	xG, err := x.Grad()
+	c.NoError(err)
+	c.InDeltaSlice([]float64{0.1, 1, 0.5, 1}, xG.Data(), 1e-6)
+	sG, err := slope.Grad()
+	c.NoError(err)
+	c.InDeltaSlice([]float64{-1, -3}, sG.Data(), 1e-6)
+
+	// a scalar slope
+	h := NewGraph()
+	a := NewVector(h, Float32, WithShape(3), WithName("a"), WithValue(tensor.New(tensor.WithShape(3), tensor.WithBacking([]float32{-2, 0, 2}))))
+	s := NewScalar(h, Float32, WithName("s"), WithValue(float32(0.25)))
+	b, err := PReLU(a, s)
+	c.NoError(err)
+	m2 := NewTapeMachine(h)
+	defer m2.Close()
+	c.NoError(m2.RunAll())
+	c.Equal([]float32{-0.5, 0, 2}, b.Value().Data())
+}
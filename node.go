@@ -33,7 +33,8 @@ type Node struct {
 	// the grouping notion is only useful for exporting to another format
 	groups encoding.Groups
 
-	g *ExprGraph // this node belongs in this graph
+	g      *ExprGraph // this node belongs in this graph
+	source string     // file:line of the code that created the node
 
 	// value bondage
 	// inputs are bound to values directly
@@ -291,6 +292,8 @@ func NewUniqueNode(opts ...NodeConsOpt) *Node {
 	m := n.g.AddNode(n)
 	if n != m {
 		returnNode(n)
+	} else if !m.g.noSources {
+		m.source = callerSource()
 	}
 	m.fixEdges()
 	return m
@@ -416,6 +419,7 @@ func (n *Node) Clone() (retVal interface{}) {
 	// other things
 	n2.name = n.name
 	n2.group = n.group
+	n2.source = n.source
 	n2.dataOn = n.dataOn
 	n2.hash = n.hash
 
@@ -190,14 +190,15 @@ func ApplyOp(op Op, children ...*Node) (retVal *Node, err error) {
 	defer leaveLogScope()
 	var retType hm.Type
 	if retType, err = inferNodeType(op, children...); err != nil {
-		return nil, errors.Wrapf(err, "Type inference error. Op: %v. Children: %#Y, OpType:%v", op, Nodes(children), op.Type())
+		setErrContext(err, op, nil, Nodes(children))
+		return nil, errors.Wrapf(err, "Type inference error. Op: %v. Children: %#Y, OpType:%v%s", op, Nodes(children), op.Type(), applySource(g))
 	}
 	typeSysLogf("Done inferring. Return type is: %#v(%T)", retType, retType)
 
 	// infer shapes, but print errors instead of returning
 	shapeLogf("op: %v(%T) inferring shape", op, op)
 	if err = checkArity(op, len(children)); err != nil {
-		return
+		return nil, errors.Wrapf(err, "Arity check failed%s", applySource(g))
 	}
 
 	ds := Nodes(children).dimSizers()
@@ -206,7 +207,8 @@ func ApplyOp(op Op, children ...*Node) (retVal *Node, err error) {
 		shapeLogf("inferred shape %v", s)
 		retVal = NewUniqueNode(WithType(retType), WithOp(op), WithChildren(children), In(g), WithShape(s...))
 	} else {
-		err = errors.Wrapf(err, "Failed to infer shape. Op: %v", op)
+		setErrContext(err, op, nil, Nodes(children))
+		err = errors.Wrapf(err, "Failed to infer shape. Op: %v%s", op, applySource(g))
 		// retVal = newUniqueNode(withType(retType), withOp(op), withChildren(children), withGraph(g))
 	}
 	returnDimSizers(ds)
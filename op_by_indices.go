@@ -80,7 +80,7 @@ func (op *byIndicesOp) checkInput(inputs ...Value) (x, indices tensor.Tensor, er
 	}
 
 	if indices.Dtype() != tensor.Int {
-		return nil, nil, errors.Errorf("Expected indices to have tensor.Int as a Dtype. Got %T instead", indices.Dtype())
+		return nil, nil, newDtypeMismatch(tensor.Int, indices.Dtype(), "Expected indices to have tensor.Int as a Dtype. Got %v instead", indices.Dtype())
 	}
 
 	return x, indices, nil
@@ -8,19 +8,25 @@ import (
 
 	"github.com/chewxy/hm"
 	"github.com/chewxy/math32"
+	"github.com/pkg/errors"
 	"gorgonia.org/tensor"
 )
 
+// Reduction describes how the per-element (or per-sample) losses of a loss function are reduced.
 type Reduction uint
 
 const (
 	ReductionMean Reduction = iota
 	ReductionSum
+	ReductionNone // the losses are returned as is. Not supported by CTCLoss
 )
 
 // CTCLoss -  implements the ctc loss operation
 // This is the implementation of the following paper: http://www.cs.toronto.edu/~graves/icml_2006.pdf
 func CTCLoss(logProbs, targets, inputLengths, targetLengths *Node, reduction Reduction) (*Node, error) {
+	if reduction == ReductionNone {
+		return nil, errors.Errorf(nyiFail, "CTCLoss", "ReductionNone")
+	}
 	op := newCTCLossOp(logProbs.Dtype(), targets.Shape().Dims(), reduction)
 
 	output, err := ApplyOp(op, logProbs, targets, inputLengths, targetLengths)
@@ -99,17 +105,17 @@ func (op *ctcLossOp) UsePreallocDo(prealloc Value, inputs ...Value) (Value, erro
 	logProbsT := inputs[0].(*tensor.Dense)
 	targetsT := inputs[1].(*tensor.Dense)
 	if targetsT.Dtype() != tensor.Int {
-		return nil, fmt.Errorf("invalid type %v for targets. it should be Int", targetsT.Dtype())
+		return nil, newDtypeMismatch(tensor.Int, targetsT.Dtype(), "invalid type %v for targets. it should be Int", targetsT.Dtype())
 	}
 
 	inputLengthsT := inputs[2].(*tensor.Dense)
 	if inputLengthsT.Dtype() != tensor.Int {
-		return nil, fmt.Errorf("invalid type %v for inputLengths. it should be Int", inputLengthsT.Dtype())
+		return nil, newDtypeMismatch(tensor.Int, inputLengthsT.Dtype(), "invalid type %v for inputLengths. it should be Int", inputLengthsT.Dtype())
 	}
 
 	targetLengthsT := inputs[3].(*tensor.Dense)
 	if targetLengthsT.Dtype() != tensor.Int {
-		return nil, fmt.Errorf("invalid type %v for inputLengths. it should be Int", targetLengthsT.Dtype())
+		return nil, newDtypeMismatch(tensor.Int, targetLengthsT.Dtype(), "invalid type %v for targetLengths. it should be Int", targetLengthsT.Dtype())
 	}
 
 	var err error
@@ -98,7 +98,7 @@ func (op *dropoutOp) UsePreallocDo(prealloc Value, inputs ...Value) (Value, erro
 		return inputTensor, nil
 	}
 
-	op.do(inputTensor.Data, prealloc.Data())
+	op.do(inputTensor.Data(), prealloc.Data())
 
 	return prealloc, nil
 }
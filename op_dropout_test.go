@@ -67,6 +67,25 @@ func TestDropoutAll(t *testing.T) {
 	}
 }
 
+func TestDropoutUsePreallocDo(t *testing.T) {
+	rand := []float64{0.0, 0.2, 0.5, 0.8, 1.0}
+	randCount := 0
+	randFn := func() float64 {
+		v := rand[randCount%len(rand)]
+		randCount++
+		return v
+	}
+
+	op := newDropoutOp(0.5, randFn)
+	x := tensor.New(tensor.WithShape(5), tensor.WithBacking([]float64{1, 2, 3, 4, 5}))
+	prealloc := tensor.New(tensor.WithShape(5), tensor.Of(tensor.Float64))
+
+	ret, err := op.UsePreallocDo(prealloc, x)
+	require.NoError(t, err)
+	assert.True(t, ret == prealloc, "expected the result to be written into the preallocated value")
+	assert.Equal(t, []float64{2, 4, 0, 0, 0}, ret.Data())
+}
+
 func dropoutTest(t *testing.T, dt tensor.Dtype) error {
 	g := NewGraph()
 	x := NewVector(g, dt, WithShape(10), WithName("x"), WithInit(RangedFrom(0)))
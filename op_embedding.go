@@ -0,0 +1,447 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+	"math"
+	"sort"
+
+	"github.com/chewxy/hm"
+	"github.com/chewxy/math32"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// EmbeddingOpt is a function that provides construction options for Embedding
+type EmbeddingOpt func(op *embeddingOp)
+
+// WithPaddingIdx marks a row of the embedding table as padding. Looking up the padding row works as usual,
+// but no gradient flows back into it, so the row keeps whatever value it was initialized with (typically zeros).
+func WithPaddingIdx(idx int) EmbeddingOpt {
+	return func(op *embeddingOp) {
+		op.paddingIdx = idx
+	}
+}
+
+// WithMaxNorm renormalizes each looked up row of the embedding table to have an L2 norm of at most maxNorm.
+// Like other frameworks do, the renormalization is done in place on the table during the forward pass.
+func WithMaxNorm(maxNorm float64) EmbeddingOpt {
+	return func(op *embeddingOp) {
+		op.maxNorm = maxNorm
+	}
+}
+
+// WithDenseGrad makes Embedding produce a dense gradient for the table instead of a *SparseRows. It is required when the table
+// is used by other nodes as well (e.g. tied weights), because the gradients flowing in from the other paths are summed up
+// by dense additions.
+func WithDenseGrad() EmbeddingOpt {
+	return func(op *embeddingOp) {
+		op.denseGrad = true
+	}
+}
+
+// Embedding looks up the rows of table (a matrix of shape (vocabulary size, embedding size)) given by ids.
+// ids has to be a tensor of tensor.Int. The result has the shape of ids with an extra dimension of the embedding size appended.
+//
+// Unlike ByIndices, the gradient of the table is a *SparseRows that only carries the rows that were looked up, unless
+// WithDenseGrad is passed in. With BindDualValues, the tapeMachine hands the *SparseRows to the solvers, and
+// VanillaSolver, AdamSolver and AdaGradSolver only update the rows that were looked up. Taking the gradient of a table
+// that is used by other nodes as well fails without WithDenseGrad.
+func Embedding(table, ids *Node, opts ...EmbeddingOpt) (*Node, error) {
+	if table.Dims() != 2 {
+		return nil, errors.Errorf("Expected the embedding table to be a matrix. Got %v instead", table.Shape())
+	}
+	op := newEmbeddingOp(ids.Dims())
+	for _, opt := range opts {
+		opt(op)
+	}
+	return ApplyOp(op, table, ids)
+}
+
+type embeddingOp struct {
+	idsDims    int
+	paddingIdx int
+	maxNorm    float64
+	denseGrad  bool
+}
+
+func newEmbeddingOp(idsDims int) *embeddingOp {
+	return &embeddingOp{
+		idsDims:    idsDims,
+		paddingIdx: -1,
+	}
+}
+
+func (op *embeddingOp) Arity() int { return 2 }
+
+func (op *embeddingOp) ReturnsPtr() bool { return false }
+
+func (op *embeddingOp) CallsExtern() bool { return false }
+
+func (op *embeddingOp) WriteHash(h hash.Hash) { fmt.Fprint(h, op.String()) }
+
+func (op *embeddingOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op *embeddingOp) String() string {
+	return fmt.Sprintf("Embedding{padding=%d, maxNorm=%v}", op.paddingIdx, op.maxNorm)
+}
+
+func (op *embeddingOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	t, ok := inputs[0].(tensor.Shape)
+	if !ok || t.Dims() != 2 {
+		return nil, errors.Errorf("Expected the embedding table to be a matrix. Got %v instead", inputs[0])
+	}
+	ids, ok := inputs[1].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected ids to have a shape. Got %v instead", inputs[1])
+	}
+
+	retVal := make(tensor.Shape, 0, ids.Dims()+1)
+	if !ids.IsScalar() {
+		retVal = append(retVal, ids...)
+	}
+	retVal = append(retVal, t[1])
+	return retVal, nil
+}
+
+func (op *embeddingOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	t := makeTensorType(2, a)
+	ids := makeTensorType(op.idsDims, tensor.Int)
+	retVal := makeTensorType(op.idsDims+1, a)
+
+	return hm.NewFnType(t, ids, retVal)
+}
+
+func (op *embeddingOp) OverwritesInput() int { return -1 }
+
+func (op *embeddingOp) checkInput(inputs ...Value) (table *tensor.Dense, ids []int, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return nil, nil, err
+	}
+
+	var ok bool
+	if table, ok = inputs[0].(*tensor.Dense); !ok {
+		return nil, nil, errors.Errorf("Expected the embedding table to be a *tensor.Dense. Got %T instead", inputs[0])
+	}
+	if ids, err = embeddingIDs(inputs[1], table.Shape()[0]); err != nil {
+		return nil, nil, err
+	}
+	return table, ids, nil
+}
+
+// embeddingIDs extracts the ids of a lookup, checking that they are all within the vocabulary.
+func embeddingIDs(v Value, vocab int) ([]int, error) {
+	var ids []int
+	switch t := v.(type) {
+	case *tensor.Dense:
+		if t.Dtype() != tensor.Int {
+			return nil, newDtypeMismatch(tensor.Int, t.Dtype(), "Expected ids to have tensor.Int as a Dtype. Got %v instead", t.Dtype())
+		}
+		ids = t.Ints()
+	case *I:
+		ids = []int{int(*t)}
+	default:
+		return nil, errors.Errorf("Expected ids to be a tensor. Got %T instead", v)
+	}
+
+	for _, id := range ids {
+		if id < 0 || id >= vocab {
+			return nil, errors.Errorf("Embedding id %d is out of range of a vocabulary of %d", id, vocab)
+		}
+	}
+	return ids, nil
+}
+
+func (op *embeddingOp) Do(inputs ...Value) (Value, error) {
+	table, ids, err := op.checkInput(inputs...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't check Embedding input")
+	}
+
+	if op.maxNorm > 0 {
+		if err = op.renorm(table, ids); err != nil {
+			return nil, err
+		}
+	}
+
+	s, err := op.InferShape(table.Shape(), inputs[1].Shape())
+	if err != nil {
+		return nil, err
+	}
+
+	cols := table.Shape()[1]
+	retVal := tensor.New(tensor.Of(table.Dtype()), tensor.WithShape(s...))
+	switch table.Dtype() {
+	case tensor.Float64:
+		src, dst := table.Float64s(), retVal.Float64s()
+		for i, id := range ids {
+			copy(dst[i*cols:(i+1)*cols], src[id*cols:(id+1)*cols])
+		}
+	case tensor.Float32:
+		src, dst := table.Float32s(), retVal.Float32s()
+		for i, id := range ids {
+			copy(dst[i*cols:(i+1)*cols], src[id*cols:(id+1)*cols])
+		}
+	default:
+		return nil, errors.Errorf(nyiFail, "Embedding", table.Dtype())
+	}
+	return retVal, nil
+}
+
+// renorm scales the looked up rows down so that their L2 norm is at most op.maxNorm
+func (op *embeddingOp) renorm(table *tensor.Dense, ids []int) error {
+	cols := table.Shape()[1]
+	seen := make(map[int]struct{}, len(ids))
+	switch table.Dtype() {
+	case tensor.Float64:
+		data := table.Float64s()
+		for _, id := range ids {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = empty
+
+			row := data[id*cols : (id+1)*cols]
+			var norm float64
+			for _, v := range row {
+				norm += v * v
+			}
+			if norm = math.Sqrt(norm); norm > op.maxNorm {
+				scale := op.maxNorm / (norm + 1e-7)
+				for i := range row {
+					row[i] *= scale
+				}
+			}
+		}
+	case tensor.Float32:
+		data := table.Float32s()
+		maxNorm := float32(op.maxNorm)
+		for _, id := range ids {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = empty
+
+			row := data[id*cols : (id+1)*cols]
+			var norm float32
+			for _, v := range row {
+				norm += v * v
+			}
+			if norm = math32.Sqrt(norm); norm > maxNorm {
+				scale := maxNorm / (norm + 1e-7)
+				for i := range row {
+					row[i] *= scale
+				}
+			}
+		}
+	default:
+		return errors.Errorf(nyiFail, "Embedding max norm", table.Dtype())
+	}
+	return nil
+}
+
+// DiffWRT is an implementation for the SDOp interface
+func (op *embeddingOp) DiffWRT(inputs int) []bool {
+	if inputs != op.Arity() {
+		panic(fmt.Sprintf("Embedding operator needs %d inputs, got %d instead", op.Arity(), inputs))
+	}
+
+	return []bool{true, false}
+}
+
+// SymDiff applies the diff op. Implementation for SDOp interface.
+func (op *embeddingOp) SymDiff(inputs Nodes, output, grad *Node) (Nodes, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+
+	table, ids := inputs[0], inputs[1]
+
+	// gradients from multiple consumers are summed up by dense additions, which a *SparseRows does not support
+	if !op.denseGrad && len(table.g.to[table]) > 1 {
+		return nil, errors.Errorf("The embedding table %v is used by %d nodes. Use WithDenseGrad to sum up its gradients", table, len(table.g.to[table]))
+	}
+	diffOp := &embeddingDiffOp{embeddingOp: op, sparse: !op.denseGrad}
+
+	retVal := make(Nodes, op.Arity())
+	var err error
+	retVal[0], err = ApplyOp(diffOp, table, ids, grad)
+	return retVal, err
+}
+
+// DoDiff calculates the diff and sets its value to the output node. Implementation for ADOp interface.
+func (op *embeddingOp) DoDiff(ctx ExecutionContext, inputs Nodes, output *Node) error {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return err
+	}
+
+	tdv := inputs[0].boundTo.(*dualValue)
+	odv := output.boundTo.(*dualValue)
+
+	ids, err := embeddingIDs(inputs[1].Value(), tdv.Value.Shape()[0])
+	if err != nil {
+		return err
+	}
+
+	d, ok := tdv.d.(*tensor.Dense)
+	if !ok {
+		return errors.Errorf("Expected the derivative of the embedding table to be a *tensor.Dense. Got %T instead", tdv.d)
+	}
+	return op.scatter(d, ids, odv.d)
+}
+
+// scatter adds the rows of the output gradient into the matching rows of a gradient of the table.
+func (op *embeddingOp) scatter(d *tensor.Dense, ids []int, grad Value) error {
+	cols := d.Shape()[1]
+	switch d.Dtype() {
+	case tensor.Float64:
+		dst := d.Float64s()
+		src, ok := grad.Data().([]float64)
+		if !ok {
+			return errors.Errorf("Expected the gradient to be []float64. Got %T instead", grad.Data())
+		}
+		for i, id := range ids {
+			if id == op.paddingIdx {
+				continue
+			}
+			vecAddF64(dst[id*cols:(id+1)*cols], src[i*cols:(i+1)*cols])
+		}
+	case tensor.Float32:
+		dst := d.Float32s()
+		src, ok := grad.Data().([]float32)
+		if !ok {
+			return errors.Errorf("Expected the gradient to be []float32. Got %T instead", grad.Data())
+		}
+		for i, id := range ids {
+			if id == op.paddingIdx {
+				continue
+			}
+			vecAddF32(dst[id*cols:(id+1)*cols], src[i*cols:(i+1)*cols])
+		}
+	default:
+		return errors.Errorf(nyiFail, "Embedding gradient", d.Dtype())
+	}
+	return nil
+}
+
+// embeddingDiffOp computes the gradient of the embedding table. It takes the table, the ids and the gradient of the output.
+type embeddingDiffOp struct {
+	*embeddingOp
+	sparse bool
+}
+
+func (op *embeddingDiffOp) Arity() int { return 3 }
+
+func (op *embeddingDiffOp) WriteHash(h hash.Hash) { fmt.Fprint(h, op.String()) }
+
+func (op *embeddingDiffOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op *embeddingDiffOp) String() string {
+	return fmt.Sprintf("EmbeddingDiff{padding=%d, sparse=%t}", op.paddingIdx, op.sparse)
+}
+
+func (op *embeddingDiffOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	s, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected the embedding table to have a shape. Got %v instead", inputs[0])
+	}
+	return s.Clone(), nil
+}
+
+// DiffWRT is an implementation for the SDOp interface. The gradient of the embedding table is not differentiable.
+func (op *embeddingDiffOp) DiffWRT(inputs int) []bool { return make([]bool, inputs) }
+
+// Type returns the type of the table. A sparse gradient is a *SparseRows, which stands for a matrix of that type.
+func (op *embeddingDiffOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	t := makeTensorType(2, a)
+	ids := makeTensorType(op.idsDims, tensor.Int)
+	grad := makeTensorType(op.idsDims+1, a)
+
+	return hm.NewFnType(t, ids, grad, t)
+}
+
+func (op *embeddingDiffOp) Do(inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	table, ids, err := op.embeddingOp.checkInput(inputs[0], inputs[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't check EmbeddingDiff input")
+	}
+	grad := inputs[2]
+
+	if !op.sparse {
+		retVal := tensor.New(tensor.Of(table.Dtype()), tensor.WithShape(table.Shape()...))
+		if err = op.scatter(retVal, ids, grad); err != nil {
+			return nil, err
+		}
+		return retVal, nil
+	}
+
+	// figure out which rows are touched. The rows are sorted so that the solvers walk the table in order.
+	slots := make(map[int]int, len(ids))
+	rows := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := slots[id]; ok || id == op.paddingIdx {
+			continue
+		}
+		slots[id] = 0
+		rows = append(rows, id)
+	}
+	sort.Ints(rows)
+	for i, r := range rows {
+		slots[r] = i
+	}
+
+	cols := table.Shape()[1]
+	vals := tensor.New(tensor.Of(table.Dtype()), tensor.WithShape(len(rows), cols))
+	switch table.Dtype() {
+	case tensor.Float64:
+		dst := vals.Float64s()
+		src, ok := grad.Data().([]float64)
+		if !ok {
+			return nil, errors.Errorf("Expected the gradient to be []float64. Got %T instead", grad.Data())
+		}
+		for i, id := range ids {
+			if id == op.paddingIdx {
+				continue
+			}
+			slot := slots[id]
+			vecAddF64(dst[slot*cols:(slot+1)*cols], src[i*cols:(i+1)*cols])
+		}
+	case tensor.Float32:
+		dst := vals.Float32s()
+		src, ok := grad.Data().([]float32)
+		if !ok {
+			return nil, errors.Errorf("Expected the gradient to be []float32. Got %T instead", grad.Data())
+		}
+		for i, id := range ids {
+			if id == op.paddingIdx {
+				continue
+			}
+			slot := slots[id]
+			vecAddF32(dst[slot*cols:(slot+1)*cols], src[i*cols:(i+1)*cols])
+		}
+	default:
+		return nil, errors.Errorf(nyiFail, "Embedding gradient", table.Dtype())
+	}
+
+	return NewSparseRows(table.Shape(), rows, vals)
+}
+
+// ensure it complies with the Op interface
+var (
+	_ Op = &embeddingDiffOp{}
+
+	_ Op   = &embeddingOp{}
+	_ SDOp = &embeddingOp{}
+	_ ADOp = &embeddingOp{}
+)
@@ -0,0 +1,304 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/tensor"
+)
+
+func TestEmbeddingOpDo(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		table         []float64
+		ids           tensor.Tensor
+		opts          []EmbeddingOpt
+		expected      []float64
+		expectedShape tensor.Shape
+		expectedTable []float64
+	}{
+		{
+			desc:          "vector of ids",
+			table:         []float64{0, 1, 2, 3, 4, 5, 6, 7},
+			ids:           tensor.New(tensor.WithShape(3), tensor.WithBacking([]int{2, 0, 2})),
+			expected:      []float64{4, 5, 0, 1, 4, 5},
+			expectedShape: tensor.Shape{3, 2},
+			expectedTable: []float64{0, 1, 2, 3, 4, 5, 6, 7},
+		},
+		{
+			desc:          "matrix of ids",
+			table:         []float64{0, 1, 2, 3, 4, 5, 6, 7},
+			ids:           tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]int{3, 1, 0, 0})),
+			expected:      []float64{6, 7, 2, 3, 0, 1, 0, 1},
+			expectedShape: tensor.Shape{2, 2, 2},
+			expectedTable: []float64{0, 1, 2, 3, 4, 5, 6, 7},
+		},
+		{
+			desc:          "max norm",
+			table:         []float64{0, 1, 3, 4, 4, 5, 6, 7},
+			ids:           tensor.New(tensor.WithShape(2), tensor.WithBacking([]int{0, 1})),
+			opts:          []EmbeddingOpt{WithMaxNorm(1)},
+			expected:      []float64{0, 1, 0.6, 0.8},
+			expectedShape: tensor.Shape{2, 2},
+			expectedTable: []float64{0, 1, 0.6, 0.8, 4, 5, 6, 7},
+		},
+	}
+
+	for _, tcase := range testCases {
+		t.Run(tcase.desc, func(t *testing.T) {
+			c := require.New(t)
+
+			table := tensor.New(tensor.WithShape(4, 2), tensor.WithBacking(tcase.table))
+
+			op := newEmbeddingOp(tcase.ids.Dims())
+			for _, opt := range tcase.opts {
+				opt(op)
+			}
+
+			output, err := op.Do(table, tcase.ids)
+			c.NoError(err)
+			c.InDeltaSlice(tcase.expected, output.Data(), 1e-6)
+			c.Equal(tcase.expectedShape, output.Shape())
+			c.InDeltaSlice(tcase.expectedTable, table.Data(), 1e-6)
+		})
+	}
+
+	op := newEmbeddingOp(1)
+	table := tensor.New(tensor.WithShape(4, 2), tensor.WithBacking(tensor.Range(tensor.Float64, 0, 8)))
+	_, err := op.Do(table, tensor.New(tensor.WithShape(2), tensor.WithBacking([]int{1, 4})))
+	require.Error(t, err)
+}
+
+func TestEmbeddingSparseGrad(t *testing.T) {
+	c := require.New(t)
+
+	g := NewGraph()
+	table := NewMatrix(g, tensor.Float64, WithShape(5, 3), WithName("table"), WithValue(tensor.New(tensor.WithShape(5, 3), tensor.WithBacking(tensor.Range(tensor.Float64, 0, 15)))))
+	ids := NewVector(g, tensor.Int, WithShape(4), WithName("ids"), WithValue(tensor.New(tensor.WithShape(4), tensor.WithBacking([]int{3, 0, 3, 4}))))
+
+	emb, err := Embedding(table, ids, WithPaddingIdx(4))
+	c.NoError(err)
+
+	cost := Must(Sum(emb))
+	_, err = Grad(cost, table)
+	c.NoError(err)
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	c.NoError(m.RunAll())
+
+	grad, err := table.Grad()
+	c.NoError(err)
+	sg, ok := grad.(*SparseRows)
+	c.True(ok, "Expected a sparse gradient. Got %T instead", grad)
+	c.Equal([]int{0, 3}, sg.Rows())
+	c.Equal([]float64{1, 1, 1, 2, 2, 2}, sg.Data())
+
+	dense, err := sg.Dense()
+	c.NoError(err)
+	c.Equal([]float64{1, 1, 1, 0, 0, 0, 0, 0, 0, 2, 2, 2, 0, 0, 0}, dense.Data())
+
+	solver := NewVanillaSolver(WithLearnRate(0.1))
+	c.NoError(solver.Step(NodesToValueGrads(Nodes{table})))
+	c.InDeltaSlice([]float64{-0.1, 0.9, 1.9, 3, 4, 5, 6, 7, 8, 8.8, 9.8, 10.8, 12, 13, 14}, table.Value().Data(), 1e-6)
+}
+
+func TestEmbeddingDenseGrad(t *testing.T) {
+	c := require.New(t)
+
+	g := NewGraph()
+	table := NewMatrix(g, tensor.Float64, WithShape(3, 2), WithName("table"), WithValue(tensor.New(tensor.WithShape(3, 2), tensor.WithBacking(tensor.Range(tensor.Float64, 0, 6)))))
+	ids := NewVector(g, tensor.Int, WithShape(2), WithName("ids"), WithValue(tensor.New(tensor.WithShape(2), tensor.WithBacking([]int{2, 2}))))
+
+	// the table is used elsewhere too, so the gradient has to be dense
+	emb, err := Embedding(table, ids, WithDenseGrad())
+	c.NoError(err)
+	cost := Must(Add(Must(Sum(emb)), Must(Sum(table))))
+	_, err = Grad(cost, table)
+	c.NoError(err)
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	c.NoError(m.RunAll())
+
+	grad, err := table.Grad()
+	c.NoError(err)
+	c.IsType(&tensor.Dense{}, grad)
+	c.Equal([]float64{1, 1, 1, 1, 3, 3}, grad.Data())
+}
+
+func TestEmbeddingSharedTable(t *testing.T) {
+	g := NewGraph()
+	table := NewMatrix(g, tensor.Float64, WithShape(3, 2), WithName("table"), WithInit(Zeroes()))
+	ids := NewVector(g, tensor.Int, WithShape(2), WithName("ids"), WithInit(Zeroes()))
+
+	// without WithDenseGrad, the gradients of the table could not be summed up
+	emb, err := Embedding(table, ids)
+	require.NoError(t, err)
+	_, err = Grad(Must(Add(Must(Sum(emb)), Must(Sum(table)))), table)
+	require.Error(t, err)
+}
+
+func TestEmbeddingLispMachine(t *testing.T) {
+	c := require.New(t)
+
+	g := NewGraph()
+	table := NewMatrix(g, tensor.Float32, WithShape(3, 2), WithName("table"), WithValue(tensor.New(tensor.WithShape(3, 2), tensor.WithBacking(tensor.Range(tensor.Float32, 0, 6)))))
+	ids := NewVector(g, tensor.Int, WithShape(3), WithName("ids"), WithValue(tensor.New(tensor.WithShape(3), tensor.WithBacking([]int{1, 1, 0}))))
+
+	emb, err := Embedding(table, ids, WithPaddingIdx(0))
+	c.NoError(err)
+	Must(Sum(emb))
+
+	m := NewLispMachine(g)
+	defer m.Close()
+	c.NoError(m.RunAll())
+
+	grad, err := table.Grad()
+	c.NoError(err)
+	c.Equal([]float32{0, 0, 2, 2, 0, 0}, grad.Data())
+}
+
+func TestSolversSparseStep(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		solver func() Solver
+	}{
+		{"Vanilla", func() Solver { return NewVanillaSolver(WithLearnRate(0.1), WithL2Reg(0.01), WithClip(5)) }},
+		{"Adam", func() Solver { return NewAdamSolver(WithLearnRate(0.1), WithL1Reg(0.01)) }},
+		{"AdaGrad", func() Solver { return NewAdaGradSolver(WithLearnRate(0.1), WithL2Reg(0.01)) }},
+	}
+
+	model := func(grad Value) []ValueGrad {
+		dv := dvUnit0(tensor.New(tensor.WithShape(4, 2), tensor.WithBacking([]float64{1, 2, 3, 4, 5, 6, 7, 8})))
+		dv.d = grad
+
+		n := new(Node)
+		n.boundTo = dv
+		return []ValueGrad{n}
+	}
+
+	for _, tcase := range testCases {
+		t.Run(tcase.desc, func(t *testing.T) {
+			c := require.New(t)
+
+			// the dense and sparse versions of the same gradient must give the same results on the touched rows
+			dense := model(tensor.New(tensor.WithShape(4, 2), tensor.WithBacking([]float64{1, 2, 0, 0, 0, 0, -3, 4})))
+			sg, err := NewSparseRows(tensor.Shape{4, 2}, []int{0, 3}, tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2, -3, 4})))
+			c.NoError(err)
+			sparse := model(sg)
+
+			c.NoError(tcase.solver().Step(dense))
+			c.NoError(tcase.solver().Step(sparse))
+
+			d1 := dense[0].Value().Data().([]float64)
+			d2 := sparse[0].Value().Data().([]float64)
+			c.InDeltaSlice(d1[0:2], d2[0:2], 1e-6)
+			c.InDeltaSlice(d1[6:8], d2[6:8], 1e-6)
+
+			// untouched rows are left alone
+			c.Equal([]float64{3, 4, 5, 6}, d2[2:6])
+		})
+	}
+}
+
+func TestEmbeddingBindDualValues(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		solver func() Solver
+		lazy   bool // only the rows that were looked up are updated
+	}{
+		{"Vanilla", func() Solver { return NewVanillaSolver(WithLearnRate(0.1), WithL2Reg(0.1)) }, true},
+		{"Adam", func() Solver { return NewAdamSolver(WithLearnRate(0.1), WithL2Reg(0.1)) }, true},
+		{"AdaGrad", func() Solver { return NewAdaGradSolver(WithLearnRate(0.1), WithL2Reg(0.1)) }, true},
+		{"Momentum", func() Solver { return NewMomentum(WithLearnRate(0.1)) }, false},
+	}
+
+	// train trains the table for a few steps, and returns its initial and final values
+	train := func(t *testing.T, solver Solver, opts ...EmbeddingOpt) (initial, final []float64) {
+		c := require.New(t)
+
+		g := NewGraph()
+		table := NewMatrix(g, tensor.Float64, WithShape(6, 2), WithName("table"), WithInit(RangedFromWithStep(1.0, 1.0)))
+		ids := NewVector(g, tensor.Int, WithShape(3), WithName("ids"), WithInit(Zeroes()))
+		emb, err := Embedding(table, ids, opts...)
+		c.NoError(err)
+		_, err = Grad(Must(Sum(Must(Square(emb)))), table)
+		c.NoError(err)
+
+		initial = table.Value().(*tensor.Dense).Clone().(*tensor.Dense).Float64s()
+		m := NewTapeMachine(g, BindDualValues(table))
+		defer m.Close()
+		for _, lookup := range [][]int{{1, 3, 1}, {3, 3, 1}, {1, 1, 1}} {
+			c.NoError(Let(ids, tensor.New(tensor.WithShape(3), tensor.WithBacking(lookup))))
+			c.NoError(m.RunAll())
+
+			grad, err := table.Grad()
+			c.NoError(err)
+			if len(opts) == 0 {
+				c.IsType(&SparseRows{}, grad)
+			}
+			c.NoError(solver.Step(NodesToValueGrads(Nodes{table})))
+			m.Reset()
+		}
+		return initial, table.Value().Data().([]float64)
+	}
+
+	for _, tcase := range testCases {
+		t.Run(tcase.desc, func(t *testing.T) {
+			c := require.New(t)
+
+			initial, sparse := train(t, tcase.solver())
+			if !tcase.lazy {
+				_, dense := train(t, tcase.solver(), WithDenseGrad())
+				c.InDeltaSlice(dense, sparse, 1e-6)
+				return
+			}
+
+			// the regularization is only applied to the rows that were looked up
+			for row := 0; row < 6; row++ {
+				lo, hi := row*2, (row+1)*2
+				if row == 1 || row == 3 {
+					c.NotEqual(initial[lo:hi], sparse[lo:hi], "row %d", row)
+					continue
+				}
+				c.Equal(initial[lo:hi], sparse[lo:hi], "row %d", row)
+			}
+		})
+	}
+}
+
+func TestAddSparseGrad(t *testing.T) {
+	c := require.New(t)
+
+	sparse := func(rows []int, vals ...float64) *SparseRows {
+		sr, err := NewSparseRows(tensor.Shape{4, 2}, rows, tensor.New(tensor.WithShape(len(rows), 2), tensor.WithBacking(vals)))
+		c.NoError(err)
+		return sr
+	}
+	a := sparse([]int{0, 2}, 1, 2, 3, 4)
+
+	// a zero derivative is replaced by a copy of the gradient
+	d, err := addSparseGrad(tensor.New(tensor.Of(tensor.Float64), tensor.WithShape(4, 2)), a)
+	c.NoError(err)
+	c.Equal([]int{0, 2}, d.(*SparseRows).Rows())
+	c.Equal([]float64{1, 2, 3, 4}, d.Data())
+	c.NotEqual(a.Uintptr(), d.Uintptr())
+
+	// the rows are merged into a sparse derivative
+	d, err = addSparseGrad(d, sparse([]int{1, 2}, 10, 20, 30, 40))
+	c.NoError(err)
+	c.Equal([]int{0, 1, 2}, d.(*SparseRows).Rows())
+	c.Equal([]float64{1, 2, 10, 20, 33, 44}, d.Data())
+
+	d.(*SparseRows).ZeroValue()
+	d, err = addSparseGrad(d, sparse([]int{3}, 5, 6))
+	c.NoError(err)
+	c.Equal([]int{3}, d.(*SparseRows).Rows())
+
+	// and scattered into a dense one
+	dense := tensor.New(tensor.WithShape(4, 2), tensor.WithBacking([]float64{1, 1, 1, 1, 1, 1, 1, 1}))
+	d, err = addSparseGrad(dense, a)
+	c.NoError(err)
+	c.Equal([]float64{2, 3, 1, 1, 4, 5, 1, 1}, d.Data())
+}
@@ -0,0 +1,752 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Gather collects the values of x along an axis, as given by the indices. indices must have the same number of dimensions as x,
+// and the result has the shape of indices. For a 3-tensor gathered along axis 1:
+//		out[i][j][k] = x[i][indices[i][j][k]][k]
+//
+// Unlike ByIndices, which selects whole slices, Gather picks a value for every position of indices.
+func Gather(x, indices *Node, axis int) (*Node, error) {
+	op, err := newGatherOp(x.Dims(), axis)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkIndexValues(x, indices, op.axis); err != nil {
+		return nil, err
+	}
+	return ApplyOp(op, x, indices)
+}
+
+// Scatter writes the values of src into a copy of x, at the positions along the axis given by the indices.
+// indices must have the same number of dimensions as x and src. For a 3-tensor scattered along axis 1:
+//		out[i][indices[i][j][k]][k] = src[i][j][k]
+//
+// If indices are repeated, which of the values gets written is undefined.
+func Scatter(x, indices, src *Node, axis int) (*Node, error) {
+	op, err := newScatterOp(x.Dims(), axis, false)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkIndexValues(x, indices, op.axis); err != nil {
+		return nil, err
+	}
+	return ApplyOp(op, x, indices, src)
+}
+
+// ScatterAdd is like Scatter, but the values of src are added to x instead. Values with repeated indices are all added up:
+//		out[i][indices[i][j][k]][k] += src[i][j][k]
+func ScatterAdd(x, indices, src *Node, axis int) (*Node, error) {
+	op, err := newScatterOp(x.Dims(), axis, true)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkIndexValues(x, indices, op.axis); err != nil {
+		return nil, err
+	}
+	return ApplyOp(op, x, indices, src)
+}
+
+/* KERNELS */
+
+// indexOffsets computes the flat offsets into a tensor of the given shape for every position of an index tensor of idxShape.
+// The coordinate along axis is taken from idx. If idx is nil, the coordinate of the position itself is used.
+// This allows the same function to be used to address the source (or destination) values of a gather or scatter.
+func indexOffsets(shape, idxShape tensor.Shape, idx []int, axis int) ([]int, error) {
+	dims := shape.Dims()
+	if idxShape.Dims() != dims {
+		return nil, errors.Errorf("Expected indices to have %d dimensions. Got %v instead", dims, idxShape)
+	}
+	for d := 0; d < dims; d++ {
+		if d != axis && idxShape[d] > shape[d] {
+			return nil, newShapeMismatch(shape, idxShape, "Indices of shape %v are too large for a tensor of shape %v along axis %d", idxShape, shape, d)
+		}
+	}
+
+	strides := shape.CalcStrides()
+	size := idxShape.TotalSize()
+	coord := make([]int, dims)
+	retVal := make([]int, size)
+	for p := 0; p < size; p++ {
+		var off int
+		for d := 0; d < dims; d++ {
+			c := coord[d]
+			if d == axis && idx != nil {
+				c = idx[p]
+				if c < 0 || c >= shape[axis] {
+					return nil, errors.Errorf("Index %d is out of range of the axis %d of a tensor of shape %v", c, axis, shape)
+				}
+			}
+			off += c * strides[d]
+		}
+		retVal[p] = off
+
+		// advance the coordinate
+		for d := dims - 1; d >= 0; d-- {
+			coord[d]++
+			if coord[d] < idxShape[d] {
+				break
+			}
+			coord[d] = 0
+		}
+	}
+	return retVal, nil
+}
+
+// denseOf returns the values as a *tensor.Dense that is laid out contiguously in memory
+func denseOf(v Value) (*tensor.Dense, error) {
+	var t tensor.Tensor
+	switch vt := v.(type) {
+	case *dualValue:
+		return denseOf(vt.Value)
+	case tensor.Tensor:
+		t = vt
+	default:
+		return nil, errors.Errorf("Expected a tensor. Got %T instead", v)
+	}
+	if t.RequiresIterator() {
+		t = tensor.Materialize(t)
+	}
+	d, ok := t.(*tensor.Dense)
+	if !ok {
+		return nil, errors.Errorf("Expected a *tensor.Dense. Got %T instead", t)
+	}
+	return d, nil
+}
+
+// addIntoDeriv adds a gradient into the derivative d. fn adds the gradient into a tensor laid out contiguously in memory:
+// d itself, or a zero tensor that is then added into d if d is a view.
+func addIntoDeriv(d Value, fn func(*tensor.Dense) error) error {
+	t, ok := d.(*tensor.Dense)
+	if !ok {
+		return errors.Errorf("Expected the derivative to be a *tensor.Dense. Got %T instead", d)
+	}
+	if !t.RequiresIterator() {
+		return fn(t)
+	}
+	tmp := tensor.New(tensor.Of(t.Dtype()), tensor.WithShape(t.Shape().Clone()...))
+	if err := fn(tmp); err != nil {
+		return err
+	}
+	if _, err := tensor.Add(t, tmp, tensor.UseUnsafe()); err != nil {
+		return errors.Wrap(err, addFail)
+	}
+	return nil
+}
+
+// gatherKernel copies src[srcOffs[p]] into dst[dstOffs[p]] for all p. If add is true, the values are added instead.
+func gatherKernel(dst, src *tensor.Dense, dstOffs, srcOffs []int, add bool) error {
+	if dst.Dtype() != src.Dtype() {
+		return newDtypeMismatch(dst.Dtype(), src.Dtype(), "Expected both tensors to have the same Dtype. Got %v and %v", dst.Dtype(), src.Dtype())
+	}
+	switch d := dst.Data().(type) {
+	case []float64:
+		s := src.Data().([]float64)
+		if add {
+			for p, off := range dstOffs {
+				d[off] += s[srcOffs[p]]
+			}
+			return nil
+		}
+		for p, off := range dstOffs {
+			d[off] = s[srcOffs[p]]
+		}
+	case []float32:
+		s := src.Data().([]float32)
+		if add {
+			for p, off := range dstOffs {
+				d[off] += s[srcOffs[p]]
+			}
+			return nil
+		}
+		for p, off := range dstOffs {
+			d[off] = s[srcOffs[p]]
+		}
+	case []int:
+		s := src.Data().([]int)
+		if add {
+			for p, off := range dstOffs {
+				d[off] += s[srcOffs[p]]
+			}
+			return nil
+		}
+		for p, off := range dstOffs {
+			d[off] = s[srcOffs[p]]
+		}
+	default:
+		return errors.Errorf(nyiFail, "gather/scatter", dst.Dtype())
+	}
+	return nil
+}
+
+// checkIndices checks that the value is a tensor of tensor.Int, returning its data
+func checkIndices(v Value) (*tensor.Dense, error) {
+	indices, err := denseOf(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "Bad indices")
+	}
+	if indices.Dtype() != tensor.Int {
+		return nil, newDtypeMismatch(tensor.Int, indices.Dtype(), "Expected indices to have tensor.Int as a Dtype. Got %v instead", indices.Dtype())
+	}
+	return indices, nil
+}
+
+// checkGatherShapes checks that the shape of the indices is compatible with the shape of x
+func checkGatherShapes(x, indices tensor.Shape, axis int) error {
+	if x.Dims() != indices.Dims() {
+		return errors.Errorf("Expected indices to have %d dimensions. Got %v instead", x.Dims(), indices)
+	}
+	if axis >= x.Dims() {
+		return errors.Errorf("Axis %d is out of range of a tensor of shape %v", axis, x)
+	}
+	for d := range x {
+		if d != axis && indices[d] > x[d] {
+			return newShapeMismatch(x, indices, "Indices of shape %v are too large for a tensor of shape %v along axis %d", indices, x, d)
+		}
+	}
+	return nil
+}
+
+// checkIndexValues checks that the indices are in range, if the values of the indices are already known (e.g. constants).
+// Shape inference only knows about shapes, so this catches bad indices at graph construction time rather than at runtime.
+func checkIndexValues(x, indices *Node, axis int) error {
+	v := indices.Value()
+	if v == nil || x.Shape() == nil {
+		return nil
+	}
+	idx, err := checkIndices(v)
+	if err != nil {
+		return err
+	}
+	_, err = indexOffsets(x.Shape(), idx.Shape(), idx.Ints(), axis)
+	return err
+}
+
+/* GATHER */
+
+type gatherOp struct {
+	dims int
+	axis int
+}
+
+func newGatherOp(dims, axis int) (*gatherOp, error) {
+	if axis < 0 {
+		axis += dims
+	}
+	if axis < 0 || axis >= dims {
+		return nil, errors.Errorf("Axis %d is out of range of a %d-tensor", axis, dims)
+	}
+	return &gatherOp{dims: dims, axis: axis}, nil
+}
+
+func (op *gatherOp) Arity() int { return 2 }
+
+func (op *gatherOp) ReturnsPtr() bool { return false }
+
+func (op *gatherOp) CallsExtern() bool { return false }
+
+func (op *gatherOp) WriteHash(h hash.Hash) { fmt.Fprint(h, op.String()) }
+
+func (op *gatherOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op *gatherOp) String() string { return fmt.Sprintf("Gather{axis=%d}", op.axis) }
+
+func (op *gatherOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	x, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected x to have a shape. Got %v instead", inputs[0])
+	}
+	indices, ok := inputs[1].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected indices to have a shape. Got %v instead", inputs[1])
+	}
+	if err := checkGatherShapes(x, indices, op.axis); err != nil {
+		return nil, err
+	}
+	return indices.Clone(), nil
+}
+
+func (op *gatherOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	t := makeTensorType(op.dims, a)
+	i := makeTensorType(op.dims, tensor.Int)
+
+	return hm.NewFnType(t, i, t)
+}
+
+func (op *gatherOp) OverwritesInput() int { return -1 }
+
+func (op *gatherOp) Do(inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	x, err := denseOf(inputs[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "Bad input for Gather")
+	}
+	indices, err := checkIndices(inputs[1])
+	if err != nil {
+		return nil, err
+	}
+
+	srcOffs, err := indexOffsets(x.Shape(), indices.Shape(), indices.Ints(), op.axis)
+	if err != nil {
+		return nil, err
+	}
+	retVal := tensor.New(tensor.Of(x.Dtype()), tensor.WithShape(indices.Shape().Clone()...))
+	dstOffs, _ := indexOffsets(retVal.Shape(), indices.Shape(), nil, op.axis)
+	if err = gatherKernel(retVal, x, dstOffs, srcOffs, false); err != nil {
+		return nil, err
+	}
+	return retVal, nil
+}
+
+// DiffWRT is an implementation for the SDOp interface
+func (op *gatherOp) DiffWRT(inputs int) []bool {
+	if inputs != op.Arity() {
+		panic(fmt.Sprintf("Gather operator needs %d inputs, got %d instead", op.Arity(), inputs))
+	}
+	return []bool{true, false}
+}
+
+// SymDiff applies the diff op. Implementation for SDOp interface.
+// The gradient of a gather is the gradient of the output scattered-added into a zero tensor shaped like x.
+func (op *gatherOp) SymDiff(inputs Nodes, output, grad *Node) (Nodes, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+
+	retVal := make(Nodes, op.Arity())
+	var err error
+	retVal[0], err = ApplyOp(&gatherDiffOp{op}, inputs[0], inputs[1], grad)
+	return retVal, err
+}
+
+// DoDiff calculates the diff and sets its value to the output node. Implementation for ADOp interface.
+func (op *gatherOp) DoDiff(ctx ExecutionContext, inputs Nodes, output *Node) error {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return err
+	}
+	xdv := inputs[0].boundTo.(*dualValue)
+	odv := output.boundTo.(*dualValue)
+
+	indices, err := checkIndices(inputs[1].Value())
+	if err != nil {
+		return err
+	}
+	grad, err := denseOf(odv.d)
+	if err != nil {
+		return err
+	}
+
+	dstOffs, err := indexOffsets(xdv.d.Shape(), indices.Shape(), indices.Ints(), op.axis)
+	if err != nil {
+		return err
+	}
+	srcOffs, _ := indexOffsets(grad.Shape(), indices.Shape(), nil, op.axis)
+	return addIntoDeriv(xdv.d, func(d *tensor.Dense) error {
+		return gatherKernel(d, grad, dstOffs, srcOffs, true)
+	})
+}
+
+// gatherDiffOp scatter-adds the gradient of a Gather into zeros shaped like x. It takes x, the indices and the gradient.
+type gatherDiffOp struct {
+	*gatherOp
+}
+
+func (op *gatherDiffOp) Arity() int { return 3 }
+
+func (op *gatherDiffOp) WriteHash(h hash.Hash) { fmt.Fprint(h, op.String()) }
+
+func (op *gatherDiffOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op *gatherDiffOp) String() string { return fmt.Sprintf("GatherDiff{axis=%d}", op.axis) }
+
+func (op *gatherDiffOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	x, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected x to have a shape. Got %v instead", inputs[0])
+	}
+	return x.Clone(), nil
+}
+
+func (op *gatherDiffOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	t := makeTensorType(op.dims, a)
+	i := makeTensorType(op.dims, tensor.Int)
+
+	return hm.NewFnType(t, i, t, t)
+}
+
+// DiffWRT is an implementation for the SDOp interface.
+func (op *gatherDiffOp) DiffWRT(inputs int) []bool { return make([]bool, inputs) }
+
+func (op *gatherDiffOp) Do(inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	x, err := denseOf(inputs[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "Bad input for GatherDiff")
+	}
+	indices, err := checkIndices(inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	grad, err := denseOf(inputs[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "Bad gradient for GatherDiff")
+	}
+
+	retVal := tensor.New(tensor.Of(x.Dtype()), tensor.WithShape(x.Shape().Clone()...))
+	dstOffs, err := indexOffsets(retVal.Shape(), indices.Shape(), indices.Ints(), op.axis)
+	if err != nil {
+		return nil, err
+	}
+	srcOffs, _ := indexOffsets(grad.Shape(), indices.Shape(), nil, op.axis)
+	if err = gatherKernel(retVal, grad, dstOffs, srcOffs, true); err != nil {
+		return nil, err
+	}
+	return retVal, nil
+}
+
+/* SCATTER */
+
+type scatterOp struct {
+	dims int
+	axis int
+	add  bool // ScatterAdd
+}
+
+func newScatterOp(dims, axis int, add bool) (*scatterOp, error) {
+	if axis < 0 {
+		axis += dims
+	}
+	if axis < 0 || axis >= dims {
+		return nil, errors.Errorf("Axis %d is out of range of a %d-tensor", axis, dims)
+	}
+	return &scatterOp{dims: dims, axis: axis, add: add}, nil
+}
+
+func (op *scatterOp) Arity() int { return 3 }
+
+func (op *scatterOp) ReturnsPtr() bool { return false }
+
+func (op *scatterOp) CallsExtern() bool { return false }
+
+func (op *scatterOp) WriteHash(h hash.Hash) { fmt.Fprint(h, op.String()) }
+
+func (op *scatterOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op *scatterOp) String() string {
+	if op.add {
+		return fmt.Sprintf("ScatterAdd{axis=%d}", op.axis)
+	}
+	return fmt.Sprintf("Scatter{axis=%d}", op.axis)
+}
+
+func (op *scatterOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	x, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected x to have a shape. Got %v instead", inputs[0])
+	}
+	indices, ok := inputs[1].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected indices to have a shape. Got %v instead", inputs[1])
+	}
+	src, ok := inputs[2].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected src to have a shape. Got %v instead", inputs[2])
+	}
+	if err := checkGatherShapes(x, indices, op.axis); err != nil {
+		return nil, err
+	}
+	// src is read at the positions of the indices, so it has to be at least as large as the indices
+	if err := checkGatherShapes(src, indices, -1); err != nil {
+		return nil, errors.Wrap(err, "Bad src")
+	}
+	return x.Clone(), nil
+}
+
+func (op *scatterOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	t := makeTensorType(op.dims, a)
+	i := makeTensorType(op.dims, tensor.Int)
+
+	return hm.NewFnType(t, i, t, t)
+}
+
+func (op *scatterOp) OverwritesInput() int { return -1 }
+
+func (op *scatterOp) Do(inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	x, err := denseOf(inputs[0])
+	if err != nil {
+		return nil, errors.Wrapf(err, "Bad input for %v", op)
+	}
+	indices, err := checkIndices(inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	src, err := denseOf(inputs[2])
+	if err != nil {
+		return nil, errors.Wrapf(err, "Bad src for %v", op)
+	}
+
+	retVal := x.Clone().(*tensor.Dense)
+	dstOffs, err := indexOffsets(retVal.Shape(), indices.Shape(), indices.Ints(), op.axis)
+	if err != nil {
+		return nil, err
+	}
+	srcOffs, err := indexOffsets(src.Shape(), indices.Shape(), nil, -1)
+	if err != nil {
+		return nil, errors.Wrap(err, "Bad src")
+	}
+	if err = gatherKernel(retVal, src, dstOffs, srcOffs, op.add); err != nil {
+		return nil, err
+	}
+	return retVal, nil
+}
+
+// DiffWRT is an implementation for the SDOp interface
+func (op *scatterOp) DiffWRT(inputs int) []bool {
+	if inputs != op.Arity() {
+		panic(fmt.Sprintf("%v operator needs %d inputs, got %d instead", op, op.Arity(), inputs))
+	}
+	return []bool{true, false, true}
+}
+
+// SymDiff applies the diff op. Implementation for SDOp interface.
+//
+// The gradient of src is the gradient of the output gathered at the indices.
+// For ScatterAdd, the gradient of x is the gradient of the output. For Scatter, the overwritten positions get no gradient.
+func (op *scatterOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	indices, src := inputs[1], inputs[2]
+
+	retVal = make(Nodes, op.Arity())
+	if op.add {
+		retVal[0] = grad
+	} else if retVal[0], err = ApplyOp(&scatterMaskOp{op}, indices, grad); err != nil {
+		return nil, err
+	}
+
+	if src.Shape().Eq(indices.Shape()) {
+		retVal[2], err = Gather(grad, indices, op.axis)
+	} else {
+		retVal[2], err = ApplyOp(&scatterSrcDiffOp{op}, src, indices, grad)
+	}
+	return retVal, err
+}
+
+// DoDiff calculates the diff and sets its value to the output node. Implementation for ADOp interface.
+func (op *scatterOp) DoDiff(ctx ExecutionContext, inputs Nodes, output *Node) error {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return err
+	}
+	xdv := inputs[0].boundTo.(*dualValue)
+	sdv := inputs[2].boundTo.(*dualValue)
+	odv := output.boundTo.(*dualValue)
+
+	indices, err := checkIndices(inputs[1].Value())
+	if err != nil {
+		return err
+	}
+	grad, err := denseOf(odv.d)
+	if err != nil {
+		return err
+	}
+
+	// dx
+	dx := grad
+	if !op.add {
+		if dx, err = op.mask(indices, grad); err != nil {
+			return err
+		}
+	}
+	if err = addIntoDeriv(xdv.d, func(xd *tensor.Dense) error {
+		_, err := tensor.Add(xd, dx, tensor.UseUnsafe())
+		return errors.Wrap(err, addFail)
+	}); err != nil {
+		return err
+	}
+
+	// dsrc
+	srcOffs, err := indexOffsets(grad.Shape(), indices.Shape(), indices.Ints(), op.axis)
+	if err != nil {
+		return err
+	}
+	dstOffs, err := indexOffsets(sdv.d.Shape(), indices.Shape(), nil, -1)
+	if err != nil {
+		return err
+	}
+	return addIntoDeriv(sdv.d, func(sd *tensor.Dense) error {
+		return gatherKernel(sd, grad, dstOffs, srcOffs, true)
+	})
+}
+
+// mask returns a copy of the gradient with the positions overwritten by a Scatter zeroed.
+func (op *scatterOp) mask(indices, grad *tensor.Dense) (*tensor.Dense, error) {
+	retVal := grad.Clone().(*tensor.Dense)
+	dstOffs, err := indexOffsets(retVal.Shape(), indices.Shape(), indices.Ints(), op.axis)
+	if err != nil {
+		return nil, err
+	}
+	zeros := tensor.New(tensor.Of(grad.Dtype()), tensor.WithShape(1))
+	srcOffs := make([]int, len(dstOffs))
+	if err = gatherKernel(retVal, zeros, dstOffs, srcOffs, false); err != nil {
+		return nil, err
+	}
+	return retVal, nil
+}
+
+// scatterMaskOp zeroes the positions of the gradient that were overwritten by a Scatter. It takes the indices and the gradient.
+type scatterMaskOp struct {
+	*scatterOp
+}
+
+func (op *scatterMaskOp) Arity() int { return 2 }
+
+func (op *scatterMaskOp) WriteHash(h hash.Hash) { fmt.Fprint(h, op.String()) }
+
+func (op *scatterMaskOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op *scatterMaskOp) String() string { return fmt.Sprintf("ScatterMask{axis=%d}", op.axis) }
+
+func (op *scatterMaskOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	grad, ok := inputs[1].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected the gradient to have a shape. Got %v instead", inputs[1])
+	}
+	return grad.Clone(), nil
+}
+
+func (op *scatterMaskOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	t := makeTensorType(op.dims, a)
+	i := makeTensorType(op.dims, tensor.Int)
+
+	return hm.NewFnType(i, t, t)
+}
+
+// DiffWRT is an implementation for the SDOp interface.
+func (op *scatterMaskOp) DiffWRT(inputs int) []bool { return make([]bool, inputs) }
+
+func (op *scatterMaskOp) Do(inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	indices, err := checkIndices(inputs[0])
+	if err != nil {
+		return nil, err
+	}
+	grad, err := denseOf(inputs[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "Bad gradient for ScatterMask")
+	}
+	return op.mask(indices, grad)
+}
+
+// scatterSrcDiffOp computes the gradient of src when src is larger than the indices. The values of src that were not scattered get no gradient.
+// It takes src, the indices and the gradient.
+type scatterSrcDiffOp struct {
+	*scatterOp
+}
+
+func (op *scatterSrcDiffOp) WriteHash(h hash.Hash) { fmt.Fprint(h, op.String()) }
+
+func (op *scatterSrcDiffOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op *scatterSrcDiffOp) String() string { return fmt.Sprintf("ScatterSrcDiff{axis=%d}", op.axis) }
+
+func (op *scatterSrcDiffOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	src, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected src to have a shape. Got %v instead", inputs[0])
+	}
+	return src.Clone(), nil
+}
+
+func (op *scatterSrcDiffOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	t := makeTensorType(op.dims, a)
+	i := makeTensorType(op.dims, tensor.Int)
+
+	return hm.NewFnType(t, i, t, t)
+}
+
+// DiffWRT is an implementation for the SDOp interface.
+func (op *scatterSrcDiffOp) DiffWRT(inputs int) []bool { return make([]bool, inputs) }
+
+func (op *scatterSrcDiffOp) Do(inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	src, err := denseOf(inputs[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "Bad src for ScatterSrcDiff")
+	}
+	indices, err := checkIndices(inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	grad, err := denseOf(inputs[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "Bad gradient for ScatterSrcDiff")
+	}
+
+	retVal := tensor.New(tensor.Of(src.Dtype()), tensor.WithShape(src.Shape().Clone()...))
+	srcOffs, err := indexOffsets(grad.Shape(), indices.Shape(), indices.Ints(), op.axis)
+	if err != nil {
+		return nil, err
+	}
+	dstOffs, err := indexOffsets(retVal.Shape(), indices.Shape(), nil, -1)
+	if err != nil {
+		return nil, err
+	}
+	if err = gatherKernel(retVal, grad, dstOffs, srcOffs, false); err != nil {
+		return nil, err
+	}
+	return retVal, nil
+}
+
+// ensure it complies with the Op interface
+var (
+	_ Op = &gatherDiffOp{}
+	_ Op = &scatterMaskOp{}
+	_ Op = &scatterSrcDiffOp{}
+
+	_ Op   = &gatherOp{}
+	_ SDOp = &gatherOp{}
+	_ ADOp = &gatherOp{}
+
+	_ Op   = &scatterOp{}
+	_ SDOp = &scatterOp{}
+	_ ADOp = &scatterOp{}
+)
@@ -0,0 +1,244 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/tensor"
+)
+
+func TestGatherOpDo(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		input          tensor.Tensor
+		indices        tensor.Tensor
+		axis           int
+		expectedOutput []float64
+		expectedShape  tensor.Shape
+		err            bool
+	}{
+		{
+			// 0 1 2
+			// 3 4 5
+			desc:           "axis 0",
+			input:          tensor.New(tensor.WithShape(2, 3), tensor.WithBacking(tensor.Range(tensor.Float64, 0, 6))),
+			indices:        tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]int{1, 0, 1, 0, 0, 1})),
+			axis:           0,
+			expectedOutput: []float64{3, 1, 5, 0, 1, 5},
+			expectedShape:  tensor.Shape{2, 3},
+		},
+		{
+			desc:           "axis 1",
+			input:          tensor.New(tensor.WithShape(2, 3), tensor.WithBacking(tensor.Range(tensor.Float64, 0, 6))),
+			indices:        tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]int{2, 2, 0, 1})),
+			axis:           1,
+			expectedOutput: []float64{2, 2, 3, 4},
+			expectedShape:  tensor.Shape{2, 2},
+		},
+		{
+			desc:    "out of range",
+			input:   tensor.New(tensor.WithShape(2, 3), tensor.WithBacking(tensor.Range(tensor.Float64, 0, 6))),
+			indices: tensor.New(tensor.WithShape(2, 1), tensor.WithBacking([]int{2, 3})),
+			axis:    1,
+			err:     true,
+		},
+	}
+
+	for _, tcase := range testCases {
+		t.Run(tcase.desc, func(t *testing.T) {
+			c := require.New(t)
+
+			op, err := newGatherOp(tcase.input.Dims(), tcase.axis)
+			c.NoError(err)
+
+			output, err := op.Do(tcase.input, tcase.indices)
+			if tcase.err {
+				c.Error(err)
+				return
+			}
+			c.NoError(err)
+			c.Equal(tcase.expectedOutput, output.Data())
+			c.Equal(tcase.expectedShape, output.Shape())
+		})
+	}
+}
+
+func TestScatterOpDo(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		add            bool
+		indices        []int
+		axis           int
+		expectedOutput []float64
+	}{
+		{
+			desc:           "Scatter axis 0",
+			indices:        []int{1, 0, 1},
+			axis:           0,
+			expectedOutput: []float64{0, 20, 0, 10, 0, 30},
+		},
+		{
+			desc:           "ScatterAdd axis 1",
+			add:            true,
+			indices:        []int{2, 2, 0},
+			axis:           1,
+			expectedOutput: []float64{30, 0, 30, 0, 0, 0},
+		},
+	}
+
+	for _, tcase := range testCases {
+		t.Run(tcase.desc, func(t *testing.T) {
+			c := require.New(t)
+
+			x := tensor.New(tensor.WithShape(2, 3), tensor.Of(tensor.Float64))
+			indices := tensor.New(tensor.WithShape(1, 3), tensor.WithBacking(tcase.indices))
+			src := tensor.New(tensor.WithShape(1, 3), tensor.WithBacking([]float64{10, 20, 30}))
+
+			op, err := newScatterOp(2, tcase.axis, tcase.add)
+			c.NoError(err)
+
+			output, err := op.Do(x, indices, src)
+			c.NoError(err)
+			c.Equal(tcase.expectedOutput, output.Data())
+			c.Equal(tensor.Shape{2, 3}, output.Shape())
+
+			// x is not modified
+			c.Equal(make([]float64, 6), x.Data())
+		})
+	}
+}
+
+func TestGatherScatterShapes(t *testing.T) {
+	c := require.New(t)
+
+	g := NewGraph()
+	x := NewMatrix(g, tensor.Float64, WithShape(2, 3), WithName("x"))
+	bad := NewMatrix(g, tensor.Int, WithShape(3, 1), WithName("bad"))
+	_, err := Gather(x, bad, 1)
+	c.Error(err)
+
+	vec := NewVector(g, tensor.Int, WithShape(2), WithName("vec"))
+	_, err = Gather(x, vec, 0)
+	c.Error(err)
+
+	outOfRange := NewMatrix(g, tensor.Int, WithShape(1, 2), WithName("outOfRange"), WithValue(tensor.New(tensor.WithShape(1, 2), tensor.WithBacking([]int{0, 3}))))
+	_, err = Gather(x, outOfRange, 1)
+	c.Error(err)
+	_, err = ScatterAdd(x, outOfRange, NewMatrix(g, tensor.Float64, WithShape(1, 2), WithName("src")), 1)
+	c.Error(err)
+}
+
+func TestGatherScatterGrad(t *testing.T) {
+	c := require.New(t)
+
+	g := NewGraph()
+	x := NewMatrix(g, tensor.Float64, WithShape(2, 3), WithName("x"), WithValue(tensor.New(tensor.WithShape(2, 3), tensor.WithBacking(tensor.Range(tensor.Float64, 0, 6)))))
+	src := NewMatrix(g, tensor.Float64, WithShape(2, 2), WithName("src"), WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2, 3, 4}))))
+	indices := NewMatrix(g, tensor.Int, WithShape(2, 2), WithName("indices"), WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]int{0, 0, 2, 1}))))
+	weights := NewMatrix(g, tensor.Float64, WithShape(2, 3), WithName("weights"), WithValue(tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, 2, 3, 4, 5, 6}))))
+
+	gathered, err := Gather(x, indices, 1)
+	c.NoError(err)
+	scattered, err := Scatter(x, indices, src, 1)
+	c.NoError(err)
+	added, err := ScatterAdd(x, indices, src, 1)
+	c.NoError(err)
+
+	cost := Must(Sum(Must(HadamardProd(scattered, weights))))
+	cost = Must(Add(cost, Must(Sum(Must(HadamardProd(added, weights))))))
+	cost = Must(Add(cost, Must(Sum(gathered))))
+
+	grads, err := Grad(cost, x, src)
+	c.NoError(err)
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	c.NoError(m.RunAll())
+
+	c.Equal([]float64{0, 0, 5, 4}, gathered.Value().Data())
+	c.Equal([]float64{2, 1, 2, 3, 4, 3}, scattered.Value().Data())
+	c.Equal([]float64{3, 1, 2, 3, 8, 8}, added.Value().Data())
+
+	// d/dx: gather contributes 2 to x[0][0], 1 to x[1][2] and x[1][1]. Scatter masks out x[0][0], x[1][2], x[1][1]. ScatterAdd passes the weights.
+	c.Equal([]float64{1 + 0 + 2, 2 + 2, 3 + 3, 4 + 4, 0 + 5 + 1, 0 + 6 + 1}, grads[0].Value().Data())
+	// d/dsrc: the weights gathered at the indices, once for Scatter and once for ScatterAdd
+	c.Equal([]float64{2, 2, 12, 10}, grads[1].Value().Data())
+}
+
+func TestGatherScatterLispMachine(t *testing.T) {
+	c := require.New(t)
+
+	g := NewGraph()
+	x := NewMatrix(g, tensor.Float64, WithShape(2, 2), WithName("x"), WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2, 3, 4}))))
+	src := NewMatrix(g, tensor.Float64, WithShape(1, 2), WithName("src"), WithValue(tensor.New(tensor.WithShape(1, 2), tensor.WithBacking([]float64{5, 6}))))
+	indices := NewMatrix(g, tensor.Int, WithShape(1, 2), WithName("indices"), WithValue(tensor.New(tensor.WithShape(1, 2), tensor.WithBacking([]int{1, 1}))))
+
+	scattered := Must(Scatter(x, indices, src, 0))
+	Must(Sum(Must(Gather(scattered, indices, 0))))
+
+	m := NewLispMachine(g)
+	defer m.Close()
+	c.NoError(m.RunAll())
+
+	dx, err := x.Grad()
+	c.NoError(err)
+	dsrc, err := src.Grad()
+	c.NoError(err)
+
+	// the second row of x is overwritten by src, and only the second row is gathered
+	c.Equal([]float64{0, 0, 0, 0}, dx.Data())
+	c.Equal([]float64{1, 1}, dsrc.Data())
+}
+
+// the derivatives that are views, e.g. transposed, are not laid out contiguously in memory. The gradients have to be added
+// into them all the same.
+func TestGatherScatterDoDiffViews(t *testing.T) {
+	c := require.New(t)
+
+	// the derivatives of x and src are transposed views of zeros
+	setup := func() (x, indices, src, output *Node) {
+		g := NewGraph()
+		bind := func(n *Node, v, d *tensor.Dense) {
+			if d != nil {
+				c.NoError(d.T())
+			}
+			n.boundTo = &dualValue{Value: v, d: d}
+		}
+		x = NewMatrix(g, tensor.Float64, WithShape(2, 3), WithName("x"))
+		bind(x, tensor.New(tensor.WithShape(2, 3), tensor.WithBacking(tensor.Range(tensor.Float64, 0, 6))), tensor.New(tensor.Of(tensor.Float64), tensor.WithShape(3, 2)))
+		indices = NewMatrix(g, tensor.Int, WithShape(1, 3), WithName("indices"), WithValue(tensor.New(tensor.WithShape(1, 3), tensor.WithBacking([]int{1, 0, 1}))))
+		src = NewMatrix(g, tensor.Float64, WithShape(1, 3), WithName("src"))
+		bind(src, tensor.New(tensor.WithShape(1, 3), tensor.WithBacking([]float64{7, 8, 9})), tensor.New(tensor.Of(tensor.Float64), tensor.WithShape(3, 1)))
+		output = NewMatrix(g, tensor.Float64, WithShape(1, 3), WithName("output"))
+		return
+	}
+	grad := func(shape ...int) *tensor.Dense {
+		return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(tensor.Range(tensor.Float64, 1, 1+tensor.Shape(shape).TotalSize())))
+	}
+
+	x, indices, _, output := setup()
+	output.boundTo = &dualValue{d: grad(1, 3)}
+	gather, err := newGatherOp(2, 0)
+	c.NoError(err)
+	c.NoError(gather.DoDiff(ExecutionContext{}, Nodes{x, indices}, output))
+	dx := x.boundTo.(*dualValue).d.(*tensor.Dense)
+	c.Equal(tensor.Shape{2, 3}, dx.Shape())
+	c.Equal([]float64{0, 2, 0, 1, 0, 3}, tensor.Materialize(dx).Data())
+
+	for _, add := range []bool{false, true} {
+		x, indices, src, output := setup()
+		output.boundTo = &dualValue{d: grad(2, 3)}
+		scatter, err := newScatterOp(2, 0, add)
+		c.NoError(err)
+		c.NoError(scatter.DoDiff(ExecutionContext{}, Nodes{x, indices, src}, output))
+
+		dx := tensor.Materialize(x.boundTo.(*dualValue).d.(*tensor.Dense))
+		if add {
+			c.Equal([]float64{1, 2, 3, 4, 5, 6}, dx.Data(), "ScatterAdd")
+		} else {
+			c.Equal([]float64{1, 0, 3, 0, 5, 0}, dx.Data(), "Scatter")
+		}
+		dsrc := tensor.Materialize(src.boundTo.(*dualValue).d.(*tensor.Dense))
+		c.Equal([]float64{4, 2, 6}, dsrc.Data(), "add: %t", add)
+	}
+}
@@ -166,7 +166,7 @@ func (op elemBinOp) InferShape(inputs ...DimSizer) (retVal tensor.Shape, err err
 				retVal = x
 			case !x.IsScalar() && !y.IsScalar():
 				if !x.Eq(y) {
-					return nil, errors.Errorf("Shape mismatch: %v and %v", x, y)
+					return nil, newShapeMismatch(x, y, "Shape mismatch: %v and %v", x, y)
 				}
 				if x.Dims() > y.Dims() {
 					retVal = x
@@ -283,6 +283,11 @@ func (op elemBinOp) DoDiff(ctx ExecutionContext, inputs Nodes, output *Node) (er
 func (op elemBinOp) ReturnsPtr() bool { return true }
 
 func (op elemBinOp) OverwritesInput() int {
+	// comparisons that return bools cannot write their results into the inputs
+	if !op.isArith() && !op.retSame {
+		return -1
+	}
+
 	if _, ok := op.arg0.(TensorType); ok {
 		return 0
 	}
@@ -551,7 +556,7 @@ func (op linAlgBinOp) InferShape(inputs ...DimSizer) (retVal tensor.Shape, err e
 		}
 
 		if x[1] != y[0] {
-			return nil, errors.Errorf("Inner dimensions do not match up")
+			return nil, newShapeMismatch(tensor.Shape{x[1], y[1]}, y, "Inner dimensions do not match up")
 		}
 
 		retVal = tensor.Shape{x[0], y[1]}
@@ -561,7 +566,7 @@ func (op linAlgBinOp) InferShape(inputs ...DimSizer) (retVal tensor.Shape, err e
 			defer tensor.ReturnInts(x)
 		}
 		if x[0] != y[0] && x[1] != y[0] {
-			return nil, errors.Errorf("Incompatible shapes: %v and %v", x, y)
+			return nil, newShapeMismatch(tensor.Shape{x[1]}, y, "Incompatible shapes: %v and %v", x, y)
 		}
 
 		switch {
@@ -584,7 +589,7 @@ func (op linAlgBinOp) InferShape(inputs ...DimSizer) (retVal tensor.Shape, err e
 		innerY := y[len(y)-2:]
 		outerY := y[:len(y)-2]
 		if !outerX.Eq(outerY) {
-			return nil, errors.Errorf("Expected outer dimensions of %v and %v to match. Got %v and %v", x, y, outerX, outerY)
+			return nil, newShapeMismatch(outerX, outerY, "Expected outer dimensions of %v and %v to match. Got %v and %v", x, y, outerX, outerY)
 		}
 
 		// batchSize := outerX.TotalSize()
@@ -29,7 +29,7 @@ func (op minBetween) InferShape(shps ...DimSizer) (tensor.Shape, error) {
 	a := shps[0].(tensor.Shape)
 	b := shps[1].(tensor.Shape)
 	if !a.Eq(b) {
-		return nil, errors.Errorf("Expected both inputs to have the same shape. Got %v and %v instead", a, b)
+		return nil, newShapeMismatch(a, b, "Expected both inputs to have the same shape. Got %v and %v instead", a, b)
 	}
 	return a.Clone(), nil
 }
@@ -98,7 +98,7 @@ func (op maxBetween) InferShape(shps ...DimSizer) (tensor.Shape, error) {
 	a := shps[0].(tensor.Shape)
 	b := shps[1].(tensor.Shape)
 	if !a.Eq(b) {
-		return nil, errors.Errorf("Expected both inputs to have the same shape. Got %v and %v instead", a, b)
+		return nil, newShapeMismatch(a, b, "Expected both inputs to have the same shape. Got %v and %v instead", a, b)
 	}
 	return a.Clone(), nil
 }
@@ -0,0 +1,355 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// NLLOpt is a function that provides construction options for NLLLoss and CrossEntropy
+type NLLOpt func(op *nllLossOp)
+
+// WithClassWeights weighs the loss of each sample by the weight of its target class. There has to be one weight per class.
+// When the reduction is ReductionMean, the weighted losses are divided by the sum of the weights of the targets, not by the number of samples.
+func WithClassWeights(weights ...float64) NLLOpt {
+	return func(op *nllLossOp) {
+		op.weights = weights
+	}
+}
+
+// WithIgnoreIndex makes the samples whose target is idx contribute nothing to the loss (nor to the gradient).
+// They are not counted when the reduction is ReductionMean.
+func WithIgnoreIndex(idx int) NLLOpt {
+	return func(op *nllLossOp) {
+		op.ignoreIndex = idx
+		op.ignore = true
+	}
+}
+
+// NLLLoss is the negative log likelihood loss. logProbs is a matrix of log-probabilities of shape (samples, classes), typically the output of LogSoftMax.
+// targets is a vector of tensor.Int holding the class of each sample.
+//
+// The result is a scalar, unless the reduction is ReductionNone, in which case the result is a vector with the loss of each sample.
+func NLLLoss(logProbs, targets *Node, reduction Reduction, opts ...NLLOpt) (*Node, error) {
+	op := newNLLLossOp(reduction)
+	for _, opt := range opts {
+		opt(op)
+	}
+	if logProbs.Dims() != 2 {
+		return nil, errors.Errorf("Expected the log-probabilities of NLLLoss to be a matrix. Got %v instead", logProbs.Shape())
+	}
+	if op.weights != nil && len(op.weights) != logProbs.Shape()[1] {
+		return nil, newShapeMismatch(tensor.Shape{logProbs.Shape()[1]}, tensor.Shape{len(op.weights)}, "Expected %d class weights. Got %d instead", logProbs.Shape()[1], len(op.weights))
+	}
+	return ApplyOp(op, logProbs, targets)
+}
+
+type nllLossOp struct {
+	reduction   Reduction
+	weights     []float64
+	ignoreIndex int
+	ignore      bool
+}
+
+func newNLLLossOp(reduction Reduction) *nllLossOp { return &nllLossOp{reduction: reduction} }
+
+func (op *nllLossOp) Arity() int { return 2 }
+
+func (op *nllLossOp) ReturnsPtr() bool { return false }
+
+func (op *nllLossOp) CallsExtern() bool { return false }
+
+func (op *nllLossOp) WriteHash(h hash.Hash) { fmt.Fprint(h, op.String()) }
+
+func (op *nllLossOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op *nllLossOp) String() string {
+	s := fmt.Sprintf("NLLLoss{reduction=%d, weights=%v", op.reduction, op.weights)
+	if op.ignore {
+		s += fmt.Sprintf(", ignore=%d", op.ignoreIndex)
+	}
+	return s + "}"
+}
+
+func (op *nllLossOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	lp, ok := inputs[0].(tensor.Shape)
+	if !ok || lp.Dims() != 2 {
+		return nil, errors.Errorf("Expected the log-probabilities of NLLLoss to be a matrix. Got %v instead", inputs[0])
+	}
+	t, ok := inputs[1].(tensor.Shape)
+	if !ok || t.TotalSize() != lp[0] {
+		return nil, errors.Errorf("Expected %d targets. Got %v instead", lp[0], inputs[1])
+	}
+	if op.reduction == ReductionNone {
+		return tensor.Shape{lp[0]}, nil
+	}
+	return tensor.ScalarShape(), nil
+}
+
+func (op *nllLossOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	lp := makeTensorType(2, a)
+	t := makeTensorType(1, tensor.Int)
+	if op.reduction == ReductionNone {
+		return hm.NewFnType(lp, t, makeTensorType(1, a))
+	}
+	return hm.NewFnType(lp, t, a)
+}
+
+func (op *nllLossOp) OverwritesInput() int { return -1 }
+
+// sampleWeights returns the weight of each sample as given by its target, and the sum of the weights.
+func (op *nllLossOp) sampleWeights(targets []int, classes int) ([]float64, float64, error) {
+	ws := make([]float64, len(targets))
+	var total float64
+	for i, t := range targets {
+		if op.ignore && t == op.ignoreIndex {
+			continue
+		}
+		if t < 0 || t >= classes {
+			return nil, 0, errors.Errorf("Target %d of sample %d is out of range [0, %d)", t, i, classes)
+		}
+		ws[i] = 1
+		if op.weights != nil {
+			ws[i] = op.weights[t]
+		}
+		total += ws[i]
+	}
+	return ws, total, nil
+}
+
+func (op *nllLossOp) checkInput(inputs ...Value) (lp *tensor.Dense, targets []int, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return nil, nil, err
+	}
+	if lp, err = denseOf(inputs[0]); err != nil {
+		return nil, nil, errors.Wrap(err, "Bad log-probabilities for NLLLoss")
+	}
+	var t *tensor.Dense
+	if t, err = checkIndices(inputs[1]); err != nil {
+		return nil, nil, errors.Wrap(err, "Bad targets for NLLLoss")
+	}
+	targets = t.Ints()
+	if len(targets) != lp.Shape()[0] {
+		return nil, nil, newShapeMismatch(tensor.Shape{lp.Shape()[0]}, t.Shape(), "Expected %d targets. Got %d instead", lp.Shape()[0], len(targets))
+	}
+	return lp, targets, nil
+}
+
+func (op *nllLossOp) Do(inputs ...Value) (Value, error) {
+	lp, targets, err := op.checkInput(inputs...)
+	if err != nil {
+		return nil, err
+	}
+	classes := lp.Shape()[1]
+	ws, total, err := op.sampleWeights(targets, classes)
+	if err != nil {
+		return nil, err
+	}
+
+	losses := make([]float64, len(targets))
+	var sum float64
+	for i, t := range targets {
+		if ws[i] == 0 {
+			continue
+		}
+		var v float64
+		switch data := lp.Data().(type) {
+		case []float64:
+			v = data[i*classes+t]
+		case []float32:
+			v = float64(data[i*classes+t])
+		default:
+			return nil, errors.Errorf(nyiFail, "NLLLoss", lp.Dtype())
+		}
+		losses[i] = -ws[i] * v
+		sum += losses[i]
+	}
+
+	switch op.reduction {
+	case ReductionNone:
+		retVal := tensor.New(tensor.Of(lp.Dtype()), tensor.WithShape(len(targets)))
+		switch data := retVal.Data().(type) {
+		case []float64:
+			copy(data, losses)
+		case []float32:
+			for i, l := range losses {
+				data[i] = float32(l)
+			}
+		}
+		return retVal, nil
+	case ReductionMean:
+		if total != 0 {
+			sum /= total
+		}
+	}
+	if lp.Dtype() == tensor.Float32 {
+		return NewF32(float32(sum)), nil
+	}
+	return NewF64(sum), nil
+}
+
+// DiffWRT is an implementation for the SDOp interface
+func (op *nllLossOp) DiffWRT(inputs int) []bool {
+	if inputs != op.Arity() {
+		panic(fmt.Sprintf("NLLLoss operator needs %d inputs, got %d instead", op.Arity(), inputs))
+	}
+	return []bool{true, false}
+}
+
+// SymDiff applies the diff op. Implementation for SDOp interface.
+func (op *nllLossOp) SymDiff(inputs Nodes, output, grad *Node) (Nodes, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	diff, err := ApplyOp(&nllLossDiffOp{op}, inputs[0], inputs[1], grad)
+	if err != nil {
+		return nil, err
+	}
+	return Nodes{diff, nil}, nil
+}
+
+// DoDiff calculates the diff and sets its value to the output node. Implementation for ADOp interface.
+func (op *nllLossOp) DoDiff(ctx ExecutionContext, inputs Nodes, output *Node) error {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return err
+	}
+	xdv := inputs[0].boundTo.(*dualValue)
+	odv := output.boundTo.(*dualValue)
+
+	diff, err := (&nllLossDiffOp{op}).Do(inputs[0].Value(), inputs[1].Value(), odv.d)
+	if err != nil {
+		return err
+	}
+	d, err := denseOf(xdv.d)
+	if err != nil {
+		return err
+	}
+	if _, err = tensor.Add(d, diff, tensor.UseUnsafe()); err != nil {
+		return errors.Wrap(err, addFail)
+	}
+	return nil
+}
+
+// nllLossDiffOp computes the gradient of NLLLoss with regards to the log-probabilities.
+// It takes the log-probabilities (for their shape), the targets, and the gradient of the output.
+type nllLossDiffOp struct {
+	*nllLossOp
+}
+
+func (op *nllLossDiffOp) Arity() int { return 3 }
+
+func (op *nllLossDiffOp) WriteHash(h hash.Hash) { fmt.Fprint(h, op.String()) }
+
+func (op *nllLossDiffOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op *nllLossDiffOp) String() string { return "Diff" + op.nllLossOp.String() }
+
+func (op *nllLossDiffOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	lp, ok := inputs[0].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected the log-probabilities to have a shape. Got %v instead", inputs[0])
+	}
+	return lp.Clone(), nil
+}
+
+func (op *nllLossDiffOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	lp := makeTensorType(2, a)
+	t := makeTensorType(1, tensor.Int)
+	var g hm.Type = a
+	if op.reduction == ReductionNone {
+		g = makeTensorType(1, a)
+	}
+	return hm.NewFnType(lp, t, g, lp)
+}
+
+// DiffWRT is an implementation for the SDOp interface.
+func (op *nllLossDiffOp) DiffWRT(inputs int) []bool { return make([]bool, inputs) }
+
+func (op *nllLossDiffOp) Do(inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	lp, targets, err := op.checkInput(inputs[0], inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	classes := lp.Shape()[1]
+	ws, total, err := op.sampleWeights(targets, classes)
+	if err != nil {
+		return nil, err
+	}
+
+	// the gradient of the output is either a scalar or (for ReductionNone) one value per sample
+	var grads []float64
+	switch g := inputs[2].(type) {
+	case *F64:
+		grads = []float64{float64(*g)}
+	case *F32:
+		grads = []float64{float64(*g)}
+	default:
+		gd, err := denseOf(g)
+		if err != nil {
+			return nil, errors.Wrap(err, "Bad gradient for NLLLoss")
+		}
+		switch data := gd.Data().(type) {
+		case []float64:
+			grads = data
+		case float64:
+			grads = []float64{data}
+		case []float32:
+			grads = make([]float64, len(data))
+			for i, v := range data {
+				grads[i] = float64(v)
+			}
+		case float32:
+			grads = []float64{float64(data)}
+		default:
+			return nil, errors.Errorf(nyiFail, "NLLLoss gradient", gd.Dtype())
+		}
+	}
+
+	retVal := tensor.New(tensor.Of(lp.Dtype()), tensor.WithShape(lp.Shape().Clone()...))
+	for i, t := range targets {
+		if ws[i] == 0 {
+			continue
+		}
+		var d float64
+		switch op.reduction {
+		case ReductionNone:
+			d = -ws[i] * grads[i]
+		case ReductionMean:
+			d = -ws[i] * grads[0] / total
+		default:
+			d = -ws[i] * grads[0]
+		}
+		switch data := retVal.Data().(type) {
+		case []float64:
+			data[i*classes+t] = d
+		case []float32:
+			data[i*classes+t] = float32(d)
+		default:
+			return nil, errors.Errorf(nyiFail, "NLLLoss", lp.Dtype())
+		}
+	}
+	return retVal, nil
+}
+
+// ensure it complies with the Op interface
+var (
+	_ Op = &nllLossDiffOp{}
+
+	_ Op   = &nllLossOp{}
+	_ SDOp = &nllLossOp{}
+	_ ADOp = &nllLossOp{}
+)
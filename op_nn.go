@@ -1260,19 +1260,19 @@ func (op *BatchNormOp) Stats() (runningMean tensor.Tensor, runningVariance tenso
 // SetStats sets the running mean and running variance. The given values are copied
 func (op *BatchNormOp) SetStats(runningMean tensor.Tensor, runningVariance tensor.Tensor) error {
 	if !runningMean.Shape().Eq(op.runningMean.Shape()) {
-		return fmt.Errorf("invalid runningMean shape %v. Expected: %v", runningMean.Shape(), op.runningMean.Shape())
+		return newShapeMismatch(op.runningMean.Shape(), runningMean.Shape(), "invalid runningMean shape %v. Expected: %v", runningMean.Shape(), op.runningMean.Shape())
 	}
 
 	if runningMean.Dtype() != op.runningMean.Dtype() {
-		return fmt.Errorf("invalid runningMean type %v. Expected: %v", runningMean.Dtype(), op.runningMean.Dtype())
+		return newDtypeMismatch(op.runningMean.Dtype(), runningMean.Dtype(), "invalid runningMean type %v. Expected: %v", runningMean.Dtype(), op.runningMean.Dtype())
 	}
 
 	if !runningVariance.Shape().Eq(op.runningVariance.Shape()) {
-		return fmt.Errorf("invalid runningVariance shape %v. Expected: %v", runningMean.Shape(), op.runningMean.Shape())
+		return newShapeMismatch(op.runningVariance.Shape(), runningVariance.Shape(), "invalid runningVariance shape %v. Expected: %v", runningVariance.Shape(), op.runningVariance.Shape())
 	}
 
 	if runningVariance.Dtype() != op.runningVariance.Dtype() {
-		return fmt.Errorf("invalid runningVariance type %v. Expected: %v", runningMean.Dtype(), op.runningMean.Dtype())
+		return newDtypeMismatch(op.runningVariance.Dtype(), runningVariance.Dtype(), "invalid runningVariance type %v. Expected: %v", runningVariance.Dtype(), op.runningVariance.Dtype())
 	}
 
 	switch op.runningMean.Dtype() {
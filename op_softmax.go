@@ -15,6 +15,14 @@ func SoftMax(x *Node, axes ...int) (*Node, error) {
 	return ApplyOp(op, x)
 }
 
+// LogSoftMax computes log(softmax(x)) in a numerically stable manner. It is equivalent to Log(SoftMax(x)) after stabilization.
+func LogSoftMax(x *Node, axes ...int) (*Node, error) {
+	op := newSoftmaxOp(x.Shape(), axes...)
+	op.isLog = true
+
+	return ApplyOp(op, x)
+}
+
 type softmaxOp struct {
 	shape tensor.Shape
 	axis  int
@@ -40,7 +48,7 @@ func (op *softmaxOp) ReturnsPtr() bool { return false }
 
 func (op *softmaxOp) CallsExtern() bool { return false }
 
-func (op *softmaxOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "Softmax{%v}()", op.axis) }
+func (op *softmaxOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "Softmax{%v, %v}()", op.axis, op.isLog) }
 
 func (op *softmaxOp) Hashcode() uint32 { return simpleHash(op) }
 
@@ -1158,7 +1158,7 @@ func (op reshapeOp) Do(vals ...Value) (Value, error) {
 			}
 		}
 		if val.Shape().TotalSize() != op.from.TotalSize() {
-			return nil, errors.Errorf("Shape mismatch. Input shape is %v. Expected %v", val.Shape(), op.from)
+			return nil, newShapeMismatch(op.from, val.Shape(), "Shape mismatch. Input shape is %v. Expected %v", val.Shape(), op.from)
 		}
 
 		if err := val.(tensor.Tensor).Reshape(op.to...); err != nil {
@@ -0,0 +1,329 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Where selects the values of a where cond is true, and the values of b otherwise. cond, a and b must all have the same shape.
+// cond is typically the result of a comparison such as Gt. It may be a tensor of tensor.Bool, or a tensor of numbers, in which case non-zero values are true.
+//
+// The gradients of a and b are the gradient of the output at the positions selected from a and b respectively. cond is not differentiable.
+func Where(cond, a, b *Node) (*Node, error) {
+	op := newWhereOp(a.Dims())
+	return ApplyOp(op, cond, a, b)
+}
+
+// whereMask returns the truthiness of every value of cond
+func whereMask(v Value) ([]bool, error) {
+	cond, err := denseOf(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "Bad condition")
+	}
+	switch data := cond.Data().(type) {
+	case []bool:
+		return data, nil
+	case []float64:
+		retVal := make([]bool, len(data))
+		for i, v := range data {
+			retVal[i] = v != 0
+		}
+		return retVal, nil
+	case []float32:
+		retVal := make([]bool, len(data))
+		for i, v := range data {
+			retVal[i] = v != 0
+		}
+		return retVal, nil
+	case []int:
+		retVal := make([]bool, len(data))
+		for i, v := range data {
+			retVal[i] = v != 0
+		}
+		return retVal, nil
+	default:
+		return nil, errors.Errorf(nyiFail, "Where condition", cond.Dtype())
+	}
+}
+
+// whereKernel writes a[i] into dst[i] where mask[i] is true, and b[i] otherwise. A nil a or b is taken to be zero.
+func whereKernel(dst *tensor.Dense, mask []bool, a, b *tensor.Dense) error {
+	if len(mask) != dst.Shape().TotalSize() {
+		return newShapeMismatch(tensor.Shape{dst.Shape().TotalSize()}, tensor.Shape{len(mask)}, "Expected a condition of %d values. Got %d instead", dst.Shape().TotalSize(), len(mask))
+	}
+	switch d := dst.Data().(type) {
+	case []float64:
+		var as, bs []float64
+		if a != nil {
+			as = a.Data().([]float64)
+		}
+		if b != nil {
+			bs = b.Data().([]float64)
+		}
+		for i, m := range mask {
+			switch {
+			case m && as != nil:
+				d[i] = as[i]
+			case !m && bs != nil:
+				d[i] = bs[i]
+			default:
+				d[i] = 0
+			}
+		}
+	case []float32:
+		var as, bs []float32
+		if a != nil {
+			as = a.Data().([]float32)
+		}
+		if b != nil {
+			bs = b.Data().([]float32)
+		}
+		for i, m := range mask {
+			switch {
+			case m && as != nil:
+				d[i] = as[i]
+			case !m && bs != nil:
+				d[i] = bs[i]
+			default:
+				d[i] = 0
+			}
+		}
+	case []int:
+		var as, bs []int
+		if a != nil {
+			as = a.Data().([]int)
+		}
+		if b != nil {
+			bs = b.Data().([]int)
+		}
+		for i, m := range mask {
+			switch {
+			case m && as != nil:
+				d[i] = as[i]
+			case !m && bs != nil:
+				d[i] = bs[i]
+			default:
+				d[i] = 0
+			}
+		}
+	default:
+		return errors.Errorf(nyiFail, "Where", dst.Dtype())
+	}
+	return nil
+}
+
+type whereOp struct {
+	dims int
+}
+
+func newWhereOp(dims int) *whereOp { return &whereOp{dims: dims} }
+
+func (op *whereOp) Arity() int { return 3 }
+
+func (op *whereOp) ReturnsPtr() bool { return false }
+
+func (op *whereOp) CallsExtern() bool { return false }
+
+func (op *whereOp) WriteHash(h hash.Hash) { fmt.Fprint(h, op.String()) }
+
+func (op *whereOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op *whereOp) String() string { return "Where" }
+
+func (op *whereOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	shapes := make([]tensor.Shape, len(inputs))
+	for i, in := range inputs {
+		s, ok := in.(tensor.Shape)
+		if !ok {
+			return nil, errors.Errorf("Expected input %d of Where to have a shape. Got %v instead", i, in)
+		}
+		shapes[i] = s
+	}
+	for _, i := range []int{0, 2} {
+		if !shapes[i].Eq(shapes[1]) {
+			return nil, newShapeMismatch(shapes[1], shapes[i], "Expected the condition and both choices to have the same shape. Got %v, %v and %v", shapes[0], shapes[1], shapes[2])
+		}
+	}
+	return shapes[1].Clone(), nil
+}
+
+func (op *whereOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	c := hm.TypeVariable('c') // the condition may have any Dtype
+	t := makeTensorType(op.dims, a)
+	ct := makeTensorType(op.dims, c)
+
+	return hm.NewFnType(ct, t, t, t)
+}
+
+func (op *whereOp) OverwritesInput() int { return -1 }
+
+func (op *whereOp) Do(inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	mask, err := whereMask(inputs[0])
+	if err != nil {
+		return nil, err
+	}
+	a, err := denseOf(inputs[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "Bad input for Where")
+	}
+	b, err := denseOf(inputs[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "Bad input for Where")
+	}
+	if a.Dtype() != b.Dtype() {
+		return nil, newDtypeMismatch(a.Dtype(), b.Dtype(), "Expected both choices of Where to have the same Dtype. Got %v and %v", a.Dtype(), b.Dtype())
+	}
+
+	retVal := tensor.New(tensor.Of(a.Dtype()), tensor.WithShape(a.Shape().Clone()...))
+	if err = whereKernel(retVal, mask, a, b); err != nil {
+		return nil, err
+	}
+	return retVal, nil
+}
+
+// DiffWRT is an implementation for the SDOp interface
+func (op *whereOp) DiffWRT(inputs int) []bool {
+	if inputs != op.Arity() {
+		panic(fmt.Sprintf("Where operator needs %d inputs, got %d instead", op.Arity(), inputs))
+	}
+	return []bool{false, true, true}
+}
+
+// SymDiff applies the diff op. Implementation for SDOp interface.
+func (op *whereOp) SymDiff(inputs Nodes, output, grad *Node) (retVal Nodes, err error) {
+	if err = checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	cond := inputs[0]
+
+	retVal = make(Nodes, op.Arity())
+	if retVal[1], err = ApplyOp(&whereDiffOp{whereOp: op, first: true}, cond, grad); err != nil {
+		return nil, err
+	}
+	if retVal[2], err = ApplyOp(&whereDiffOp{whereOp: op, first: false}, cond, grad); err != nil {
+		return nil, err
+	}
+	return retVal, nil
+}
+
+// DoDiff calculates the diff and sets its value to the output node. Implementation for ADOp interface.
+func (op *whereOp) DoDiff(ctx ExecutionContext, inputs Nodes, output *Node) error {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return err
+	}
+	adv := inputs[1].boundTo.(*dualValue)
+	bdv := inputs[2].boundTo.(*dualValue)
+	odv := output.boundTo.(*dualValue)
+
+	mask, err := whereMask(inputs[0].Value())
+	if err != nil {
+		return err
+	}
+	grad, err := denseOf(odv.d)
+	if err != nil {
+		return err
+	}
+
+	for i, dv := range []*dualValue{adv, bdv} {
+		d, err := denseOf(dv.d)
+		if err != nil {
+			return err
+		}
+		masked := tensor.New(tensor.Of(grad.Dtype()), tensor.WithShape(grad.Shape().Clone()...))
+		if i == 0 {
+			err = whereKernel(masked, mask, grad, nil)
+		} else {
+			err = whereKernel(masked, mask, nil, grad)
+		}
+		if err != nil {
+			return err
+		}
+		if _, err = tensor.Add(d, masked, tensor.UseUnsafe()); err != nil {
+			return errors.Wrap(err, addFail)
+		}
+	}
+	return nil
+}
+
+// whereDiffOp keeps the gradient where the condition selects the first choice (or the second, if first is false), and zeroes it elsewhere.
+// It takes the condition and the gradient.
+type whereDiffOp struct {
+	*whereOp
+	first bool
+}
+
+func (op *whereDiffOp) Arity() int { return 2 }
+
+func (op *whereDiffOp) WriteHash(h hash.Hash) { fmt.Fprint(h, op.String()) }
+
+func (op *whereDiffOp) Hashcode() uint32 { return simpleHash(op) }
+
+func (op *whereDiffOp) String() string { return fmt.Sprintf("WhereDiff{first=%t}", op.first) }
+
+func (op *whereDiffOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	grad, ok := inputs[1].(tensor.Shape)
+	if !ok {
+		return nil, errors.Errorf("Expected the gradient to have a shape. Got %v instead", inputs[1])
+	}
+	return grad.Clone(), nil
+}
+
+func (op *whereDiffOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	c := hm.TypeVariable('c')
+	t := makeTensorType(op.dims, a)
+	ct := makeTensorType(op.dims, c)
+
+	return hm.NewFnType(ct, t, t)
+}
+
+// DiffWRT is an implementation for the SDOp interface.
+func (op *whereDiffOp) DiffWRT(inputs int) []bool { return make([]bool, inputs) }
+
+func (op *whereDiffOp) Do(inputs ...Value) (Value, error) {
+	if err := checkArity(op, len(inputs)); err != nil {
+		return nil, err
+	}
+	mask, err := whereMask(inputs[0])
+	if err != nil {
+		return nil, err
+	}
+	grad, err := denseOf(inputs[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "Bad gradient for WhereDiff")
+	}
+
+	retVal := tensor.New(tensor.Of(grad.Dtype()), tensor.WithShape(grad.Shape().Clone()...))
+	if op.first {
+		err = whereKernel(retVal, mask, grad, nil)
+	} else {
+		err = whereKernel(retVal, mask, nil, grad)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return retVal, nil
+}
+
+// ensure it complies with the Op interface
+var (
+	_ Op = &whereDiffOp{}
+
+	_ Op   = &whereOp{}
+	_ SDOp = &whereOp{}
+	_ ADOp = &whereOp{}
+)
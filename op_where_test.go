@@ -0,0 +1,89 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/tensor"
+)
+
+func TestWhereOpDo(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		cond     tensor.Tensor
+		expected []float64
+	}{
+		{
+			desc:     "bool",
+			cond:     tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]bool{true, false, false, true})),
+			expected: []float64{1, 20, 30, 4},
+		},
+		{
+			desc:     "float",
+			cond:     tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{0, 0.5, -1, 0})),
+			expected: []float64{10, 2, 3, 40},
+		},
+	}
+
+	for _, tcase := range testCases {
+		t.Run(tcase.desc, func(t *testing.T) {
+			c := require.New(t)
+
+			a := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{1, 2, 3, 4}))
+			b := tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{10, 20, 30, 40}))
+
+			output, err := newWhereOp(2).Do(tcase.cond, a, b)
+			c.NoError(err)
+			c.Equal(tcase.expected, output.Data())
+		})
+	}
+}
+
+func TestWhereGrad(t *testing.T) {
+	c := require.New(t)
+
+	g := NewGraph()
+	a := NewVector(g, tensor.Float64, WithShape(4), WithName("a"), WithValue(tensor.New(tensor.WithBacking([]float64{-1, 2, -3, 4}))))
+	b := NewVector(g, tensor.Float64, WithShape(4), WithName("b"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 1, 1, 1}))))
+	zero := NewVector(g, tensor.Float64, WithShape(4), WithName("zero"), WithInit(Zeroes()))
+
+	cond, err := Gt(a, zero, false)
+	c.NoError(err)
+
+	w, err := Where(cond, a, Must(Mul(b, NewConstant(3.0))))
+	c.NoError(err)
+
+	cost := Must(Sum(w))
+	grads, err := Grad(cost, a, b)
+	c.NoError(err)
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	c.NoError(m.RunAll())
+
+	c.Equal([]float64{3, 2, 3, 4}, w.Value().Data())
+	c.Equal([]float64{0, 1, 0, 1}, grads[0].Value().Data())
+	c.Equal([]float64{3, 0, 3, 0}, grads[1].Value().Data())
+
+	bad := NewGraph()
+	_, err = Where(NewVector(bad, tensor.Bool, WithShape(3)), NewVector(bad, tensor.Float64, WithShape(3)), NewVector(bad, tensor.Float64, WithShape(2)))
+	c.Error(err)
+
+	// the lisp machine uses DoDiff
+	g2 := NewGraph()
+	a2 := NewVector(g2, tensor.Float64, WithShape(2), WithName("a"), WithValue(tensor.New(tensor.WithBacking([]float64{1, 2}))))
+	b2 := NewVector(g2, tensor.Float64, WithShape(2), WithName("b"), WithValue(tensor.New(tensor.WithBacking([]float64{3, 4}))))
+	cond2 := NewVector(g2, tensor.Bool, WithShape(2), WithName("cond"), WithValue(tensor.New(tensor.WithBacking([]bool{false, true}))))
+	Must(Sum(Must(Where(cond2, a2, b2))))
+
+	lm := NewLispMachine(g2)
+	defer lm.Close()
+	c.NoError(lm.RunAll())
+
+	ga, err := a2.Grad()
+	c.NoError(err)
+	gb, err := b2.Grad()
+	c.NoError(err)
+	c.Equal([]float64{0, 1}, ga.Data())
+	c.Equal([]float64{1, 0}, gb.Data())
+}
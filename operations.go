@@ -16,27 +16,8 @@ func binOpNode(op BinaryOp, a, b *Node) (retVal *Node, err error) {
 	stabLogf("Creating node for %v, a: %p, b: %p", op, a, b)
 	enterLogScope()
 	defer leaveLogScope()
-	// maybe make stabilization a build tag?
-	if stabilization {
-		enterLogScope()
-		if ebo, ok := op.(elemBinOp); ok {
-			ot := ebo.binOpType()
-
-			enterLogScope()
-			for _, fn := range binOpStabilizationFns[ot] {
-				if retVal, err = fn(a, b); err == nil {
-					leaveLogScope()
-					return
-				}
-
-				if _, ok := err.(errNoStabilization); !ok {
-					leaveLogScope()
-					return
-				}
-			}
-			leaveLogScope()
-		}
-		leaveLogScope()
+	if retVal, err = rewriteEager(op, a, b); retVal != nil || err != nil {
+		return
 	}
 	stabLogf("No bin op stabilization")
 	return ApplyOp(op, a, b)
@@ -125,7 +106,7 @@ func Auto(op func(a, b *Node, leftPattern, rightPattern []byte) (*Node, error),
 	bShape := b.Shape()
 
 	if aShape.Dims() != bShape.Dims() {
-		return nil, fmt.Errorf("shapes %v and %v should have the same dimensions", aShape, bShape)
+		return nil, newShapeMismatch(aShape, bShape, "shapes %v and %v should have the same dimensions", aShape, bShape)
 	}
 
 	var (
@@ -149,28 +130,8 @@ func unaryOpNode(op Op, a *Node) (retVal *Node, err error) {
 	stabLogf("Creating node for %v, a: %p %v", op, a, a)
 	enterLogScope()
 	defer leaveLogScope()
-	if stabilization {
-
-		// do optimization/stabilization
-		// TODO: maybe recursively stabilize?
-		enterLogScope()
-		ot := op.(elemUnaryOp).unaryOpType()
-		for _, fn := range unaryOpStabilizationFns[ot] {
-			if retVal, err = fn(a); err == nil {
-				stabLogf("stabilized")
-				leaveLogScope()
-				return
-			}
-
-			if _, ok := err.(errNoStabilization); !ok {
-				stabLogf("Actual error")
-				leaveLogScope()
-				return
-			}
-			stabLogf("No stabilization found")
-		}
-		leaveLogScope()
-		stabLogf("No stabilizations - retVal: %v", retVal)
+	if retVal, err = rewriteEager(op, a); retVal != nil || err != nil {
+		return
 	}
 
 	return ApplyOp(op, a)
@@ -494,7 +455,7 @@ func Concat(axis int, ns ...*Node) (retVal *Node, err error) {
 		}
 
 		if n.shape.Dims() != d {
-			err = errors.Errorf("Dimension mismatch. Expected all the nodes to be concatenated to have %d dimensions. Got %d instead", d, n.shape.Dims())
+			err = newShapeMismatch(ns[0].shape, n.shape, "Dimension mismatch. Expected all the nodes to be concatenated to have %d dimensions. Got %d instead", d, n.shape.Dims())
 			return
 		}
 	}
@@ -575,14 +536,14 @@ func Reshape(n *Node, to tensor.Shape) (retVal *Node, err error) {
 		}
 		inferred, rem := divmod(n.Shape().TotalSize(), prod)
 		if rem != 0 {
-			return nil, errors.Errorf("Cannot reshape %v to %v", n.Shape(), to)
+			return nil, newShapeMismatch(n.Shape(), to, "Cannot reshape %v to %v", n.Shape(), to)
 		}
 		to[infer] = inferred
 	}
 
 	// the Node n might not have shape at this point, in that case we skip the check
 	if n.Shape().Dims() > 0 && n.Shape().TotalSize() != to.TotalSize() {
-		return nil, errors.Errorf("shape size doesn't not match. Expected %v, got %v", n.Shape().TotalSize(), to.TotalSize())
+		return nil, newShapeMismatch(n.Shape(), to, "shape size doesn't not match. Expected %v, got %v", n.Shape().TotalSize(), to.TotalSize())
 	}
 
 	op := reshapeOp{
@@ -636,7 +597,7 @@ func Tensordot(aAxes []int, bAxes []int, a, b *Node) (retVal *Node, err error) {
 
 	for aAxis, aDim := range aAxes {
 		if aShape[aDim] != bShape[bAxes[aAxis]] {
-			return nil, errors.New("Dimension mismatch: Can't contract tensors along supplied axes")
+			return nil, newShapeMismatch(aShape, bShape, "Dimension mismatch: Can't contract tensors along supplied axes")
 		}
 	}
 
@@ -45,7 +45,7 @@ func (o scalarBinOp) Do(same bool, vals ...Value) (retVal Value, err error) {
 	at := TypeOf(vals[0])
 	bt := TypeOf(vals[1])
 	if !at.Eq(bt) {
-		err = errors.Errorf("Type Mismatch: %v != %v", at, bt)
+		err = newDtypeMismatch(vals[0].Dtype(), vals[1].Dtype(), "Type Mismatch: %v != %v", at, bt)
 		return
 	}
 
@@ -366,7 +366,7 @@ func (o tBinOp) do(vals []Value, opts ...tensor.FuncOpt) (retVal Value, err erro
 	d1 := vals[1].Dtype()
 
 	if d0 != d1 {
-		return nil, errors.Errorf("Dtype mismatch for bin op: %v and %v", d0, d1)
+		return nil, newDtypeMismatch(d0, d1, "Dtype mismatch for bin op: %v and %v", d0, d1)
 	}
 
 	// extract the goddamn values
@@ -773,3 +773,74 @@ func softplusDiff(x, y *Node) (err error) {
 	}
 	return
 }
+
+// activationDiffExpr is the diff expression of the activation functions whose derivatives are unary operators themselves.
+func activationDiffExpr(deriv ʘUnaryOperatorType, x, gradY *Node) (retVal *Node, err error) {
+	if retVal, err = unaryOpNode(newElemUnaryOp(deriv, x), x); err != nil {
+		return nil, errors.Wrapf(err, "Failed to carry %v()", deriv)
+	}
+	WithGroupName(gradClust)(retVal)
+	return HadamardProd(retVal, gradY)
+}
+
+func activationDiff(deriv ʘUnaryOperatorType, x, y *Node) (err error) {
+	xdv, ydv := getDV(x, y)
+
+	op := newElemUnaryOp(deriv, x)
+
+	var d Value
+	if d, err = op.Do(xdv.Value); err != nil {
+		return errors.Wrapf(err, doFail, op)
+	}
+
+	if dT, ok := d.(tensor.Tensor); ok {
+		defer returnTensor(dT)
+	}
+
+	mul := newElemBinOp(mulOpType, x, y)
+	err = mul.IncrDo(xdv.d, d, ydv.d)
+	if err = checkErrSetDeriv(err, xdv); err != nil {
+		return errors.Wrapf(err, autodiffFail, x)
+	}
+	return
+}
+
+func geluDiffExpr(x, y, gradY *Node) (*Node, error) {
+	return activationDiffExpr(geluDerivOpType, x, gradY)
+}
+func geluDiff(x, y *Node) error { return activationDiff(geluDerivOpType, x, y) }
+
+func geluTanhDiffExpr(x, y, gradY *Node) (*Node, error) {
+	return activationDiffExpr(geluTanhDerivOpType, x, gradY)
+}
+func geluTanhDiff(x, y *Node) error { return activationDiff(geluTanhDerivOpType, x, y) }
+
+func siluDiffExpr(x, y, gradY *Node) (*Node, error) {
+	return activationDiffExpr(siluDerivOpType, x, gradY)
+}
+func siluDiff(x, y *Node) error { return activationDiff(siluDerivOpType, x, y) }
+
+func eluDiffExpr(x, y, gradY *Node) (*Node, error) {
+	return activationDiffExpr(eluDerivOpType, x, gradY)
+}
+func eluDiff(x, y *Node) error { return activationDiff(eluDerivOpType, x, y) }
+
+func seluDiffExpr(x, y, gradY *Node) (*Node, error) {
+	return activationDiffExpr(seluDerivOpType, x, gradY)
+}
+func seluDiff(x, y *Node) error { return activationDiff(seluDerivOpType, x, y) }
+
+func hardSigmoidDiffExpr(x, y, gradY *Node) (*Node, error) {
+	return activationDiffExpr(hardSigmoidDerivOpType, x, gradY)
+}
+func hardSigmoidDiff(x, y *Node) error { return activationDiff(hardSigmoidDerivOpType, x, y) }
+
+func hardSwishDiffExpr(x, y, gradY *Node) (*Node, error) {
+	return activationDiffExpr(hardSwishDerivOpType, x, gradY)
+}
+func hardSwishDiff(x, y *Node) error { return activationDiff(hardSwishDerivOpType, x, y) }
+
+func softsignDiffExpr(x, y, gradY *Node) (*Node, error) {
+	return activationDiffExpr(softsignDerivOpType, x, gradY)
+}
+func softsignDiff(x, y *Node) error { return activationDiff(softsignDerivOpType, x, y) }
@@ -40,6 +40,26 @@ var (
 	// softplus isn't necessarily only a numerical stabilization op
 	// (you can use it elsewhere), but I included it under numerical optimization
 
+	// more activation functions
+	geluf64        = sf64UnaryOperator(_geluf64)
+	geluTanhf64    = sf64UnaryOperator(_geluTanhf64)
+	siluf64        = sf64UnaryOperator(_siluf64)
+	eluf64         = sf64UnaryOperator(_eluf64)
+	seluf64        = sf64UnaryOperator(_seluf64)
+	hardSigmoidf64 = sf64UnaryOperator(_hardSigmoidf64)
+	hardSwishf64   = sf64UnaryOperator(_hardSwishf64)
+	softsignf64    = sf64UnaryOperator(_softsignf64)
+
+	// derivatives of the activation functions above
+	geluDerivf64        = sf64UnaryOperator(_geluDerivf64)
+	geluTanhDerivf64    = sf64UnaryOperator(_geluTanhDerivf64)
+	siluDerivf64        = sf64UnaryOperator(_siluDerivf64)
+	eluDerivf64         = sf64UnaryOperator(_eluDerivf64)
+	seluDerivf64        = sf64UnaryOperator(_seluDerivf64)
+	hardSigmoidDerivf64 = sf64UnaryOperator(_hardSigmoidDerivf64)
+	hardSwishDerivf64   = sf64UnaryOperator(_hardSwishDerivf64)
+	softsignDerivf64    = sf64UnaryOperator(_softsignDerivf64)
+
 	/* Float32 */
 
 	// non differentiable
@@ -69,6 +89,26 @@ var (
 	log1pf32    = sf32UnaryOperator(math32.Log1p)
 	expm1f32    = sf32UnaryOperator(math32.Expm1)
 	softplusf32 = sf32UnaryOperator(_softplusf32)
+
+	// more activation functions
+	geluf32        = sf32UnaryOperator(_geluf32)
+	geluTanhf32    = sf32UnaryOperator(_geluTanhf32)
+	siluf32        = sf32UnaryOperator(_siluf32)
+	eluf32         = sf32UnaryOperator(_eluf32)
+	seluf32        = sf32UnaryOperator(_seluf32)
+	hardSigmoidf32 = sf32UnaryOperator(_hardSigmoidf32)
+	hardSwishf32   = sf32UnaryOperator(_hardSwishf32)
+	softsignf32    = sf32UnaryOperator(_softsignf32)
+
+	// derivatives of the activation functions above
+	geluDerivf32        = sf32UnaryOperator(_geluDerivf32)
+	geluTanhDerivf32    = sf32UnaryOperator(_geluTanhDerivf32)
+	siluDerivf32        = sf32UnaryOperator(_siluDerivf32)
+	eluDerivf32         = sf32UnaryOperator(_eluDerivf32)
+	seluDerivf32        = sf32UnaryOperator(_seluDerivf32)
+	hardSigmoidDerivf32 = sf32UnaryOperator(_hardSigmoidDerivf32)
+	hardSwishDerivf32   = sf32UnaryOperator(_hardSwishDerivf32)
+	softsignDerivf32    = sf32UnaryOperator(_softsignDerivf32)
 )
 
 type ʘUnaryOperatorType byte
@@ -101,6 +141,26 @@ const (
 	expm1OpType
 	softplusOpType
 
+	// more activation functions
+	geluOpType
+	geluTanhOpType
+	siluOpType
+	eluOpType
+	seluOpType
+	hardSigmoidOpType
+	hardSwishOpType
+	softsignOpType
+
+	// derivatives of the activation functions above. They are not differentiable themselves
+	geluDerivOpType
+	geluTanhDerivOpType
+	siluDerivOpType
+	eluDerivOpType
+	seluDerivOpType
+	hardSigmoidDerivOpType
+	hardSwishDerivOpType
+	softsignDerivOpType
+
 	maxʘUnaryOperator // delimits end of all possible unary ops
 )
 
@@ -122,6 +182,9 @@ var ʘUnaryOpStrs = [maxʘUnaryOperator]string{
 	"cube", "tanh", "sigmoid",
 
 	"log1p", "expm1", "softplus",
+
+	"gelu", "geluTanh", "silu", "elu", "selu", "hardSigmoid", "hardSwish", "softsign",
+	"geluDeriv", "geluTanhDeriv", "siluDeriv", "eluDeriv", "seluDeriv", "hardSigmoidDeriv", "hardSwishDeriv", "softsignDeriv",
 }
 
 // ʘUnaryOpDifferentiable is the array of whether a unary operator is differentiable
@@ -134,6 +197,9 @@ var ʘUnaryOpDifferentiable = [maxʘUnaryOperator]bool{
 	true, true, true,
 
 	true, true, true,
+
+	true, true, true, true, true, true, true, true,
+	false, false, false, false, false, false, false, false,
 }
 
 var ʘUnaryOpDiffExprs = [maxʘUnaryOperator]func(x, y, gradY *Node) (*Node, error){
@@ -143,6 +209,9 @@ var ʘUnaryOpDiffExprs = [maxʘUnaryOperator]func(x, y, gradY *Node) (*Node, err
 	inverseDiffExpr, inverseSqrtDiffExpr, cubeDiffExpr, tanhDiffExpr, sigmoidDiffExpr,
 
 	log1pDiffExpr, expm1DiffExpr, softplusDiffExpr,
+
+	geluDiffExpr, geluTanhDiffExpr, siluDiffExpr, eluDiffExpr, seluDiffExpr, hardSigmoidDiffExpr, hardSwishDiffExpr, softsignDiffExpr,
+	nondiffUnaryOpExpr, nondiffUnaryOpExpr, nondiffUnaryOpExpr, nondiffUnaryOpExpr, nondiffUnaryOpExpr, nondiffUnaryOpExpr, nondiffUnaryOpExpr, nondiffUnaryOpExpr,
 }
 
 var ʘUnaryOpDiffFns = [maxʘUnaryOperator]func(x, y *Node) error{
@@ -152,6 +221,9 @@ var ʘUnaryOpDiffFns = [maxʘUnaryOperator]func(x, y *Node) error{
 	inverseDiff, inverseSqrtDiff, cubeDiff, tanhDiff, sigmoidDiff,
 
 	log1pDiff, expm1Diff, softplusDiff,
+
+	geluDiff, geluTanhDiff, siluDiff, eluDiff, seluDiff, hardSigmoidDiff, hardSwishDiff, softsignDiff,
+	nondiffUnaryOp, nondiffUnaryOp, nondiffUnaryOp, nondiffUnaryOp, nondiffUnaryOp, nondiffUnaryOp, nondiffUnaryOp, nondiffUnaryOp,
 }
 
 var sf64UnaryOperators = [maxʘUnaryOperator]*sf64UnaryOperator{
@@ -176,6 +248,23 @@ var sf64UnaryOperators = [maxʘUnaryOperator]*sf64UnaryOperator{
 	&log1pf64,
 	&expm1f64,
 	&softplusf64,
+
+	&geluf64,
+	&geluTanhf64,
+	&siluf64,
+	&eluf64,
+	&seluf64,
+	&hardSigmoidf64,
+	&hardSwishf64,
+	&softsignf64,
+	&geluDerivf64,
+	&geluTanhDerivf64,
+	&siluDerivf64,
+	&eluDerivf64,
+	&seluDerivf64,
+	&hardSigmoidDerivf64,
+	&hardSwishDerivf64,
+	&softsignDerivf64,
 }
 
 var sf32UnaryOperators = [maxʘUnaryOperator]*sf32UnaryOperator{
@@ -200,4 +289,21 @@ var sf32UnaryOperators = [maxʘUnaryOperator]*sf32UnaryOperator{
 	&log1pf32,
 	&expm1f32,
 	&softplusf32,
+
+	&geluf32,
+	&geluTanhf32,
+	&siluf32,
+	&eluf32,
+	&seluf32,
+	&hardSigmoidf32,
+	&hardSwishf32,
+	&softsignf32,
+	&geluDerivf32,
+	&geluTanhDerivf32,
+	&siluDerivf32,
+	&eluDerivf32,
+	&seluDerivf32,
+	&hardSigmoidDerivf32,
+	&hardSwishDerivf32,
+	&softsignDerivf32,
 }
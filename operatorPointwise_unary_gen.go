@@ -3,7 +3,6 @@ package gorgonia
 // Code generated by genapi, which is a API generation tool for Gorgonia. DO NOT EDIT.
 
 func (f *sf32UnaryOperator) unaryOpType() ʘUnaryOperatorType {
-
 	switch f {
 	case &absf32:
 		return absOpType
@@ -45,13 +44,45 @@ func (f *sf32UnaryOperator) unaryOpType() ʘUnaryOperatorType {
 		return expm1OpType
 	case &softplusf32:
 		return softplusOpType
+	case &geluf32:
+		return geluOpType
+	case &geluTanhf32:
+		return geluTanhOpType
+	case &siluf32:
+		return siluOpType
+	case &eluf32:
+		return eluOpType
+	case &seluf32:
+		return seluOpType
+	case &hardSigmoidf32:
+		return hardSigmoidOpType
+	case &hardSwishf32:
+		return hardSwishOpType
+	case &softsignf32:
+		return softsignOpType
+	case &geluDerivf32:
+		return geluDerivOpType
+	case &geluTanhDerivf32:
+		return geluTanhDerivOpType
+	case &siluDerivf32:
+		return siluDerivOpType
+	case &eluDerivf32:
+		return eluDerivOpType
+	case &seluDerivf32:
+		return seluDerivOpType
+	case &hardSigmoidDerivf32:
+		return hardSigmoidDerivOpType
+	case &hardSwishDerivf32:
+		return hardSwishDerivOpType
+	case &softsignDerivf32:
+		return softsignDerivOpType
 	}
 	return maxʘUnaryOperator
 }
+
 func (f *sf32UnaryOperator) String() string { return f.unaryOpType().String() }
 
 func (f *sf64UnaryOperator) unaryOpType() ʘUnaryOperatorType {
-
 	switch f {
 	case &absf64:
 		return absOpType
@@ -93,7 +124,40 @@ func (f *sf64UnaryOperator) unaryOpType() ʘUnaryOperatorType {
 		return expm1OpType
 	case &softplusf64:
 		return softplusOpType
+	case &geluf64:
+		return geluOpType
+	case &geluTanhf64:
+		return geluTanhOpType
+	case &siluf64:
+		return siluOpType
+	case &eluf64:
+		return eluOpType
+	case &seluf64:
+		return seluOpType
+	case &hardSigmoidf64:
+		return hardSigmoidOpType
+	case &hardSwishf64:
+		return hardSwishOpType
+	case &softsignf64:
+		return softsignOpType
+	case &geluDerivf64:
+		return geluDerivOpType
+	case &geluTanhDerivf64:
+		return geluTanhDerivOpType
+	case &siluDerivf64:
+		return siluDerivOpType
+	case &eluDerivf64:
+		return eluDerivOpType
+	case &seluDerivf64:
+		return seluDerivOpType
+	case &hardSigmoidDerivf64:
+		return hardSigmoidDerivOpType
+	case &hardSwishDerivf64:
+		return hardSwishDerivOpType
+	case &softsignDerivf64:
+		return softsignDerivOpType
 	}
 	return maxʘUnaryOperator
 }
+
 func (f *sf64UnaryOperator) String() string { return f.unaryOpType().String() }
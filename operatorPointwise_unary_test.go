@@ -609,3 +609,89 @@ func TestSoftplus(t *testing.T) {
 	assert.True(floatsEqual32(correctDF32s, xG.Data().([]float32)))
 	assert.True(floatsEqual32(correctDF32s, aG.Data().([]float32)))
 }
+
+func TestActivations(t *testing.T) {
+	testCases := []struct {
+		name string
+		fn   func(*Node) (*Node, error)
+		ref  func(float64) float64
+	}{
+		{"GELU", GELU, func(x float64) float64 { return x * 0.5 * math.Erfc(-x/math.Sqrt2) }},
+		{"GELUTanh", GELUTanh, func(x float64) float64 {
+			return 0.5 * x * (1 + math.Tanh(math.Sqrt(2/math.Pi)*(x+0.044715*math.Pow(x, 3))))
+		}},
+		{"SiLU", SiLU, func(x float64) float64 { return x / (1 + math.Exp(-x)) }},
+		{"ELU", ELU, func(x float64) float64 { return math.Max(0, x) + math.Min(0, math.Exp(x)-1) }},
+		{"SELU", SELU, func(x float64) float64 {
+			return 1.0507009873554804934 * (math.Max(0, x) + math.Min(0, 1.6732632423543772848*(math.Exp(x)-1)))
+		}},
+		{"HardSigmoid", HardSigmoid, func(x float64) float64 { return math.Min(math.Max(x/6+0.5, 0), 1) }},
+		{"HardSwish", HardSwish, func(x float64) float64 { return x * math.Min(math.Max(x+3, 0), 6) / 6 }},
+		{"Softsign", Softsign, func(x float64) float64 { return x / (1 + math.Abs(x)) }},
+	}
+
+	// the kinks of ELU, SELU, HardSigmoid and HardSwish are avoided
+	xs := []float64{-4, -2.5, -1, -0.3, 0.2, 1, 2.5, 4}
+	const h = 1e-6
+
+	for _, tcase := range testCases {
+		for _, dt := range []tensor.Dtype{Float64, Float32} {
+			t.Run(tcase.name+"/"+dt.String(), func(t *testing.T) {
+				var backing interface{}
+				tol := 1e-6
+				if dt == Float64 {
+					backing = append([]float64{}, xs...)
+				} else {
+					xs32 := make([]float32, len(xs))
+					for i, x := range xs {
+						xs32[i] = float32(x)
+					}
+					backing = xs32
+					tol = 1e-4
+				}
+
+				g := NewGraph()
+				x := NewVector(g, dt, WithShape(len(xs)), WithName("x"), WithValue(tensor.New(tensor.WithShape(len(xs)), tensor.WithBacking(backing))))
+				y := Must(tcase.fn(x))
+				cost := Must(Sum(y))
+				if _, err := Grad(cost, x); err != nil {
+					t.Fatal(err)
+				}
+				m := NewTapeMachine(g)
+				defer m.Close()
+				if err := m.RunAll(); err != nil {
+					t.Fatal(err)
+				}
+				grad, err := x.Grad()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				ys := activationTestF64s(y.Value())
+				gs := activationTestF64s(grad)
+				for i, x := range xs {
+					assert.InDelta(t, tcase.ref(x), ys[i], tol, "value at %v", x)
+					numeric := (tcase.ref(x+h) - tcase.ref(x-h)) / (2 * h)
+					assert.InDelta(t, numeric, gs[i], tol, "gradient at %v", x)
+				}
+
+				// the lisp machine has to agree with the tape machine
+				_, _, _, _, _, err = unaryOpTest(t, dt, tensor.Shape{4}, tcase.fn)
+				assert.NoError(t, err)
+			})
+		}
+	}
+}
+
+func activationTestF64s(v Value) []float64 {
+	switch data := v.Data().(type) {
+	case []float32:
+		retVal := make([]float64, len(data))
+		for i, d := range data {
+			retVal[i] = float64(d)
+		}
+		return retVal
+	default:
+		return data.([]float64)
+	}
+}
@@ -31,6 +31,7 @@ func returnNode(n *Node) {
 	n.group = ""
 	n.groups = nil
 	n.g = nil
+	n.source = ""
 	n.boundTo = nil
 	n.derivOf = nil
 	n.deriv = nil
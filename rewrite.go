@@ -0,0 +1,436 @@
+package gorgonia
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// this file deals with the rewrites of graphs: rules that replace a pattern of nodes with an equivalent expression,
+// such as the numerical stabilizations, or the simplifications of the algebra.
+
+// maxRewritePasses bounds the number of passes over a graph, in case the rules undo each other
+const maxRewritePasses = 64
+
+// OpMatcher reports whether an op is matched by a Pattern.
+type OpMatcher func(Op) bool
+
+// OpTypeOf matches the ops of the same Go type as op, whatever their parameters, e.g. all the transpositions.
+func OpTypeOf(op Op) OpMatcher {
+	t := reflect.TypeOf(op)
+	return func(o Op) bool { return reflect.TypeOf(o) == t }
+}
+
+// ElemwiseOp matches the elementwise ops by name. The binary ops are named add, sub, mul, div, pow, lt, gt, lte, gte, eq and ne.
+// The unary ops are named as their functions, in lower camel case: exp, ln, log1p, neg, sigmoid, softplus...
+func ElemwiseOp(name string) OpMatcher {
+	return func(op Op) bool {
+		switch o := op.(type) {
+		case elemBinOp:
+			return ʘBinOpNames[o.binOpType()] == name
+		case elemUnaryOp:
+			return o.unaryOpType().String() == name
+		}
+		return false
+	}
+}
+
+func binOpIs(t ʘBinaryOperatorType) OpMatcher {
+	return func(op Op) bool {
+		ebo, ok := op.(elemBinOp)
+		return ok && ebo.binOpType() == t
+	}
+}
+
+func unaryOpIs(t ʘUnaryOperatorType) OpMatcher {
+	return func(op Op) bool {
+		euo, ok := op.(elemUnaryOp)
+		return ok && euo.unaryOpType() == t
+	}
+}
+
+// Pattern describes the nodes that a RewriteRule looks for: a tree of ops, whose leaves match any node or a constant.
+// The nodes matched by a pattern may be named, for the rule to find them in the Match.
+//
+// For example, the pattern of exp(x)-1 is
+//		OpNode(ElemwiseOp("sub"), OpNode(ElemwiseOp("exp"), AnyNode("x")), ConstNode(1))
+type Pattern struct {
+	name        string
+	op          OpMatcher
+	constant    bool
+	value       float64
+	children    []*Pattern
+	commutative bool
+}
+
+// AnyNode matches any node, and names it. If the name is used several times in a pattern, all of them must match the same node.
+func AnyNode(name string) *Pattern { return &Pattern{name: name} }
+
+// ConstNode matches a scalar constant of the value, whatever its Dtype.
+func ConstNode(v float64) *Pattern { return &Pattern{constant: true, value: v} }
+
+// OpNode matches a node whose op is matched by op, and whose inputs are matched by the children, in order.
+func OpNode(op OpMatcher, children ...*Pattern) *Pattern {
+	return &Pattern{op: op, children: children}
+}
+
+// As names the node matched by the pattern.
+func (p *Pattern) As(name string) *Pattern {
+	p.name = name
+	return p
+}
+
+// Commutative lets the two inputs of a binary op match in either order.
+func (p *Pattern) Commutative() *Pattern {
+	p.commutative = true
+	return p
+}
+
+func (p *Pattern) matchNode(n *Node, m *Match) bool {
+	switch {
+	case p.constant:
+		if !isConstValue(n, p.value) {
+			return false
+		}
+	case p.op != nil:
+		if n.isInput() || !p.matchOp(n.op, n.children, m) {
+			return false
+		}
+	}
+	return m.bind(p.name, n)
+}
+
+// matchOp matches the op and the inputs of a node. The node itself may not exist yet.
+func (p *Pattern) matchOp(op Op, children Nodes, m *Match) bool {
+	if op == nil || p.op == nil || !p.op(op) || len(children) != len(p.children) {
+		return false
+	}
+	if matchAll(p.children, children, m) {
+		return true
+	}
+	if p.commutative && len(children) == 2 {
+		return matchAll(p.children, Nodes{children[1], children[0]}, m)
+	}
+	return false
+}
+
+func matchAll(ps []*Pattern, ns Nodes, m *Match) bool {
+	saved := len(m.bound)
+	for i, p := range ps {
+		if !p.matchNode(ns[i], m) {
+			m.bound = m.bound[:saved]
+			return false
+		}
+	}
+	return true
+}
+
+// isConstValue returns true if the node is a scalar constant of the value
+func isConstValue(n *Node, v float64) bool {
+	c, ok := n.op.(constant)
+	if !ok {
+		return false
+	}
+	s, ok := c.Value().(Scalar)
+	if !ok {
+		return false
+	}
+	switch x := s.Data().(type) {
+	case float64:
+		return x == v
+	case float32:
+		return float64(x) == v
+	case int:
+		return float64(x) == v
+	case int64:
+		return float64(x) == v
+	case int32:
+		return float64(x) == v
+	case byte:
+		return float64(x) == v
+	}
+	return false
+}
+
+// Match holds the nodes matched by the pattern of a RewriteRule.
+type Match struct {
+	// Root is the node matched by the pattern. It is nil when an eager rule is tried on a node that is being created.
+	Root  *Node
+	Op    Op // the op of the root
+	Graph *ExprGraph
+
+	bound []binding // all the matched nodes but the root, named or not
+}
+
+type binding struct {
+	name string
+	n    *Node
+}
+
+// Node returns the node matched by the pattern with the name, or nil.
+func (m *Match) Node(name string) *Node {
+	for _, b := range m.bound {
+		if b.name == name && name != "" {
+			return b.n
+		}
+	}
+	return nil
+}
+
+func (m *Match) bind(name string, n *Node) bool {
+	if prev := m.Node(name); prev != nil && prev != n {
+		return false
+	}
+	m.bound = append(m.bound, binding{name, n})
+	return true
+}
+
+// RewriteRule replaces the nodes matched by a Pattern with the node built by Replace.
+// The replacement must have the type and the shape of the root of the pattern.
+type RewriteRule struct {
+	Name    string
+	Pattern *Pattern
+
+	// Where, if set, is a condition on the matched nodes that the pattern cannot express.
+	Where func(m *Match) bool
+
+	// Replace builds the replacement in the graph of the match, typically with the functions of the operations API.
+	// It may return one of the matched nodes, e.g. x for x*1.
+	Replace func(m *Match) (*Node, error)
+
+	// Eager rules are also tried when the nodes are created, so that the graph never holds the expression.
+	// The stabilizations are eager, and they are all turned off by UseNonStable.
+	Eager bool
+}
+
+var rewriteRegistry struct {
+	sync.RWMutex
+	rules []RewriteRule
+}
+
+// RegisterRewrite registers a rule to be applied by Rewrite, or by a *tapeMachine created WithRewrites. The rules are tried in the order they
+// are registered, after the rules of the library: the stabilizations, and the simplifications of x*1, x+0, transpositions and reshapes.
+func RegisterRewrite(rule RewriteRule) {
+	if rule.Pattern == nil || rule.Pattern.op == nil {
+		panic("The pattern of a rewrite rule must match an op")
+	}
+	if rule.Replace == nil {
+		panic("A rewrite rule must have a Replace function")
+	}
+	rewriteRegistry.Lock()
+	rewriteRegistry.rules = append(rewriteRegistry.rules, rule)
+	rewriteRegistry.Unlock()
+}
+
+func rewriteRules() []RewriteRule {
+	rewriteRegistry.RLock()
+	defer rewriteRegistry.RUnlock()
+	return rewriteRegistry.rules
+}
+
+func (rule *RewriteRule) enabled() bool {
+	return !rule.Eager || stabilization
+}
+
+func (rule *RewriteRule) matches(op Op, children Nodes, m *Match) bool {
+	if !rule.Pattern.matchOp(op, children, m) {
+		return false
+	}
+	return rule.Where == nil || rule.Where(m)
+}
+
+// rewriteEager tries the eager rules on the node that applying op to the children creates.
+// It returns nil if none of them applies.
+func rewriteEager(op Op, children ...*Node) (retVal *Node, err error) {
+	rules := rewriteRules()
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Eager || !rule.enabled() {
+			continue
+		}
+		m := &Match{Op: op, Graph: children[0].g}
+		if !rule.matches(op, children, m) {
+			continue
+		}
+		stabLogf("Rewriting with %v", rule.Name)
+		if retVal, err = rule.Replace(m); err != nil {
+			return nil, errors.Wrapf(err, "Rewrite %q failed", rule.Name)
+		}
+		if g := m.Graph; g != nil {
+			g.sweepMatched(m)
+			g.reindex()
+		}
+		return retVal, nil
+	}
+	return nil, nil
+}
+
+// Rewrite applies the registered rules to the nodes of the graph until none of them applies, and returns the number of rewrites.
+// The graph is changed in place. Compile does not rewrite the graphs it compiles: call Rewrite before, or create the
+// *tapeMachine with WithRewrites.
+//
+// A rewritten node keeps its identity when it can: the node takes the op and the inputs of the replacement. When the replacement
+// is an existing node, e.g. x for x*1, the consumers of the rewritten node use it instead, unless the rewritten node is an output
+// of the graph, is named, is bound to a value or is part of a gradient; then it is left alone.
+// The nodes that are no longer used by anything are removed from the graph, and will not have values when the graph runs.
+// Name the intermediate nodes that are to be read after a run.
+func Rewrite(g *ExprGraph) (applied int, err error) {
+	compileLogf("rewriting")
+	enterLogScope()
+	defer leaveLogScope()
+
+	for pass := 0; pass < maxRewritePasses; pass++ {
+		var changed bool
+		// the nodes are created after their inputs, so the inputs are rewritten first
+		nodes := append(Nodes(nil), g.all...)
+		for _, n := range nodes {
+			if _, ok := g.to[n]; !ok || n.op == nil || n.isInput() || n.isStmt || n.isConstant() {
+				continue // removed by an earlier rewrite, or not an op
+			}
+			var ok bool
+			if ok, err = g.rewriteNode(n); err != nil {
+				return applied, err
+			}
+			if ok {
+				applied++
+				changed = true
+			}
+		}
+		if !changed {
+			return applied, nil
+		}
+	}
+	return applied, errors.Errorf("The rewrites of the graph did not reach a fixpoint after %d passes", maxRewritePasses)
+}
+
+// rewriteNode applies the first rule that matches n
+func (g *ExprGraph) rewriteNode(n *Node) (bool, error) {
+	rules := rewriteRules()
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.enabled() {
+			continue
+		}
+		m := &Match{Root: n, Op: n.op, Graph: g}
+		if !rule.matches(n.op, n.children, m) {
+			continue
+		}
+
+		mark := int64(g.counter)
+		r, err := rule.Replace(m)
+		if err != nil {
+			return false, errors.Wrapf(err, "Rewrite %q failed on %s", rule.Name, n.provenance())
+		}
+		if r == nil || r == n {
+			continue
+		}
+		if !r.t.Eq(n.t) || !SameShape(r.shape, n.shape) {
+			return false, errors.Errorf("Rewrite %q replaced %s of type %v and shape %v by a node of type %v and shape %v",
+				rule.Name, n.provenance(), n.t, n.shape, r.t, r.shape)
+		}
+
+		switch {
+		case r.id >= mark:
+			// the replacement is new: n takes its place
+			g.adopt(n, r)
+			g.sweep(r)
+		case !n.kept(g):
+			g.redirect(n, r)
+		case r.op != nil && !r.isInput() && !r.isConstant():
+			g.adopt(n, r)
+		default:
+			continue
+		}
+		compileLogf("Rewrote %v with %v", n.Name(), rule.Name)
+		g.sweepMatched(m)
+		g.reindex()
+		return true, nil
+	}
+	return false, nil
+}
+
+// kept returns true if the node may be held by the user: it is an output of the graph, or it is named, bound or part of a gradient
+func (n *Node) kept(g *ExprGraph) bool {
+	return len(g.to[n]) == 0 || n.name != "" || n.boundTo != nil || n.deriv != nil || len(n.derivOf) > 0 || n.isStmt
+}
+
+// adopt makes n compute what r computes, with the op and the inputs of r
+func (g *ExprGraph) adopt(n, r *Node) {
+	for _, child := range n.children {
+		g.to[child] = g.to[child].remove(n)
+	}
+	n.op = r.op
+	n.children = append(Nodes(nil), r.children...)
+	for _, child := range n.children {
+		if !g.to[child].Contains(n) {
+			g.to[child] = append(g.to[child], n)
+		}
+	}
+}
+
+// redirect makes the consumers of n use r instead, and removes n
+func (g *ExprGraph) redirect(n, r *Node) {
+	for _, parent := range g.to[n] {
+		for i, child := range parent.children {
+			if child == n {
+				parent.children[i] = r
+			}
+		}
+		if !g.to[r].Contains(parent) {
+			g.to[r] = append(g.to[r], parent)
+		}
+	}
+	g.to[n] = nil
+	g.sweep(n)
+}
+
+// sweepMatched removes the matched nodes that are no longer used
+func (g *ExprGraph) sweepMatched(m *Match) {
+	for _, b := range m.bound {
+		g.sweep(b.n)
+	}
+}
+
+// sweep removes n, and then its inputs, if nothing uses them and the user cannot hold them
+func (g *ExprGraph) sweep(n *Node) {
+	if _, ok := g.to[n]; !ok || len(g.to[n]) > 0 || n.isInput() || n.isConstant() || n.op == nil {
+		return
+	}
+	if n.name != "" || n.boundTo != nil || n.deriv != nil || len(n.derivOf) > 0 || n.isStmt {
+		return
+	}
+	stabLogf("Removing %v", n.Name())
+	for _, child := range n.children {
+		g.to[child] = g.to[child].remove(n)
+	}
+	delete(g.to, n)
+	g.all = g.all.remove(n)
+	for _, child := range n.children {
+		g.sweep(child)
+	}
+}
+
+// reindex rebuilds the indices of the graph after its nodes were rewritten or removed: the hashes of the nodes
+// depend on their inputs, and the IDs are indexed by their position.
+func (g *ExprGraph) reindex() {
+	g.byID = make(map[int64]int)
+	g.byHash = make(map[uint32]*Node)
+	g.evac = make(map[uint32]Nodes)
+	for _, n := range g.all {
+		n.hashed = false
+	}
+	for _, n := range g.all {
+		hash := n.Hashcode()
+		existing, ok := g.byHash[hash]
+		switch {
+		case !ok:
+			g.byHash[hash] = n
+		case existing == nil:
+			g.evac[hash] = append(g.evac[hash], n)
+		default:
+			g.evac[hash] = Nodes{existing, n}
+			g.byHash[hash] = nil
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/tensor"
+)
+
+func TestRewrite_simplifications(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithName("x"), WithShape(2, 3), WithValue(tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float64{1, 2, 3, 4, 5, 6}))))
+
+	mul := Must(Mul(x, onef64))
+	add := Must(Add(zerof64, mul))
+	tt := Must(Transpose(Must(Transpose(add))))
+	r := Must(Reshape(Must(Reshape(tt, tensor.Shape{6})), tensor.Shape{3, 2}))
+	cost := Must(Sum(r))
+
+	m := NewTapeMachine(g, WithRewrites())
+	defer m.Close()
+	require.NoError(t, m.RunAll())
+	assert.Equal(21.0, cost.Value().Data())
+
+	// x*1, 0+x and the transpositions are gone, and the reshapes are merged into one
+	assert.False(g.all.Contains(mul))
+	assert.False(g.all.Contains(add))
+	assert.False(g.all.Contains(tt))
+	assert.Equal(r, cost.children[0])
+	assert.Equal(x, r.children[0])
+	assert.Equal(tensor.Shape{3, 2}, r.op.(reshapeOp).to)
+
+	// the graph is at its fixpoint
+	n, err := Rewrite(g)
+	assert.NoError(err)
+	assert.Equal(0, n)
+}
+
+func TestRewrite_notCompiled(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithName("x"), WithShape(2, 3), WithInit(RangedFrom(1)))
+	xtt := Must(Transpose(Must(Transpose(x))))
+	y := Must(Mul(xtt, onef64))
+	cost := Must(Sum(y))
+	nodes := len(g.AllNodes())
+
+	// compiling does not rewrite the graph: the intermediate nodes held by the user still get their values
+	m := NewTapeMachine(g)
+	defer m.Close()
+	require.NoError(t, m.RunAll())
+	assert.Equal(nodes, len(g.AllNodes()))
+	assert.True(g.all.Contains(xtt))
+	assert.True(g.all.Contains(y))
+	if assert.NotNil(xtt.Value()) && assert.NotNil(y.Value()) {
+		assert.Equal([]float64{1, 2, 3, 4, 5, 6}, y.Value().Data())
+	}
+	assert.Equal(21.0, cost.Value().Data())
+}
+
+func TestRewrite_transposes(t *testing.T) {
+	g := NewGraph()
+	x := NewTensor(g, Float64, 3, WithName("x"), WithShape(2, 3, 4))
+	a := Must(Transpose(x, 1, 2, 0))
+	b := Must(Transpose(a, 0, 2, 1))
+	cost := Must(Sum(b))
+
+	n, err := Rewrite(g)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// b keeps its identity, and transposes x directly
+	assert.Equal(t, b, cost.children[0])
+	assert.Equal(t, x, b.children[0])
+	assert.Equal(t, []int{1, 0, 2}, b.op.(transposeOp).pattern)
+	assert.Equal(t, tensor.Shape{3, 2, 4}, b.Shape())
+	assert.False(t, g.all.Contains(a))
+}
+
+func TestRewrite_keptNodes(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithName("x"), WithShape(2), WithInit(RangedFrom(1)))
+
+	// y is named, and z is an output, so they are kept: z computes square(y) itself
+	y := Must(Mul(x, onef64))
+	WithName("y")(y)
+	sq := Must(Square(y))
+	z := Must(Add(sq, zerof64))
+
+	m := NewTapeMachine(g, BindDualValues(), WithRewrites())
+	defer m.Close()
+	require.NoError(t, m.RunAll())
+
+	assert.True(g.all.Contains(y))
+	assert.False(g.all.Contains(sq))
+	assert.True(y == z.children[0])
+	assert.Equal(squareOpType, z.op.(elemUnaryOp).unaryOpType())
+	assert.Equal([]float64{1, 4}, z.Value().Data())
+}
+
+func TestRewrite_gradients(t *testing.T) {
+	g := NewGraph()
+	x := NewVector(g, Float64, WithName("x"), WithShape(3), WithValue(tensor.New(tensor.WithBacking([]float64{1, 2, 3}))))
+	y := Must(Mul(Must(Mul(x, onef64)), x))
+	cost := Must(Sum(y))
+	_, err := Grad(cost, x)
+	require.NoError(t, err)
+
+	m := NewTapeMachine(g, BindDualValues(x))
+	defer m.Close()
+	require.NoError(t, m.RunAll())
+
+	grad, err := x.Grad()
+	require.NoError(t, err)
+	assert.Equal(t, []float64{2, 4, 6}, grad.Data())
+	assert.Equal(t, 14.0, cost.Value().Data())
+}
+
+func TestRewrite_stabilizations(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithName("x"), WithShape(5))
+
+	// log(softmax(x)) is stabilized as it is created, without touching the softmax, which may be used elsewhere
+	sm := Must(SoftMax(x))
+	other := Must(Square(sm))
+	lsm := Must(Log(sm))
+	if op, ok := lsm.op.(*softmaxOp); assert.True(ok) {
+		assert.True(op.isLog)
+	}
+	assert.Equal(x, lsm.children[0])
+	assert.False(sm.op.(*softmaxOp).isLog)
+	assert.True(g.all.Contains(sm))
+	assert.Equal(sm, other.children[0])
+
+	// the stabilizations are not eager when they are turned off, but are applied when the graph is rewritten
+	UseNonStable()
+	e := Must(Exp(x))
+	s := Must(Sub(e, onef64))
+	UseStabilization()
+	assert.Equal(e, s.children[0])
+	cost := Must(Sum(s))
+
+	_, err := Rewrite(g)
+	require.NoError(t, err)
+	assert.Equal(x, s.children[0])
+	assert.Equal(s, cost.children[0])
+	assert.Equal(expm1OpType, s.op.(elemUnaryOp).unaryOpType())
+	assert.False(g.all.Contains(e))
+}
+
+func TestRegisterRewrite(t *testing.T) {
+	assert := assert.New(t)
+
+	// x*x → square(x), only in the graphs of this test
+	RegisterRewrite(RewriteRule{
+		Name:    "x*x → square(x)",
+		Pattern: OpNode(ElemwiseOp("mul"), AnyNode("x"), AnyNode("x")),
+		Where:   func(m *Match) bool { return m.Graph.name == t.Name() },
+		Replace: func(m *Match) (*Node, error) { return Square(m.Node("x")) },
+	})
+
+	g := NewGraph(WithGraphName(t.Name()))
+	x := NewVector(g, Float64, WithName("x"), WithShape(2))
+	y := NewVector(g, Float64, WithName("y"), WithShape(2))
+	xx := Must(HadamardProd(x, x))
+	xy := Must(HadamardProd(x, y))
+	Must(Add(xx, xy))
+
+	n, err := Rewrite(g)
+	require.NoError(t, err)
+	assert.Equal(1, n)
+	assert.Equal(squareOpType, xx.op.(elemUnaryOp).unaryOpType())
+	assert.Equal(Nodes{x}, xx.children)
+	assert.Equal(mulOpType, xy.op.(elemBinOp).binOpType())
+
+	// the rules are tried in other graphs too, where Where stops them
+	h := NewGraph()
+	x2 := NewVector(h, Float64, WithName("x"), WithShape(2))
+	xx2 := Must(HadamardProd(x2, x2))
+	Must(Sum(xx2))
+	n, err = Rewrite(h)
+	require.NoError(t, err)
+	assert.Equal(0, n)
+
+	assert.Panics(func() {
+		RegisterRewrite(RewriteRule{Pattern: AnyNode("x"), Replace: func(*Match) (*Node, error) { return nil, nil }})
+	})
+}
+
+func TestPattern_match(t *testing.T) {
+	g := NewGraph()
+	x := NewVector(g, Float64, WithName("x"), WithShape(2))
+	y := NewVector(g, Float64, WithName("y"), WithShape(2))
+	sum := Must(Add(x, y))
+	one := NewConstant(1.0)
+
+	tests := []struct {
+		name    string
+		pattern *Pattern
+		n       *Node
+		matches bool
+	}{
+		{"any", OpNode(ElemwiseOp("add"), AnyNode("a"), AnyNode("b")), sum, true},
+		{"same name", OpNode(ElemwiseOp("add"), AnyNode("a"), AnyNode("a")), sum, false},
+		{"wrong op", OpNode(ElemwiseOp("sub"), AnyNode("a"), AnyNode("b")), sum, false},
+		{"op type", OpNode(OpTypeOf(elemBinOp{}), AnyNode("a"), AnyNode("b")), sum, true},
+		{"arity", OpNode(ElemwiseOp("add"), AnyNode("a")), sum, false},
+		{"input is not an op", OpNode(ElemwiseOp("add"), OpNode(ElemwiseOp("neg"), AnyNode("a")), AnyNode("b")), sum, false},
+		{"constant", OpNode(ElemwiseOp("add"), AnyNode("a"), ConstNode(1)), Must(Add(x, one)), true},
+		{"other constant", OpNode(ElemwiseOp("add"), AnyNode("a"), ConstNode(2)), Must(Add(x, one)), false},
+		{"not commutative", OpNode(ElemwiseOp("add"), ConstNode(1), AnyNode("a")), Must(Add(x, one)), false},
+		{"commutative", OpNode(ElemwiseOp("add"), ConstNode(1), AnyNode("a")).Commutative(), Must(Add(x, one)), true},
+	}
+	for _, tc := range tests {
+		m := &Match{Root: tc.n, Op: tc.n.op, Graph: g}
+		if got := tc.pattern.matchOp(tc.n.op, tc.n.children, m); got != tc.matches {
+			t.Errorf("%v: expected the match to be %t", tc.name, tc.matches)
+		}
+		if tc.matches && tc.name == "any" && (m.Node("a") != x || m.Node("b") != y) {
+			t.Errorf("%v: bound %v and %v", tc.name, m.Node("a"), m.Node("b"))
+		}
+	}
+}
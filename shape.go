@@ -67,6 +67,20 @@ func calcBroadcastShape(a *Node, expectedDims int, broadcastAlong []int) (newSha
 	return
 }
 
+// SameShape reports whether two shapes have the same dimensions. Unlike tensor.Shape.Eq, it does not consider a vector (n)
+// to be the same as a column vector (n, 1).
+func SameShape(a, b tensor.Shape) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // KeepDims is a function that ensures that input and output dimensions are the same though the shape may change.
 //
 // The expandLeft flag in the function indicates if any shape expansion should be done leftwards or rightwards.
@@ -58,3 +58,14 @@ func Example_keepDims() {
 	// [[3.5]]
 
 }
+
+func ExampleSameShape() {
+	fmt.Println(tensor.Shape{2}.Eq(tensor.Shape{2, 1}))
+	fmt.Println(SameShape(tensor.Shape{2}, tensor.Shape{2, 1}))
+	fmt.Println(SameShape(tensor.Shape{2, 1}, tensor.Shape{2, 1}))
+
+	// Output:
+	// true
+	// false
+	// true
+}
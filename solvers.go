@@ -26,6 +26,11 @@ type Namer interface {
 }
 
 func newCachedDV(n ValueGrad, weights, grad Value, zero bool) (cached *dualValue, err error) {
+	// a sparse gradient only carries some rows, but the cache has to cover all of the weights
+	if _, ok := grad.(*SparseRows); ok {
+		grad = weights
+	}
+
 	cached = new(dualValue)
 	if cached.Value, err = CloneValue(weights); err != nil {
 		if nm, ok := n.(Namer); ok {
@@ -57,6 +62,28 @@ func extractWeightGrad(n ValueGrad) (weights, grad Value, err error) {
 	return
 }
 
+// extractWeightDenseGrad is extractWeightGrad for the solvers that cannot apply a *SparseRows lazily: a sparse gradient
+// is turned into its dense equivalent. The dense gradient replaces the derivative of a node bound to a dual value, so
+// that the solver zeroes it after the step.
+func extractWeightDenseGrad(n ValueGrad) (weights, grad Value, err error) {
+	if weights, grad, err = extractWeightGrad(n); err != nil {
+		return
+	}
+	sg, ok := grad.(*SparseRows)
+	if !ok {
+		return
+	}
+	if grad, err = sg.Dense(); err != nil {
+		return weights, nil, errors.Wrap(err, "Failed to densify a sparse gradient")
+	}
+	if nd, ok := n.(*Node); ok {
+		if dv, ok := nd.boundTo.(*dualValue); ok {
+			dv.SetDeriv(grad)
+		}
+	}
+	return
+}
+
 // SolverOpt is a function that provides construction options for a Solver
 type SolverOpt func(s Solver)
 
@@ -256,7 +283,7 @@ func (s *RMSPropSolver) Step(model []ValueGrad) (err error) {
 
 	for i, n := range model {
 		var weights, grad Value
-		if weights, grad, err = extractWeightGrad(n); err != nil {
+		if weights, grad, err = extractWeightDenseGrad(n); err != nil {
 			return err
 		}
 
@@ -460,6 +487,13 @@ func (s *AdamSolver) Step(model []ValueGrad) (err error) {
 			s.cache[i] = cached
 		}
 
+		if sg, ok := grad.(*SparseRows); ok {
+			if err = s.sparseStep(weights, sg, cached, correction1, correction2); err != nil {
+				return err
+			}
+			continue
+		}
+
 		cvm := cached.Value // means of gradients
 		cvv := cached.d     // variances of gradients
 
@@ -762,6 +796,14 @@ func (s *VanillaSolver) Step(model []ValueGrad) (err error) {
 		if weights, grad, err = extractWeightGrad(n); err != nil {
 			return err
 		}
+
+		if sg, ok := grad.(*SparseRows); ok {
+			if err = s.sparseStep(weights, sg); err != nil {
+				return err
+			}
+			continue
+		}
+
 		switch w := weights.(type) {
 		case *tensor.Dense:
 			g := grad.(*tensor.Dense)
@@ -958,7 +1000,7 @@ func (s *Momentum) Step(model []ValueGrad) (err error) {
 
 	for i, n := range model {
 		var weights, grad Value
-		if weights, grad, err = extractWeightGrad(n); err != nil {
+		if weights, grad, err = extractWeightDenseGrad(n); err != nil {
 			return err
 		}
 
@@ -1198,6 +1240,13 @@ func (s *AdaGradSolver) Step(model []ValueGrad) (err error) {
 			s.cache[i] = cached
 		}
 
+		if sg, ok := grad.(*SparseRows); ok {
+			if err = s.sparseStep(weights, sg, cached); err != nil {
+				return err
+			}
+			continue
+		}
+
 		cv := cached.Value
 
 		switch cw := cv.(type) {
@@ -1399,7 +1448,7 @@ func (s *BarzilaiBorweinSolver) Step(model []ValueGrad) (err error) {
 
 		for nodeNr, node := range model {
 			var weights, grad Value
-			if weights, grad, err = extractWeightGrad(node); err != nil {
+			if weights, grad, err = extractWeightDenseGrad(node); err != nil {
 				return err
 			}
 
@@ -1477,7 +1526,7 @@ func (s *BarzilaiBorweinSolver) Step(model []ValueGrad) (err error) {
 	// Save this iteration's values for the next run
 	for nodeNr, node := range model {
 		var weights, grad Value
-		if weights, grad, err = extractWeightGrad(node); err != nil {
+		if weights, grad, err = extractWeightDenseGrad(node); err != nil {
 			return err
 		}
 
@@ -1495,7 +1544,7 @@ func (s *BarzilaiBorweinSolver) Step(model []ValueGrad) (err error) {
 	// Update the weights
 	for _, node := range model {
 		var weights, grad Value
-		if weights, grad, err = extractWeightGrad(node); err != nil {
+		if weights, grad, err = extractWeightDenseGrad(node); err != nil {
 			return err
 		}
 
@@ -1526,3 +1575,188 @@ func (s *BarzilaiBorweinSolver) Step(model []ValueGrad) (err error) {
 
 	return nil
 }
+
+/* SPARSE GRADIENTS */
+
+// sparseRowsOf checks that a *SparseRows gradient can be applied onto the weights, and returns the weights as a *tensor.Dense along with the width of a row.
+func sparseRowsOf(weights Value, grad *SparseRows) (w *tensor.Dense, cols int, err error) {
+	var ok bool
+	if w, ok = weights.(*tensor.Dense); !ok {
+		return nil, 0, errors.Errorf("Sparse gradients can only be applied to a *tensor.Dense. Got %T instead", weights)
+	}
+	if !w.Shape().Eq(grad.Shape()) {
+		return nil, 0, newShapeMismatch(w.Shape(), grad.Shape(), shapeMismatchErr, w.Shape(), grad.Shape())
+	}
+	if w.Dtype() != grad.Dtype() {
+		return nil, 0, newDtypeMismatch(w.Dtype(), grad.Dtype(), "Expected a sparse gradient of %v. Got %v instead", w.Dtype(), grad.Dtype())
+	}
+	return w, grad.Shape()[1], nil
+}
+
+// sparseStep applies the gradient only to the rows that the gradient carries.
+func (s *VanillaSolver) sparseStep(weights Value, grad *SparseRows) error {
+	w, cols, err := sparseRowsOf(weights, grad)
+	if err != nil {
+		return err
+	}
+
+	switch w.Dtype() {
+	case tensor.Float64:
+		wd, gd := w.Float64s(), grad.Values().Float64s()
+		for i, row := range grad.Rows() {
+			ws, gs := wd[row*cols:(row+1)*cols], gd[i*cols:(i+1)*cols]
+			for j := range ws {
+				g := regularizeF64(gs[j], ws[j], s.useL1Reg, s.l1reg, s.useL2Reg, s.l2reg, s.batch, s.useClip, s.clip)
+				ws[j] -= s.eta * g
+			}
+		}
+	case tensor.Float32:
+		wd, gd := w.Float32s(), grad.Values().Float32s()
+		eta := float32(s.eta)
+		for i, row := range grad.Rows() {
+			ws, gs := wd[row*cols:(row+1)*cols], gd[i*cols:(i+1)*cols]
+			for j := range ws {
+				g := float32(regularizeF64(float64(gs[j]), float64(ws[j]), s.useL1Reg, s.l1reg, s.useL2Reg, s.l2reg, s.batch, s.useClip, s.clip))
+				ws[j] -= eta * g
+			}
+		}
+	default:
+		return errors.Errorf(nyiFail, "VanillaSolver sparse step", w.Dtype())
+	}
+	grad.ZeroValue()
+	return nil
+}
+
+// regularizeF64 applies the L1 and L2 regularization, the batching and the clipping of a solver to the gradient g of the weight w.
+// It is used by the sparse steps, which update the weights one by one.
+func regularizeF64(g, w float64, useL1Reg bool, l1reg float64, useL2Reg bool, l2reg, batch float64, useClip bool, clip float64) float64 {
+	if useL1Reg {
+		if w < 0 {
+			g -= l1reg
+		} else if w > 0 {
+			g += l1reg
+		}
+	}
+	if useL2Reg {
+		g += l2reg * w
+	}
+	if batch > 1 {
+		g *= 1 / batch
+	}
+	if useClip && clip > 0 {
+		g = math.Max(-clip, math.Min(clip, g))
+	}
+	return g
+}
+
+// sparseStep performs a lazy Adam update: the moments are only updated for the rows that the gradient carries.
+// The bias corrections are those of the current iteration.
+func (s *AdamSolver) sparseStep(weights Value, grad *SparseRows, cached *dualValue, correction1, correction2 float64) error {
+	w, cols, err := sparseRowsOf(weights, grad)
+	if err != nil {
+		return err
+	}
+	m, mok := cached.Value.(*tensor.Dense)
+	v, vok := cached.d.(*tensor.Dense)
+	if !mok || !vok {
+		return errors.Errorf("Expected the cached moments to be *tensor.Dense. Got %T and %T instead", cached.Value, cached.d)
+	}
+
+	switch w.Dtype() {
+	case tensor.Float64:
+		wd, gd, md, vd := w.Float64s(), grad.Values().Float64s(), m.Float64s(), v.Float64s()
+		for i, row := range grad.Rows() {
+			lo, hi := row*cols, (row+1)*cols
+			ws, ms, vs, gs := wd[lo:hi], md[lo:hi], vd[lo:hi], gd[i*cols:(i+1)*cols]
+			for j := range ws {
+				g := regularizeF64(gs[j], ws[j], s.useL1Reg, s.l1reg, s.useL2Reg, s.l2reg, s.batch, s.useClip, s.clip)
+				ms[j] = s.beta1*ms[j] + (1-s.beta1)*g
+				vs[j] = s.beta2*vs[j] + (1-s.beta2)*g*g
+				mHat := ms[j] / correction1
+				vHat := vs[j] / correction2
+				ws[j] -= s.eta * mHat / (math.Sqrt(vHat) + s.eps)
+			}
+		}
+	case tensor.Float32:
+		wd, gd, md, vd := w.Float32s(), grad.Values().Float32s(), m.Float32s(), v.Float32s()
+		beta1, beta2 := float32(s.beta1), float32(s.beta2)
+		eta, eps := float32(s.eta), float32(s.eps)
+		c1, c2 := float32(correction1), float32(correction2)
+		for i, row := range grad.Rows() {
+			lo, hi := row*cols, (row+1)*cols
+			ws, ms, vs, gs := wd[lo:hi], md[lo:hi], vd[lo:hi], gd[i*cols:(i+1)*cols]
+			for j := range ws {
+				g := float32(regularizeF64(float64(gs[j]), float64(ws[j]), s.useL1Reg, s.l1reg, s.useL2Reg, s.l2reg, s.batch, s.useClip, s.clip))
+				ms[j] = beta1*ms[j] + (1-beta1)*g
+				vs[j] = beta2*vs[j] + (1-beta2)*g*g
+				mHat := ms[j] / c1
+				vHat := vs[j] / c2
+				ws[j] -= eta * mHat / (math32.Sqrt(vHat) + eps)
+			}
+		}
+	default:
+		return errors.Errorf(nyiFail, "AdamSolver sparse step", w.Dtype())
+	}
+	grad.ZeroValue()
+	return nil
+}
+
+// sparseStep performs a lazy AdaGrad update: the accumulated squared gradients are only updated for the rows that the gradient carries.
+func (s *AdaGradSolver) sparseStep(weights Value, grad *SparseRows, cached *dualValue) error {
+	w, cols, err := sparseRowsOf(weights, grad)
+	if err != nil {
+		return err
+	}
+	c, ok := cached.Value.(*tensor.Dense)
+	if !ok {
+		return errors.Errorf("Expected the cached squared gradients to be a *tensor.Dense. Got %T instead", cached.Value)
+	}
+
+	switch w.Dtype() {
+	case tensor.Float64:
+		wd, gd, cd := w.Float64s(), grad.Values().Float64s(), c.Float64s()
+		for i, row := range grad.Rows() {
+			lo, hi := row*cols, (row+1)*cols
+			ws, cs, gs := wd[lo:hi], cd[lo:hi], gd[i*cols:(i+1)*cols]
+			for j := range ws {
+				g := gs[j]
+				cs[j] += g * g
+				if s.useClip {
+					g = math.Max(-s.clip, math.Min(s.clip, g))
+				}
+				upd := -s.eta * g / math.Sqrt(cs[j]+s.eps)
+				if s.useL2Reg {
+					upd -= ws[j] * s.l2reg
+				}
+				ws[j] += upd
+			}
+		}
+	case tensor.Float32:
+		wd, gd, cd := w.Float32s(), grad.Values().Float32s(), c.Float32s()
+		eta, eps, clip, l2reg := float32(s.eta), float32(s.eps), float32(s.clip), float32(s.l2reg)
+		for i, row := range grad.Rows() {
+			lo, hi := row*cols, (row+1)*cols
+			ws, cs, gs := wd[lo:hi], cd[lo:hi], gd[i*cols:(i+1)*cols]
+			for j := range ws {
+				g := gs[j]
+				cs[j] += g * g
+				if s.useClip {
+					if g > clip {
+						g = clip
+					} else if g < -clip {
+						g = -clip
+					}
+				}
+				upd := -eta * g / math32.Sqrt(cs[j]+eps)
+				if s.useL2Reg {
+					upd -= ws[j] * l2reg
+				}
+				ws[j] += upd
+			}
+		}
+	default:
+		return errors.Errorf(nyiFail, "AdaGradSolver sparse step", w.Dtype())
+	}
+	grad.ZeroValue()
+	return nil
+}
@@ -0,0 +1,77 @@
+package gorgonia
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// this file deals with the provenance of nodes: the place in the user's code where they are created,
+// so that the errors can point to the line that built a faulty node.
+
+// maxSourceDepth is the number of frames that are walked to find the code that called the library
+const maxSourceDepth = 64
+
+// WithoutSourceLocations is a ExprGraph construction option that stops recording where its nodes are created.
+// Walking the stack for every node has a cost, which large graphs built in a loop may not want to pay.
+func WithoutSourceLocations() graphconopt {
+	f := func(g *ExprGraph) {
+		g.noSources = true
+	}
+	return f
+}
+
+// Source returns the file and the line of the code that created the node, or an empty string if they were not recorded.
+// The location is the first caller outside of Gorgonia, so the nodes made by Mul, or by a layer of a subpackage,
+// are located at the call in the user's code.
+func (n *Node) Source() string { return n.source }
+
+// callerSource returns the location of the first caller outside of the library
+func callerSource() string {
+	var pcs [maxSourceDepth]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	for {
+		f, more := frames.Next()
+		if !isLibraryFrame(f) {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// isLibraryFrame returns true if the frame is in Gorgonia or in the runtime. The tests and the examples are user code.
+func isLibraryFrame(f runtime.Frame) bool {
+	switch {
+	case strings.HasSuffix(f.File, "_test.go"):
+		return false
+	case strings.HasPrefix(f.Function, "gorgonia.org/gorgonia/examples/"):
+		return false
+	}
+	return strings.HasPrefix(f.Function, "gorgonia.org/gorgonia.") ||
+		strings.HasPrefix(f.Function, "gorgonia.org/gorgonia/") ||
+		strings.HasPrefix(f.Function, "runtime.")
+}
+
+// provenance names the node and where it was created, for error messages
+func (n *Node) provenance() string {
+	if n.source == "" {
+		return fmt.Sprintf("%s(%x)", n.Name(), n.id)
+	}
+	return fmt.Sprintf("%s(%x) created at %s", n.Name(), n.id, shortSource(n.source))
+}
+
+// applySource describes where an op is applied, for the errors of ApplyOp
+func applySource(g *ExprGraph) string {
+	if g.noSources {
+		return ""
+	}
+	return ". Applied at " + shortSource(callerSource())
+}
+
+// shortSource trims the directory of a location, as the log package does, to keep the messages short
+func shortSource(source string) string {
+	return filepath.Base(source)
+}
@@ -0,0 +1,107 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/chewxy/hm"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/tensor"
+)
+
+// failingOp is an op that type checks, but fails when it runs, with err if it is set
+type failingOp struct{ err error }
+
+func (op failingOp) Arity() int { return 1 }
+func (op failingOp) Type() hm.Type {
+	a := hm.TypeVariable('a')
+	return hm.NewFnType(a, a)
+}
+func (op failingOp) InferShape(inputs ...DimSizer) (tensor.Shape, error) {
+	return inputs[0].(tensor.Shape).Clone(), nil
+}
+func (op failingOp) Do(...Value) (Value, error) {
+	if op.err != nil {
+		return nil, op.err
+	}
+	return nil, errors.New("failingOp always fails")
+}
+func (op failingOp) ReturnsPtr() bool      { return false }
+func (op failingOp) CallsExtern() bool     { return false }
+func (op failingOp) OverwritesInput() int  { return -1 }
+func (op failingOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "failingOp") }
+func (op failingOp) Hashcode() uint32      { return simpleHash(op) }
+func (op failingOp) String() string        { return "failingOp" }
+
+// here returns the location of its caller, shifted by delta lines
+func here(delta int) string {
+	_, file, line, _ := runtime.Caller(1)
+	return fmt.Sprintf("%s:%d", file, line+delta)
+}
+
+func TestNode_Source(t *testing.T) {
+	g := NewGraph()
+	x := NewMatrix(g, tensor.Float64, WithShape(2, 3), WithName("x"))
+	xLoc := here(-1)
+	w := NewMatrix(g, tensor.Float64, WithShape(3, 4), WithName("w"))
+	xw := Must(Mul(x, w))
+	xwLoc := here(-1)
+
+	assert.Equal(t, xLoc, x.Source())
+	assert.Equal(t, xwLoc, xw.Source(), "the nodes made by the library are located at the call in the user's code")
+
+	// a node that is already in the graph keeps the location where it was first created
+	again := Must(Mul(x, w))
+	assert.Equal(t, xw, again)
+	assert.Equal(t, xwLoc, again.Source())
+
+	g2 := NewGraph(WithoutSourceLocations())
+	y := NewMatrix(g2, tensor.Float64, WithShape(2, 3))
+	assert.Equal(t, "", y.Source())
+	assert.Equal(t, "", Must(Tanh(y)).Source())
+}
+
+func TestApplyOp_sourceErrors(t *testing.T) {
+	g := NewGraph()
+	x := NewMatrix(g, tensor.Float64, WithShape(2, 3))
+	y := NewMatrix(g, tensor.Float64, WithShape(2, 3))
+	_, err := Mul(x, y)
+	loc := filepath.Base(here(-1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Applied at "+loc)
+
+	g2 := NewGraph(WithoutSourceLocations())
+	x = NewMatrix(g2, tensor.Float64, WithShape(2, 3))
+	y = NewMatrix(g2, tensor.Float64, WithShape(2, 3))
+	_, err = Mul(x, y)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "Applied at")
+}
+
+func TestVM_sourceErrors(t *testing.T) {
+	g := NewGraph()
+	x := NewMatrix(g, tensor.Float64, WithShape(2, 3), WithName("x"), WithInit(RangedFrom(0)))
+	failed := Must(ApplyOp(failingOp{}, x))
+	loc := filepath.Base(here(-1))
+
+	m := NewTapeMachine(g)
+	defer m.Close()
+	err := m.RunAll()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failingOp(%0)(1) created at "+loc)
+	var ctx vmContextualError
+	require.True(t, errors.As(err, &ctx), "%v", err)
+	assert.Equal(t, failed, ctx.Node())
+
+	lm := NewLispMachine(g, ExecuteFwdOnly())
+	defer lm.Close()
+	err = lm.RunAll()
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "created at "+loc), "%v", err)
+}
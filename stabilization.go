@@ -2,144 +2,132 @@ package gorgonia
 
 import "github.com/pkg/errors"
 
-var unaryOpStabilizationFns = make(map[ʘUnaryOperatorType][]func(*Node) (*Node, error))
-var binOpStabilizationFns = make(map[ʘBinaryOperatorType][]func(*Node, *Node) (*Node, error))
+// the rewrite rules of the library. The stabilizations are eager: they are applied as the nodes are created.
+// The optimizations are applied when the graph is rewritten, with Rewrite or WithRewrites.
 
-func init() {
-	unaryOpStabilizationFns[lnOpType] = []func(*Node) (*Node, error){
-		logSigmoidStabilization,
-		logStabilization,
-		logSoftmaxStabilization,
-	}
-	binOpStabilizationFns[subOpType] = []func(*Node, *Node) (*Node, error){
-		exp1mStabilization,
-		oneMinusSigmoidStabilization,
-	}
-	unaryOpStabilizationFns[log1pOpType] = []func(*Node) (*Node, error){
-		log1pExpStabilization,
-		log1pNegSigmoidStabilization,
-	}
-	unaryOpStabilizationFns[negOpType] = []func(*Node) (*Node, error){negNegOptimization}
+func isSoftmax(op Op) bool {
+	sm, ok := op.(*softmaxOp)
+	return ok && !sm.isLog
 }
 
-// logStabilization converts
-// 	log(1+a) or log(a+1) to log1p(a)
-//	log(1-a) to log1p(-a)
-// place before log; a should be positive.
-func logStabilization(a *Node) (retVal *Node, err error) {
-	stabLogf("Stabilizing log(1+a) of %v", a)
-	enterLogScope()
-	defer leaveLogScope()
-
-	var x *Node
-	var aop elemBinOp
-	var ok bool
-
-	if aop, ok = a.op.(elemBinOp); !ok {
-		return a, noStabilizationErr{}
-	}
-	input0 := a.children[0]
-	input1 := a.children[1]
-
-	stabLogf("input0: %v", input0.Name())
-	stabLogf("input1: %v", input1.Name())
-	bot := aop.ʘBinaryOperator.binOpType()
-	switch bot {
-	case addOpType:
-		if cnst, ok := input0.op.(constant); ok {
-			if constEq(cnst, onef32ConstOp) || constEq(cnst, onef64ConstOp) {
-				x = input1
-				break
-			}
-		}
-
-		if cnst, ok := input1.op.(constant); ok {
-			if constEq(cnst, onef32ConstOp) || constEq(cnst, onef64ConstOp) {
-				x = input0
-				break
-			}
-		}
-
-		return a, noStabilizationErr{}
-	case subOpType:
-		if cnst, ok := input0.op.(constant); !ok || (ok && !constEq(cnst, onef32ConstOp) && !constEq(cnst, onef64ConstOp)) {
-			return a, noStabilizationErr{}
-		}
-		x = input1
-	default:
-		return a, noStabilizationErr{}
+func init() {
+	// stabilizations, in the order they are tried
+	for _, rule := range []RewriteRule{
+		{
+			Name:    "log(sigmoid(x)) → -softplus(-x)",
+			Pattern: OpNode(unaryOpIs(lnOpType), OpNode(unaryOpIs(sigmoidOpType), AnyNode("x"))),
+			Replace: logSigmoidStabilization,
+		},
+		{
+			Name:    "log(1+x) → log1p(x)",
+			Pattern: OpNode(unaryOpIs(lnOpType), OpNode(binOpIs(addOpType), ConstNode(1), AnyNode("x")).Commutative()),
+			Replace: logStabilization,
+		},
+		{
+			Name:    "log(1-x) → log1p(-x)",
+			Pattern: OpNode(unaryOpIs(lnOpType), OpNode(binOpIs(subOpType), ConstNode(1), AnyNode("x"))),
+			Replace: logOneMinusStabilization,
+		},
+		{
+			Name:    "log(softmax(x)) → logsoftmax(x)",
+			Pattern: OpNode(unaryOpIs(lnOpType), OpNode(isSoftmax, AnyNode("x")).As("softmax")),
+			Replace: logSoftmaxStabilization,
+		},
+		{
+			Name: "log(a*softmax(x)) → log(a) + logsoftmax(x)",
+			Pattern: OpNode(unaryOpIs(lnOpType),
+				OpNode(binOpIs(mulOpType), AnyNode("a"), OpNode(isSoftmax, AnyNode("x")).As("softmax")).Commutative()),
+			Replace: logMulSoftmaxStabilization,
+		},
+		{
+			Name:    "exp(x)-1 → expm1(x)",
+			Pattern: OpNode(binOpIs(subOpType), OpNode(unaryOpIs(expOpType), AnyNode("x")), ConstNode(1)),
+			Replace: exp1mStabilization,
+		},
+		{
+			Name:    "1-sigmoid(x) → sigmoid(-x)",
+			Pattern: OpNode(binOpIs(subOpType), ConstNode(1), OpNode(unaryOpIs(sigmoidOpType), AnyNode("x"))),
+			Replace: oneMinusSigmoidStabilization,
+		},
+		{
+			Name:    "log1p(exp(x)) → softplus(x)",
+			Pattern: OpNode(unaryOpIs(log1pOpType), OpNode(unaryOpIs(expOpType), AnyNode("x"))),
+			Replace: log1pExpStabilization,
+		},
+		{
+			Name:    "log1p(-sigmoid(x)) → -softplus(x)",
+			Pattern: OpNode(unaryOpIs(log1pOpType), OpNode(unaryOpIs(negOpType), OpNode(unaryOpIs(sigmoidOpType), AnyNode("x")))),
+			Replace: log1pNegSigmoidStabilization,
+		},
+		{
+			Name:    "-(-x) → x",
+			Pattern: OpNode(unaryOpIs(negOpType), OpNode(unaryOpIs(negOpType), AnyNode("x"))),
+			Replace: negNegOptimization,
+		},
+	} {
+		rule.Eager = true
+		RegisterRewrite(rule)
+	}
+
+	// optimizations
+	identity := func(m *Match) (*Node, error) { return m.Node("x"), nil }
+	for _, rule := range []RewriteRule{
+		{Name: "x*1 → x", Pattern: OpNode(binOpIs(mulOpType), AnyNode("x"), ConstNode(1)).Commutative(), Replace: identity},
+		{Name: "x/1 → x", Pattern: OpNode(binOpIs(divOpType), AnyNode("x"), ConstNode(1)), Replace: identity},
+		{Name: "x+0 → x", Pattern: OpNode(binOpIs(addOpType), AnyNode("x"), ConstNode(0)).Commutative(), Replace: identity},
+		{Name: "x-0 → x", Pattern: OpNode(binOpIs(subOpType), AnyNode("x"), ConstNode(0)), Replace: identity},
+		{
+			Name:    "transpose(transpose(x)) → transpose(x)",
+			Pattern: OpNode(OpTypeOf(transposeOp{}), OpNode(OpTypeOf(transposeOp{}), AnyNode("x")).As("inner")),
+			Replace: transposeTransposeOptimization,
+		},
+		{
+			Name:    "reshape(reshape(x)) → reshape(x)",
+			Pattern: OpNode(OpTypeOf(reshapeOp{}), OpNode(OpTypeOf(reshapeOp{}), AnyNode("x"))),
+			Replace: reshapeReshapeOptimization,
+		},
+		{
+			Name:    "reshape(x) → x",
+			Pattern: OpNode(OpTypeOf(reshapeOp{}), AnyNode("x")),
+			Where:   func(m *Match) bool { return SameShape(m.Op.(reshapeOp).to, m.Node("x").Shape()) },
+			Replace: identity,
+		},
+	} {
+		RegisterRewrite(rule)
 	}
+}
 
-	g := a.g
-	g.removeAllEdgesFrom(a) // remove all references
-	g.RemoveNode(a)
-	defer returnNode(a) // send it back to the pool, since it is literally useless now
+// logStabilization converts log(1+x) or log(x+1) to log1p(x)
+func logStabilization(m *Match) (*Node, error) {
+	return Log1p(m.Node("x"))
+}
 
-	if bot == subOpType {
-		if retVal, err = Neg(x); err == nil {
-			return Log1p(retVal)
-		}
+// logOneMinusStabilization converts log(1-x) to log1p(-x)
+func logOneMinusStabilization(m *Match) (retVal *Node, err error) {
+	if retVal, err = Neg(m.Node("x")); err != nil {
 		return nil, errors.Wrap(err, negFail)
 	}
-	return Log1p(x)
+	return Log1p(retVal)
 }
 
-// expStabilization converts exp(x)-1 to expm1(x)
-// place before sub; i0 should be exp(x); i1 should be 1
-func exp1mStabilization(a, b *Node) (retVal *Node, err error) {
-	stabLogf("Stabilizing exp(x)-1 to expm1(x) of %v and %v", a, b)
-	enterLogScope()
-	defer leaveLogScope()
-
-	if cnst, ok := b.op.(constant); !ok || (ok && !constEq(cnst, onef32ConstOp) && !constEq(cnst, onef64ConstOp)) {
-		return nil, noStabilizationErr{}
-	}
-
-	if euo, ok := a.op.(elemUnaryOp); !ok || euo.unaryOpType() != expOpType {
-		return nil, noStabilizationErr{}
-	}
-
-	op := newElemUnaryOp(expm1OpType, a.children[0])
-	return ApplyOp(op, a.children[0])
+// exp1mStabilization converts exp(x)-1 to expm1(x)
+func exp1mStabilization(m *Match) (*Node, error) {
+	x := m.Node("x")
+	op := newElemUnaryOp(expm1OpType, x)
+	return ApplyOp(op, x)
 }
 
 // oneMinusSigmoidStabilization stabilizes 1-sigmoid(x) by replacing it with sigmoid(-x)
-// place before sub
-func oneMinusSigmoidStabilization(a, b *Node) (retVal *Node, err error) {
-	stabLogf("Stabilizing 1-sigmoid(x) to sigmoid(-x) of %v and %v", a, b)
-	enterLogScope()
-	defer leaveLogScope()
-
-	if cnst, ok := a.op.(constant); !ok || (ok && !constEq(cnst, onef32ConstOp) && !constEq(cnst, onef64ConstOp)) {
-		return nil, noStabilizationErr{}
-	}
-
-	if euo, ok := b.op.(elemUnaryOp); !ok || euo.unaryOpType() != sigmoidOpType {
-		return nil, noStabilizationErr{}
-	}
-
-	x := b.children[0]
-	if retVal, err = Neg(x); err == nil {
+func oneMinusSigmoidStabilization(m *Match) (retVal *Node, err error) {
+	if retVal, err = Neg(m.Node("x")); err == nil {
 		return Sigmoid(retVal)
 	}
 	return nil, errors.Wrap(err, negFail)
 }
 
 // logSigmoidStabilization stabilizes log(sigmoid(x)) by replacing it with -softplus(-x)
-// place before log; a should be sigmoid(x)
-func logSigmoidStabilization(a *Node) (retVal *Node, err error) {
-	stabLogf("Stabilizing log sigmoid of %v", a)
-	enterLogScope()
-	defer leaveLogScope()
-
-	if euo, ok := a.op.(elemUnaryOp); !ok || (ok && euo.unaryOpType() != sigmoidOpType) {
-		return a, noStabilizationErr{}
-	}
-
-	x := a.children[0]
-	stabLogf("x : %v", x.Name())
-
-	if retVal, err = Neg(x); err == nil {
+func logSigmoidStabilization(m *Match) (retVal *Node, err error) {
+	if retVal, err = Neg(m.Node("x")); err == nil {
 		if retVal, err = Softplus(retVal); err == nil {
 			retVal, err = Neg(retVal)
 			if err != nil {
@@ -153,42 +141,13 @@ func logSigmoidStabilization(a *Node) (retVal *Node, err error) {
 }
 
 // log1pExpStabilization stabilizes log1p(exp(x)) by substituting it with softplus(x)
-// place before log1p; a should be exp(x)
-func log1pExpStabilization(a *Node) (retVal *Node, err error) {
-	stabLogf("Stabilizing log1p(exp(x)) of %v", a)
-	enterLogScope()
-	defer leaveLogScope()
-
-	if euo, ok := a.op.(elemUnaryOp); !ok || (ok && euo.unaryOpType() != expOpType) {
-		stabLogf("op: %v; %v", a.op, a.children)
-		return a, noStabilizationErr{}
-	}
-
-	x := a.children[0]
-	stabLogf("OKKKKK")
-	return Softplus(x)
+func log1pExpStabilization(m *Match) (*Node, error) {
+	return Softplus(m.Node("x"))
 }
 
 // log1pNegSigmoidStabilization stabilizes log1p(-sigmoid(x)) by substituting it with -softplus(x)
-// place before log1p;  a should be -sigmoid(x)
-func log1pNegSigmoidStabilization(a *Node) (retVal *Node, err error) {
-	stabLogf("Stabilizing log1p(-sigmoid(x)) : %v", a)
-	enterLogScope()
-	defer leaveLogScope()
-
-	if euo, ok := a.op.(elemUnaryOp); !ok || (ok && euo.unaryOpType() != negOpType) {
-		return a, noStabilizationErr{}
-	}
-
-	if euo, ok := a.children[0].op.(elemUnaryOp); !ok || (ok && euo.unaryOpType() != sigmoidOpType) {
-		return a, noStabilizationErr{}
-	}
-
-	x := a.children[0].children[0]
-
-	stabLogf("x : %v", x.Name())
-
-	if retVal, err = Softplus(x); err == nil {
+func log1pNegSigmoidStabilization(m *Match) (retVal *Node, err error) {
+	if retVal, err = Softplus(m.Node("x")); err == nil {
 		retVal, err = Neg(retVal)
 		if err != nil {
 			return nil, errors.Wrap(err, negFail)
@@ -198,84 +157,49 @@ func log1pNegSigmoidStabilization(a *Node) (retVal *Node, err error) {
 	return nil, errors.Wrap(err, softplusFail)
 }
 
-// logSoftmaxStabilization converts
-// 	log(softmax(a)) to softmax{isLog: true}(a)
-//	log(a * softmax(b)) to log(a) + softmax{isLog: true}(b)
-func logSoftmaxStabilization(a *Node) (retVal *Node, err error) {
-	stabLogf("Stabilizing log(softmax) of %v", a)
-	enterLogScope()
-	defer leaveLogScope()
-
-	switch op := a.op.(type) {
-	case *softmaxOp:
-		op.isLog = true
-		return a, nil
-	case elemBinOp:
-		if op.ʘBinaryOperator.binOpType() == mulOpType {
-			fst := a.children[0]
-			snd := a.children[1]
-
-			var hasSm bool
-			var smop1, smop2 *softmaxOp
-			if smop, ok := fst.op.(*softmaxOp); ok {
-				hasSm = true
-				smop1 = smop
-			}
-			if smop, ok := snd.op.(*softmaxOp); ok {
-				hasSm = true
-				smop2 = smop
-			}
-
-			if hasSm {
-				var newFst, newSnd *Node
-				switch {
-				case smop1 != nil && smop2 == nil:
-					smop1.isLog = true
-					newFst = fst
-					if newSnd, err = Log(snd); err != nil {
-						return nil, err
-					}
-				case smop1 == nil && smop2 != nil:
-					smop2.isLog = true
-					newSnd = snd
-					if newFst, err = Log(fst); err != nil {
-						return nil, err
-					}
-				case smop1 != nil && smop2 != nil:
-					smop1.isLog = true
-					smop2.isLog = true
-					newFst = fst
-					newSnd = snd
-				default:
-					return a, noStabilizationErr{}
-				}
-
-				// g := a.g
-				// g.removeAllEdgesFrom(a) // remove all references
-				// g.RemoveNode(a)
-				// returnNode(a) // send it back to the pool, since it is literally useless now
-				return Add(newFst, newSnd)
-			}
+// logSoftmaxStabilization converts log(softmax(x)) to softmax{isLog: true}(x)
+func logSoftmaxStabilization(m *Match) (*Node, error) {
+	op := m.Node("softmax").op.(*softmaxOp)
+	return LogSoftMax(m.Node("x"), op.axis)
+}
 
-		}
+// logMulSoftmaxStabilization converts log(a * softmax(x)) to log(a) + softmax{isLog: true}(x).
+// If a is a softmax too, log(a) is stabilized in turn.
+func logMulSoftmaxStabilization(m *Match) (retVal *Node, err error) {
+	var loga, logsm *Node
+	if loga, err = Log(m.Node("a")); err != nil {
+		return nil, err
 	}
-	return a, noStabilizationErr{}
-
+	if logsm, err = logSoftmaxStabilization(m); err != nil {
+		return nil, err
+	}
+	return Add(loga, logsm)
 }
 
 /* Graph Optimizations */
 
 // negNegOptimization optimizes away -(-x) to just return x
-// place before neg
-func negNegOptimization(a *Node) (retVal *Node, err error) {
-	stabLogf("Optimizing -(-x)")
-	enterLogScope()
-	defer leaveLogScope()
+func negNegOptimization(m *Match) (*Node, error) {
+	return m.Node("x"), nil
+}
 
-	if euo, ok := a.op.(elemUnaryOp); !ok || (ok && euo.unaryOpType() != negOpType) {
-		return a, noStabilizationErr{}
+// transposeTransposeOptimization composes two transpositions into one, or none if they cancel out
+func transposeTransposeOptimization(m *Match) (*Node, error) {
+	inner := m.Node("inner").op.(transposeOp).pattern
+	outer := m.Op.(transposeOp).pattern
+	axes := make([]int, len(outer))
+	for i, axis := range outer {
+		axes[i] = inner[axis]
 	}
+	return Transpose(m.Node("x"), axes...)
+}
 
-	x := a.children[0]
-	return x, nil
+// reshapeReshapeOptimization reshapes x once, to the final shape
+func reshapeReshapeOptimization(m *Match) (*Node, error) {
+	x := m.Node("x")
+	to := m.Op.(reshapeOp).to
+	if SameShape(to, x.Shape()) {
+		return x, nil
+	}
+	return Reshape(x, to.Clone())
 }
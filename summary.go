@@ -0,0 +1,264 @@
+package gorgonia
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia/internal/encoding"
+	"gorgonia.org/tensor"
+)
+
+// ModelSummary is the static report of a graph made by Summary: the parameters, the cost and the memory of its nodes,
+// as estimated before any run.
+type ModelSummary struct {
+	Nodes  []NodeSummary  // in the order of execution
+	Groups []GroupSummary // sorted by name
+
+	Params int   // the number of scalar parameters
+	FLOPs  int64 // the estimated number of floating point operations of a run
+	Bytes  int64 // the size of the outputs of all the nodes
+
+	// PeakMemory is the largest amount of memory, per device, held at once by the registers of the compiled program.
+	PeakMemory map[Device]int64
+}
+
+// NodeSummary is the summary of a node.
+type NodeSummary struct {
+	Node   *Node
+	Op     string
+	Shape  tensor.Shape
+	Params int   // the size of the parameters first used by the node
+	FLOPs  int64 // the estimated number of floating point operations of the node
+	Bytes  int64 // the size of the output of the node
+}
+
+// GroupSummary sums up the nodes of a group, e.g. the nodes of a convolution. A node may be part of several groups.
+type GroupSummary struct {
+	Name   string
+	Nodes  int
+	Params int
+	FLOPs  int64
+	Bytes  int64
+}
+
+// Summary reports the parameters, the estimated cost and the memory of a graph, without running it.
+//
+// The parameters are the inputs that are bound to a value when the summary is made, e.g. with WithInit or WithValue,
+// and they are counted by the node that uses them first. The inputs that are only bound before a run, such as the data, are not.
+//
+// The FLOPs are estimated per op: a matrix multiplication of (m, k) by (k, n) costs 2·m·n·k, a pooling costs
+// the size of its kernel per output element, and the elementwise ops and the reductions cost one operation per element.
+// The convolutions are made of an Im2Col and a matrix multiplication, so their cost is the one of the multiplication.
+// The ops that only move data, such as Reshape or Transpose, cost nothing.
+//
+// The peak memory is predicted from the registers allocated by the compiler: the registers live at the same instruction
+// cannot share memory, so the peak is the largest sum of the sizes of the registers live at once.
+//
+// Summary does not change the graph: it is reported as it is built, without the rewrites of Rewrite.
+func Summary(g *ExprGraph) (*ModelSummary, error) {
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to compile the graph")
+	}
+
+	retVal := &ModelSummary{PeakMemory: peakMemory(prog, locMap)}
+	counted := make(map[*Node]bool)
+	groups := make(map[string]*GroupSummary)
+	for _, n := range prog.sorted {
+		if n.isStmt {
+			continue
+		}
+		ns := NodeSummary{
+			Node:  n,
+			Op:    "input",
+			Shape: n.shape,
+			FLOPs: flops(n),
+			Bytes: nodeBytes(n),
+		}
+		if n.op != nil {
+			ns.Op = n.op.String()
+		}
+		for _, child := range n.children {
+			if isParam(child) && !counted[child] {
+				counted[child] = true
+				ns.Params += logicalSize(child.shape)
+			}
+		}
+
+		retVal.Nodes = append(retVal.Nodes, ns)
+		retVal.FLOPs += ns.FLOPs
+		retVal.Bytes += ns.Bytes
+		for _, grp := range n.groups {
+			if isDefaultGroup(grp) {
+				continue
+			}
+			name := strings.TrimRight(grp.Name, " ")
+			gs, ok := groups[name]
+			if !ok {
+				gs = &GroupSummary{Name: name}
+				groups[name] = gs
+			}
+			gs.Nodes++
+			gs.Params += ns.Params
+			gs.FLOPs += ns.FLOPs
+			gs.Bytes += ns.Bytes
+		}
+	}
+
+	// the parameters that are not used by any node
+	for _, n := range prog.sorted {
+		if isParam(n) && !counted[n] {
+			counted[n] = true
+			for i := range retVal.Nodes {
+				if retVal.Nodes[i].Node == n {
+					retVal.Nodes[i].Params = logicalSize(n.shape)
+				}
+			}
+		}
+	}
+	for n := range counted {
+		retVal.Params += logicalSize(n.shape)
+	}
+
+	for _, gs := range groups {
+		retVal.Groups = append(retVal.Groups, *gs)
+	}
+	sort.Slice(retVal.Groups, func(i, j int) bool { return retVal.Groups[i].Name < retVal.Groups[j].Name })
+	return retVal, nil
+}
+
+// String formats the summary as tables: the nodes, the groups if any, and the totals.
+func (s *ModelSummary) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Node\tOp\tShape\tParams\tFLOPs\tBytes\t")
+	for _, ns := range s.Nodes {
+		fmt.Fprintf(w, "%s\t%s\t%v\t%d\t%d\t%d\t\n", ns.Node.Name(), ns.Op, ns.Shape, ns.Params, ns.FLOPs, ns.Bytes)
+	}
+	if len(s.Groups) > 0 {
+		fmt.Fprintln(w, "\t\t\t\t\t\t")
+		fmt.Fprintln(w, "Group\tNodes\t\tParams\tFLOPs\tBytes\t")
+		for _, gs := range s.Groups {
+			fmt.Fprintf(w, "%s\t%d\t\t%d\t%d\t%d\t\n", gs.Name, gs.Nodes, gs.Params, gs.FLOPs, gs.Bytes)
+		}
+	}
+	w.Flush()
+
+	fmt.Fprintf(&buf, "\nParams: %d | FLOPs: %d | Bytes: %d\n", s.Params, s.FLOPs, s.Bytes)
+	devices := make([]Device, 0, len(s.PeakMemory))
+	for d := range s.PeakMemory {
+		devices = append(devices, d)
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i] < devices[j] })
+	for _, d := range devices {
+		fmt.Fprintf(&buf, "Peak memory (%v): %d\n", d, s.PeakMemory[d])
+	}
+	return buf.String()
+}
+
+// isParam returns true if the node is an input that already holds a value
+func isParam(n *Node) bool {
+	return n.isInput() && !n.isRandom() && n.boundTo != nil
+}
+
+func isDefaultGroup(grp encoding.Group) bool {
+	switch grp.ID {
+	case encoding.UndefinedCluster.ID, encoding.ExprGraphCluster.ID, encoding.ConstantCluster.ID,
+		encoding.InputCluster.ID, encoding.StrayCluster.ID:
+		return true
+	}
+	return false
+}
+
+func nodeBytes(n *Node) int64 {
+	dt, err := dtypeOf(n.t)
+	if err != nil || n.shape == nil {
+		return 0
+	}
+	return calcMemSize(dt, n.shape)
+}
+
+// flops estimates the number of floating point operations of a node
+func flops(n *Node) int64 {
+	if n.op == nil || n.shape == nil {
+		return 0
+	}
+	out := int64(logicalSize(n.shape))
+	switch op := n.op.(type) {
+	case elemBinOp, elemUnaryOp:
+		return out
+	case linAlgBinOp:
+		a := n.children[0].shape
+		if op.āBinaryOperator == outerProdOperator {
+			return out
+		}
+		// the size of the contracted axis of a
+		k := a[len(a)-1]
+		if op.transA && a.Dims() > 1 {
+			k = a[len(a)-2]
+		}
+		return 2 * out * int64(k)
+	case tensordotOp:
+		a := n.children[0].shape
+		k := int64(1)
+		for _, axis := range op.aAxes {
+			k *= int64(a[axis])
+		}
+		return 2 * out * k
+	case *maxPoolOp:
+		return out * int64(op.h*op.w)
+	case *avgPoolOp:
+		return out * int64(op.h*op.w+1)
+	case *maxPoolDiffOp:
+		return int64(logicalSize(n.children[2].shape)) * int64(op.h*op.w)
+	case *avgPoolDiffOp:
+		return int64(logicalSize(n.children[2].shape)) * int64(op.h*op.w+1)
+	case *globalAveragePoolOp, sumOp, maxOp:
+		return int64(logicalSize(n.children[0].shape))
+	}
+	return 0
+}
+
+// peakMemory walks the instructions of a program, and sums the sizes of the registers that are live at each of them.
+// A register holds the largest of the values written to it.
+func peakMemory(prog *program, locMap map[*Node]register) map[Device]int64 {
+	sizes := make(map[register]int64)
+	for n, reg := range locMap {
+		if n.isStmt {
+			continue
+		}
+		if b := nodeBytes(n); b > sizes[reg] {
+			sizes[reg] = b
+		}
+	}
+
+	retVal := make(map[Device]int64)
+	for i := range prog.sorted {
+		live := make(map[register]bool)
+		for _, n := range prog.sorted {
+			iv, ok := prog.df.intervals[n]
+			reg, hasReg := locMap[n]
+			if !ok || !hasReg || n.isStmt {
+				continue
+			}
+			// an interval ends at the last instruction that reads it, which needs it
+			if iv.start <= i && i <= iv.end {
+				live[reg] = true
+			}
+		}
+		mem := make(map[Device]int64)
+		for reg := range live {
+			mem[reg.device] += sizes[reg]
+		}
+		for d, m := range mem {
+			if m > retVal[d] {
+				retVal[d] = m
+			}
+		}
+	}
+	return retVal
+}
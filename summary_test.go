@@ -0,0 +1,104 @@
+package gorgonia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/tensor"
+)
+
+func TestSummary(t *testing.T) {
+	g := NewGraph()
+	x := NewMatrix(g, tensor.Float64, WithShape(2, 3), WithName("x"))
+	w := NewMatrix(g, tensor.Float64, WithShape(3, 4), WithName("w"), WithInit(GlorotN(1)))
+	xw := Must(Mul(x, w))
+	act := Must(Tanh(xw))
+	Must(Sum(act))
+
+	s, err := Summary(g)
+	require.NoError(t, err)
+	require.Len(t, s.Nodes, 5)
+
+	byName := make(map[string]NodeSummary)
+	for _, ns := range s.Nodes {
+		byName[ns.Node.Name()] = ns
+	}
+	assert.Equal(t, "input", byName["x"].Op)
+	assert.Equal(t, 0, byName["x"].Params)
+	assert.Equal(t, 0, byName["w"].Params, "the parameters are counted by their first user")
+
+	mul := byName[xw.Name()]
+	assert.Equal(t, "A × B", mul.Op)
+	assert.Equal(t, tensor.Shape{2, 4}, mul.Shape)
+	assert.Equal(t, 12, mul.Params)
+	assert.Equal(t, int64(2*2*4*3), mul.FLOPs)
+	assert.Equal(t, int64(8*8), mul.Bytes)
+	assert.Equal(t, int64(8), byName[act.Name()].FLOPs)
+
+	assert.Equal(t, 12, s.Params)
+	assert.Equal(t, int64(48+8+8), s.FLOPs)
+	assert.Equal(t, int64(48+96+64+64+8), s.Bytes)
+	assert.Empty(t, s.Groups)
+
+	// the inputs are live during the whole run, and at least one of the matrices computed from them
+	peak := s.PeakMemory[CPU]
+	assert.True(t, peak >= 48+96+64, "peak memory %d", peak)
+	assert.True(t, peak <= s.Bytes, "peak memory %d", peak)
+
+	str := s.String()
+	assert.Contains(t, str, "A × B")
+	assert.Contains(t, str, "Params: 12 | FLOPs: 64 | Bytes: 280")
+	assert.Contains(t, str, "Peak memory (CPU): ")
+}
+
+func TestSummary_readOnly(t *testing.T) {
+	g := NewGraph()
+	x := NewMatrix(g, tensor.Float64, WithShape(2, 3), WithName("x"))
+	xtt := Must(Transpose(Must(Transpose(x))))
+	y := Must(Mul(xtt, onef64))
+	Must(Sum(y))
+	nodes := g.AllNodes()
+
+	s, err := Summary(g)
+	require.NoError(t, err)
+	assert.Equal(t, nodes, g.AllNodes())
+	assert.Len(t, s.Nodes, len(nodes))
+}
+
+func TestSummary_groups(t *testing.T) {
+	g := NewGraph()
+	x := NewTensor(g, tensor.Float64, 4, WithShape(1, 1, 4, 4), WithName("x"))
+	filter := NewTensor(g, tensor.Float64, 4, WithShape(2, 1, 3, 3), WithName("filter"), WithInit(GlorotN(1)))
+	conv := Must(Conv2d(x, filter, tensor.Shape{3, 3}, []int{1, 1}, []int{1, 1}, nil))
+	pooled := Must(MaxPool2D(conv, tensor.Shape{2, 2}, []int{0, 0}, []int{2, 2}))
+
+	s, err := Summary(g)
+	require.NoError(t, err)
+	assert.Equal(t, 18, s.Params)
+
+	groups := make(map[string]GroupSummary)
+	for _, gs := range s.Groups {
+		groups[gs.Name] = gs
+	}
+	require.Contains(t, groups, "Convolution")
+	require.Contains(t, groups, "Maxpool")
+	// a (16, 9) × (9, 2) multiplication
+	assert.Equal(t, int64(2*16*2*9), groups["Convolution"].FLOPs)
+	assert.Equal(t, 18, groups["Convolution"].Params)
+
+	var pool NodeSummary
+	for _, ns := range s.Nodes {
+		if ns.Node == pooled {
+			pool = ns
+		}
+	}
+	assert.Equal(t, tensor.Shape{1, 2, 2, 2}, pool.Shape)
+	assert.Equal(t, int64(8*4), pool.FLOPs)
+	assert.Equal(t, int64(8*4), groups["Maxpool"].FLOPs)
+}
+
+func TestSummary_empty(t *testing.T) {
+	_, err := Summary(NewGraph())
+	assert.Error(t, err)
+}
@@ -0,0 +1,248 @@
+package train
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/cheggaaa/pb.v1"
+	"gorgonia.org/gorgonia/data"
+	"gorgonia.org/gorgonia/encoding/statedict"
+	"gorgonia.org/gorgonia/encoding/tensorboard"
+)
+
+// Callback hooks into the training loop. Returning an error aborts the training.
+type Callback interface {
+	OnEpochBegin(t *Trainer, epoch int) error
+
+	// OnBatchEnd is called after every training batch, with the running values of the metrics over the epoch so far.
+	OnBatchEnd(t *Trainer, b *data.Batch, logs Logs) error
+
+	// OnEpochEnd is called with the values of the metrics over the epoch, including those of the validation set.
+	OnEpochEnd(t *Trainer, epoch int, logs Logs) error
+}
+
+// CallbackFuncs is a Callback made of functions. Any of them may be nil.
+type CallbackFuncs struct {
+	EpochBegin func(t *Trainer, epoch int) error
+	BatchEnd   func(t *Trainer, b *data.Batch, logs Logs) error
+	EpochEnd   func(t *Trainer, epoch int, logs Logs) error
+}
+
+// OnEpochBegin calls EpochBegin.
+func (c CallbackFuncs) OnEpochBegin(t *Trainer, epoch int) error {
+	if c.EpochBegin == nil {
+		return nil
+	}
+	return c.EpochBegin(t, epoch)
+}
+
+// OnBatchEnd calls BatchEnd.
+func (c CallbackFuncs) OnBatchEnd(t *Trainer, b *data.Batch, logs Logs) error {
+	if c.BatchEnd == nil {
+		return nil
+	}
+	return c.BatchEnd(t, b, logs)
+}
+
+// OnEpochEnd calls EpochEnd.
+func (c CallbackFuncs) OnEpochEnd(t *Trainer, epoch int, logs Logs) error {
+	if c.EpochEnd == nil {
+		return nil
+	}
+	return c.EpochEnd(t, epoch, logs)
+}
+
+// Mode tells whether a monitored metric is better when it is lower or higher.
+type Mode int
+
+const (
+	// Min is for metrics that are better when lower, such as losses.
+	Min Mode = iota
+	// Max is for metrics that are better when higher, such as accuracies.
+	Max
+)
+
+// improves tells whether v improves on best by more than delta
+func (m Mode) improves(v, best, delta float64) bool {
+	if m == Max {
+		return v > best+delta
+	}
+	return v < best-delta
+}
+
+func (m Mode) worst() float64 {
+	if m == Max {
+		return math.Inf(-1)
+	}
+	return math.Inf(1)
+}
+
+func monitored(logs Logs, name string) (float64, error) {
+	v, ok := logs[name]
+	if !ok {
+		return 0, errors.Errorf("The monitored metric %q is not logged. Available metrics: %v", name, logs)
+	}
+	return v, nil
+}
+
+// EarlyStopping stops the training once the monitored metric has not improved for Patience epochs.
+type EarlyStopping struct {
+	Monitor  string  // the name of the metric, such as "val_loss"
+	Mode     Mode    // whether the metric is better when lower (the default) or higher
+	Patience int     // the number of epochs without improvement to wait for
+	MinDelta float64 // the minimum change that counts as an improvement
+
+	best  float64
+	wait  int
+	epoch int
+}
+
+// Best returns the best value of the monitored metric, and the epoch it was reached at.
+func (c *EarlyStopping) Best() (float64, int) { return c.best, c.epoch }
+
+// OnEpochBegin resets the state of the callback at the start of training.
+func (c *EarlyStopping) OnEpochBegin(t *Trainer, epoch int) error {
+	if epoch == 0 {
+		c.best, c.wait, c.epoch = c.Mode.worst(), 0, 0
+	}
+	return nil
+}
+
+// OnBatchEnd does nothing.
+func (c *EarlyStopping) OnBatchEnd(t *Trainer, b *data.Batch, logs Logs) error { return nil }
+
+// OnEpochEnd stops the Trainer if the monitored metric has run out of patience.
+func (c *EarlyStopping) OnEpochEnd(t *Trainer, epoch int, logs Logs) error {
+	v, err := monitored(logs, c.Monitor)
+	if err != nil {
+		return err
+	}
+	if c.Mode.improves(v, c.best, c.MinDelta) {
+		c.best, c.epoch, c.wait = v, epoch, 0
+		return nil
+	}
+	if c.wait++; c.wait >= c.Patience {
+		t.Stop()
+	}
+	return nil
+}
+
+// Checkpoint saves the parameters of the Trainer as a safetensors file whenever the monitored metric improves.
+// The epoch and the value of the metric are stored in the metadata of the file.
+type Checkpoint struct {
+	Monitor string // the name of the metric, such as "val_loss"
+	Mode    Mode   // whether the metric is better when lower (the default) or higher
+	Path    string
+
+	best float64
+}
+
+// OnEpochBegin resets the state of the callback at the start of training.
+func (c *Checkpoint) OnEpochBegin(t *Trainer, epoch int) error {
+	if epoch == 0 {
+		c.best = c.Mode.worst()
+	}
+	return nil
+}
+
+// OnBatchEnd does nothing.
+func (c *Checkpoint) OnBatchEnd(t *Trainer, b *data.Batch, logs Logs) error { return nil }
+
+// OnEpochEnd saves the parameters if the monitored metric improved.
+func (c *Checkpoint) OnEpochEnd(t *Trainer, epoch int, logs Logs) error {
+	v, err := monitored(logs, c.Monitor)
+	if err != nil {
+		return err
+	}
+	if !c.Mode.improves(v, c.best, 0) {
+		return nil
+	}
+	c.best = v
+	sd, err := statedict.Save(t.Params())
+	if err != nil {
+		return errors.Wrap(err, "Unable to save a checkpoint")
+	}
+	metadata := map[string]string{
+		"epoch":   strconv.Itoa(epoch),
+		c.Monitor: strconv.FormatFloat(v, 'g', -1, 64),
+	}
+	return errors.Wrap(statedict.SaveSafetensors(c.Path, sd, metadata), "Unable to save a checkpoint")
+}
+
+// Progress shows a progress bar over the batches of every epoch, followed by the logs of the epoch.
+type Progress struct {
+	Output io.Writer // defaults to os.Stderr
+
+	bar *pb.ProgressBar
+}
+
+func (c *Progress) output() io.Writer {
+	if c.Output == nil {
+		return os.Stderr
+	}
+	return c.Output
+}
+
+// OnEpochBegin starts the progress bar of the epoch.
+func (c *Progress) OnEpochBegin(t *Trainer, epoch int) error {
+	c.bar = pb.New(t.loader.Len())
+	c.bar.Output = c.output()
+	c.bar.SetRefreshRate(time.Second)
+	c.bar.SetMaxWidth(100)
+	c.bar.Prefix(fmt.Sprintf("Epoch %d", epoch))
+	c.bar.Start()
+	return nil
+}
+
+// OnBatchEnd advances the progress bar, showing the running loss.
+func (c *Progress) OnBatchEnd(t *Trainer, b *data.Batch, logs Logs) error {
+	c.bar.Postfix(fmt.Sprintf(" loss=%.4g", logs["loss"]))
+	c.bar.Increment()
+	return nil
+}
+
+// OnEpochEnd finishes the progress bar and prints the logs of the epoch.
+func (c *Progress) OnEpochEnd(t *Trainer, epoch int, logs Logs) error {
+	c.bar.Finish()
+	_, err := fmt.Fprintf(c.output(), "Epoch %d: %v\n", epoch, logs)
+	return err
+}
+
+// TensorBoard logs the metrics of every epoch to a TensorBoard event file, along with the graph at the start of the training.
+// The Writer is flushed at the end of every epoch, but not closed.
+type TensorBoard struct {
+	Writer     *tensorboard.Writer
+	Histograms bool // also log the histograms of the values and the gradients of the parameters at the end of every epoch
+}
+
+// OnEpochBegin logs the graph at the start of training.
+func (c *TensorBoard) OnEpochBegin(t *Trainer, epoch int) error {
+	if epoch == 0 {
+		return c.Writer.Graph(t.cost.Graph())
+	}
+	return nil
+}
+
+// OnBatchEnd does nothing.
+func (c *TensorBoard) OnBatchEnd(t *Trainer, b *data.Batch, logs Logs) error { return nil }
+
+// OnEpochEnd logs the metrics, and the histograms of the parameters if asked to.
+func (c *TensorBoard) OnEpochEnd(t *Trainer, epoch int, logs Logs) error {
+	if err := c.Writer.Scalars(epoch, logs); err != nil {
+		return err
+	}
+	if c.Histograms {
+		if err := c.Writer.Values(epoch, t.Params()...); err != nil {
+			return err
+		}
+		if err := c.Writer.Gradients(epoch, t.Params()...); err != nil {
+			return err
+		}
+	}
+	return c.Writer.Flush()
+}
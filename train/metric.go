@@ -0,0 +1,128 @@
+package train
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/gorgonia/metrics"
+)
+
+// Logs holds the values of the metrics, by name. The metrics of the validation set are prefixed with "val_".
+type Logs map[string]float64
+
+func (l Logs) String() string {
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%.6g", k, l[k])
+	}
+	return strings.Join(parts, " ")
+}
+
+// Metric is computed over the batches of an epoch from the values of nodes of the graph.
+type Metric interface {
+	Name() string
+
+	// Nodes returns the nodes the metric reads, so that they are computed when evaluating the validation set.
+	Nodes() gorgonia.Nodes
+
+	// Reset is called at the start of every epoch and of every evaluation.
+	Reset()
+
+	// Update is called after every batch has run.
+	Update() error
+
+	// Value returns the value of the metric over the batches seen since the last Reset.
+	Value() float64
+}
+
+// Mean returns a Metric that averages the value of a scalar node over the batches.
+//
+// Like all the metrics that read nodes, it has to be created before the Trainer, as it adds a node to the graph that reads the value of n.
+func Mean(name string, n *gorgonia.Node) Metric {
+	m := &mean{name: name}
+	m.read = gorgonia.Read(n, &m.v)
+	return m
+}
+
+type mean struct {
+	name  string
+	read  *gorgonia.Node
+	v     gorgonia.Value
+	sum   float64
+	count int
+}
+
+func (m *mean) Name() string          { return m.name }
+func (m *mean) Nodes() gorgonia.Nodes { return gorgonia.Nodes{m.read} }
+func (m *mean) Reset()                { m.sum, m.count = 0, 0 }
+
+func (m *mean) Update() error {
+	v, err := scalar(m.v)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to update %q", m.name)
+	}
+	m.sum += v
+	m.count++
+	return nil
+}
+
+func (m *mean) Value() float64 {
+	if m.count == 0 {
+		return 0
+	}
+	return m.sum / float64(m.count)
+}
+
+// FromMetric returns a Metric that updates m with the values of the predictions and the targets after every batch.
+func FromMetric(name string, m metrics.Metric, pred, target *gorgonia.Node) Metric {
+	retVal := &fromMetric{name: name, m: m}
+	retVal.readPred = gorgonia.Read(pred, &retVal.pred)
+	retVal.readTarget = gorgonia.Read(target, &retVal.target)
+	return retVal
+}
+
+type fromMetric struct {
+	name                 string
+	m                    metrics.Metric
+	readPred, readTarget *gorgonia.Node
+	pred, target         gorgonia.Value
+}
+
+func (m *fromMetric) Name() string          { return m.name }
+func (m *fromMetric) Nodes() gorgonia.Nodes { return gorgonia.Nodes{m.readPred, m.readTarget} }
+func (m *fromMetric) Reset()                { m.m.Reset() }
+func (m *fromMetric) Value() float64        { return m.m.Value() }
+
+func (m *fromMetric) Update() error {
+	return errors.Wrapf(m.m.Update(m.pred, m.target), "Unable to update %q", m.name)
+}
+
+// scalar returns a scalar value as a float64
+func scalar(v gorgonia.Value) (float64, error) {
+	if v == nil {
+		return 0, errors.New("The node has not been read")
+	}
+	if v.Shape().TotalSize() != 1 {
+		return 0, errors.Errorf("Expected a scalar. Got a value of shape %v instead", v.Shape())
+	}
+	d := v.Data()
+	switch x := d.(type) {
+	case float64:
+		return x, nil
+	case float32:
+		return float64(x), nil
+	case []float64:
+		return x[0], nil
+	case []float32:
+		return float64(x[0]), nil
+	}
+	return 0, errors.Errorf("Expected a float. Got %T instead", d)
+}
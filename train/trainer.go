@@ -0,0 +1,281 @@
+// Package train provides a Trainer that runs the training loop of a model: it feeds the batches of a DataLoader
+// into the graph, runs the graph on a tape machine, steps a Solver, and evaluates metrics on a validation set.
+//
+// Callbacks hook into the loop. EarlyStopping, Checkpoint, Progress and TensorBoard are provided:
+//		t, err := train.New(cost, model.Parameters(), gorgonia.Nodes{x, y}, gorgonia.NewAdamSolver(),
+//			train.WithValidation(valLoader),
+//			train.WithMetrics(train.Mean("accuracy", acc)),
+//			train.WithCallbacks(
+//				&train.EarlyStopping{Monitor: "val_loss", Patience: 3},
+//				&train.Checkpoint{Monitor: "val_accuracy", Mode: train.Max, Path: "best.safetensors"},
+//				&train.Progress{},
+//			),
+//		)
+//		...
+//		defer t.Close()
+//		history, err := t.Fit(trainLoader, 20)
+package train
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/gorgonia/data"
+	"gorgonia.org/gorgonia/module"
+)
+
+// Trainer runs the training loop of a model.
+type Trainer struct {
+	cost   *gorgonia.Node
+	params gorgonia.Nodes
+	inputs gorgonia.Nodes
+	solver gorgonia.Solver
+
+	metrics    []Metric // the first metric is the loss
+	callbacks  []Callback
+	validation *data.DataLoader
+	mod        module.Module
+	vmOpts     []gorgonia.VMOpt
+
+	machine, evalMachine gorgonia.VM
+
+	loader *data.DataLoader // the DataLoader being fitted
+	epoch  int
+	stop   bool
+}
+
+// Opt is a construction option for a Trainer.
+type Opt func(t *Trainer)
+
+// WithValidation evaluates the cost and the metrics on the given DataLoader at the end of every epoch.
+// The results are logged with a "val_" prefix, such as "val_loss".
+func WithValidation(loader *data.DataLoader) Opt {
+	return func(t *Trainer) { t.validation = loader }
+}
+
+// WithMetrics adds metrics to compute on top of the loss.
+func WithMetrics(metrics ...Metric) Opt {
+	return func(t *Trainer) { t.metrics = append(t.metrics, metrics...) }
+}
+
+// WithCallbacks adds callbacks. They are called in the order they are given.
+func WithCallbacks(callbacks ...Callback) Opt {
+	return func(t *Trainer) { t.callbacks = append(t.callbacks, callbacks...) }
+}
+
+// WithModule keeps the mode of the module in step with the Trainer, so that the graphs built from it later are in the same mode.
+// The Trainer switches the ops of its graph between training and inference itself, with or without a module. See module.SetTraining.
+func WithModule(m module.Module) Opt {
+	return func(t *Trainer) { t.mod = m }
+}
+
+// WithVMOpts passes options on to the tape machine that trains the model.
+func WithVMOpts(opts ...gorgonia.VMOpt) Opt {
+	return func(t *Trainer) { t.vmOpts = append(t.vmOpts, opts...) }
+}
+
+// New creates a Trainer that minimizes the scalar cost with respect to params.
+// The fields of each batch are let into inputs, in order. New computes the gradients of the cost symbolically,
+// so gorgonia.Grad must not have been called on the cost already.
+//
+// The input nodes have fixed shapes, so the DataLoaders should drop their last batch if it may be smaller than the others.
+func New(cost *gorgonia.Node, params, inputs gorgonia.Nodes, solver gorgonia.Solver, opts ...Opt) (*Trainer, error) {
+	if !cost.IsScalar() {
+		return nil, errors.Errorf("Expected the cost to be a scalar. Got %v instead", cost.Shape())
+	}
+	if len(params) == 0 {
+		return nil, errors.New("There are no parameters to train")
+	}
+	t := &Trainer{
+		cost:    cost,
+		params:  params,
+		inputs:  inputs,
+		solver:  solver,
+		metrics: []Metric{Mean("loss", cost)},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	if _, err := gorgonia.Grad(cost, params...); err != nil {
+		return nil, errors.Wrap(err, "Unable to compute the gradients of the cost")
+	}
+	vmOpts := append([]gorgonia.VMOpt{gorgonia.BindDualValues(params...)}, t.vmOpts...)
+	t.machine = gorgonia.NewTapeMachine(cost.Graph(), vmOpts...)
+	if t.validation != nil {
+		// the validation set only needs the forward pass of the cost and of the metrics
+		var roots gorgonia.Nodes
+		for _, m := range t.metrics {
+			roots = append(roots, m.Nodes()...)
+		}
+		t.evalMachine = gorgonia.NewTapeMachine(cost.Graph().SubgraphRoots(roots...))
+	}
+	return t, nil
+}
+
+// Params returns the parameters being trained.
+func (t *Trainer) Params() gorgonia.Nodes { return t.params }
+
+// Epoch returns the current epoch, counting from 0.
+func (t *Trainer) Epoch() int { return t.epoch }
+
+// Stop stops the training at the end of the current epoch. It is meant to be called by callbacks.
+func (t *Trainer) Stop() { t.stop = true }
+
+// Fit trains the model for the given number of epochs, or until a callback stops it.
+// It returns the logs of every epoch that was run.
+func (t *Trainer) Fit(loader *data.DataLoader, epochs int) (history []Logs, err error) {
+	t.loader, t.stop = loader, false
+	for epoch := 0; epoch < epochs && !t.stop; epoch++ {
+		t.epoch = epoch
+		for _, cb := range t.callbacks {
+			if err = cb.OnEpochBegin(t, epoch); err != nil {
+				return history, err
+			}
+		}
+
+		var logs Logs
+		if logs, err = t.trainEpoch(loader); err != nil {
+			return history, errors.Wrapf(err, "Epoch %d", epoch)
+		}
+		if t.validation != nil {
+			var val Logs
+			if val, err = t.Evaluate(t.validation); err != nil {
+				return history, errors.Wrapf(err, "Epoch %d", epoch)
+			}
+			for k, v := range val {
+				logs["val_"+k] = v
+			}
+		}
+		history = append(history, logs)
+
+		for _, cb := range t.callbacks {
+			if err = cb.OnEpochEnd(t, epoch, logs); err != nil {
+				return history, err
+			}
+		}
+	}
+	return history, nil
+}
+
+func (t *Trainer) trainEpoch(loader *data.DataLoader) (Logs, error) {
+	if err := t.setTraining(true); err != nil {
+		return nil, err
+	}
+	t.resetMetrics()
+	it := loader.Iter()
+	defer it.Close()
+	for it.Next() {
+		b := it.Batch()
+		if err := t.step(b); err != nil {
+			return nil, errors.Wrapf(err, "Batch %d", b.Index)
+		}
+		logs := t.logs()
+		for _, cb := range t.callbacks {
+			if err := cb.OnBatchEnd(t, b, logs); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return t.logs(), nil
+}
+
+func (t *Trainer) step(b *data.Batch) error {
+	defer t.machine.Reset()
+	if err := b.Let(t.inputs...); err != nil {
+		return err
+	}
+	if err := t.machine.RunAll(); err != nil {
+		return err
+	}
+	if err := t.updateMetrics(); err != nil {
+		return err
+	}
+	return t.solver.Step(gorgonia.NodesToValueGrads(t.params))
+}
+
+// Evaluate computes the cost and the metrics over the batches of a DataLoader, without training. The graph is run in inference mode.
+// It requires a validation set to have been given with WithValidation, as that is when the machine evaluating the model is built.
+func (t *Trainer) Evaluate(loader *data.DataLoader) (Logs, error) {
+	if t.evalMachine == nil {
+		return nil, errors.New("Evaluate requires the Trainer to be created WithValidation")
+	}
+	if err := t.setTraining(false); err != nil {
+		return nil, err
+	}
+	t.resetMetrics()
+	it := loader.Iter()
+	defer it.Close()
+	for it.Next() {
+		b := it.Batch()
+		if err := t.eval(b); err != nil {
+			return nil, errors.Wrapf(err, "Evaluating batch %d", b.Index)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return t.logs(), nil
+}
+
+func (t *Trainer) eval(b *data.Batch) error {
+	defer t.evalMachine.Reset()
+	if err := b.Let(t.inputs...); err != nil {
+		return err
+	}
+	if err := t.evalMachine.RunAll(); err != nil {
+		return err
+	}
+	return t.updateMetrics()
+}
+
+// setTraining switches the ops that behave differently in training and in inference, such as dropout and batch normalization,
+// in the graph the Trainer runs
+func (t *Trainer) setTraining(training bool) error {
+	for _, n := range t.cost.Graph().AllNodes() {
+		if op, ok := n.Op().(gorgonia.TrainModeOp); ok {
+			if err := op.SetTraining(training); err != nil {
+				return err
+			}
+		}
+	}
+	if t.mod == nil {
+		return nil
+	}
+	return module.SetTraining(t.mod, training)
+}
+
+func (t *Trainer) resetMetrics() {
+	for _, m := range t.metrics {
+		m.Reset()
+	}
+}
+
+func (t *Trainer) updateMetrics() error {
+	for _, m := range t.metrics {
+		if err := m.Update(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Trainer) logs() Logs {
+	logs := make(Logs, len(t.metrics))
+	for _, m := range t.metrics {
+		logs[m.Name()] = m.Value()
+	}
+	return logs
+}
+
+// Close closes the machines of the Trainer.
+func (t *Trainer) Close() error {
+	if t.evalMachine != nil {
+		if err := t.evalMachine.Close(); err != nil {
+			return err
+		}
+	}
+	return t.machine.Close()
+}
@@ -0,0 +1,235 @@
+package train
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/gorgonia/data"
+	"gorgonia.org/gorgonia/encoding/statedict"
+	"gorgonia.org/gorgonia/encoding/tensorboard"
+	"gorgonia.org/gorgonia/metrics"
+	"gorgonia.org/gorgonia/module"
+	"gorgonia.org/tensor"
+)
+
+// regression sets up a linear regression of y = 2x₀ - x₁ + 0.5
+type regression struct {
+	g       *gorgonia.ExprGraph
+	x, y    *gorgonia.Node
+	pred    *gorgonia.Node
+	cost    *gorgonia.Node
+	model   *module.Linear
+	dataset *data.TensorDataset
+}
+
+func newRegression(t *testing.T, batchSize int) *regression {
+	r := new(regression)
+	r.g = gorgonia.NewGraph()
+	r.x = gorgonia.NewMatrix(r.g, tensor.Float64, gorgonia.WithShape(batchSize, 2), gorgonia.WithName("x"))
+	r.y = gorgonia.NewMatrix(r.g, tensor.Float64, gorgonia.WithShape(batchSize, 1), gorgonia.WithName("y"))
+	r.model = module.NewLinear(module.NewScope(r.g, tensor.Float64, "fc"), 2, 1)
+	var err error
+	r.pred, err = r.model.Forward(r.x)
+	require.NoError(t, err)
+	r.cost = gorgonia.Must(gorgonia.Mean(gorgonia.Must(gorgonia.Square(gorgonia.Must(gorgonia.Sub(r.pred, r.y))))))
+
+	rnd := rand.New(rand.NewSource(1337))
+	const n = 32
+	xs := make([]float64, 2*n)
+	ys := make([]float64, n)
+	for i := 0; i < n; i++ {
+		xs[2*i], xs[2*i+1] = rnd.Float64(), rnd.Float64()
+		ys[i] = 2*xs[2*i] - xs[2*i+1] + 0.5
+	}
+	r.dataset, err = data.NewTensorDataset(
+		tensor.New(tensor.WithShape(n, 2), tensor.WithBacking(xs)),
+		tensor.New(tensor.WithShape(n, 1), tensor.WithBacking(ys)),
+	)
+	require.NoError(t, err)
+	return r
+}
+
+func TestTrainer_Fit(t *testing.T) {
+	r := newRegression(t, 4)
+	var batches, epochEnds int
+	counter := CallbackFuncs{
+		BatchEnd: func(t *Trainer, b *data.Batch, logs Logs) error {
+			batches++
+			return nil
+		},
+		EpochEnd: func(tr *Trainer, epoch int, logs Logs) error {
+			epochEnds++
+			assert.Equal(t, epoch, tr.Epoch())
+			return nil
+		},
+	}
+	var out bytes.Buffer
+	tr, err := New(r.cost, r.model.Parameters(), gorgonia.Nodes{r.x, r.y}, gorgonia.NewAdamSolver(gorgonia.WithLearnRate(0.1)),
+		WithValidation(data.NewDataLoader(r.dataset, data.WithBatchSize(4))),
+		WithModule(r.model),
+		WithMetrics(FromMetric("mae", new(metrics.MAE), r.pred, r.y)),
+		WithCallbacks(counter, &Progress{Output: &out}),
+	)
+	require.NoError(t, err)
+	defer tr.Close()
+
+	loader := data.NewDataLoader(r.dataset, data.WithBatchSize(4), data.WithShuffle(1))
+	history, err := tr.Fit(loader, 30)
+	require.NoError(t, err)
+	require.Len(t, history, 30)
+	assert.Equal(t, 30*8, batches)
+	assert.Equal(t, 30, epochEnds)
+
+	first, last := history[0], history[len(history)-1]
+	assert.Contains(t, first, "loss")
+	assert.Contains(t, first, "val_loss")
+	assert.Contains(t, first, "mae")
+	assert.True(t, last["val_mae"] < 0.1, "val_mae is %v", last["val_mae"])
+	assert.True(t, last["loss"] < first["loss"]/10, "loss went from %v to %v", first["loss"], last["loss"])
+	assert.True(t, last["val_loss"] < 0.01, "val_loss is %v", last["val_loss"])
+	assert.Contains(t, out.String(), "Epoch 29: loss=")
+
+	// evaluation does not train
+	before := r.model.Weight.Value().Data().([]float64)[0]
+	logs, err := tr.Evaluate(loader)
+	require.NoError(t, err)
+	assert.InDelta(t, last["val_loss"], logs["loss"], 1e-9)
+	assert.Equal(t, before, r.model.Weight.Value().Data().([]float64)[0])
+}
+
+func TestTrainer_WithModule(t *testing.T) {
+	r := newRegression(t, 4)
+	// the prediction goes through a dropout, which must be left out when evaluating
+	drop := module.NewDropout(0.5)
+	seq := module.NewSequential(r.model, drop)
+	pred, err := drop.Forward(r.pred)
+	require.NoError(t, err)
+	cost := gorgonia.Must(gorgonia.Mean(gorgonia.Must(gorgonia.Square(gorgonia.Must(gorgonia.Sub(pred, r.y))))))
+
+	loader := data.NewDataLoader(r.dataset, data.WithBatchSize(4))
+	tr, err := New(cost, seq.Parameters(), gorgonia.Nodes{r.x, r.y}, gorgonia.NewVanillaSolver(),
+		WithValidation(loader),
+		WithModule(seq),
+	)
+	require.NoError(t, err)
+	defer tr.Close()
+
+	_, err = tr.Fit(loader, 2)
+	require.NoError(t, err)
+	a, err := tr.Evaluate(loader)
+	require.NoError(t, err)
+	b, err := tr.Evaluate(loader)
+	require.NoError(t, err)
+	assert.Equal(t, a["loss"], b["loss"])
+}
+
+func TestTrainer_EvalMode(t *testing.T) {
+	r := newRegression(t, 4)
+	// a dropout that is not part of any module is left out when evaluating as well
+	pred, err := gorgonia.Dropout(r.pred, 0.5)
+	require.NoError(t, err)
+	cost := gorgonia.Must(gorgonia.Mean(gorgonia.Must(gorgonia.Square(gorgonia.Must(gorgonia.Sub(pred, r.y))))))
+
+	loader := data.NewDataLoader(r.dataset, data.WithBatchSize(4))
+	tr, err := New(cost, r.model.Parameters(), gorgonia.Nodes{r.x, r.y}, gorgonia.NewVanillaSolver(), WithValidation(loader))
+	require.NoError(t, err)
+	defer tr.Close()
+
+	_, err = tr.Fit(loader, 2)
+	require.NoError(t, err)
+	a, err := tr.Evaluate(loader)
+	require.NoError(t, err)
+	b, err := tr.Evaluate(loader)
+	require.NoError(t, err)
+	assert.Equal(t, a["loss"], b["loss"])
+}
+
+func TestTrainer_EarlyStoppingAndCheckpoint(t *testing.T) {
+	r := newRegression(t, 8)
+	// a metric that gets worse after the third epoch
+	var epoch int
+	wobble := CallbackFuncs{
+		EpochBegin: func(t *Trainer, e int) error { epoch = e; return nil },
+		EpochEnd: func(t *Trainer, e int, logs Logs) error {
+			logs["wobble"] = float64((epoch - 2) * (epoch - 2))
+			return nil
+		},
+	}
+	stopping := &EarlyStopping{Monitor: "wobble", Patience: 2}
+	path := filepath.Join(t.TempDir(), "best.safetensors")
+	checkpoint := &Checkpoint{Monitor: "wobble", Path: path}
+	events, err := tensorboard.NewWriter(t.TempDir())
+	require.NoError(t, err)
+	defer events.Close()
+	start, err := os.Stat(events.Path())
+	require.NoError(t, err)
+
+	tr, err := New(r.cost, r.model.Parameters(), gorgonia.Nodes{r.x, r.y}, gorgonia.NewVanillaSolver(),
+		WithCallbacks(wobble, stopping, checkpoint, &TensorBoard{Writer: events, Histograms: true}),
+	)
+	require.NoError(t, err)
+	defer tr.Close()
+
+	history, err := tr.Fit(data.NewDataLoader(r.dataset, data.WithBatchSize(8)), 100)
+	require.NoError(t, err)
+	// the best epoch is 2, and there is no improvement in epochs 3 and 4
+	assert.Len(t, history, 5)
+	best, bestEpoch := stopping.Best()
+	assert.Equal(t, 0.0, best)
+	assert.Equal(t, 2, bestEpoch)
+
+	sd, err := statedict.LoadSafetensors(path)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"fc.weight", "fc.bias"}, sd.Keys())
+
+	// the graph, and the logs and histograms of every epoch are flushed
+	end, err := os.Stat(events.Path())
+	require.NoError(t, err)
+	assert.True(t, end.Size() > start.Size()+5*100, "%d bytes of events", end.Size()-start.Size())
+
+	// a missing metric is an error
+	missing := &EarlyStopping{Monitor: "val_loss"}
+	tr.callbacks = []Callback{missing}
+	_, err = tr.Fit(data.NewDataLoader(r.dataset, data.WithBatchSize(8)), 1)
+	assert.Error(t, err)
+
+	_, err = tr.Evaluate(data.NewDataLoader(r.dataset, data.WithBatchSize(8)))
+	assert.Error(t, err)
+}
+
+func TestTrainer_Errors(t *testing.T) {
+	r := newRegression(t, 4)
+	_, err := New(r.x, r.model.Parameters(), gorgonia.Nodes{r.x, r.y}, gorgonia.NewVanillaSolver())
+	assert.Error(t, err)
+	_, err = New(r.cost, nil, gorgonia.Nodes{r.x, r.y}, gorgonia.NewVanillaSolver())
+	assert.Error(t, err)
+
+	abort := CallbackFuncs{
+		BatchEnd: func(t *Trainer, b *data.Batch, logs Logs) error { return errors.New("abort") },
+	}
+	tr, err := New(r.cost, r.model.Parameters(), gorgonia.Nodes{r.x, r.y}, gorgonia.NewVanillaSolver(), WithCallbacks(abort))
+	require.NoError(t, err)
+	defer tr.Close()
+	history, err := tr.Fit(data.NewDataLoader(r.dataset, data.WithBatchSize(4)), 3)
+	assert.EqualError(t, errors.Cause(err), "abort")
+	assert.Empty(t, history)
+
+	// the last batch is smaller than the input nodes
+	tr.callbacks = nil
+	_, err = tr.Fit(data.NewDataLoader(r.dataset, data.WithBatchSize(5)), 1)
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "Epoch 0"), err.Error())
+}
+
+func TestLogs(t *testing.T) {
+	logs := Logs{"val_loss": 0.5, "loss": 0.25}
+	assert.Equal(t, "loss=0.25 val_loss=0.5", logs.String())
+}
@@ -65,6 +65,9 @@ func inferNodeType(op Op, children ...*Node) (retVal hm.Type, err error) {
 	// var t0 hm.Type
 	var sub hm.Subs
 	if sub, err = hm.Unify(fn, fnType); err != nil {
+		if dme := dtypeMismatchOf(op, children, err); dme != nil {
+			err = dme
+		}
 		return nil, errors.Wrapf(err, "Unable to unify while inferring type of %v", op)
 	}
 
@@ -77,6 +80,46 @@ func inferNodeType(op Op, children ...*Node) (retVal hm.Type, err error) {
 	return retVal, nil
 }
 
+// dtypeMismatchOf returns a DtypeMismatchError, with the message of the unification error, if the Dtypes of the children
+// do not agree with the type of the op: two children have different Dtypes where the type of the op has the same type
+// variable, or a child does not have the Dtype that the op requires (e.g. tensor.Int for indices).
+func dtypeMismatchOf(op Op, children Nodes, err error) *DtypeMismatchError {
+	fnType, ok := op.Type().(*hm.FunctionType)
+	if !ok {
+		return nil
+	}
+	defer hm.ReturnFnType(fnType)
+	params := fnType.FlatTypes()
+	defer hm.ReturnTypes(params)
+
+	seen := make(map[hm.TypeVariable]tensor.Dtype)
+	for i, child := range children {
+		if i >= len(params)-1 {
+			break
+		}
+		dt, dtErr := dtypeOf(child.t)
+		if dtErr != nil {
+			return nil
+		}
+		param := params[i]
+		if tt, ok := param.(TensorType); ok {
+			param = tt.Of
+		}
+		switch want := param.(type) {
+		case tensor.Dtype:
+			if dt != want {
+				return newDtypeMismatch(want, dt, "%s", err.Error())
+			}
+		case hm.TypeVariable:
+			if first, ok := seen[want]; ok && first != dt {
+				return newDtypeMismatch(first, dt, "%s", err.Error())
+			}
+			seen[want] = dt
+		}
+	}
+	return nil
+}
+
 func isScalarType(t hm.Type) bool {
 	switch tt := t.(type) {
 	case tensor.Dtype:
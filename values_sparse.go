@@ -0,0 +1,247 @@
+package gorgonia
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// SparseRows is a Value representing a matrix of which only a subset of rows are stored. The rows that are not stored are implicitly zero.
+//
+// It is the gradient produced by Embedding: a lookup only touches a handful of rows of a (potentially very large) table,
+// so there is no point in materializing the gradient of the whole table. VanillaSolver, AdamSolver and AdaGradSolver apply
+// a *SparseRows lazily, touching only the rows it carries. The other solvers apply its dense equivalent.
+type SparseRows struct {
+	shape tensor.Shape  // shape of the dense equivalent
+	rows  []int         // indices of the stored rows, in ascending order
+	vals  *tensor.Dense // the stored rows. Shape: (len(rows), shape[1])
+}
+
+// NewSparseRows creates a new *SparseRows. shape is the shape of the dense matrix it represents, rows are the indices of the rows stored in vals.
+// rows must be unique and in ascending order, and vals must be a matrix with len(rows) rows.
+func NewSparseRows(shape tensor.Shape, rows []int, vals *tensor.Dense) (*SparseRows, error) {
+	if shape.Dims() != 2 {
+		return nil, errors.Errorf("SparseRows only supports matrices. Got shape %v", shape)
+	}
+	if vals.Dims() != 2 || vals.Shape()[0] != len(rows) || vals.Shape()[1] != shape[1] {
+		return nil, newShapeMismatch(tensor.Shape{len(rows), shape[1]}, vals.Shape(), "Expected values of shape (%d, %d). Got %v instead", len(rows), shape[1], vals.Shape())
+	}
+	if !sort.IntsAreSorted(rows) {
+		return nil, errors.New("Expected rows to be sorted in ascending order")
+	}
+	for i, r := range rows {
+		if r < 0 || r >= shape[0] {
+			return nil, errors.Errorf("Row %d out of range of shape %v", r, shape)
+		}
+		if i > 0 && rows[i-1] == r {
+			return nil, errors.Errorf("Row %d is repeated", r)
+		}
+	}
+	return &SparseRows{shape: shape.Clone(), rows: rows, vals: vals}, nil
+}
+
+// Rows returns the indices of the stored rows.
+func (r *SparseRows) Rows() []int { return r.rows }
+
+// Values returns the stored rows as a matrix. The ith row of the matrix is the row Rows()[i] of the dense equivalent.
+func (r *SparseRows) Values() *tensor.Dense { return r.vals }
+
+// Shape returns the shape of the dense equivalent.
+func (r *SparseRows) Shape() tensor.Shape { return r.shape.Clone() }
+
+// Size returns the number of elements of the dense equivalent.
+func (r *SparseRows) Size() int { return r.shape.TotalSize() }
+
+// Data returns the data of the stored rows.
+func (r *SparseRows) Data() interface{} { return r.vals.Data() }
+
+// Dtype returns the Dtype of the values.
+func (r *SparseRows) Dtype() tensor.Dtype { return r.vals.Dtype() }
+
+// Uintptr returns the pointer to the memory of the stored rows.
+func (r *SparseRows) Uintptr() uintptr { return r.vals.Uintptr() }
+
+// MemSize returns the size of the memory of the stored rows.
+func (r *SparseRows) MemSize() uintptr { return r.vals.MemSize() }
+
+// Format implements fmt.Formatter.
+func (r *SparseRows) Format(s fmt.State, c rune) {
+	fmt.Fprintf(s, "SparseRows %v. Rows: %v\n", r.shape, r.rows)
+	r.vals.Format(s, c)
+}
+
+// Clone clones the *SparseRows. It implements CloneErrorer.
+func (r *SparseRows) Clone() (interface{}, error) {
+	rows := make([]int, len(r.rows))
+	copy(rows, r.rows)
+	return &SparseRows{
+		shape: r.shape.Clone(),
+		rows:  rows,
+		vals:  r.vals.Clone().(*tensor.Dense),
+	}, nil
+}
+
+// ZeroValue zeroes the stored rows. It implements ZeroValuer.
+func (r *SparseRows) ZeroValue() Value {
+	r.vals.Zero()
+	return r
+}
+
+// Dense returns the dense equivalent.
+func (r *SparseRows) Dense() (*tensor.Dense, error) {
+	retVal := tensor.New(tensor.Of(r.Dtype()), tensor.WithShape(r.shape...))
+	if err := r.AddTo(retVal); err != nil {
+		return nil, err
+	}
+	return retVal, nil
+}
+
+// AddTo adds the stored rows to the matching rows of a dense matrix.
+func (r *SparseRows) AddTo(dst *tensor.Dense) error {
+	if !dst.Shape().Eq(r.shape) {
+		return newShapeMismatch(r.shape, dst.Shape(), shapeMismatchErr, r.shape, dst.Shape())
+	}
+	if dst.Dtype() != r.Dtype() {
+		return newDtypeMismatch(r.Dtype(), dst.Dtype(), "Dtype Mismatch. Expected %v. Got %v instead.", r.Dtype(), dst.Dtype())
+	}
+	cols := r.shape[1]
+	switch r.Dtype() {
+	case tensor.Float64:
+		d := dst.Float64s()
+		v := r.vals.Float64s()
+		for i, row := range r.rows {
+			vecAddF64(d[row*cols:(row+1)*cols], v[i*cols:(i+1)*cols])
+		}
+	case tensor.Float32:
+		d := dst.Float32s()
+		v := r.vals.Float32s()
+		for i, row := range r.rows {
+			vecAddF32(d[row*cols:(row+1)*cols], v[i*cols:(i+1)*cols])
+		}
+	default:
+		return errors.Errorf(nyiFail, "SparseRows.AddTo", r.Dtype())
+	}
+	return nil
+}
+
+// addSparseGrad adds a sparse gradient to the derivative of a dual value, and returns the new derivative.
+//
+// A derivative that is zero (it has just been created, or a solver has applied it) is replaced by a copy of the
+// sparse gradient, so that the gradient reaches the solvers as a *SparseRows and they only update the rows it carries.
+// Otherwise the sparse gradient is added to the derivative: the rows are merged into a sparse derivative, and scattered
+// into a dense one.
+func addSparseGrad(d Value, grad *SparseRows) (Value, error) {
+	switch d := d.(type) {
+	case *SparseRows:
+		if d.isZero() {
+			return CloneValue(grad)
+		}
+		return d.merge(grad)
+	case *tensor.Dense:
+		if isZeroDense(d) {
+			return CloneValue(grad)
+		}
+		if err := grad.AddTo(d); err != nil {
+			return nil, err
+		}
+		return d, nil
+	default:
+		return nil, errors.Errorf("Cannot add a sparse gradient to %T", d)
+	}
+}
+
+// merge returns the sum of two *SparseRows. The result stores the union of their rows.
+func (r *SparseRows) merge(other *SparseRows) (*SparseRows, error) {
+	if !r.shape.Eq(other.shape) {
+		return nil, newShapeMismatch(r.shape, other.shape, shapeMismatchErr, r.shape, other.shape)
+	}
+	if r.Dtype() != other.Dtype() {
+		return nil, newDtypeMismatch(r.Dtype(), other.Dtype(), "Dtype Mismatch. Expected %v. Got %v instead.", r.Dtype(), other.Dtype())
+	}
+
+	rows := make([]int, 0, len(r.rows)+len(other.rows))
+	for i, j := 0, 0; i < len(r.rows) || j < len(other.rows); {
+		switch {
+		case j == len(other.rows) || (i < len(r.rows) && r.rows[i] < other.rows[j]):
+			rows = append(rows, r.rows[i])
+			i++
+		case i == len(r.rows) || other.rows[j] < r.rows[i]:
+			rows = append(rows, other.rows[j])
+			j++
+		default:
+			rows = append(rows, r.rows[i])
+			i++
+			j++
+		}
+	}
+
+	cols := r.shape[1]
+	vals := tensor.New(tensor.Of(r.Dtype()), tensor.WithShape(len(rows), cols))
+	retVal := &SparseRows{shape: r.shape.Clone(), rows: rows, vals: vals}
+	for _, s := range []*SparseRows{r, other} {
+		if err := s.addRowsTo(retVal); err != nil {
+			return nil, err
+		}
+	}
+	return retVal, nil
+}
+
+// addRowsTo adds the stored rows to the matching stored rows of dst, which has to store all of them.
+func (r *SparseRows) addRowsTo(dst *SparseRows) error {
+	cols := r.shape[1]
+	slot := 0
+	for i, row := range r.rows {
+		for dst.rows[slot] != row {
+			slot++
+		}
+		switch r.Dtype() {
+		case tensor.Float64:
+			vecAddF64(dst.vals.Float64s()[slot*cols:(slot+1)*cols], r.vals.Float64s()[i*cols:(i+1)*cols])
+		case tensor.Float32:
+			vecAddF32(dst.vals.Float32s()[slot*cols:(slot+1)*cols], r.vals.Float32s()[i*cols:(i+1)*cols])
+		default:
+			return errors.Errorf(nyiFail, "SparseRows.merge", r.Dtype())
+		}
+	}
+	return nil
+}
+
+// isZero returns true if all the stored rows are zero.
+func (r *SparseRows) isZero() bool { return isZeroDense(r.vals) }
+
+// isZeroDense returns true if all the elements of a float tensor are zero. Tensors of other Dtypes are never zero.
+func isZeroDense(t *tensor.Dense) bool {
+	switch t.Dtype() {
+	case tensor.Float64:
+		for _, v := range t.Float64s() {
+			if v != 0 {
+				return false
+			}
+		}
+	case tensor.Float32:
+		for _, v := range t.Float32s() {
+			if v != 0 {
+				return false
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func vecAddF64(a, b []float64) {
+	b = b[:len(a)]
+	for i := range a {
+		a[i] += b[i]
+	}
+}
+
+func vecAddF32(a, b []float32) {
+	b = b[:len(a)]
+	for i := range a {
+		a[i] += b[i]
+	}
+}
@@ -310,3 +310,36 @@ func WithEngine(e tensor.Engine) VMOpt {
 	}
 	return f
 }
+
+// WithRewrites is an option for *tapeMachine only. The graph is rewritten with the registered rules before it is compiled,
+// which simplifies it, e.g. x*1 into x. See Rewrite: the graph is changed in place, and the intermediate nodes that are
+// rewritten away will not have values. The option has no effect with WithPrecompiled.
+func WithRewrites() VMOpt {
+	f := func(m VM) {
+		switch v := m.(type) {
+		case *tapeMachine:
+			v.rewrites = true
+		default:
+			panic(nyi("WithRewrites", v))
+		}
+	}
+	return f
+}
+
+// WithArena is an option for *tapeMachine only. The CPU memory of the intermediate values then comes from an arena:
+// the buffer of a value that is not needed anymore is handed to the values computed after it, and to the next runs, so
+// that a machine that runs many times does not leave garbage behind.
+//
+// The values of the inputs, the outputs, the named nodes, the statements, the gradients of the inputs and the watched
+// nodes are kept. The values of the other nodes may be overwritten by the end of a run: name the nodes to read them.
+func WithArena() VMOpt {
+	f := func(m VM) {
+		switch v := m.(type) {
+		case *tapeMachine:
+			v.useArena = true
+		default:
+			panic(nyi("WithArena", v))
+		}
+	}
+	return f
+}
@@ -35,6 +35,8 @@ type lispMachine struct {
 	tabcount  int
 	logFlags  byte
 
+	watchDump string // where the NaN and Inf watches dump the inputs of the offending instruction
+
 	runFlags     byte // supposed to go into state stuff.  Placed here for better compacting of struct
 	checkedRoots bool // supposed to go into state stuff.
 	evalMode     bool
@@ -170,12 +172,7 @@ func (m *lispMachine) RunAll() (err error) {
 				syncChan <- struct{}{}
 			}
 		case err = <-errChan:
-			if m.fwd < len(m.sorted) {
-				err = vmContextualError{
-					error: errors.Wrapf(err, "Running Node: %v", m.sorted[m.fwd]),
-					node:  m.sorted[m.fwd],
-					instr: m.fwd,
-				}
+			if _, ok := err.(vmContextualError); ok {
 				return
 			}
 			return errors.Wrap(err, "RunAll")
@@ -268,7 +265,15 @@ func (m *lispMachine) runall(errChan chan error, doneChan chan struct{}) {
 	}
 
 	if err != nil {
-		errChan <- err
+		// the loop has moved past the node that failed
+		n := m.sorted[m.fwd-1]
+		setErrContext(err, n.op, n, n.children)
+		errChan <- vmContextualError{
+			error: errors.Wrapf(err, "Running Node: %s", n.provenance()),
+			node:  n,
+			instr: m.fwd - 1,
+		}
+		return
 	}
 
 	// send a synchronous signal, do all (if any) CUDA work before continuing with backprop
@@ -500,9 +505,19 @@ func (m *lispMachine) forward() (err error) {
 	}
 	m.watchedLogf("Added to Queue")
 
-	if m.watchNaN() && !n.isStmt {
-		if hasNaN(n.boundTo, dev) {
-			return errors.New("NaN found in value")
+	if !n.isStmt && (m.watchNaN() || m.watchInf()) {
+		report := func() *WatchReport {
+			ins := make([]watchInput, len(inputs))
+			for i, in := range inputs {
+				ins[i] = watchInput{node: children[i], v: in.Value}
+			}
+			return newWatchReport(n, m.fwd, false, n.boundTo, ins, m.watchDump)
+		}
+		if m.watchNaN() && hasNaN(n.boundTo, dev) {
+			return &NaNDetectedError{Node: n, Report: report()}
+		}
+		if m.watchInf() && hasInf(n.boundTo, dev) {
+			return &InfDetectedError{Node: n, Report: report()}
 		}
 	}
 
@@ -531,7 +546,7 @@ func (m *lispMachine) backward() (err error) {
 
 	// actual differentiation
 	if err = instr.do(); err != nil {
-		return errors.Wrapf(err, autodiffFail, instr.ADOp)
+		return errors.Wrapf(err, autodiffFail+". Node: %s", instr.ADOp, instr.output.provenance())
 	}
 
 	// Make sure that all the engines of all the values are set to use the correct engine
@@ -548,20 +563,38 @@ func (m *lispMachine) backward() (err error) {
 
 	m.leaveLogScope()
 
-	if m.watchNaN() {
-		if hasNaN(instr.output.boundTo, instr.ctx.Device) {
-			return errors.New("NaN found in value")
+	for _, n := range append(Nodes{instr.output}, instr.inputs...) {
+		if m.watchNaN() && hasNaN(n.boundTo, instr.ctx.Device) {
+			return &NaNDetectedError{Node: n, Report: m.backwardReport(instr, n, hasNaN)}
 		}
-
-		for _, in := range instr.inputs {
-			if hasNaN(in.boundTo, instr.ctx.Device) {
-				return errors.New("NaN found in value")
-			}
+		if m.watchInf() && hasInf(n.boundTo, instr.ctx.Device) {
+			return &InfDetectedError{Node: n, Report: m.backwardReport(instr, n, hasInf)}
 		}
 	}
 	return
 }
 
+// backwardReport reports a differentiation after which n holds a NaN or an Inf, as found by has. The differentiation reads
+// the values of the inputs, and the gradient of the output.
+func (m *lispMachine) backwardReport(instr adInstr, n *Node, has func(Value, Device) bool) *WatchReport {
+	ins := make([]watchInput, 0, len(instr.inputs)+1)
+	for _, in := range instr.inputs {
+		ins = append(ins, watchInput{node: in, v: in.boundTo.(*dualValue).Value})
+	}
+	ins = append(ins, watchInput{node: instr.output, grad: true, v: instr.output.boundTo.(*dualValue).d})
+
+	var out Value
+	if dv, ok := n.boundTo.(*dualValue); ok {
+		out = dv.d
+		if has(dv.Value, instr.ctx.Device) {
+			out = dv.Value
+		}
+	}
+	r := newWatchReport(n, m.bwd, true, out, ins, m.watchDump)
+	r.Op = instr.output.op
+	return r
+}
+
 func (m *lispMachine) watchedLogf(format string, attrs ...interface{}) {
 	if !m.logFwd() && !DEBUG {
 		goto backwards
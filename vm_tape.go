@@ -35,9 +35,19 @@ type tapeMachine struct {
 	tabcount     int
 	logFlags     byte
 	closureQueue []func() error
+	watchDump    string // where the NaN and Inf watches dump the inputs of the offending instruction
 
 	runFlags byte //  spare2: trace(copy values and put into nodes)
 	evalMode bool
+	rewrites bool // rewrite the graph before compiling it
+
+	// CPU arena
+	useArena   bool
+	arena      *cpuArena
+	arenaGroup []int           // the registers that share memory have the same group
+	arenaUntil []int           // the pc after which each register is dead, or -1 if the register is not managed by the arena
+	arenaHeld  []*tensor.Dense // the buffers of the arena held by the registers
+	arenaFrees [][]int         // the registers that die at each pc
 }
 
 // NewTapeMachine creates a VM that compiles a graph into a prog.
@@ -58,6 +68,11 @@ func NewTapeMachine(g *ExprGraph, opts ...VMOpt) *tapeMachine {
 	m.doAlloc()
 
 	if m.p == nil || m.locMap == nil {
+		if m.rewrites {
+			if _, err := Rewrite(g); err != nil {
+				panic(err)
+			}
+		}
 		prog, locMap, err := Compile(g)
 		if err != nil {
 			panic(err)
@@ -69,6 +84,7 @@ func NewTapeMachine(g *ExprGraph, opts ...VMOpt) *tapeMachine {
 	m.cpumem = make([]Value, m.p.cpulocs)
 	m.gpumem = make([]Value, m.p.gpulocs)
 	m.init()
+	m.initArena()
 	for _, n := range m.p.g.AllNodes() {
 		setEngine(n.boundTo, m.Engine)
 
@@ -126,6 +142,7 @@ func (m *tapeMachine) Reset() {
 	for i := range m.cpumem {
 		m.cpumem[i] = nil
 	}
+	m.arenaReset()
 }
 
 func (m *tapeMachine) Close() error {
@@ -247,11 +264,27 @@ func (m *tapeMachine) runall(errChan chan error, doneChan chan struct{}) {
 	for ; m.pc < len(m.p.instructions); m.pc++ {
 		instr := m.p.instructions[m.pc]
 		m.logf("PC %d", m.pc)
+		var inputs []Value // the inputs of the instruction, for the NaN and Inf reports
+		if op, ok := instr.(*execOp); ok && (m.watchNaN() || m.watchInf()) {
+			inputs = m.watchInputs(op)
+		}
 		if err := instr.exec(m); err != nil {
+			if op, ok := instr.(*execOp); ok && m.p.g.node(op.id) != nil {
+				n := m.p.g.node(op.id)
+				setErrContext(err, op.op, n, n.children)
+				errChan <- vmContextualError{
+					error: errors.Wrapf(err, "PC %d. Failed to execute instruction %v of node %s", m.pc, instr, n.provenance()),
+					node:  n,
+					instr: m.pc,
+				}
+				return
+			}
 			err = errors.Wrapf(err, "PC %d. Failed to execute instruction %v", m.pc, instr)
 			errChan <- err
 			return
 		}
+		m.arenaStep(m.pc)
+
 		// only proceed to check NaNs and Infs for execOp
 		if _, ok := instr.(*execOp); !ok {
 			continue
@@ -269,9 +302,8 @@ func (m *tapeMachine) runall(errChan chan error, doneChan chan struct{}) {
 				}
 
 				if hasNaN(v, CPU) {
-					n := m.p.g.Node(id).(*Node)
-					err := errors.Errorf("NaN found in value. Node: %v(%x)", n, n.ID())
-					errChan <- err
+					n := m.p.g.node(id)
+					errChan <- &NaNDetectedError{Node: n, Report: m.watchReport(instr.(*execOp), n, v, inputs)}
 					return
 				}
 			}
@@ -289,9 +321,8 @@ func (m *tapeMachine) runall(errChan chan error, doneChan chan struct{}) {
 				}
 
 				if hasInf(v, CPU) {
-					n := m.p.g.Node(id).(*Node)
-					err := errors.Errorf("Inf found in value. Node: %v(%x)", n, n.ID())
-					errChan <- err
+					n := m.p.g.node(id)
+					errChan <- &InfDetectedError{Node: n, Report: m.watchReport(instr.(*execOp), n, v, inputs)}
 					return
 				}
 			}
@@ -300,6 +331,37 @@ func (m *tapeMachine) runall(errChan chan error, doneChan chan struct{}) {
 	doneChan <- struct{}{}
 }
 
+// watchInputs returns the inputs of an instruction before it is executed. The inputs that the instruction may overwrite
+// are copied, so that the report of a NaN or an Inf shows what went into the instruction.
+func (m *tapeMachine) watchInputs(instr *execOp) []Value {
+	inputs := make([]Value, len(instr.readFrom))
+	for i, reg := range instr.readFrom {
+		v := m.getValue(reg)
+		if v != nil && (instr.useUnsafe || reg == instr.writeTo) {
+			if c, err := CloneValue(v); err == nil {
+				v = c
+			}
+		}
+		inputs[i] = v
+	}
+	return inputs
+}
+
+// watchReport reports the instruction that is being executed, whose result v holds a NaN or an Inf.
+func (m *tapeMachine) watchReport(instr *execOp, n *Node, v Value, values []Value) *WatchReport {
+	inputs := make([]watchInput, len(instr.readFrom))
+	for i := range instr.readFrom {
+		inputs[i].v = values[i]
+		if n != nil && len(n.children) == len(instr.readFrom) {
+			inputs[i].node = n.children[i]
+		}
+	}
+	backward := n != nil && (len(n.derivOf) > 0 || n.group == gradClust)
+	r := newWatchReport(n, m.pc, backward, v, inputs, m.watchDump)
+	r.Op = instr.op
+	return r
+}
+
 func (m *tapeMachine) getValue(r register) Value {
 	switch r.device {
 	case CPU:
@@ -521,6 +583,11 @@ func (instr alloc) exec(m *tapeMachine) (err error) {
 		return errors.Wrapf(err, dtypeExtractionFail, instr.t)
 	}
 
+	if m.arena != nil && instr.writeTo.device == CPU && m.arenaUntil[instr.writeTo.id] >= 0 && !instr.s.IsScalar() {
+		m.writeValue(instr.writeTo, m.arenaGet(instr.writeTo, dt, instr.s))
+		return nil
+	}
+
 	reg := m.getValue(instr.writeTo)
 	if reg != nil && reg.Dtype() == dt && reg.Shape().Eq(instr.s) {
 		return nil
@@ -645,7 +712,7 @@ func newExecOp(n *Node) *execOp {
 	compileLogf("op %v uses GPU %v", n.op, useGPU)
 	dt, err := dtypeOf(n.t)
 	if err != nil {
-		panic(err)
+		panic(errors.Wrapf(err, dtypeExtractionFail+". Node: %s", n.t, n.provenance()))
 	}
 	size := calcMemSize(dt, n.Shape())
 
@@ -0,0 +1,286 @@
+package gorgonia
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// This file deals with the CPU arena of the tapeMachine. The registers of the intermediate values borrow their
+// buffers from the arena, and give them back after the last instruction that reads them, so that the registers
+// that are not live at the same time share their memory, and the next runs do not allocate at all.
+//
+// The registers that the users may read after a run (inputs, outputs, named nodes, statements, the gradients of the
+// inputs, watched nodes...) are not managed by the arena. Neither are the registers that share their memory with them.
+
+// ArenaStats returns the statistics of the CPU arena of the machine. They are all zero if the machine has no arena.
+func (m *tapeMachine) ArenaStats() ArenaStats {
+	if m.arena == nil {
+		return ArenaStats{}
+	}
+	return m.arena.stats
+}
+
+// initArena computes the liveness of the CPU registers, and which of them are managed by the arena.
+func (m *tapeMachine) initArena() {
+	if !m.useArena || m.p.cpulocs == 0 {
+		return
+	}
+	n := m.p.cpulocs
+	isCPU := func(r register) bool { return r.device == CPU && r.id >= 0 && r.id < n }
+
+	// the registers that may share memory are put in the same group
+	group := make([]int, n)
+	for i := range group {
+		group[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if group[i] != i {
+			group[i] = find(group[i])
+		}
+		return group[i]
+	}
+	// a scalar register cannot share its memory with a tensor
+	scalar := make([]bool, n)
+	for i := range scalar {
+		scalar[i] = true
+	}
+	for node, r := range m.locMap {
+		if isCPU(r) && !node.IsScalar() {
+			scalar[r.id] = false
+		}
+	}
+	union := func(a, b register) {
+		if isCPU(a) && isCPU(b) && !scalar[a.id] && !scalar[b.id] {
+			group[find(a.id)] = find(b.id)
+		}
+	}
+
+	lastUse := make([]int, n)
+	computed := make([]bool, n) // written by an execOp
+	read := make([]bool, n)     // read by an execOp
+	protected := make([]bool, n)
+	for i := range lastUse {
+		lastUse[i] = -1
+	}
+	use := func(r register, pc int) {
+		if isCPU(r) {
+			lastUse[r.id] = pc
+		}
+	}
+
+	var pending []register // read by extern calls that may be batched until the next flush
+	for pc, instr := range m.p.instructions {
+		for _, r := range instr.reads() {
+			use(r, pc)
+		}
+		use(instr.writes(), pc)
+
+		switch instr := instr.(type) {
+		case *execOp:
+			if isCPU(instr.writeTo) {
+				computed[instr.writeTo.id] = true
+			}
+			for _, r := range instr.readFrom {
+				if isCPU(r) {
+					read[r.id] = true
+				}
+			}
+			// the ops that return views of their inputs are caught when they run
+			if instr.useUnsafe {
+				for _, r := range instr.readFrom {
+					union(r, instr.writeTo)
+				}
+			}
+			if instr.op.CallsExtern() {
+				pending = append(pending, instr.readFrom...)
+			}
+		case letInstr:
+			union(instr.readFrom, instr.writeTo)
+			if isCPU(instr.readFrom) {
+				read[instr.readFrom.id] = true
+			}
+		case deviceTransport:
+			union(instr.from, instr.to)
+		case flushInstr:
+			for _, r := range pending {
+				use(r, pc)
+			}
+			pending = pending[:0]
+		}
+	}
+	for _, r := range pending {
+		use(r, len(m.p.instructions)-1)
+	}
+
+	for i := range protected {
+		// the values that are not computed by the program, and the results of the program
+		protected[i] = !computed[i] || !read[i]
+	}
+	for node, r := range m.locMap {
+		if isCPU(r) && m.keepsValue(node) {
+			protected[r.id] = true
+		}
+	}
+	for _, r := range m.watchRegs {
+		if isCPU(r) {
+			protected[r.id] = true
+		}
+	}
+
+	// a group is managed if none of its registers is protected, and it is released after the last use of any of them
+	until := make([]int, n)
+	for i := range until {
+		until[i] = -1
+	}
+	for i := range group {
+		root := find(i)
+		if protected[i] {
+			protected[root] = true
+		}
+		if lastUse[i] > until[root] {
+			until[root] = lastUse[i]
+		}
+	}
+
+	m.arena = newCPUArena()
+	m.arenaGroup = group
+	m.arenaUntil = make([]int, n)
+	m.arenaHeld = make([]*tensor.Dense, n)
+	m.arenaFrees = make([][]int, len(m.p.instructions))
+	for i := range group {
+		root := find(i)
+		group[i] = root
+		if protected[root] || until[root] < 0 {
+			m.arenaUntil[i] = -1
+			continue
+		}
+		m.arenaUntil[i] = until[root]
+		m.arenaFrees[until[root]] = append(m.arenaFrees[until[root]], i)
+	}
+}
+
+// keepsValue returns true if the value of the node may be read by the users after a run.
+func (m *tapeMachine) keepsValue(n *Node) bool {
+	if n.isInput() || n.isStmt || n.isRoot() || n.name != "" || m.watchNodes.Contains(n) {
+		return true
+	}
+	// the gradients of the inputs are read after the runs
+	for _, of := range n.derivOf {
+		if of.isInput() || of.name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// arenaManaged returns true if the instruction can write its result into a buffer of the arena.
+func (m *tapeMachine) arenaManaged(instr *execOp) bool {
+	if m.arena == nil || instr.writeTo.device != CPU || m.arenaUntil[instr.writeTo.id] < 0 {
+		return false
+	}
+	if _, ok := instr.op.(UsePreallocDoer); !ok || instr.useUnsafe {
+		return false
+	}
+	for _, r := range instr.readFrom {
+		if r == instr.writeTo {
+			return false
+		}
+	}
+	n := m.p.g.node(instr.id)
+	return n != nil && !n.shape.IsScalar()
+}
+
+// arenaDo executes the op of the instruction into the buffer held by its register. If the op cannot write into the
+// buffer, the buffer is given back, the register leaves the arena for good, and the op is executed with Do.
+func (m *tapeMachine) arenaDo(instr *execOp, inputs []Value) (v Value, err error) {
+	n := m.p.g.node(instr.id)
+	var dt tensor.Dtype
+	if dt, err = dtypeOf(n.t); err != nil {
+		return nil, errors.Wrapf(err, dtypeExtractionFail, n.t)
+	}
+	buf := m.arenaGet(instr.writeTo, dt, n.shape)
+	if v, err = instr.op.(UsePreallocDoer).UsePreallocDo(buf, inputs...); err == nil {
+		return v, nil
+	}
+	m.arenaLeave(instr.writeTo.id)
+	if v, err = instr.op.Do(inputs...); err != nil {
+		return nil, errors.Wrap(err, opDoFail)
+	}
+	return v, nil
+}
+
+// arenaGet returns the buffer held by a managed register, borrowing one from the arena if it holds none.
+func (m *tapeMachine) arenaGet(r register, dt tensor.Dtype, s tensor.Shape) *tensor.Dense {
+	if buf := m.arenaHeld[r.id]; buf != nil {
+		if buf.Dtype() == dt && SameShape(buf.Shape(), s) {
+			return buf
+		}
+		m.arena.put(buf)
+	}
+	buf := m.arena.get(dt, s)
+	setEngine(buf, m.Engine)
+	m.arenaHeld[r.id] = buf
+	return buf
+}
+
+// arenaLeave takes a register out of the arena.
+func (m *tapeMachine) arenaLeave(id int) {
+	if buf := m.arenaHeld[id]; buf != nil {
+		m.arena.put(buf)
+		m.arenaHeld[id] = nil
+	}
+	m.arenaUntil[id] = -1
+	m.cpumem[id] = nil
+}
+
+// arenaStep is called after the instruction at pc is executed. It gives back the buffers of the registers that are
+// not live anymore.
+func (m *tapeMachine) arenaStep(pc int) {
+	if m.arena == nil {
+		return
+	}
+
+	// an op may return a view of an input, outside of the group of the input: the input then keeps its buffer for good
+	if instr, ok := m.p.instructions[pc].(*execOp); ok && instr.writeTo.device == CPU {
+		if v := m.cpumem[instr.writeTo.id]; v != nil {
+			for _, r := range instr.readFrom {
+				if r.device != CPU || m.arenaGroup[r.id] == m.arenaGroup[instr.writeTo.id] || m.arenaHeld[r.id] == nil {
+					continue
+				}
+				if overlaps(v, m.arenaHeld[r.id]) {
+					m.arena.drop(m.arenaHeld[r.id])
+					m.arenaHeld[r.id] = nil
+					m.arenaUntil[r.id] = -1
+				}
+			}
+		}
+	}
+
+	for _, id := range m.arenaFrees[pc] {
+		if buf := m.arenaHeld[id]; buf != nil && m.arenaUntil[id] == pc {
+			m.arena.put(buf)
+			m.arenaHeld[id] = nil
+		}
+	}
+}
+
+// arenaReset gives back all the buffers held by the registers.
+func (m *tapeMachine) arenaReset() {
+	if m.arena == nil {
+		return
+	}
+	for i, buf := range m.arenaHeld {
+		if buf != nil {
+			m.arena.put(buf)
+			m.arenaHeld[i] = nil
+		}
+	}
+}
+
+// overlaps returns true if the memory of a value lies within the memory of a buffer
+func overlaps(v Value, buf *tensor.Dense) bool {
+	start, end := buf.Uintptr(), buf.Uintptr()+buf.MemSize()
+	p := v.Uintptr()
+	return p >= start && p < end
+}
@@ -0,0 +1,129 @@
+package gorgonia
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/tensor"
+)
+
+func TestCPUArena(t *testing.T) {
+	assert := assert.New(t)
+	a := newCPUArena()
+
+	x := a.get(tensor.Float64, tensor.Shape{2, 3})
+	assert.Equal(tensor.Shape{2, 3}, x.Shape())
+	assert.Equal(6, len(x.Data().([]float64)))
+	a.put(x)
+
+	// a smaller tensor reuses the buffer, within the slack
+	y := a.get(tensor.Float64, tensor.Shape{4})
+	assert.Equal(4, len(y.Data().([]float64)))
+	assert.Equal(x.Uintptr(), y.Uintptr())
+	a.put(y)
+
+	// but not a much smaller one, nor one of another Dtype
+	z := a.get(tensor.Float64, tensor.Shape{2})
+	w := a.get(tensor.Float32, tensor.Shape{6})
+	assert.NotEqual(x.Uintptr(), z.Uintptr())
+	assert.NotEqual(x.Uintptr(), w.Uintptr())
+
+	// the tensors that the arena did not hand out are ignored
+	a.put(tensor.New(tensor.WithShape(6), tensor.Of(tensor.Float64)))
+
+	s := a.stats
+	assert.Equal(4, s.Gets)
+	assert.Equal(3, s.Allocs)
+	assert.Equal(1, s.Reuses())
+	assert.Equal(2, s.Puts)
+	assert.Equal(int64(2*8+6*4), s.InUse)
+	assert.Equal(int64(6*8), s.Free)
+	assert.Equal(int64(6*8+2*8+6*4), s.Allocated)
+	assert.Equal(int64(6*8), s.PeakInUse)
+}
+
+// arenaMLP is a small network with a backward pass, so that many intermediate values die before the run ends.
+func arenaMLP(t testing.TB) (g *ExprGraph, ws Nodes, cost *Node) {
+	g = NewGraph()
+	x := NewMatrix(g, Float64, WithName("x"), WithShape(8, 16), WithInit(RangedFromWithStep(-1.0, 0.01)))
+	h := x
+	for i, size := range []int{32, 32, 32, 4} {
+		w := NewMatrix(g, Float64, WithName(fmt.Sprintf("w%d", i)), WithShape(h.Shape()[1], size), WithInit(RangedFromWithStep(-0.5, 0.001)))
+		ws = append(ws, w)
+		h = Must(Tanh(Must(Mul(h, w))))
+	}
+	cost = Must(Mean(Must(Square(h))))
+	if _, err := Grad(cost, ws...); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestTapeMachine_arena(t *testing.T) {
+	assert := assert.New(t)
+
+	run := func(opts ...VMOpt) (cost float64, grads []tensor.Tensor, stats []ArenaStats) {
+		g, ws, c := arenaMLP(t)
+		m := NewTapeMachine(g, append(opts, BindDualValues(ws...))...)
+		defer m.Close()
+		for i := 0; i < 3; i++ {
+			require.NoError(t, m.RunAll())
+			stats = append(stats, m.ArenaStats())
+			m.Reset()
+		}
+		for _, w := range ws {
+			grad, err := w.Grad()
+			require.NoError(t, err)
+			grads = append(grads, grad.(tensor.Tensor))
+		}
+		return c.Value().Data().(float64), grads, stats
+	}
+
+	cost, grads, stats := run(WithArena())
+	expectedCost, expectedGrads, noStats := run()
+	assert.Equal(expectedCost, cost)
+	for i := range grads {
+		assert.Equal(expectedGrads[i].Data(), grads[i].Data())
+	}
+	assert.Equal(ArenaStats{}, noStats[2])
+
+	// the registers share buffers within a run, and the next runs do not allocate at all
+	first := stats[0]
+	assert.True(first.Gets > 0)
+	assert.True(first.Reuses() > 0, "%v", first)
+	for _, s := range stats[1:] {
+		assert.Equal(first.Allocs, s.Allocs, "%v", s)
+		assert.Equal(first.Allocated, s.Allocated, "%v", s)
+	}
+	last := stats[len(stats)-1]
+	assert.Equal(3*first.Gets, last.Gets)
+	assert.Equal(int64(0), last.InUse)
+}
+
+func BenchmarkTapeMachine_arena(b *testing.B) {
+	for _, bench := range []struct {
+		name string
+		opts []VMOpt
+	}{
+		{"arena", []VMOpt{WithArena()}},
+		{"no arena", nil},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			g, ws, _ := arenaMLP(b)
+			m := NewTapeMachine(g, append(bench.opts, BindDualValues(ws...))...)
+			defer m.Close()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := m.RunAll(); err != nil {
+					b.Fatal(err)
+				}
+				m.Reset()
+			}
+			b.StopTimer()
+			b.Logf("%v", m.ArenaStats())
+		})
+	}
+}
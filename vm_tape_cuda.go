@@ -106,6 +106,10 @@ func (instr *execOp) exec(m *tapeMachine) (err error) {
 					return errors.Wrap(err, opDoFail)
 				}
 			}
+		case m.arenaManaged(instr):
+			if v, err = m.arenaDo(instr, inputs); err != nil {
+				return err
+			}
 		case instr.useUnsafe:
 			if ud, ok := instr.op.(UnsafeDoer); ok {
 				if v, err = ud.UnsafeDo(inputs...); err != nil {
@@ -165,6 +169,16 @@ func (instr *execOp) exec(m *tapeMachine) (err error) {
 				add := newEBOByType(addOpType, TypeOf(dv.d), TypeOf(v))
 				switch dev {
 				case CPU:
+					// sparse gradients are handed to the solvers as they are, so that they only update the rows that were looked up
+					if sr, ok := v.(*SparseRows); ok {
+						d, err := addSparseGrad(dv.d, sr)
+						if err != nil {
+							return err
+						}
+						dv.SetDeriv(d)
+						src.bind(dv)
+						continue
+					}
 					if d, err := add.UnsafeDo(dv.d, v); err == nil {
 						dv.SetDeriv(d)
 						src.bind(dv)
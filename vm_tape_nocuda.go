@@ -54,6 +54,10 @@ func (instr *execOp) exec(m *tapeMachine) (err error) {
 				return errors.Wrap(err, opDoFail)
 			}
 		}
+	case m.arenaManaged(instr):
+		if v, err = m.arenaDo(instr, inputs); err != nil {
+			return err
+		}
 	case usePrealloc:
 		if pd, ok := instr.op.(UsePreallocDoer); ok {
 			p := m.cpumem[instr.writeTo.id]
@@ -132,6 +136,17 @@ func (instr *execOp) exec(m *tapeMachine) (err error) {
 			default:
 				dv := dvUnit(src.boundTo)
 
+				// sparse gradients are handed to the solvers as they are, so that they only update the rows that were looked up
+				if sr, ok := v.(*SparseRows); ok {
+					d, err := addSparseGrad(dv.d, sr)
+					if err != nil {
+						return err
+					}
+					dv.SetDeriv(d)
+					src.bind(dv)
+					continue
+				}
+
 				add := newEBOByType(addOpType, TypeOf(dv.d), TypeOf(v))
 
 				if d, err := add.UnsafeDo(dv.d, v); err == nil {
@@ -7,7 +7,22 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// tapeMachineModes are the option sets that the tapeMachine tests run under, so that the arena is held to the same behaviour as the default allocation.
+var tapeMachineModes = []struct {
+	name string
+	opts []VMOpt
+}{
+	{"default", nil},
+	{"arena", []VMOpt{WithArena()}},
+}
+
 func Test_tapeMachine_Reset(t *testing.T) {
+	for _, mode := range tapeMachineModes {
+		t.Run(mode.name, func(t *testing.T) { testTapeMachineReset(t, mode.opts...) })
+	}
+}
+
+func testTapeMachineReset(t *testing.T, opts ...VMOpt) {
 	g := NewGraph()
 
 	var x, y, z *Node
@@ -21,8 +36,8 @@ func Test_tapeMachine_Reset(t *testing.T) {
 	}
 
 	// create a VM to run the program on
-	m1 := NewTapeMachine(g)
-	m2 := NewTapeMachine(g)
+	m1 := NewTapeMachine(g, opts...)
+	m2 := NewTapeMachine(g, opts...)
 	defer m1.Close()
 	defer m2.Close()
 
@@ -54,6 +69,12 @@ func Test_tapeMachine_Reset(t *testing.T) {
 }
 
 func Test_tapeMachineEvalMode(t *testing.T) {
+	for _, mode := range tapeMachineModes {
+		t.Run(mode.name, func(t *testing.T) { testTapeMachineEvalMode(t, mode.opts...) })
+	}
+}
+
+func testTapeMachineEvalMode(t *testing.T, opts ...VMOpt) {
 	c := require.New(t)
 
 	g := NewGraph()
@@ -77,12 +98,12 @@ func Test_tapeMachineEvalMode(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	trainVM := NewTapeMachine(g, BindDualValues(x, scale, bias), TraceExec())
+	trainVM := NewTapeMachine(g, append([]VMOpt{BindDualValues(x, scale, bias), TraceExec()}, opts...)...)
 
 	err = trainVM.RunAll()
 	c.NoError(err)
 
-	evalVM := NewTapeMachine(g, EvalMode())
+	evalVM := NewTapeMachine(g, append([]VMOpt{EvalMode()}, opts...)...)
 	err = evalVM.RunAll()
 	c.NoError(err)
 }
@@ -0,0 +1,231 @@
+package gorgonia
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// this file deals with the reports of the NaN and Inf watches of the VMs.
+
+// WithWatchDump makes the NaN and Inf watches of a VM write the inputs of the offending instruction to the directory,
+// in the numpy format, for offline inspection. The directory is created if it does not exist.
+// The files are named after the instruction, e.g. fwd_12_input0.npy.
+func WithWatchDump(dir string) VMOpt {
+	f := func(m VM) {
+		switch v := m.(type) {
+		case *lispMachine:
+			v.watchDump = dir
+		case *tapeMachine:
+			v.watchDump = dir
+		default:
+			panic(nyi("WithWatchDump", v))
+		}
+	}
+	return f
+}
+
+// ValueStats sums up a value. NaNs and Infs are counted, and left out of the other statistics.
+type ValueStats struct {
+	Size          int
+	Min, Max      float64
+	Mean          float64
+	NaN, Inf      int
+	Dtype         tensor.Dtype
+	NotFloatValue bool // the value is not made of floats, so only its size is known
+}
+
+func (s ValueStats) String() string {
+	if s.NotFloatValue {
+		return fmt.Sprintf("%v, size %d", s.Dtype, s.Size)
+	}
+	return fmt.Sprintf("%v, size %d, min %v, max %v, mean %v, NaN %d, Inf %d", s.Dtype, s.Size, s.Min, s.Max, s.Mean, s.NaN, s.Inf)
+}
+
+// InputReport is an input of the instruction that is reported by a watch.
+type InputReport struct {
+	Node  *Node // nil if it is not known
+	Grad  bool  // the input is the gradient of the node, rather than its value
+	Stats ValueStats
+	File  string // where the input was dumped, if it was
+}
+
+// WatchReport describes the first instruction whose result holds a NaN or an Inf, as found by WithNaNWatch or WithInfWatch.
+//
+// The watches check the result of every instruction, so the reported instruction is the first one to produce a NaN or an Inf.
+// If one of its inputs already holds one, the input is an input of the graph, and the report says so in its statistics.
+type WatchReport struct {
+	Node *Node // the node whose value, or gradient if Backward, holds the NaN or the Inf
+	Op   Op
+
+	// Instruction is the program counter of the tape machine. For the lisp machine, it is the position of the node in the
+	// sorted graph, or in the queue of differentiations if Backward.
+	Instruction int
+	Backward    bool
+
+	Inputs []InputReport
+	Output ValueStats
+
+	// DumpErr is the error that happened while dumping the inputs, if any.
+	DumpErr error
+}
+
+func (r *WatchReport) pass() string {
+	if r.Backward {
+		return "backward"
+	}
+	return "forward"
+}
+
+// String formats the report, with a line per input.
+func (r *WatchReport) String() string {
+	var buf bytes.Buffer
+	r.format(&buf)
+	return buf.String()
+}
+
+func (r *WatchReport) format(w io.Writer) {
+	fmt.Fprintf(w, "%s instruction %d: %v", r.pass(), r.Instruction, r.Op)
+	if r.Node != nil {
+		fmt.Fprintf(w, " in node %s", r.Node.provenance())
+	}
+	fmt.Fprintf(w, "\n\toutput: %v\n", r.Output)
+	for i, in := range r.Inputs {
+		name := "?"
+		if in.Node != nil {
+			name = in.Node.Name()
+		}
+		if in.Grad {
+			name = "∂" + name
+		}
+		fmt.Fprintf(w, "\tinput %d %s: %v", i, name, in.Stats)
+		if in.File != "" {
+			fmt.Fprintf(w, " (dumped to %s)", in.File)
+		}
+		fmt.Fprintln(w)
+	}
+	if r.DumpErr != nil {
+		fmt.Fprintf(w, "\tunable to dump the inputs: %v\n", r.DumpErr)
+	}
+}
+
+// watchInput is an input of a reported instruction
+type watchInput struct {
+	node *Node
+	grad bool
+	v    Value
+}
+
+// newWatchReport sums up the inputs of an instruction, and dumps them to dir if it is set
+func newWatchReport(n *Node, instr int, backward bool, output Value, inputs []watchInput, dir string) *WatchReport {
+	r := &WatchReport{
+		Node:        n,
+		Instruction: instr,
+		Backward:    backward,
+		Output:      valueStats(output),
+	}
+	if n != nil {
+		r.Op = n.op
+	}
+	for i, in := range inputs {
+		ir := InputReport{Node: in.node, Grad: in.grad, Stats: valueStats(in.v)}
+		if dir != "" && r.DumpErr == nil {
+			ir.File = filepath.Join(dir, fmt.Sprintf("%s_%d_input%d.npy", r.pass()[:3], instr, i))
+			if r.DumpErr = dumpValue(ir.File, in.v); r.DumpErr != nil {
+				ir.File = ""
+			}
+		}
+		r.Inputs = append(r.Inputs, ir)
+	}
+	return r
+}
+
+func valueStats(v Value) (retVal ValueStats) {
+	if dv, ok := v.(*dualValue); ok {
+		v = dv.Value
+	}
+	if v == nil {
+		return ValueStats{NotFloatValue: true}
+	}
+	retVal.Dtype = v.Dtype()
+	retVal.Size = logicalSize(v.Shape())
+
+	var xs []float64
+	switch data := v.Data().(type) {
+	case []float64:
+		xs = data
+	case float64:
+		xs = []float64{data}
+	case []float32:
+		xs = make([]float64, len(data))
+		for i, x := range data {
+			xs[i] = float64(x)
+		}
+	case float32:
+		xs = []float64{float64(data)}
+	default:
+		retVal.NotFloatValue = true
+		return
+	}
+
+	var count int
+	var sum float64
+	for _, x := range xs {
+		switch {
+		case math.IsNaN(x):
+			retVal.NaN++
+			continue
+		case math.IsInf(x, 0):
+			retVal.Inf++
+			continue
+		}
+		if count == 0 || x < retVal.Min {
+			retVal.Min = x
+		}
+		if count == 0 || x > retVal.Max {
+			retVal.Max = x
+		}
+		count++
+		sum += x
+	}
+	if count > 0 {
+		retVal.Mean = sum / float64(count)
+	}
+	return
+}
+
+// dumpValue writes a value to a file in the numpy format
+func dumpValue(file string, v Value) (err error) {
+	if dv, ok := v.(*dualValue); ok {
+		v = dv.Value
+	}
+	var t *tensor.Dense
+	switch vt := v.(type) {
+	case *tensor.Dense:
+		t = vt
+	case Scalar:
+		t = tensor.New(tensor.FromScalar(vt.Data()))
+	default:
+		return errors.Errorf("Unable to dump a %T", v)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return errors.Wrap(err, "Unable to create the dump directory")
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to create %v", file)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	return t.WriteNpy(f)
+}
@@ -0,0 +1,134 @@
+package gorgonia
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorgonia.org/tensor"
+)
+
+func TestWatchReport_forward(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dump")
+	g := NewGraph()
+	x := NewVector(g, tensor.Float64, WithShape(3), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{1, -1, 3}))))
+	logx := Must(Log(x))
+	Must(Sum(logx))
+
+	for _, m := range []VM{
+		NewTapeMachine(g, WithNaNWatch(), WithWatchDump(dir)),
+		NewLispMachine(g, ExecuteFwdOnly(), WithNaNWatch(), WithWatchDump(dir)),
+	} {
+		err := m.RunAll()
+		m.Close()
+
+		var nde *NaNDetectedError
+		require.True(t, errors.As(err, &nde), "%T: %v", m, err)
+		r := nde.Report
+		require.NotNil(t, r, "%T", m)
+		assert.Equal(t, logx, r.Node)
+		assert.Equal(t, "ln", r.Op.String())
+		assert.False(t, r.Backward)
+		assert.Equal(t, 1, r.Output.NaN)
+		assert.NoError(t, r.DumpErr)
+
+		require.Len(t, r.Inputs, 1, "%T", m)
+		in := r.Inputs[0]
+		assert.Equal(t, x, in.Node)
+		assert.Equal(t, ValueStats{Size: 3, Min: -1, Max: 3, Mean: 1, Dtype: tensor.Float64}, in.Stats)
+
+		// the dump holds the input
+		f, ferr := os.Open(in.File)
+		require.NoError(t, ferr)
+		dumped := new(tensor.Dense)
+		require.NoError(t, dumped.ReadNpy(f))
+		f.Close()
+		assert.Equal(t, []float64{1, -1, 3}, dumped.Data())
+
+		assert.Contains(t, r.String(), "forward instruction")
+		assert.Contains(t, r.String(), "input 0 x: float64, size 3, min -1, max 3, mean 1, NaN 0, Inf 0 (dumped to ")
+		assert.Contains(t, err.Error(), "NaN found in value")
+	}
+}
+
+func TestWatchReport_overwrittenInput(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dump")
+	g := NewGraph()
+	x := NewVector(g, tensor.Float64, WithShape(3), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{1, -1, 3}))))
+	x2 := Must(Mul(x, twof64))
+	logx2 := Must(Log(x2))
+	Must(Sum(logx2))
+
+	m := NewTapeMachine(g, WithNaNWatch(), WithWatchDump(dir))
+	defer m.Close()
+
+	// the logarithm is computed in place, in the register of x*2
+	var inPlace bool
+	for _, instr := range m.p.instructions {
+		if op, ok := instr.(*execOp); ok && op.id == logx2.ID() {
+			inPlace = op.useUnsafe || op.readFrom[0] == op.writeTo
+		}
+	}
+	require.True(t, inPlace, "the test needs the logarithm to overwrite its input")
+
+	var nde *NaNDetectedError
+	err := m.RunAll()
+	require.True(t, errors.As(err, &nde), "%v", err)
+	r := nde.Report
+	assert.Equal(t, logx2, r.Node)
+	assert.Equal(t, 1, r.Output.NaN)
+	require.Len(t, r.Inputs, 1)
+	assert.Equal(t, x2, r.Inputs[0].Node)
+	assert.Equal(t, ValueStats{Size: 3, Min: -2, Max: 6, Mean: 2, Dtype: tensor.Float64}, r.Inputs[0].Stats)
+
+	f, err := os.Open(r.Inputs[0].File)
+	require.NoError(t, err)
+	defer f.Close()
+	dumped := new(tensor.Dense)
+	require.NoError(t, dumped.ReadNpy(f))
+	assert.Equal(t, []float64{2, -2, 6}, dumped.Data())
+}
+
+func TestWatchReport_backward(t *testing.T) {
+	// the square root is 0 at 0, but its gradient is infinite
+	build := func() (*ExprGraph, *Node, *Node) {
+		g := NewGraph()
+		x := NewVector(g, tensor.Float64, WithShape(2), WithName("x"), WithValue(tensor.New(tensor.WithBacking([]float64{0, 4}))))
+		sqrtx := Must(Sqrt(x))
+		cost := Must(Sum(sqrtx))
+		return g, x, cost
+	}
+
+	g, x, cost := build()
+	_, err := Grad(cost, x)
+	require.NoError(t, err)
+	m := NewTapeMachine(g, WithInfWatch())
+	err = m.RunAll()
+	m.Close()
+	var ide *InfDetectedError
+	require.True(t, errors.As(err, &ide), "%v", err)
+	require.NotNil(t, ide.Report)
+	assert.True(t, ide.Report.Backward, ide.Report.String())
+	assert.Equal(t, 1, ide.Report.Output.Inf)
+
+	g, x, _ = build()
+	lm := NewLispMachine(g, WithInfWatch())
+	err = lm.RunAll()
+	lm.Close()
+	ide = nil
+	require.True(t, errors.As(err, &ide), "%v", err)
+	r := ide.Report
+	require.NotNil(t, r)
+	assert.True(t, r.Backward)
+	assert.Equal(t, x, r.Node)
+	assert.Equal(t, "sqrt", r.Op.String())
+	assert.Equal(t, 1, r.Output.Inf)
+	require.Len(t, r.Inputs, 2)
+	assert.Equal(t, x, r.Inputs[0].Node)
+	assert.True(t, r.Inputs[1].Grad)
+	assert.Contains(t, r.String(), "backward instruction")
+	assert.Contains(t, r.String(), "input 1 ∂sqrt")
+}
@@ -0,0 +1,226 @@
+package dataviz
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorgonia.org/gorgonia"
+	xvm "gorgonia.org/gorgonia/x/vm"
+)
+
+// NodeStats is the time a node spent in each of its states during a run.
+type NodeStats struct {
+	ID   int64
+	Name string
+	Op   string
+
+	Start, End time.Time
+	Waiting    time.Duration // waiting for the inputs on the input channels
+	Computing  time.Duration // running the op
+	Emitting   time.Duration // waiting for the consumers to take the output
+}
+
+// GoroutineStats is the time a goroutine spent in each state: the goroutine of a node, or a worker of the pool of the machine.
+type GoroutineStats struct {
+	Worker int   // the worker of the pool, or 0 for the goroutine of a node
+	Node   int64 // the node, for the goroutine of a node
+
+	Waiting, Computing, Emitting time.Duration
+
+	// Utilisation is the fraction of the run the goroutine spent computing
+	Utilisation float64
+}
+
+// OpStats is the time spent running the ops of one kind.
+type OpStats struct {
+	Count int
+	Total time.Duration
+	Max   time.Duration
+}
+
+// Analysis sums up the traces of a run, to show where concurrency is lost.
+type Analysis struct {
+	// Wall is the duration of the run, from the first state to start to the last one to end
+	Wall time.Duration
+
+	// the sums over all the nodes
+	Waiting, Computing, Emitting time.Duration
+
+	Nodes      []NodeStats      // sorted by ID
+	Goroutines []GoroutineStats // the workers first, then the goroutines of the nodes sorted by ID
+	Ops        map[string]OpStats
+
+	// CriticalPath is the chain of nodes, from an input to an output, with the longest compute time.
+	// No schedule can run the graph faster than CriticalPathDuration, however many goroutines run it.
+	CriticalPath         []int64
+	CriticalPathDuration time.Duration
+}
+
+// Analyze computes the statistics of the traces of a run of a graph.
+func Analyze(traces []xvm.Trace, g *gorgonia.ExprGraph) *Analysis {
+	ss := spans(traces)
+	a := &Analysis{Ops: make(map[string]OpStats)}
+	if len(ss) == 0 {
+		return a
+	}
+
+	start, end := ss[0].start, ss[0].end
+	nodes := make(map[int64]*NodeStats)
+	type goroutine struct {
+		worker int
+		node   int64
+	}
+	goroutines := make(map[goroutine]*GoroutineStats)
+	for _, s := range ss {
+		if s.end.After(end) {
+			end = s.end
+		}
+
+		ns, ok := nodes[s.id]
+		if !ok {
+			n := graphNode(g, s.id)
+			ns = &NodeStats{ID: s.id, Name: nodeName(n, s.id), Op: opName(n), Start: s.start, End: s.end}
+			nodes[s.id] = ns
+		}
+		if s.end.After(ns.End) {
+			ns.End = s.end
+		}
+
+		key := goroutine{worker: s.worker}
+		if s.worker == 0 {
+			key.node = s.id
+		}
+		gs, ok := goroutines[key]
+		if !ok {
+			gs = &GoroutineStats{Worker: key.worker, Node: key.node}
+			goroutines[key] = gs
+		}
+
+		d := s.duration()
+		switch s.state {
+		case stateReceive:
+			ns.Waiting += d
+			gs.Waiting += d
+			a.Waiting += d
+		case stateCompute:
+			ns.Computing += d
+			gs.Computing += d
+			a.Computing += d
+		case stateEmit:
+			ns.Emitting += d
+			gs.Emitting += d
+			a.Emitting += d
+		}
+	}
+	a.Wall = end.Sub(start)
+
+	for _, ns := range nodes {
+		a.Nodes = append(a.Nodes, *ns)
+		if ns.Computing == 0 {
+			continue
+		}
+		op := a.Ops[ns.Op]
+		op.Count++
+		op.Total += ns.Computing
+		if ns.Computing > op.Max {
+			op.Max = ns.Computing
+		}
+		a.Ops[ns.Op] = op
+	}
+	sort.Slice(a.Nodes, func(i, j int) bool { return a.Nodes[i].ID < a.Nodes[j].ID })
+
+	for _, gs := range goroutines {
+		if a.Wall > 0 {
+			gs.Utilisation = float64(gs.Computing) / float64(a.Wall)
+		}
+		a.Goroutines = append(a.Goroutines, *gs)
+	}
+	sort.Slice(a.Goroutines, func(i, j int) bool {
+		gi, gj := a.Goroutines[i], a.Goroutines[j]
+		switch {
+		case gi.Worker > 0 && gj.Worker > 0:
+			return gi.Worker < gj.Worker
+		case gi.Worker > 0 || gj.Worker > 0:
+			return gi.Worker > 0
+		}
+		return gi.Node < gj.Node
+	})
+
+	if g != nil {
+		a.CriticalPath, a.CriticalPathDuration = criticalPath(g, nodes)
+	}
+	return a
+}
+
+// criticalPath finds the chain of nodes with the longest compute time. A node can only start once all its children are computed,
+// so the longest chain ending at a node is the node followed by the longest chain of its children.
+func criticalPath(g *gorgonia.ExprGraph, nodes map[int64]*NodeStats) ([]int64, time.Duration) {
+	longest := make(map[int64]time.Duration)
+	next := make(map[int64]int64) // the child on the longest chain
+	var visit func(id int64) time.Duration
+	visit = func(id int64) time.Duration {
+		if d, ok := longest[id]; ok {
+			return d
+		}
+		var best time.Duration
+		bestChild := int64(-1)
+		for children := g.From(id); children.Next(); {
+			child := children.Node().ID()
+			if d := visit(child); bestChild < 0 || d > best {
+				best, bestChild = d, child
+			}
+		}
+		if ns, ok := nodes[id]; ok {
+			best += ns.Computing
+		}
+		longest[id] = best
+		next[id] = bestChild
+		return best
+	}
+
+	var path []int64
+	var total time.Duration
+	head := int64(-1)
+	for _, n := range g.AllNodes() {
+		if d := visit(n.ID()); head < 0 || d > total {
+			head, total = n.ID(), d
+		}
+	}
+	for id := head; id >= 0; id = next[id] {
+		path = append(path, id)
+	}
+	// from the inputs to the output
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, total
+}
+
+// Parallelism is the average number of ops running at the same time: the total compute time over the wall time.
+func (a *Analysis) Parallelism() float64 {
+	if a.Wall == 0 {
+		return 0
+	}
+	return float64(a.Computing) / float64(a.Wall)
+}
+
+// String is a short report of the analysis.
+func (a *Analysis) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "wall: %v, computing: %v, waiting for inputs: %v, emitting: %v, parallelism: %.2f\n",
+		a.Wall, a.Computing, a.Waiting, a.Emitting, a.Parallelism())
+	fmt.Fprintf(&sb, "critical path: %v over %d nodes\n", a.CriticalPathDuration, len(a.CriticalPath))
+
+	ops := make([]string, 0, len(a.Ops))
+	for op := range a.Ops {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool { return a.Ops[ops[i]].Total > a.Ops[ops[j]].Total })
+	for _, op := range ops {
+		s := a.Ops[op]
+		fmt.Fprintf(&sb, "\t%s: %d × %v (max %v)\n", op, s.Count, s.Total/time.Duration(s.Count), s.Max)
+	}
+	return sb.String()
+}
@@ -0,0 +1,131 @@
+package dataviz
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gorgonia.org/gorgonia"
+	xvm "gorgonia.org/gorgonia/x/vm"
+	"gorgonia.org/tensor"
+)
+
+// traceGraph builds c = a + b, d = c × a and e = tanh(b)
+func traceGraph() (g *gorgonia.ExprGraph, a, b, c, d, e *gorgonia.Node) {
+	g = gorgonia.NewGraph()
+	a = gorgonia.NewScalar(g, tensor.Float64, gorgonia.WithName("a"), gorgonia.WithValue(1.0))
+	b = gorgonia.NewScalar(g, tensor.Float64, gorgonia.WithName("b"), gorgonia.WithValue(2.0))
+	c = gorgonia.Must(gorgonia.Add(a, b))
+	d = gorgonia.Must(gorgonia.Mul(c, a))
+	e = gorgonia.Must(gorgonia.Tanh(b))
+	return
+}
+
+// traceSpan returns the traces of the start and the end of a state, from start to end milliseconds after t0
+func traceSpan(id int64, worker int, state string, t0 time.Time, start, end int) []xvm.Trace {
+	fn := "gorgonia.org/gorgonia/x/vm." + state
+	t := xvm.Trace{StateFunction: fn, ID: id, Worker: worker, Start: t0.Add(time.Duration(start) * time.Millisecond)}
+	done := t
+	done.End = t0.Add(time.Duration(end) * time.Millisecond)
+	return []xvm.Trace{t, done}
+}
+
+func syntheticTraces(c, d, e *gorgonia.Node) []xvm.Trace {
+	t0 := time.Now()
+	var traces []xvm.Trace
+	traces = append(traces, traceSpan(c.ID(), 0, stateReceive, t0, 0, 2)...)
+	traces = append(traces, traceSpan(c.ID(), 0, stateCompute, t0, 2, 12)...)
+	traces = append(traces, traceSpan(c.ID(), 0, stateEmit, t0, 12, 13)...)
+	traces = append(traces, traceSpan(d.ID(), 0, stateReceive, t0, 0, 13)...)
+	traces = append(traces, traceSpan(d.ID(), 0, stateCompute, t0, 13, 18)...)
+	traces = append(traces, traceSpan(e.ID(), 0, stateCompute, t0, 0, 30)...)
+	return traces
+}
+
+func TestAnalyze(t *testing.T) {
+	g, _, b, c, d, e := traceGraph()
+	a := Analyze(syntheticTraces(c, d, e), g)
+
+	if a.Wall != 30*time.Millisecond {
+		t.Errorf("expected a wall time of 30ms, got %v", a.Wall)
+	}
+	if a.Computing != 45*time.Millisecond || a.Waiting != 15*time.Millisecond || a.Emitting != time.Millisecond {
+		t.Errorf("unexpected totals: computing %v, waiting %v, emitting %v", a.Computing, a.Waiting, a.Emitting)
+	}
+	if p := a.Parallelism(); p != 1.5 {
+		t.Errorf("expected a parallelism of 1.5, got %v", p)
+	}
+
+	// b → tanh(b) takes longer than a → a+b → (a+b)×a
+	if len(a.CriticalPath) != 2 || a.CriticalPath[0] != b.ID() || a.CriticalPath[1] != e.ID() {
+		t.Errorf("expected the critical path [%d %d], got %v", b.ID(), e.ID(), a.CriticalPath)
+	}
+	if a.CriticalPathDuration != 30*time.Millisecond {
+		t.Errorf("expected a critical path of 30ms, got %v", a.CriticalPathDuration)
+	}
+
+	if len(a.Nodes) != 3 || a.Nodes[0].ID != c.ID() {
+		t.Fatalf("expected the stats of 3 nodes sorted by ID, got %v", a.Nodes)
+	}
+	if ns := a.Nodes[1]; ns.ID != d.ID() || ns.Waiting != 13*time.Millisecond || ns.Computing != 5*time.Millisecond {
+		t.Errorf("unexpected stats of %v: %+v", d, ns)
+	}
+	if len(a.Goroutines) != 3 {
+		t.Fatalf("expected 3 goroutines, got %d", len(a.Goroutines))
+	}
+	if u := a.Goroutines[2].Utilisation; u != 1 {
+		t.Errorf("expected tanh(b) to compute for the whole run, got a utilisation of %v", u)
+	}
+	if op := a.Ops[e.Op().String()]; op.Count != 1 || op.Total != 30*time.Millisecond {
+		t.Errorf("unexpected stats of the op of %v: %+v", e, op)
+	}
+	if s := a.String(); !strings.Contains(s, "critical path: 30ms over 2 nodes") {
+		t.Errorf("unexpected report:\n%s", s)
+	}
+
+	if a := Analyze(nil, g); a.Wall != 0 || len(a.Nodes) != 0 || a.Parallelism() != 0 {
+		t.Errorf("expected an empty analysis without traces, got %+v", a)
+	}
+}
+
+func TestAnalyze_Workers(t *testing.T) {
+	g, _, _, _, _, _ := traceGraph()
+	ctx, traceC := xvm.WithTracing(context.Background())
+	var traces []xvm.Trace
+	done := make(chan struct{})
+	go func() {
+		for t := range traceC {
+			traces = append(traces, t)
+		}
+		close(done)
+	}()
+	m := xvm.NewMachine(g, xvm.WithWorkers(2))
+	err := m.Run(ctx)
+	m.Close()
+	xvm.CloseTracing(ctx)
+	<-done
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := Analyze(traces, g)
+	if len(a.Nodes) != 3 {
+		t.Fatalf("expected the 3 ops to be traced, got %v", a.Nodes)
+	}
+	for _, gs := range a.Goroutines {
+		if gs.Worker < 1 || gs.Worker > 2 {
+			t.Errorf("expected the ops to run on the 2 workers, got %+v", gs)
+		}
+	}
+	// which chain is the longest depends on the timings, but it goes from an input to an output
+	path := a.CriticalPath
+	if len(path) < 2 || g.From(path[0]).Len() != 0 || g.To(path[len(path)-1]).Len() != 0 {
+		t.Fatalf("expected a critical path from an input to an output, got %v", path)
+	}
+	for i := 1; i < len(path); i++ {
+		if !g.HasEdgeFromTo(path[i], path[i-1]) {
+			t.Errorf("%d does not depend on %d in the critical path %v", path[i], path[i-1], path)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package dataviz
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorgonia.org/gorgonia"
+	xvm "gorgonia.org/gorgonia/x/vm"
+)
+
+// span is a completed state of a node
+type span struct {
+	id     int64
+	worker int
+	state  string
+	start  time.Time
+	end    time.Time
+}
+
+func (s span) duration() time.Duration { return s.end.Sub(s.start) }
+
+// spans returns the completed states of the traces, sorted by start. The traces of the start of the states are skipped.
+func spans(traces []xvm.Trace) []span {
+	var zerotime time.Time
+	retVal := make([]span, 0, len(traces)/2)
+	for _, t := range traces {
+		if t.End == zerotime {
+			continue
+		}
+		retVal = append(retVal, span{
+			id:     t.ID,
+			worker: t.Worker,
+			state:  stateName(t.StateFunction),
+			start:  t.Start,
+			end:    t.End,
+		})
+	}
+	sort.SliceStable(retVal, func(i, j int) bool { return retVal[i].start.Before(retVal[j].start) })
+	return retVal
+}
+
+// stateName strips the package of a state function, e.g. gorgonia.org/gorgonia/x/vm.computeFwd is computeFwd
+func stateName(fn string) string {
+	return fn[strings.LastIndex(fn, ".")+1:]
+}
+
+// The states of the nodes of xvm
+const (
+	stateReceive = "receiveInput"
+	stateCompute = "computeFwd"
+	stateEmit    = "emitOutput"
+)
+
+// graphNode returns the node of the graph with the given ID, if there is one
+func graphNode(g *gorgonia.ExprGraph, id int64) *gorgonia.Node {
+	if g == nil {
+		return nil
+	}
+	n, _ := g.Node(id).(*gorgonia.Node)
+	return n
+}
+
+func nodeName(n *gorgonia.Node, id int64) string {
+	if n == nil {
+		return strconv.FormatInt(id, 10)
+	}
+	return n.Name()
+}
+
+func opName(n *gorgonia.Node) string {
+	if n == nil || n.Op() == nil {
+		return "input"
+	}
+	return n.Op().String()
+}
+
+// maxTracedValue is the size of the largest values that are written in the traces
+const maxTracedValue = 16
+
+type chromeEvent struct {
+	Name string                 `json:"name"`
+	Cat  string                 `json:"cat,omitempty"`
+	Ph   string                 `json:"ph"`
+	Ts   float64                `json:"ts"`
+	Dur  float64                `json:"dur,omitempty"`
+	Pid  int                    `json:"pid"`
+	Tid  int64                  `json:"tid"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+type chromeTrace struct {
+	TraceEvents     []chromeEvent `json:"traceEvents"`
+	DisplayTimeUnit string        `json:"displayTimeUnit"`
+}
+
+func microseconds(d time.Duration) float64 { return float64(d) / float64(time.Microsecond) }
+
+// DumpChromeTrace writes the traces in the Chrome trace event format, which chrome://tracing and https://ui.perfetto.dev open.
+//
+// Every state of a node is a slice on the timeline of the goroutine that ran it: the node's own goroutine,
+// or a worker of the pool of the machine. The slices carry the ID, op, shape and dtype of the node, and its value if it is small.
+// The value is read from the graph, so it is the one of the last run.
+func DumpChromeTrace(traces []xvm.Trace, g *gorgonia.ExprGraph, w io.Writer) error {
+	ss := spans(traces)
+	out := chromeTrace{
+		TraceEvents:     make([]chromeEvent, 0, len(ss)+1),
+		DisplayTimeUnit: "ns",
+	}
+	out.TraceEvents = append(out.TraceEvents, chromeEvent{Name: "process_name", Ph: "M", Pid: 1, Args: map[string]interface{}{"name": "xvm"}})
+	if len(ss) == 0 {
+		return json.NewEncoder(w).Encode(out)
+	}
+
+	origin := ss[0].start
+	threads := make(map[int64]string)
+	for _, s := range ss {
+		n := graphNode(g, s.id)
+		tid, thread := s.id, nodeName(n, s.id)
+		if s.worker > 0 {
+			// the node IDs are positive, so the workers do not clash with them
+			tid, thread = -int64(s.worker), fmt.Sprintf("worker %d", s.worker)
+		}
+		threads[tid] = thread
+
+		name := s.state
+		if s.state == stateCompute {
+			name = nodeName(n, s.id)
+		}
+		out.TraceEvents = append(out.TraceEvents, chromeEvent{
+			Name: name,
+			Cat:  s.state,
+			Ph:   "X",
+			Ts:   microseconds(s.start.Sub(origin)),
+			Dur:  microseconds(s.duration()),
+			Pid:  1,
+			Tid:  tid,
+			Args: nodeArgs(n, s.id),
+		})
+	}
+	for tid, name := range threads {
+		out.TraceEvents = append(out.TraceEvents, chromeEvent{Name: "thread_name", Ph: "M", Pid: 1, Tid: tid, Args: map[string]interface{}{"name": name}})
+	}
+	return json.NewEncoder(w).Encode(out)
+}
+
+func nodeArgs(n *gorgonia.Node, id int64) map[string]interface{} {
+	args := map[string]interface{}{"id": id}
+	if n == nil {
+		return args
+	}
+	args["op"] = opName(n)
+	args["shape"] = fmt.Sprintf("%v", n.Shape())
+	if t := n.Type(); t != nil {
+		args["type"] = t.String()
+	}
+	if v := n.Value(); v != nil && v.Shape().TotalSize() <= maxTracedValue {
+		args["value"] = fmt.Sprintf("%v", v)
+	}
+	return args
+}
@@ -0,0 +1,58 @@
+package dataviz
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestDumpChromeTrace(t *testing.T) {
+	g, _, _, c, d, e := traceGraph()
+	traces := syntheticTraces(c, d, e)
+	traces = append(traces, traceSpan(c.ID(), 2, stateCompute, traces[0].Start, 40, 41)...)
+
+	var buf bytes.Buffer
+	if err := DumpChromeTrace(traces, g, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var out chromeTrace
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	var slices int
+	threads := make(map[int64]string)
+	for _, ev := range out.TraceEvents {
+		switch ev.Ph {
+		case "X":
+			slices++
+		case "M":
+			if ev.Name == "thread_name" {
+				threads[ev.Tid] = ev.Args["name"].(string)
+			}
+		}
+	}
+	if slices != 7 {
+		t.Errorf("expected a slice per state, got %d", slices)
+	}
+	if threads[-2] != "worker 2" || threads[e.ID()] != e.Name() {
+		t.Errorf("unexpected threads: %v", threads)
+	}
+
+	// the compute of c, 2ms after the start, is named after the node
+	var found bool
+	for _, ev := range out.TraceEvents {
+		if ev.Ph == "X" && ev.Cat == stateCompute && ev.Tid == c.ID() {
+			found = true
+			if ev.Name != c.Name() || ev.Ts != 2000 || ev.Dur != 10000 {
+				t.Errorf("unexpected slice: %+v", ev)
+			}
+			if ev.Args["op"] != c.Op().String() || ev.Args["shape"] != "()" {
+				t.Errorf("expected the op and the shape of the node, got %v", ev.Args)
+			}
+		}
+	}
+	if !found {
+		t.Error("the compute of c is missing")
+	}
+}
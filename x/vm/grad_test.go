@@ -0,0 +1,130 @@
+package xvm
+
+import (
+	"context"
+	"testing"
+
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// model builds cost = Σ((x·w)²) + Σ(w⊙w) + Σ(tanh(x·w)), so that w and x·w have several consumers.
+func model(t *testing.T) (g *gorgonia.ExprGraph, x, w, cost *gorgonia.Node) {
+	g = gorgonia.NewGraph()
+	x = gorgonia.NewMatrix(g, tensor.Float64, gorgonia.WithShape(3, 2), gorgonia.WithName("x"),
+		gorgonia.WithValue(tensor.New(tensor.WithShape(3, 2), tensor.WithBacking([]float64{1, 2, 3, 4, 5, 6}))))
+	w = gorgonia.NewMatrix(g, tensor.Float64, gorgonia.WithShape(2, 2), gorgonia.WithName("w"),
+		gorgonia.WithValue(tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{0.1, -0.2, 0.3, 0.05}))))
+	xw := gorgonia.Must(gorgonia.Mul(x, w))
+	sq := gorgonia.Must(gorgonia.Sum(gorgonia.Must(gorgonia.Square(xw))))
+	ww := gorgonia.Must(gorgonia.Sum(gorgonia.Must(gorgonia.HadamardProd(w, w))))
+	th := gorgonia.Must(gorgonia.Sum(gorgonia.Must(gorgonia.Tanh(xw))))
+	cost = gorgonia.Must(gorgonia.Add(gorgonia.Must(gorgonia.Add(sq, ww)), th))
+	if _, err := gorgonia.Grad(cost, w); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func assertClose(t *testing.T, name string, want, got []float64) {
+	t.Helper()
+	if len(want) != len(got) {
+		t.Fatalf("%s: expected %v, got %v", name, want, got)
+	}
+	for i := range want {
+		if d := want[i] - got[i]; d > 1e-9 || d < -1e-9 {
+			t.Fatalf("%s: expected %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestMachine_Grad(t *testing.T) {
+	g, _, w, cost := model(t)
+	tm := gorgonia.NewTapeMachine(g, gorgonia.BindDualValues(w))
+	defer tm.Close()
+	if err := tm.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	wantCost := cost.Value().Data().(float64)
+	wantGrad, err := w.Grad()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, _, w, cost = model(t)
+	m := NewMachine(g)
+	defer m.Close()
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := cost.Value().Data().(float64); got-wantCost > 1e-9 || wantCost-got > 1e-9 {
+		t.Fatalf("expected a cost of %v, got %v", wantCost, got)
+	}
+	grad, err := w.Grad()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "grad", wantGrad.Data().([]float64), grad.Data().([]float64))
+}
+
+func TestMachine_Solver(t *testing.T) {
+	const steps = 5
+	g, x, w, _ := model(t)
+	tm := gorgonia.NewTapeMachine(g, gorgonia.BindDualValues(w))
+	defer tm.Close()
+	solver := gorgonia.NewVanillaSolver(gorgonia.WithLearnRate(0.01))
+	for i := 0; i < steps; i++ {
+		if err := tm.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		if err := solver.Step(gorgonia.NodesToValueGrads(gorgonia.Nodes{w})); err != nil {
+			t.Fatal(err)
+		}
+		tm.Reset()
+	}
+	want := w.Value().Data().([]float64)
+
+	g, x, w, _ = model(t)
+	m := NewMachine(g)
+	defer m.Close()
+	solver = gorgonia.NewVanillaSolver(gorgonia.WithLearnRate(0.01))
+	for i := 0; i < steps; i++ {
+		if err := m.Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if err := solver.Step(gorgonia.NodesToValueGrads(gorgonia.Nodes{w})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertClose(t, "weights", want, w.Value().Data().([]float64))
+
+	// a new value of an input is used by the next run
+	if err := gorgonia.Let(x, tensor.New(tensor.WithShape(3, 2), tensor.WithBacking(make([]float64, 6)))); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	grad, err := w.Grad()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// with x = 0, the gradient of the cost is that of Σ(w⊙w)
+	wv := w.Value().Data().([]float64)
+	assertClose(t, "grad", []float64{2 * wv[0], 2 * wv[1], 2 * wv[2], 2 * wv[3]}, grad.Data().([]float64))
+}
+
+func TestMachine_Variadic(t *testing.T) {
+	g := gorgonia.NewGraph()
+	a := gorgonia.NewVector(g, tensor.Float64, gorgonia.WithShape(2), gorgonia.WithName("a"),
+		gorgonia.WithValue(tensor.New(tensor.WithBacking([]float64{1, 2}))))
+	b := gorgonia.NewVector(g, tensor.Float64, gorgonia.WithShape(3), gorgonia.WithName("b"),
+		gorgonia.WithValue(tensor.New(tensor.WithBacking([]float64{3, 4, 5}))))
+	c := gorgonia.Must(gorgonia.Concat(0, a, b))
+	m := NewMachine(g)
+	defer m.Close()
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "concat", []float64{1, 2, 3, 4, 5}, c.Value().Data().([]float64))
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/pkg/errors"
 	"gorgonia.org/gorgonia"
 )
 
@@ -13,10 +15,17 @@ import (
 type Machine struct {
 	nodes  []*node
 	pubsub *pubsub
+
+	workers     int
+	timeout     time.Duration
+	nodeTimeout time.Duration
+	running     sync.WaitGroup // the ops running on their own goroutines, which a run may abandon
+	closed      bool
 }
 
-// NewMachine creates an exeuction machine from an exprgraph
-func NewMachine(g *gorgonia.ExprGraph) *Machine {
+// NewMachine creates an exeuction machine from an exprgraph.
+// The machine can be run any number of times, until it is closed.
+func NewMachine(g *gorgonia.ExprGraph, opts ...MachineOpt) *Machine {
 	if g == nil {
 		return nil
 	}
@@ -40,10 +49,36 @@ func NewMachine(g *gorgonia.ExprGraph) *Machine {
 	m := &Machine{
 		nodes: nodes,
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	for _, n := range nodes {
+		n.timeout = m.nodeTimeout
+		n.running = &m.running
+	}
 	m.pubsub = createNetwork(nodes, g)
+	linkConsumers(nodes, g)
 	return m
 }
 
+// linkConsumers sets the consumers of every node, for the pool of workers to pass the outputs along
+func linkConsumers(ns []*node, g *gorgonia.ExprGraph) {
+	ids := make(map[int64]*node, len(ns))
+	for _, n := range ns {
+		ids[n.id] = n
+	}
+	for _, n := range ns {
+		if n.op == nil {
+			continue
+		}
+		from := g.From(n.id)
+		for i := 0; from.Next(); i++ {
+			child := ids[from.Node().ID()]
+			child.consumers = append(child.consumers, edge{to: n, pos: i})
+		}
+	}
+}
+
 // createNetwork instantiate all the channels and create the pubsubs
 func createNetwork(ns []*node, g *gorgonia.ExprGraph) *pubsub {
 	ids := make(map[int64]*node, len(ns))
@@ -93,18 +128,81 @@ func createNetwork(ns []*node, g *gorgonia.ExprGraph) *pubsub {
 	return ps
 }
 
-// Run the computation
+// Run the computation.
+//
+// Once it completes, the results of the ops are bound to the nodes of the graph, so that they can be read with Value().
+// This includes the gradients of a graph differentiated with gorgonia.Grad: they are computed concurrently with the rest
+// of the graph, and the learnables can then be given to any gorgonia.Solver as ValueGrads.
+// The inputs are read again at every run, so the machine can be run in a training loop.
+//
+// When ctx is done, or the timeout of WithTimeout expires, no new op is started and Run returns once the running ops
+// have returned or timed out. The errors of the nodes are reported with their IDs and names.
+// The ops that a run gives up on keep running in the background: the next run waits for them to return before it starts.
 func (m *Machine) Run(ctx context.Context) error {
-	cancel, wg := m.pubsub.run(ctx)
-	err := m.runAllNodes(ctx)
-	cancel()
-	// wait for the infrastructure to settle
-	wg.Wait()
-	return err
+	if m.closed {
+		return errors.New("The machine is closed")
+	}
+	if m.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.timeout)
+		defer cancel()
+	}
+	if err := m.waitAbandoned(ctx); err != nil {
+		return err
+	}
+
+	var err error
+	if m.workers > 0 {
+		err = m.runPool(ctx)
+	} else {
+		cancel, wg := m.pubsub.run(ctx)
+		err = m.runAllNodes(ctx)
+		cancel()
+		// wait for the infrastructure to settle
+		wg.Wait()
+	}
+	if err != nil {
+		return err
+	}
+	return m.bindResults()
+}
+
+// waitAbandoned waits for the ops abandoned by the previous runs to return, as they may still read and write the values
+// of the nodes.
+func (m *Machine) waitAbandoned(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "The ops abandoned by the previous run are still running")
+	}
+}
+
+// bindResults binds the outputs of the ops to their nodes.
+// The gradient of a learnable is the value of its deriv node, so this is what makes Grad() work.
+func (m *Machine) bindResults() error {
+	for _, n := range m.nodes {
+		if n.op == nil || n.ref == nil || n.output == nil {
+			continue
+		}
+		if err := gorgonia.UnsafeLet(n.ref, n.output); err != nil {
+			return errors.Wrapf(err, "Unable to bind the result of %v", n.ref)
+		}
+	}
+	return nil
 }
 
 // Close all the plumbing to avoid leaking
 func (m *Machine) Close() {
+	if m.closed {
+		return
+	}
+	m.closed = true
 	allChans := make(map[chan<- gorgonia.Value]struct{}, 0)
 	for _, pub := range m.pubsub.publishers {
 		for i := range pub.subscribers {
@@ -125,9 +223,10 @@ func (m *Machine) Close() {
 }
 
 type nodeError struct {
-	id  int64
-	t   time.Time
-	err error
+	id   int64
+	name string
+	t    time.Time
+	err  error
 }
 
 type nodeErrors []nodeError
@@ -136,6 +235,11 @@ func (e nodeErrors) Error() string {
 	var sb strings.Builder
 	for _, e := range e {
 		sb.WriteString(strconv.Itoa(int(e.id)))
+		if e.name != "" {
+			sb.WriteString(" (")
+			sb.WriteString(e.name)
+			sb.WriteString(")")
+		}
 		sb.WriteString(":")
 		sb.WriteString(e.err.Error())
 		sb.WriteString("\n")
@@ -152,9 +256,10 @@ func (m *Machine) runAllNodes(ctx context.Context) error {
 		go func(n *node) {
 			err := n.Compute(ctx)
 			errC <- nodeError{
-				id:  n.id,
-				t:   time.Now(),
-				err: err,
+				id:   n.id,
+				name: n.name,
+				t:    time.Now(),
+				err:  err,
 			}
 		}(m.nodes[i])
 	}
@@ -569,6 +569,21 @@ func Test_nodeErrors_Error(t *testing.T) {
 			},
 			"0:error\n",
 		},
+		{
+			"named",
+			[]nodeError{
+				{
+					id:   1,
+					name: "x",
+					err:  errors.New("error"),
+				},
+				{
+					id:  2,
+					err: errors.New("error"),
+				},
+			},
+			"1 (x):error\n2:error\n",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -3,6 +3,9 @@ package xvm
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"gorgonia.org/gorgonia"
 )
@@ -14,13 +17,28 @@ type Doer interface {
 
 type node struct {
 	id             int64
+	name           string
+	ref            *gorgonia.Node // the node of the graph: inputs read their values from it, and the results of ops are bound to it
 	op             Doer
+	timeout        time.Duration   // the maximum duration of the op, if not zero
+	running        *sync.WaitGroup // the ops of the machine running on their own goroutines, see do
 	output         gorgonia.Value
 	outputC        chan gorgonia.Value
 	receivedValues int
 	err            error
 	inputValues    []gorgonia.Value
 	inputC         chan ioValue
+
+	// used when running on a pool of workers instead of channels
+	consumers []edge
+	pending   int32 // the number of inputs not yet computed
+	worker    int   // the worker running the node, for the traces
+}
+
+// edge is an input of a node: the node consumes the output of another node at the given position.
+type edge struct {
+	to  *node
+	pos int
 }
 
 // ioValue is a value with a position. as the infrastructure cannot guaranty the
@@ -37,6 +55,10 @@ func defaultState(_ context.Context, n *node) stateFn {
 	n.receivedValues = 0
 	n.err = nil
 	if n.op == nil {
+		// the value of an input may have changed since the last run, e.g. when a Solver updates the learnables
+		if n.ref != nil {
+			n.output = n.ref.Value()
+		}
 		return emitOutput
 	}
 	return receiveInput
@@ -66,8 +88,8 @@ func receiveInput(ctx context.Context, n *node) stateFn {
 	return computeFwd
 }
 
-func computeFwd(_ context.Context, n *node) stateFn {
-	v, err := n.op.Do(n.inputValues...)
+func computeFwd(ctx context.Context, n *node) stateFn {
+	v, err := n.do(ctx)
 	if err != nil {
 		n.err = err
 		return nil
@@ -76,6 +98,53 @@ func computeFwd(_ context.Context, n *node) stateFn {
 	return emitOutput
 }
 
+// do runs the op on the input values.
+// Ops cannot be interrupted: if a deadline is set, by the node timeout or by ctx, the op runs on its own goroutine so
+// that do returns an error once the deadline is exceeded, or ctx is done. The op is then abandoned: its result is
+// discarded once it returns, and the machine waits for it before the next run.
+// Without a deadline, the op runs on the calling goroutine.
+func (n *node) do(ctx context.Context) (gorgonia.Value, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, ok := ctx.Deadline(); !ok && n.timeout == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return n.op.Do(n.inputValues...)
+	}
+	type result struct {
+		v   gorgonia.Value
+		err error
+	}
+	resC := make(chan result, 1) // so that a late op does not block forever
+	if n.running != nil {
+		n.running.Add(1)
+	}
+	go func(inputs []gorgonia.Value) {
+		if n.running != nil {
+			defer n.running.Done()
+		}
+		v, err := n.op.Do(inputs...)
+		resC <- result{v, err}
+	}(append([]gorgonia.Value(nil), n.inputValues...))
+
+	var timeout <-chan time.Time
+	if n.timeout > 0 {
+		timer := time.NewTimer(n.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case res := <-resC:
+		return res.v, res.err
+	case <-timeout:
+		return nil, fmt.Errorf("timed out after %v", n.timeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 func emitOutput(ctx context.Context, n *node) stateFn {
 	if n == nil || n.outputC == nil {
 		return nil
@@ -111,10 +180,17 @@ func newOp(n *gorgonia.Node, hasOutputChan bool) *node {
 		outputC = make(chan gorgonia.Value, 0)
 
 	}
+	arity := n.Op().Arity()
+	if arity < 0 {
+		// variadic ops take all the children of the node
+		arity = n.Graph().From(n.ID()).Len()
+	}
 	return &node{
 		id:          n.ID(),
+		name:        n.Name(),
+		ref:         n,
 		op:          n.Op(),
-		inputValues: make([]gorgonia.Value, n.Op().Arity()),
+		inputValues: make([]gorgonia.Value, arity),
 		inputC:      make(chan ioValue, 0),
 		outputC:     outputC,
 	}
@@ -126,6 +202,8 @@ func newInput(n *gorgonia.Node) *node {
 	}
 	return &node{
 		id:      n.ID(),
+		name:    n.Name(),
+		ref:     n,
 		output:  n.Value(),
 		outputC: make(chan gorgonia.Value, 0),
 	}
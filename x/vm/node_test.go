@@ -4,7 +4,11 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"gorgonia.org/gorgonia"
 )
@@ -168,6 +172,61 @@ func Test_computeFwd(t *testing.T) {
 	}
 }
 
+// stackOp records the stack of the goroutine that runs it
+type stackOp struct {
+	stack string
+}
+
+func (op *stackOp) Do(...gorgonia.Value) (gorgonia.Value, error) {
+	buf := make([]byte, 1<<16)
+	op.stack = string(buf[:runtime.Stack(buf, false)])
+	return nil, nil
+}
+
+func Test_node_do(t *testing.T) {
+	var running sync.WaitGroup
+	cancelled, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	withDeadline, cancelDeadline := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelDeadline()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		timeout   time.Duration
+		goroutine bool
+	}{
+		{"no context", nil, 0, false},
+		{"context without deadline", cancelled, 0, false},
+		{"context with deadline", withDeadline, 0, true},
+		{"node timeout", cancelled, time.Minute, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := &stackOp{}
+			n := &node{op: op, timeout: tt.timeout, running: &running}
+			if _, err := n.do(tt.ctx); err != nil {
+				t.Fatal(err)
+			}
+			running.Wait()
+			if goroutine := strings.Contains(op.stack, "created by gorgonia.org/gorgonia/x/vm.(*node).do"); goroutine != tt.goroutine {
+				t.Errorf("expected the op to run on its own goroutine: %t. Stack:\n%s", tt.goroutine, op.stack)
+			}
+		})
+	}
+
+	// a cancelled context does not start the op
+	cancel()
+	op := &stackOp{}
+	n := &node{op: op, running: &running}
+	if _, err := n.do(cancelled); err != context.Canceled {
+		t.Errorf("expected %v. Got %v", context.Canceled, err)
+	}
+	if op.stack != "" {
+		t.Error("the op should not have run")
+	}
+}
+
 func Test_node_ComputeForward(t *testing.T) {
 	type fields struct {
 		op             gorgonia.Op
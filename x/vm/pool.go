@@ -0,0 +1,142 @@
+package xvm
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MachineOpt is an option of NewMachine
+type MachineOpt func(*Machine)
+
+// WithWorkers runs the ops on a pool of n workers instead of a goroutine per node.
+// A node is handed to a worker once all its inputs are computed, so no worker ever waits for another.
+// This bounds the number of goroutines, and of ops running at the same time, for large graphs.
+func WithWorkers(n int) MachineOpt {
+	return func(m *Machine) {
+		m.workers = n
+	}
+}
+
+// WithTimeout limits the duration of every run.
+func WithTimeout(d time.Duration) MachineOpt {
+	return func(m *Machine) {
+		m.timeout = d
+	}
+}
+
+// WithNodeTimeout limits the duration of every op. An op that takes longer fails its node, and the run with it.
+func WithNodeTimeout(d time.Duration) MachineOpt {
+	return func(m *Machine) {
+		m.nodeTimeout = d
+	}
+}
+
+// runPool runs the nodes on m.workers goroutines. The nodes are queued as soon as all their inputs are computed.
+func (m *Machine) runPool(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ready := make(chan *node, len(m.nodes))
+	for _, n := range m.nodes {
+		n.err = nil
+		n.receivedValues = 0
+		n.pending = int32(len(n.inputValues))
+		if n.pending == 0 {
+			ready <- n
+		}
+	}
+	remaining := int64(len(m.nodes))
+	done := make(chan struct{})
+	if remaining == 0 {
+		close(done)
+	}
+
+	var mu sync.Mutex
+	var errs nodeErrors
+	worker := func(wg *sync.WaitGroup, id int) {
+		defer wg.Done()
+		for {
+			// once the run is over, do not start another node, even if one is ready
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			default:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case n := <-ready:
+				n.worker = id
+				if err := n.runOnce(ctx); err != nil {
+					mu.Lock()
+					// the nodes cancelled because of another one's error are not reported
+					if err != context.Canceled || len(errs) == 0 {
+						errs = append(errs, nodeError{id: n.id, name: n.name, t: time.Now(), err: err})
+					}
+					mu.Unlock()
+					// failfast, on error, cancel
+					cancel()
+					return
+				}
+				for _, e := range n.consumers {
+					v := n.output
+					if len(n.consumers) > 1 {
+						v = view(v)
+					}
+					e.to.inputValues[e.pos] = v
+					if atomic.AddInt32(&e.to.pending, -1) == 0 {
+						ready <- e.to
+					}
+				}
+				if atomic.AddInt64(&remaining, -1) == 0 {
+					close(done)
+				}
+			}
+		}
+	}
+
+	workers := m.workers
+	if workers > len(m.nodes) {
+		workers = len(m.nodes)
+	}
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go worker(&wg, i+1)
+	}
+	wg.Wait()
+
+	if len(errs) != 0 {
+		return errs
+	}
+	if left := atomic.LoadInt64(&remaining); left > 0 {
+		return errors.Wrapf(ctx.Err(), "%d nodes were not run", left)
+	}
+	return nil
+}
+
+// runOnce computes a node whose inputs are all set, without going through the channels
+func (n *node) runOnce(ctx context.Context) error {
+	if n.op == nil {
+		if n.ref != nil {
+			n.output = n.ref.Value()
+		}
+		return nil
+	}
+	t := trace(ctx, nil, n, computeFwd)
+	defer trace(ctx, t, nil, nil)
+	v, err := n.do(ctx)
+	if err != nil {
+		return err
+	}
+	n.output = v
+	return nil
+}
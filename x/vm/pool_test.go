@@ -0,0 +1,214 @@
+package xvm
+
+import (
+	"context"
+	"fmt"
+	"hash"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// slowOp is an addition that takes its time, and keeps track of how many of its kind run at the same time
+type slowOp struct {
+	gorgonia.Op
+	id      int
+	sleep   *int64 // nanoseconds
+	running *int32
+	max     *int32
+}
+
+func (op *slowOp) Do(vs ...gorgonia.Value) (gorgonia.Value, error) {
+	r := atomic.AddInt32(op.running, 1)
+	defer atomic.AddInt32(op.running, -1)
+	for {
+		m := atomic.LoadInt32(op.max)
+		if r <= m || atomic.CompareAndSwapInt32(op.max, m, r) {
+			break
+		}
+	}
+	time.Sleep(time.Duration(atomic.LoadInt64(op.sleep)))
+	return op.Op.Do(vs...)
+}
+
+func (op *slowOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "slow%d", op.id) }
+func (op *slowOp) Hashcode() uint32      { return uint32(op.id) }
+func (op *slowOp) String() string        { return fmt.Sprintf("slow%d", op.id) }
+
+type slowGraph struct {
+	g       *gorgonia.ExprGraph
+	outputs gorgonia.Nodes
+	sleep   int64
+	running int32
+	max     int32
+}
+
+// newSlowGraph builds n additions of a and b that each sleep for d
+func newSlowGraph(t *testing.T, n int, d time.Duration) *slowGraph {
+	tmp := gorgonia.NewGraph()
+	add := gorgonia.Must(gorgonia.Add(gorgonia.NewScalar(tmp, tensor.Float64), gorgonia.NewScalar(tmp, tensor.Float64)))
+	sg := &slowGraph{g: gorgonia.NewGraph(), sleep: int64(d)}
+	a := gorgonia.NewScalar(sg.g, tensor.Float64, gorgonia.WithName("a"), gorgonia.WithValue(1.0))
+	b := gorgonia.NewScalar(sg.g, tensor.Float64, gorgonia.WithName("b"), gorgonia.WithValue(2.0))
+	for i := 0; i < n; i++ {
+		op := &slowOp{Op: add.Op(), id: i, sleep: &sg.sleep, running: &sg.running, max: &sg.max}
+		out, err := gorgonia.ApplyOp(op, a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sg.outputs = append(sg.outputs, out)
+	}
+	return sg
+}
+
+func (sg *slowGraph) check(t *testing.T) {
+	t.Helper()
+	for _, out := range sg.outputs {
+		if v := out.Value().Data().(float64); v != 3 {
+			t.Fatalf("%v: expected 3, got %v", out, v)
+		}
+	}
+}
+
+func TestMachine_Workers(t *testing.T) {
+	sg := newSlowGraph(t, 50, time.Millisecond)
+	m := NewMachine(sg.g, WithWorkers(4))
+	defer m.Close()
+	// the machine is reused
+	for i := 0; i < 2; i++ {
+		if err := m.Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		sg.check(t)
+	}
+	if sg.max > 4 || sg.max < 1 {
+		t.Errorf("expected at most 4 ops at the same time, got %d", sg.max)
+	}
+	if len(sg.outputs) != 50 || sg.outputs[0] == sg.outputs[1] {
+		t.Fatalf("the slow ops should be distinct nodes")
+	}
+}
+
+func TestMachine_WorkersGrad(t *testing.T) {
+	g, _, w, cost := model(t)
+	m := NewMachine(g)
+	defer m.Close()
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	wantCost := cost.Value().Data().(float64)
+	wantGrad, _ := w.Grad()
+
+	g, _, w, cost = model(t)
+	pool := NewMachine(g, WithWorkers(3))
+	defer pool.Close()
+	for i := 0; i < 3; i++ {
+		if err := pool.Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		assertClose(t, "cost", []float64{wantCost}, []float64{cost.Value().Data().(float64)})
+		grad, err := w.Grad()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertClose(t, "grad", wantGrad.Data().([]float64), grad.Data().([]float64))
+	}
+}
+
+func TestMachine_Timeouts(t *testing.T) {
+	for _, workers := range []int{0, 2} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			sg := newSlowGraph(t, 1, time.Second)
+			m := NewMachine(sg.g, WithWorkers(workers), WithNodeTimeout(20*time.Millisecond))
+			defer m.Close()
+			start := time.Now()
+			err := m.Run(context.Background())
+			if err == nil {
+				t.Fatal("expected the node to time out")
+			}
+			if time.Since(start) > 500*time.Millisecond {
+				t.Errorf("the run should not wait for the op, it took %v", time.Since(start))
+			}
+			id := sg.outputs[0].ID()
+			if want := fmt.Sprintf("%d (%s):timed out after 20ms", id, sg.outputs[0].Name()); !strings.Contains(err.Error(), want) {
+				t.Errorf("expected %q in the error. Got %q", want, err)
+			}
+
+			// once the op is fast again, the machine can be run again
+			atomic.StoreInt64(&sg.sleep, 0)
+			if err := m.Run(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			sg.check(t)
+
+			atomic.StoreInt64(&sg.sleep, int64(time.Second))
+			m.timeout, m.nodeTimeout = 20*time.Millisecond, 0
+			for _, n := range m.nodes {
+				n.timeout = 0
+			}
+			start = time.Now()
+			err = m.Run(context.Background())
+			if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+				t.Errorf("expected the run to time out. Got %v", err)
+			}
+			if time.Since(start) > 500*time.Millisecond {
+				t.Errorf("the run should not wait for the op, it took %v", time.Since(start))
+			}
+		})
+	}
+}
+
+func TestMachine_AbandonedOps(t *testing.T) {
+	for _, workers := range []int{0, 2} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			sg := newSlowGraph(t, 1, 200*time.Millisecond)
+			m := NewMachine(sg.g, WithWorkers(workers), WithNodeTimeout(20*time.Millisecond))
+			defer m.Close()
+			if err := m.Run(context.Background()); err == nil {
+				t.Fatal("expected the node to time out")
+			}
+
+			// the next run waits for the abandoned op, so that the op never runs twice at the same time
+			atomic.StoreInt64(&sg.sleep, 0)
+			if err := m.Run(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			sg.check(t)
+			if max := atomic.LoadInt32(&sg.max); max != 1 {
+				t.Errorf("expected the op to run once at a time. It ran %d times at the same time", max)
+			}
+
+			// unless the run times out first
+			atomic.StoreInt64(&sg.sleep, int64(200*time.Millisecond))
+			if err := m.Run(context.Background()); err == nil {
+				t.Fatal("expected the node to time out")
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			if err := m.Run(ctx); err == nil || !strings.Contains(err.Error(), "abandoned") {
+				t.Errorf("expected the run to time out waiting for the abandoned op. Got %v", err)
+			}
+		})
+	}
+}
+
+func TestMachine_Cancel(t *testing.T) {
+	sg := newSlowGraph(t, 8, 20*time.Millisecond)
+	m := NewMachine(sg.g, WithWorkers(1))
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(30*time.Millisecond, cancel)
+	err := m.Run(ctx)
+	if err == nil || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Errorf("expected the run to be cancelled. Got %v", err)
+	}
+
+	m.Close()
+	m.Close()
+	if err := m.Run(context.Background()); err == nil {
+		t.Error("expected an error when running a closed machine")
+	}
+}
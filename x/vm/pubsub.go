@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
 )
 
 type publisher struct {
@@ -73,8 +74,12 @@ func broadcast(ctx context.Context, globalWG *sync.WaitGroup, ch <-chan gorgonia
 		select {
 		case msg := <-ch:
 			for _, c := range cs {
+				v := msg
+				if len(cs) > 1 {
+					v = view(msg)
+				}
 				select {
-				case c <- msg:
+				case c <- v:
 				case <-ctx.Done():
 					return
 				}
@@ -84,3 +89,13 @@ func broadcast(ctx context.Context, globalWG *sync.WaitGroup, ch <-chan gorgonia
 		}
 	}
 }
+
+// view returns a tensor that shares the data of v, but not its shape and strides.
+// Some ops transpose their inputs in place before restoring them (e.g. the matrix multiplications of the gradients),
+// so the consumers of a value that is broadcast to several of them each need their own view.
+func view(v gorgonia.Value) gorgonia.Value {
+	if t, ok := v.(*tensor.Dense); ok {
+		return t.ShallowClone()
+	}
+	return v
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
 )
 
 func Test_merge(t *testing.T) {
@@ -125,6 +126,33 @@ func Test_broadcast(t *testing.T) {
 			t.Errorf("broadcast want %v, got %v", &fortyTwo, v1)
 		}
 	})
+	t.Run("broadcast a tensor to several subscribers", func(t *testing.T) {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		cs0 := make(chan gorgonia.Value, 0)
+		cs1 := make(chan gorgonia.Value, 0)
+		c := make(chan gorgonia.Value, 0)
+		go broadcast(ctx, &wg, c, cs0, cs1)
+		v := tensor.New(tensor.WithShape(2, 3), tensor.WithBacking([]float32{1, 2, 3, 4, 5, 6}))
+		c <- v
+		v0 := (<-cs0).(*tensor.Dense)
+		v1 := (<-cs1).(*tensor.Dense)
+		if v0 == v || v1 == v || v0 == v1 {
+			t.Fatal("every subscriber should get its own view of the tensor")
+		}
+		// transposing a view in place does not change the others
+		if err := v0.T(); err != nil {
+			t.Fatal(err)
+		}
+		if !v1.Shape().Eq(tensor.Shape{2, 3}) || !v.Shape().Eq(tensor.Shape{2, 3}) {
+			t.Errorf("broadcast views share their shapes: %v and %v", v1.Shape(), v.Shape())
+		}
+		if &v1.Data().([]float32)[0] != &v.Data().([]float32)[0] {
+			t.Error("broadcast views should share the data")
+		}
+	})
 }
 
 func Test_pubsub_run(t *testing.T) {
@@ -12,6 +12,7 @@ type Trace struct {
 	//fmt.Println(runtime.FuncForPC(reflect.ValueOf(state).Pointer()).Name())
 	StateFunction string
 	ID            int64
+	Worker        int `json:",omitempty"` // the worker of the pool that ran the node, counting from 1, or 0 if the node has its own goroutine
 	Start         time.Time
 	End           time.Time `json:",omitempty"`
 }
@@ -57,6 +58,7 @@ func trace(ctx context.Context, t *Trace, n *node, state stateFn) *Trace {
 	if t == nil {
 		t = &Trace{
 			ID:            n.id,
+			Worker:        n.worker,
 			StateFunction: runtime.FuncForPC(reflect.ValueOf(state).Pointer()).Name(),
 			Start:         now(),
 		}